@@ -2,21 +2,62 @@ package main
 
 import (
 	"context"
+	"errors"
+	"flag"
 	"fmt"
 	"os"
 	"os/signal"
+	"path/filepath"
 	"syscall"
+	"text/tabwriter"
 	"time"
 
 	"steri-connect-go/internal/api"
+	// Device families register their adapters with internal/adapters
+	_ "steri-connect-go/internal/adapters/getinge"
+	_ "steri-connect-go/internal/adapters/melag"
 	"steri-connect-go/internal/api/middleware"
 	"steri-connect-go/internal/config"
 	"steri-connect-go/internal/database"
 	"steri-connect-go/internal/devices"
 	"steri-connect-go/internal/logging"
+	"steri-connect-go/internal/simulator"
 )
 
+// resolveSecretKeyFile returns the secret key file to use
+// Older versions created the key next to the database; such a key keeps being used until
+// it is moved, because a new key could not decrypt the stored secrets.
+func resolveSecretKeyFile(cfg config.DatabaseConfig) string {
+	logger := logging.Get()
+	if cfg.SecretKey != "" {
+		return cfg.SecretKeyFile
+	}
+
+	keyFile := cfg.SecretKeyFile
+	dbDir := filepath.Dir(cfg.Path)
+	legacyKeyFile := filepath.Join(dbDir, "secret.key")
+	if _, err := os.Stat(keyFile); os.IsNotExist(err) {
+		if _, err := os.Stat(legacyKeyFile); err == nil {
+			logger.Warn("Using secret key next to the database, move it to the configured location",
+				"key_file", legacyKeyFile, "secret_key_file", keyFile)
+			keyFile = legacyKeyFile
+		}
+	}
+
+	keyDir, err1 := filepath.Abs(filepath.Dir(keyFile))
+	dbDir, err2 := filepath.Abs(dbDir)
+	if err1 == nil && err2 == nil && keyDir == dbDir {
+		logger.Warn("Secret key is stored in the database directory; anyone with a copy of the directory can decrypt device passwords and recompute the audit log hashes",
+			"key_file", keyFile)
+	}
+	return keyFile
+}
+
 func main() {
+	simulate := flag.Bool("simulate", false, "Run built-in Melag and Getinge device simulators and connect all devices to them")
+	migrateStatus := flag.Bool("migrate-status", false, "Print the state of the database schema migrations and exit")
+	flag.Parse()
+
 	// Load configuration
 	cfg, err := config.Load("config/config.yaml")
 	if err != nil {
@@ -24,6 +65,10 @@ func main() {
 		os.Exit(1)
 	}
 
+	if *migrateStatus {
+		os.Exit(printMigrationStatus(cfg.Database.Path))
+	}
+
 	// Initialize logger with config
 	logConfig := logging.Config{
 		Level:         cfg.Logging.Level,
@@ -57,13 +102,41 @@ func main() {
 
 	logger.Info("Database initialized successfully")
 
+	// Load the key used to encrypt device passwords at rest
+	secretKeyFile := resolveSecretKeyFile(cfg.Database)
+	if err := database.InitSecretKey(cfg.Database.SecretKey, secretKeyFile); err != nil {
+		logger.Error("Failed to load secret key", "error", err)
+		os.Exit(1)
+	}
+
 	// Initialize device manager
 	deviceManager := devices.NewManager()
 	devices.SetManager(deviceManager) // Set as global manager for API handlers
+
+	// Start device simulator and point all adapters at it
+	if *simulate {
+		// The simulated washer reports its cycles over Modbus TCP
+		cfg.Devices.Getinge.Modbus.Enabled = true
+
+		sim := simulator.New(cfg.Simulator)
+		if err := sim.Start(); err != nil {
+			logger.Error("Failed to start device simulator", "error", err)
+			os.Exit(1)
+		}
+		defer sim.Stop()
+
+		deviceManager.SetEndpointResolver(sim.Endpoint)
+		if err := sim.SeedDevices(); err != nil {
+			logger.Warn("Failed to create simulated devices", "error", err)
+		}
+		logger.Warn("Simulation mode: all devices are connected to the built-in simulator")
+	}
+
 	if err := deviceManager.LoadDevices(); err != nil {
 		logger.Warn("Failed to load devices", "error", err)
 		// Continue anyway - devices can be added later
 	}
+	deviceManager.StartCycleWatchdog()
 	defer deviceManager.Shutdown()
 
 	logger.Info("Device manager initialized")
@@ -119,3 +192,52 @@ func main() {
 		logger.Info("Server shut down gracefully")
 	}
 }
+
+// printMigrationStatus prints the applied and pending schema migrations without changing the database
+// Returns the exit code: 1 if the database cannot be started by this binary.
+func printMigrationStatus(dbPath string) int {
+	if err := database.OpenDatabaseReadOnly(dbPath); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			fmt.Printf("Database %s does not exist; it is created with all migrations on the first start.\n", dbPath)
+			return 0
+		}
+		fmt.Printf("Failed to open database: %v\n", err)
+		return 1
+	}
+	defer database.Close()
+
+	states, err := database.MigrationStatus()
+	if err != nil {
+		fmt.Printf("Failed to read migration status: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("Database: %s\n\n", dbPath)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tSTATE\tAPPLIED AT")
+	exitCode, pending := 0, 0
+	for _, state := range states {
+		appliedAt := "-"
+		if state.AppliedAt != nil {
+			appliedAt = state.AppliedAt.Local().Format("2006-01-02 15:04:05")
+		}
+		fmt.Fprintf(w, "%03d\t%s\t%s\t%s\n", state.Version, state.Name, state.State, appliedAt)
+		switch state.State {
+		case database.MigrationPending:
+			pending++
+		case database.MigrationModified, database.MigrationUnknown:
+			exitCode = 1
+		}
+	}
+	w.Flush()
+
+	fmt.Println()
+	if exitCode != 0 {
+		fmt.Println("The database schema does not match this version; the server will refuse to start.")
+	} else if pending > 0 {
+		fmt.Printf("%d pending migration(s) will be applied on the next start.\n", pending)
+	} else {
+		fmt.Println("The database schema is up to date.")
+	}
+	return exitCode
+}
@@ -0,0 +1,121 @@
+package adapters
+
+import (
+	"context"
+	"time"
+)
+
+// Optional interfaces implemented by adapters in addition to DeviceAdapter.
+// The manager and API check for them together with the declared capabilities.
+
+// CycleController is implemented by adapters with full remote cycle control
+type CycleController interface {
+	CycleStarter
+	CycleStatusReader
+}
+
+// CycleStarter is implemented by adapters that can start a cycle (CapabilityCycleStart)
+type CycleStarter interface {
+	StartCycle(params CycleStartParams) error
+}
+
+// CycleStatusReader is implemented by adapters that report the running cycle (CapabilityCycleStatus)
+type CycleStatusReader interface {
+	GetCycleStatus() (CycleStatus, error)
+}
+
+// CycleResumer is implemented by status readers that only report what the device writes after
+// they connected. ResumeCycle makes the adapter report a cycle that was running before the
+// service restarted, including status and result the device wrote in the meantime.
+// cycleNumber is the device's cycle number if known, startedAt the start of the cycle.
+type CycleResumer interface {
+	ResumeCycle(cycleNumber string, startedAt time.Time)
+}
+
+// ReachabilityProber is implemented by adapters monitored by periodic probes (CapabilityReachability)
+// Probe returns an error only if the probe could not be sent; an unanswered probe is
+// a result with Reachable false.
+type ReachabilityProber interface {
+	Probe() (ProbeResult, error)
+	SetLastPing(t *time.Time)
+	SetLastReachable(reachable bool)
+	SetConnectionState(state ConnectionState)
+}
+
+// ProtocolArchiveImporter is implemented by adapters that can import historical
+// cycle protocols from the device (CapabilityProtocolImport)
+type ProtocolArchiveImporter interface {
+	ImportProtocolArchive(ctx context.Context, onProgress func(ImportProgress)) (ImportProgress, error)
+}
+
+// DeviceInfoProvider is implemented by adapters reporting model, serial and firmware (CapabilityDeviceInfo)
+type DeviceInfoProvider interface {
+	GetDeviceInfo() (DeviceInfo, error)
+}
+
+// HealthChecker is implemented by adapters holding a session that can drop without notice
+// (e.g. an FTP control connection). CheckHealth verifies the session; after a failure the
+// adapter is no longer connected and must be connected again.
+type HealthChecker interface {
+	CheckHealth() error
+}
+
+// Supports reports whether the adapter implements the interface behind a capability
+func Supports(adapter DeviceAdapter, capability Capability) bool {
+	var ok bool
+	switch capability {
+	case CapabilityCycleStart:
+		_, ok = adapter.(CycleStarter)
+	case CapabilityCycleStatus:
+		_, ok = adapter.(CycleStatusReader)
+	case CapabilityReachability:
+		_, ok = adapter.(ReachabilityProber)
+	case CapabilityProtocolImport:
+		_, ok = adapter.(ProtocolArchiveImporter)
+	case CapabilityDeviceInfo:
+		_, ok = adapter.(DeviceInfoProvider)
+	}
+	return ok
+}
+
+// Methods used for reachability probes
+const (
+	ProbeMethodICMP    = "icmp"     // Unprivileged datagram ICMP echo
+	ProbeMethodICMPRaw = "icmp_raw" // ICMP echo over a raw socket
+	ProbeMethodTCP     = "tcp"      // TCP connect to a known port
+)
+
+// ProbeResult is the outcome of a single reachability probe
+type ProbeResult struct {
+	Reachable bool
+	Method    string        // One of the ProbeMethod constants
+	Sent      int           // Echo requests (or connection attempts) sent
+	Received  int           // Answers received
+	RTT       time.Duration // Average round-trip time of the answers (0 without answer)
+}
+
+// PacketLoss returns the share of unanswered requests in percent
+func (r ProbeResult) PacketLoss() float64 {
+	if r.Sent == 0 {
+		return 100
+	}
+	return float64(r.Sent-r.Received) * 100 / float64(r.Sent)
+}
+
+// DeviceInfo describes the device as reported by the device itself
+type DeviceInfo struct {
+	Manufacturer string `json:"manufacturer"`
+	Model        string `json:"model,omitempty"`
+	SerialNumber string `json:"serial_number,omitempty"`
+	Firmware     string `json:"firmware,omitempty"`
+}
+
+// ImportProgress reports the state of a protocol archive import
+type ImportProgress struct {
+	Total      int `json:"total"`      // Protocol files found in the archive
+	Processed  int `json:"processed"`  // Files handled so far
+	Imported   int `json:"imported"`   // New cycles written to the database
+	Duplicates int `json:"duplicates"` // Cycles already present (same device cycle number)
+	Skipped    int `json:"skipped"`    // Protocols without result (cycle still running)
+	Failed     int `json:"failed"`     // Unreadable or incomplete protocol files
+}
@@ -56,6 +56,13 @@ type CycleStatus struct {
 	Pressure        *float64      `json:"pressure,omitempty"`
 	TimeRemaining   *time.Duration `json:"time_remaining,omitempty"` // Time remaining
 	IsRunning       bool          `json:"is_running"` // true if cycle is actively running
+	Result          string        `json:"result,omitempty"` // "OK" or "NOK" once the cycle has finished
+	ErrorCode       string        `json:"error_code,omitempty"`
 	Error           string        `json:"error,omitempty"`
+	CycleNumber     string        `json:"cycle_number,omitempty"` // Cycle number assigned by the device
+	Program         string        `json:"program,omitempty"` // Program reported by the device (device-initiated cycles)
+	A0Value         *float64      `json:"a0_value,omitempty"` // Disinfection A0 value (washer-disinfectors)
+	MeasuredAt      *time.Time    `json:"measured_at,omitempty"` // Time of the reading on the device clock, nil if the device reports none
+	Stale           bool          `json:"stale,omitempty"` // No new reading since the previous call; the last known status is repeated
 }
 
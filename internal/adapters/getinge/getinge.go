@@ -1,8 +1,10 @@
 package getinge
 
 import (
+	"errors"
 	"fmt"
 	"net"
+	"strconv"
 	"sync"
 	"time"
 
@@ -21,6 +23,9 @@ type GetingeAdapter struct {
 	logger      *logging.Logger
 	lastPing    *time.Time
 	lastReachable bool
+	probeAddress string // Fixed host:port checked by TCP instead of ICMP (e.g. device simulator)
+	probeOptions ProbeOptions
+	icmpWarning  sync.Once // Logs the TCP fallback once per adapter
 }
 
 // NewGetingeAdapter creates a new Getinge adapter instance
@@ -40,6 +45,10 @@ func NewGetingeAdapter(device *database.Device, pingTimeout time.Duration) (*Get
 		state:       adapters.StateDisconnected,
 		pingTimeout: pingTimeout,
 		logger:      logger,
+		probeOptions: ProbeOptions{
+			Method: adapters.ProbeMethodICMP,
+			Count:  3,
+		},
 	}
 
 	return adapter, nil
@@ -120,16 +129,6 @@ func (a *GetingeAdapter) GetStatus() (database.DeviceStatus, error) {
 	}, nil
 }
 
-// StartCycle is not implemented for Getinge devices in MVP
-func (a *GetingeAdapter) StartCycle(params adapters.CycleStartParams) error {
-	return fmt.Errorf("start cycle not supported for Getinge devices in MVP")
-}
-
-// GetCycleStatus is not implemented for Getinge devices in MVP
-func (a *GetingeAdapter) GetCycleStatus() (adapters.CycleStatus, error) {
-	return adapters.CycleStatus{}, fmt.Errorf("cycle status not supported for Getinge devices in MVP")
-}
-
 // GetDeviceID returns the device ID
 func (a *GetingeAdapter) GetDeviceID() int {
 	return a.deviceID
@@ -152,46 +151,110 @@ func (a *GetingeAdapter) SetConnectionState(state adapters.ConnectionState) {
 	a.setState(state)
 }
 
-// Ping performs a network reachability check to the device IP address
-// Uses TCP connection attempt as a proxy for ICMP ping (Windows-compatible)
+// ProbeOptions configures how Ping and Probe check reachability
+type ProbeOptions struct {
+	Method      string // adapters.ProbeMethodICMP (echo request) or adapters.ProbeMethodTCP
+	Count       int    // Echo requests per probe
+	TCPFallback bool   // Probe TCPPorts when ICMP sockets are not permitted
+	TCPPorts    []int
+}
+
+// SetProbeOptions changes the probe method; the default is ICMP echo without TCP fallback
+func (a *GetingeAdapter) SetProbeOptions(opts ProbeOptions) {
+	a.stateMutex.Lock()
+	defer a.stateMutex.Unlock()
+	a.probeOptions = opts
+}
+
+// SetProbeAddress makes Ping check only the given host:port (e.g. for the device simulator)
+func (a *GetingeAdapter) SetProbeAddress(addr string) {
+	a.stateMutex.Lock()
+	defer a.stateMutex.Unlock()
+	a.probeAddress = addr
+}
+
+// Ping checks whether the device is reachable
+// Returns an error if the device did not answer or the probe could not be sent.
 func (a *GetingeAdapter) Ping() (bool, error) {
-	// Try to connect to a common port (port 80 or 443) to check reachability
-	// This is a simple reachability test that works on Windows without raw sockets
+	result, err := a.Probe()
+	if err != nil {
+		return false, err
+	}
+	if !result.Reachable {
+		return false, fmt.Errorf("device unreachable (%s, %d of %d answered)", result.Method, result.Received, result.Sent)
+	}
+	return true, nil
+}
+
+// Probe sends ICMP echo requests to the device and reports round-trip time and packet loss
+// A TCP connect to known ports is only used if configured as method or as fallback
+// when the system permits no ICMP sockets.
+func (a *GetingeAdapter) Probe() (adapters.ProbeResult, error) {
 	timeout := a.pingTimeout
 	if timeout == 0 {
 		timeout = 5 * time.Second
 	}
 
-	// Try connecting to port 80 (HTTP) or 443 (HTTPS) as a reachability test
-	addresses := []string{
-		net.JoinHostPort(a.device.IP, "80"),
-		net.JoinHostPort(a.device.IP, "443"),
-		net.JoinHostPort(a.device.IP, "22"), // SSH
+	a.stateMutex.RLock()
+	probeAddress := a.probeAddress
+	opts := a.probeOptions
+	a.stateMutex.RUnlock()
+
+	if probeAddress != "" {
+		return tcpProbe([]string{probeAddress}, timeout), nil
 	}
 
-	for _, addr := range addresses {
-		conn, err := net.DialTimeout("tcp", addr, timeout)
-		if err == nil {
-			conn.Close()
-			return true, nil
-		}
+	if opts.Method == adapters.ProbeMethodTCP {
+		return tcpProbe(a.tcpAddresses(opts.TCPPorts), timeout), nil
+	}
+
+	ip := net.ParseIP(a.device.IP)
+	if ip == nil {
+		return adapters.ProbeResult{}, fmt.Errorf("invalid device IP address: %s", a.device.IP)
+	}
+	count := opts.Count
+	if count < 1 {
+		count = 3
 	}
 
-	// If all TCP attempts failed, try a simple ICMP-like check using UDP
-	// This is a fallback for devices that don't respond to TCP
-	udpConn, err := net.DialTimeout("udp", net.JoinHostPort(a.device.IP, "53"), timeout)
-	if err == nil {
-		udpConn.Close()
-		return true, nil
+	result, err := icmpEcho(ip, count, timeout)
+	if errors.Is(err, ErrICMPUnavailable) && opts.TCPFallback {
+		a.icmpWarning.Do(func() {
+			a.logger.Warn("ICMP not permitted, probing TCP ports instead",
+				"device_id", a.deviceID,
+				"error", err)
+		})
+		return tcpProbe(a.tcpAddresses(opts.TCPPorts), timeout), nil
 	}
+	return result, err
+}
 
-	// Final check: try to resolve the IP address (basic connectivity)
-	_, err = net.LookupAddr(a.device.IP)
-	if err == nil {
-		return true, nil
+// tcpAddresses returns host:port of the device for the given ports
+func (a *GetingeAdapter) tcpAddresses(ports []int) []string {
+	addresses := make([]string, len(ports))
+	for i, port := range ports {
+		addresses[i] = net.JoinHostPort(a.device.IP, strconv.Itoa(port))
 	}
+	return addresses
+}
 
-	return false, fmt.Errorf("device unreachable via TCP/UDP checks")
+// tcpProbe tries the addresses in turn; the device is reachable if one accepts a connection
+// A refused connection also proves reachability but cannot be told apart from a
+// filtered port on every platform, so only accepted connections count.
+func tcpProbe(addresses []string, timeout time.Duration) adapters.ProbeResult {
+	result := adapters.ProbeResult{Method: adapters.ProbeMethodTCP, Sent: 1}
+	for _, addr := range addresses {
+		start := time.Now()
+		conn, err := net.DialTimeout("tcp", addr, timeout)
+		if err == nil {
+			result.RTT = time.Since(start)
+			conn.Close()
+			result.Reachable = true
+			result.Received = 1
+			return result
+		}
+	}
+	return result
 }
 
 // GetLastPing returns the last ping time
@@ -0,0 +1,65 @@
+package getinge
+
+import (
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"steri-connect-go/internal/adapters"
+	"steri-connect-go/internal/database"
+)
+
+func newTestAdapter(t *testing.T, ip string) *GetingeAdapter {
+	t.Helper()
+	adapter, err := NewGetingeAdapter(&database.Device{ID: 1, Name: "RDG", Manufacturer: "Getinge", IP: ip}, 500*time.Millisecond)
+	if err != nil {
+		t.Fatalf("Failed to create adapter: %v", err)
+	}
+	return adapter
+}
+
+func TestProbeICMPLoopback(t *testing.T) {
+	adapter := newTestAdapter(t, "127.0.0.1")
+
+	result, err := adapter.Probe()
+	if errors.Is(err, ErrICMPUnavailable) {
+		t.Skipf("ICMP not permitted in this environment: %v", err)
+	}
+	if err != nil {
+		t.Fatalf("Probe failed: %v", err)
+	}
+	if !result.Reachable || result.Sent != 3 || result.Received != 3 || result.PacketLoss() != 0 {
+		t.Errorf("Expected 3 of 3 echo replies from loopback, got %+v", result)
+	}
+	if result.Method != adapters.ProbeMethodICMP && result.Method != adapters.ProbeMethodICMPRaw {
+		t.Errorf("Expected ICMP probe method, got %s", result.Method)
+	}
+}
+
+func TestProbeTCP(t *testing.T) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("Failed to listen: %v", err)
+	}
+	port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close() // Closed port first: refused connections do not count as reachable
+
+	adapter := newTestAdapter(t, "127.0.0.1")
+	adapter.SetProbeOptions(ProbeOptions{Method: adapters.ProbeMethodTCP, TCPPorts: []int{port}})
+
+	if result, err := adapter.Probe(); err != nil || result.Reachable {
+		t.Errorf("Expected closed port to be unreachable, got %+v, %v", result, err)
+	}
+
+	listener, err = net.Listen("tcp", listener.Addr().String())
+	if err != nil {
+		t.Fatalf("Failed to listen again: %v", err)
+	}
+	defer listener.Close()
+
+	result, err := adapter.Probe()
+	if err != nil || !result.Reachable || result.Method != adapters.ProbeMethodTCP {
+		t.Errorf("Expected open port to be reachable over TCP, got %+v, %v", result, err)
+	}
+}
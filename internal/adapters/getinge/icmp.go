@@ -0,0 +1,177 @@
+package getinge
+
+import (
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/net/icmp"
+	"golang.org/x/net/ipv4"
+	"golang.org/x/net/ipv6"
+
+	"steri-connect-go/internal/adapters"
+)
+
+// Spacing between the echo requests of one probe
+const echoSpacing = 200 * time.Millisecond
+
+var (
+	ErrICMPUnavailable = errors.New("ICMP sockets not permitted")
+)
+
+// echoSequence numbers echo requests across probes, so replies to an earlier
+// (timed out) probe are not counted for the next one
+var echoSequence atomic.Uint32
+
+// icmpEndpoint describes the socket types available for one IP version
+type icmpEndpoint struct {
+	protocol    int // IANA protocol number used to parse replies
+	listenAddr  string
+	datagram    string // Unprivileged datagram socket (Linux: net.ipv4.ping_group_range)
+	raw         string // Raw socket (root or CAP_NET_RAW)
+	requestType icmp.Type
+	replyType   icmp.Type
+}
+
+var (
+	icmpV4 = icmpEndpoint{
+		protocol:    1,
+		listenAddr:  "0.0.0.0",
+		datagram:    "udp4",
+		raw:         "ip4:icmp",
+		requestType: ipv4.ICMPTypeEcho,
+		replyType:   ipv4.ICMPTypeEchoReply,
+	}
+	icmpV6 = icmpEndpoint{
+		protocol:    58,
+		listenAddr:  "::",
+		datagram:    "udp6",
+		raw:         "ip6:ipv6-icmp",
+		requestType: ipv6.ICMPTypeEchoRequest,
+		replyType:   ipv6.ICMPTypeEchoReply,
+	}
+)
+
+// icmpEcho sends count echo requests to ip and collects the replies until all are
+// answered or timeout has passed since the first request.
+// An unprivileged datagram socket is preferred; a raw socket is used if the system
+// does not allow those. ErrICMPUnavailable is returned if neither can be opened.
+func icmpEcho(ip net.IP, count int, timeout time.Duration) (adapters.ProbeResult, error) {
+	endpoint := icmpV4
+	if ip.To4() == nil {
+		endpoint = icmpV6
+	}
+
+	method := adapters.ProbeMethodICMP
+	conn, err := icmp.ListenPacket(endpoint.datagram, endpoint.listenAddr)
+	if err != nil {
+		datagramErr := err
+		method = adapters.ProbeMethodICMPRaw
+		conn, err = icmp.ListenPacket(endpoint.raw, endpoint.listenAddr)
+		if err != nil {
+			return adapters.ProbeResult{}, fmt.Errorf("%w: %v; %v", ErrICMPUnavailable, datagramErr, err)
+		}
+	}
+	defer conn.Close()
+
+	// Datagram sockets get the identifier assigned by the kernel and only receive
+	// their own replies; raw sockets see every echo reply and are matched by identifier
+	var target net.Addr = &net.UDPAddr{IP: ip}
+	id := 0
+	if method == adapters.ProbeMethodICMPRaw {
+		target = &net.IPAddr{IP: ip}
+		id = rand.IntN(0xffff) + 1
+	}
+
+	result := adapters.ProbeResult{Method: method}
+	sentAt := make(map[int]time.Time, count)
+	var totalRTT time.Duration
+	deadline := time.Now().Add(timeout)
+	buffer := make([]byte, 1500)
+
+	// receive reads replies until the given time or until all requests are answered
+	receive := func(until time.Time) error {
+		for result.Received < count {
+			if err := conn.SetReadDeadline(until); err != nil {
+				return fmt.Errorf("failed to set read deadline: %w", err)
+			}
+			n, peer, err := conn.ReadFrom(buffer)
+			if err != nil {
+				var netErr net.Error
+				if errors.As(err, &netErr) && netErr.Timeout() {
+					return nil
+				}
+				return fmt.Errorf("failed to read echo reply: %w", err)
+			}
+
+			message, err := icmp.ParseMessage(endpoint.protocol, buffer[:n])
+			if err != nil || message.Type != endpoint.replyType {
+				continue
+			}
+			echo, ok := message.Body.(*icmp.Echo)
+			if !ok || (id != 0 && echo.ID != id) || !sameHost(peer, ip) {
+				continue
+			}
+			sent, pending := sentAt[echo.Seq]
+			if !pending {
+				continue // Duplicate or reply to an earlier probe
+			}
+			delete(sentAt, echo.Seq)
+			result.Received++
+			totalRTT += time.Since(sent)
+		}
+		return nil
+	}
+
+	var sendErr error
+	written := 0
+	for i := 0; i < count && time.Now().Before(deadline); i++ {
+		seq := int(echoSequence.Add(1) & 0xffff)
+		request := icmp.Message{
+			Type: endpoint.requestType,
+			Body: &icmp.Echo{ID: id, Seq: seq, Data: []byte("steri-connect")},
+		}
+		packet, err := request.Marshal(nil)
+		if err != nil {
+			return result, fmt.Errorf("failed to build echo request: %w", err)
+		}
+		result.Sent++
+		if _, err := conn.WriteTo(packet, target); err != nil {
+			sendErr = err // e.g. no route to host, counted as lost
+		} else {
+			sentAt[seq] = time.Now()
+			written++
+		}
+
+		until := time.Now().Add(echoSpacing)
+		if i == count-1 || until.After(deadline) {
+			until = deadline
+		}
+		if err := receive(until); err != nil {
+			return result, err
+		}
+	}
+
+	if written == 0 && sendErr != nil {
+		return result, fmt.Errorf("failed to send echo request: %w", sendErr)
+	}
+	if result.Received > 0 {
+		result.Reachable = true
+		result.RTT = totalRTT / time.Duration(result.Received)
+	}
+	return result, nil
+}
+
+// sameHost reports whether a reply came from ip
+func sameHost(peer net.Addr, ip net.IP) bool {
+	switch addr := peer.(type) {
+	case *net.UDPAddr:
+		return addr.IP.Equal(ip)
+	case *net.IPAddr:
+		return addr.IP.Equal(ip)
+	}
+	return false
+}
@@ -0,0 +1,159 @@
+package getinge
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"sync"
+	"time"
+)
+
+// Modbus function codes and limits used by the status interface
+const (
+	modbusReadHoldingRegisters = 0x03
+	modbusMaxRegisters         = 125 // Maximum quantity per read request
+	modbusHeaderLength         = 7   // MBAP header: transaction, protocol, length, unit
+)
+
+var (
+	ErrModbusException = errors.New("modbus exception response")
+)
+
+// modbusExceptionMessages describes the standard Modbus exception codes
+var modbusExceptionMessages = map[byte]string{
+	0x01: "illegal function",
+	0x02: "illegal data address",
+	0x03: "illegal data value",
+	0x04: "server device failure",
+	0x06: "server device busy",
+	0x0B: "gateway target device failed to respond",
+}
+
+// modbusClient is a minimal Modbus TCP client for reading holding registers
+// The connection is opened on first use and dropped after any error, so the
+// next request reconnects.
+type modbusClient struct {
+	address string
+	unitID  byte
+	timeout time.Duration
+
+	mu            sync.Mutex
+	conn          net.Conn
+	transactionID uint16
+}
+
+// newModbusClient creates a client for the Modbus TCP server at address (host:port)
+func newModbusClient(address string, unitID byte, timeout time.Duration) *modbusClient {
+	if timeout <= 0 {
+		timeout = 3 * time.Second
+	}
+	return &modbusClient{
+		address: address,
+		unitID:  unitID,
+		timeout: timeout,
+	}
+}
+
+// ReadHoldingRegisters reads quantity registers starting at address (function code 3)
+func (c *modbusClient) ReadHoldingRegisters(address, quantity uint16) ([]uint16, error) {
+	if quantity == 0 || quantity > modbusMaxRegisters {
+		return nil, fmt.Errorf("invalid register quantity %d (must be 1-%d)", quantity, modbusMaxRegisters)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == nil {
+		conn, err := net.DialTimeout("tcp", c.address, c.timeout)
+		if err != nil {
+			return nil, fmt.Errorf("failed to connect to Modbus server %s: %w", c.address, err)
+		}
+		c.conn = conn
+	}
+
+	values, err := c.readHoldingRegisters(address, quantity)
+	if err != nil && !errors.Is(err, ErrModbusException) {
+		// Framing is lost after I/O errors, start over with a fresh connection
+		c.conn.Close()
+		c.conn = nil
+	}
+	return values, err
+}
+
+// Close closes the connection (the next request reconnects)
+func (c *modbusClient) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.conn == nil {
+		return nil
+	}
+	err := c.conn.Close()
+	c.conn = nil
+	return err
+}
+
+// readHoldingRegisters performs one request/response exchange on the open connection
+func (c *modbusClient) readHoldingRegisters(address, quantity uint16) ([]uint16, error) {
+	c.transactionID++
+	transactionID := c.transactionID
+
+	request := make([]byte, modbusHeaderLength+5)
+	binary.BigEndian.PutUint16(request[0:], transactionID)
+	binary.BigEndian.PutUint16(request[2:], 0) // Protocol identifier (always 0)
+	binary.BigEndian.PutUint16(request[4:], 6) // Unit identifier + PDU
+	request[6] = c.unitID
+	request[7] = modbusReadHoldingRegisters
+	binary.BigEndian.PutUint16(request[8:], address)
+	binary.BigEndian.PutUint16(request[10:], quantity)
+
+	if err := c.conn.SetDeadline(time.Now().Add(c.timeout)); err != nil {
+		return nil, fmt.Errorf("failed to set deadline: %w", err)
+	}
+	if _, err := c.conn.Write(request); err != nil {
+		return nil, fmt.Errorf("failed to send Modbus request: %w", err)
+	}
+
+	header := make([]byte, modbusHeaderLength)
+	if _, err := io.ReadFull(c.conn, header); err != nil {
+		return nil, fmt.Errorf("failed to read Modbus response header: %w", err)
+	}
+	length := binary.BigEndian.Uint16(header[4:])
+	if length < 2 || length > 256 {
+		return nil, fmt.Errorf("invalid Modbus response length %d", length)
+	}
+	pdu := make([]byte, length-1)
+	if _, err := io.ReadFull(c.conn, pdu); err != nil {
+		return nil, fmt.Errorf("failed to read Modbus response: %w", err)
+	}
+
+	if got := binary.BigEndian.Uint16(header[0:]); got != transactionID {
+		return nil, fmt.Errorf("unexpected Modbus transaction ID %d (expected %d)", got, transactionID)
+	}
+
+	functionCode := pdu[0]
+	if functionCode == modbusReadHoldingRegisters|0x80 {
+		code := pdu[1]
+		message, known := modbusExceptionMessages[code]
+		if !known {
+			message = "unknown exception"
+		}
+		return nil, fmt.Errorf("%w: code %d (%s)", ErrModbusException, code, message)
+	}
+	if functionCode != modbusReadHoldingRegisters {
+		return nil, fmt.Errorf("unexpected Modbus function code %d", functionCode)
+	}
+
+	byteCount := int(pdu[1])
+	if byteCount != int(quantity)*2 || len(pdu) < 2+byteCount {
+		return nil, fmt.Errorf("unexpected Modbus byte count %d for %d registers", byteCount, quantity)
+	}
+
+	values := make([]uint16, quantity)
+	for i := range values {
+		values[i] = binary.BigEndian.Uint16(pdu[2+2*i:])
+	}
+	return values, nil
+}
@@ -0,0 +1,90 @@
+package getinge
+
+import (
+	"net"
+	"strconv"
+	"time"
+
+	"steri-connect-go/internal/adapters"
+	"steri-connect-go/internal/config"
+	"steri-connect-go/internal/database"
+)
+
+func init() {
+	adapters.Register(adapters.Registration{
+		Manufacturer: "Getinge",
+		Capabilities: []adapters.Capability{
+			adapters.CapabilityReachability,
+			adapters.CapabilityCycleStatus, // Only with devices.getinge.modbus enabled
+		},
+		New: newAdapter,
+	})
+}
+
+// newAdapter creates a Getinge adapter using the configured ping timeout
+// With Modbus enabled the adapter also reads cycle status from the washer.
+func newAdapter(device *database.Device, opts adapters.FactoryOptions) (adapters.DeviceAdapter, error) {
+	cfg := config.Get().Devices.Getinge
+	pingTimeout := time.Duration(cfg.PingTimeout) * time.Second
+	if pingTimeout == 0 {
+		pingTimeout = 5 * time.Second // Default
+	}
+
+	if !cfg.Modbus.Enabled {
+		adapter, err := NewGetingeAdapter(device, pingTimeout)
+		if err != nil {
+			return nil, err
+		}
+		adapter.SetProbeOptions(probeOptions(cfg))
+		if opts.Endpoint != "" {
+			adapter.SetProbeAddress(opts.Endpoint)
+		}
+		return adapter, nil
+	}
+
+	settings := modbusSettings(cfg.Modbus)
+	settings.Address = net.JoinHostPort(device.IP, strconv.Itoa(cfg.Modbus.Port))
+	if opts.Endpoint != "" {
+		settings.Address = opts.Endpoint
+	}
+
+	adapter, err := NewGetingeModbusAdapter(device, pingTimeout, settings)
+	if err != nil {
+		return nil, err
+	}
+	adapter.SetProbeOptions(probeOptions(cfg))
+	if opts.Endpoint != "" {
+		adapter.SetProbeAddress(opts.Endpoint)
+	}
+	return adapter, nil
+}
+
+// probeOptions converts the reachability probe configuration
+func probeOptions(cfg config.GetingeConfig) ProbeOptions {
+	return ProbeOptions{
+		Method:      cfg.ProbeMethod,
+		Count:       cfg.ProbeCount,
+		TCPFallback: cfg.TCPFallback,
+		TCPPorts:    cfg.TCPPorts,
+	}
+}
+
+// modbusSettings converts the Modbus configuration (without address)
+func modbusSettings(cfg config.GetingeModbusConfig) ModbusSettings {
+	return ModbusSettings{
+		UnitID:  byte(cfg.UnitID),
+		Timeout: time.Duration(cfg.Timeout) * time.Second,
+		Registers: RegisterMap{
+			Program:     uint16(cfg.Registers.Program),
+			Phase:       uint16(cfg.Registers.Phase),
+			Temperature: uint16(cfg.Registers.Temperature),
+			A0Value:     uint16(cfg.Registers.A0Value),
+			Result:      uint16(cfg.Registers.Result),
+			ErrorCode:   uint16(cfg.Registers.ErrorCode),
+		},
+		TemperatureScale: cfg.TemperatureScale,
+		A0Scale:          cfg.A0Scale,
+		Phases:           cfg.Phases,
+		Programs:         cfg.Programs,
+	}
+}
@@ -0,0 +1,172 @@
+package getinge
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"time"
+
+	"steri-connect-go/internal/adapters"
+	"steri-connect-go/internal/database"
+)
+
+// Values of the result register
+const (
+	resultNone   = 0 // Idle or cycle running
+	resultPassed = 1
+	resultFailed = 2
+)
+
+// RegisterMap holds the holding register addresses (0-based) of the washer status values
+type RegisterMap struct {
+	Program     uint16
+	Phase       uint16
+	Temperature uint16 // Signed, multiplied by ModbusSettings.TemperatureScale
+	A0Value     uint16 // Multiplied by ModbusSettings.A0Scale
+	Result      uint16 // 0 = none/running, 1 = passed, 2 = failed
+	ErrorCode   uint16
+}
+
+// span returns the first register and the number of registers covering the whole map
+func (m RegisterMap) span() (uint16, int) {
+	registers := []uint16{m.Program, m.Phase, m.Temperature, m.A0Value, m.Result, m.ErrorCode}
+	low, high := registers[0], registers[0]
+	for _, register := range registers {
+		if register < low {
+			low = register
+		}
+		if register > high {
+			high = register
+		}
+	}
+	return low, int(high-low) + 1
+}
+
+// ModbusSettings configures the Modbus TCP status interface of a washer-disinfector
+type ModbusSettings struct {
+	Address          string // host:port of the Modbus TCP server
+	UnitID           byte
+	Timeout          time.Duration
+	Registers        RegisterMap
+	TemperatureScale float64
+	A0Scale          float64
+	Phases           map[int]string // Phase register value -> name; 0 is always idle
+	Programs         map[int]string // Program register value -> name
+}
+
+// GetingeModbusAdapter reads washer-disinfector cycle status over Modbus TCP
+// Reachability is still monitored by ping; cycles are started at the device and
+// only observed, so the adapter implements CycleStatusReader but not CycleStarter.
+type GetingeModbusAdapter struct {
+	*GetingeAdapter
+	settings ModbusSettings
+	client   *modbusClient
+}
+
+// NewGetingeModbusAdapter creates a Getinge adapter that reads cycle status over Modbus TCP
+func NewGetingeModbusAdapter(device *database.Device, pingTimeout time.Duration, settings ModbusSettings) (*GetingeModbusAdapter, error) {
+	base, err := NewGetingeAdapter(device, pingTimeout)
+	if err != nil {
+		return nil, err
+	}
+	if settings.Address == "" {
+		return nil, fmt.Errorf("modbus address cannot be empty")
+	}
+	if _, count := settings.Registers.span(); count > modbusMaxRegisters {
+		return nil, fmt.Errorf("modbus registers span %d addresses (must be within %d)", count, modbusMaxRegisters)
+	}
+	if settings.TemperatureScale == 0 {
+		settings.TemperatureScale = 0.1
+	}
+	if settings.A0Scale == 0 {
+		settings.A0Scale = 1
+	}
+
+	return &GetingeModbusAdapter{
+		GetingeAdapter: base,
+		settings:       settings,
+		client:         newModbusClient(settings.Address, settings.UnitID, settings.Timeout),
+	}, nil
+}
+
+// Disconnect closes the Modbus connection and marks the device as disconnected
+func (a *GetingeModbusAdapter) Disconnect() error {
+	if err := a.client.Close(); err != nil {
+		a.logger.Warn("Error closing Modbus connection",
+			"device_id", a.deviceID,
+			"error", err)
+	}
+	return a.GetingeAdapter.Disconnect()
+}
+
+// GetCycleStatus reads the status registers and maps them to a cycle status
+// A finished cycle reports COMPLETED or FAILED until the next program starts.
+func (a *GetingeModbusAdapter) GetCycleStatus() (adapters.CycleStatus, error) {
+	start, count := a.settings.Registers.span()
+	values, err := a.client.ReadHoldingRegisters(start, uint16(count))
+	if err != nil {
+		return adapters.CycleStatus{}, fmt.Errorf("failed to read status registers: %w", err)
+	}
+	register := func(address uint16) uint16 {
+		return values[address-start]
+	}
+
+	regs := a.settings.Registers
+	temperature := scale(float64(int16(register(regs.Temperature))), a.settings.TemperatureScale)
+	a0Value := scale(float64(register(regs.A0Value)), a.settings.A0Scale)
+
+	status := adapters.CycleStatus{
+		ProgressPercent: -1, // The washer does not report progress
+		Temperature:     &temperature,
+		A0Value:         &a0Value,
+		Program:         a.programName(int(register(regs.Program))),
+	}
+
+	phase := int(register(regs.Phase))
+	switch result := register(regs.Result); {
+	case result == resultPassed:
+		status.Phase = "COMPLETED"
+		status.Result = "OK"
+		status.ProgressPercent = 100
+	case result == resultFailed:
+		status.Phase = "FAILED"
+		status.Result = "NOK"
+		if errorCode := register(regs.ErrorCode); errorCode != 0 {
+			status.ErrorCode = strconv.Itoa(int(errorCode))
+			status.Error = fmt.Sprintf("Getinge error %d", errorCode)
+		}
+	case result != resultNone:
+		return adapters.CycleStatus{}, fmt.Errorf("unexpected result register value %d", result)
+	case phase == 0:
+		status.Phase = "IDLE"
+	default:
+		status.Phase = a.phaseName(phase)
+		status.IsRunning = true
+	}
+
+	return status, nil
+}
+
+// phaseName maps a phase register value to its configured name
+func (a *GetingeModbusAdapter) phaseName(phase int) string {
+	if name, ok := a.settings.Phases[phase]; ok && name != "" {
+		return name
+	}
+	return fmt.Sprintf("Phase %d", phase)
+}
+
+// programName maps a program register value to its configured name
+func (a *GetingeModbusAdapter) programName(program int) string {
+	if program == 0 {
+		return ""
+	}
+	if name, ok := a.settings.Programs[program]; ok && name != "" {
+		return name
+	}
+	return fmt.Sprintf("Programm %d", program)
+}
+
+// scale applies a register factor, dropping floating point noise (519 * 0.1 = 51.9)
+func scale(raw, factor float64) float64 {
+	return math.Round(raw*factor*1e6) / 1e6
+}
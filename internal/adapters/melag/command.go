@@ -0,0 +1,121 @@
+package melag
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"steri-connect-go/internal/adapters"
+)
+
+// Cycle start over the MELAnet Box works by file exchange. A start request
+// (*.req) is uploaded to the command directory; the box answers with an
+// acknowledgement file of the same base name (*.ack) once the autoclave has
+// accepted or refused the request.
+//
+// Start request:
+//
+//	Befehl: START
+//	Auftrag: START20261016081502
+//	Programm: Universal-Programm
+//	Temperatur: 134.0
+//	Druck: 2.10
+//	Dauer: 5
+//
+// Acknowledgement:
+//
+//	Status: OK | ABGELEHNT | TUER_OFFEN | BELEGT
+//	Fehler: 12 Programm unbekannt
+
+// Command file extensions used for the start handshake
+const (
+	requestFileExt = ".req"
+	ackFileExt     = ".ack"
+)
+
+// Errors returned by StartCycle when the device refuses the start request
+var (
+	ErrProgramRejected = errors.New("program rejected by device")
+	ErrDoorOpen        = errors.New("device door is open")
+	ErrDeviceBusy      = errors.New("device is busy")
+	ErrAckTimeout      = errors.New("no acknowledgement from device")
+)
+
+// buildStartRequest renders the start request file for the given parameters
+func buildStartRequest(jobID string, params adapters.CycleStartParams) []byte {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Befehl: START\r\n")
+	fmt.Fprintf(&b, "Auftrag: %s\r\n", jobID)
+	if params.Program != "" {
+		fmt.Fprintf(&b, "Programm: %s\r\n", params.Program)
+	}
+	if params.Temperature != nil {
+		fmt.Fprintf(&b, "Temperatur: %s\r\n", strconv.FormatFloat(*params.Temperature, 'f', 1, 64))
+	}
+	if params.Pressure != nil {
+		fmt.Fprintf(&b, "Druck: %s\r\n", strconv.FormatFloat(*params.Pressure, 'f', 2, 64))
+	}
+	if params.Duration != nil {
+		fmt.Fprintf(&b, "Dauer: %d\r\n", *params.Duration)
+	}
+	return []byte(b.String())
+}
+
+// startJobID returns the base name for the request and acknowledgement files
+func startJobID(t time.Time) string {
+	return "START" + t.Format("20060102150405")
+}
+
+// parseStartAck interprets an acknowledgement file; nil means the start was accepted
+func parseStartAck(r io.Reader) error {
+	var status, code, description string
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff")), ":")
+		if !ok {
+			continue
+		}
+		value = strings.TrimSpace(value)
+		switch strings.ToLower(strings.TrimSpace(key)) {
+		case "status", "quittung", "ack":
+			status = value
+		case "fehler", "error":
+			code, description = parseError(value)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return fmt.Errorf("failed to read acknowledgement: %w", err)
+	}
+
+	detail := description
+	if code != "" {
+		detail = strings.TrimSpace(code + " " + description)
+	}
+
+	normalized := strings.ToUpper(strings.NewReplacer("Ü", "UE", "ü", "UE", " ", "_", "-", "_").Replace(status))
+	var err error
+	switch {
+	case normalized == "":
+		return fmt.Errorf("acknowledgement without status")
+	case normalized == "OK", normalized == "ANGENOMMEN", normalized == "ACCEPTED":
+		return nil
+	case strings.Contains(normalized, "TUER"), strings.Contains(normalized, "DOOR"):
+		err = ErrDoorOpen
+	case strings.Contains(normalized, "BELEGT"), strings.Contains(normalized, "BUSY"):
+		err = ErrDeviceBusy
+	case strings.Contains(normalized, "ABGELEHNT"), strings.Contains(normalized, "REJECT"):
+		err = ErrProgramRejected
+	default:
+		return fmt.Errorf("unexpected acknowledgement status %q", status)
+	}
+
+	if detail != "" {
+		return fmt.Errorf("%w: %s", err, detail)
+	}
+	return err
+}
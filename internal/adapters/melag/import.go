@@ -0,0 +1,137 @@
+package melag
+
+import (
+	"context"
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+
+	"github.com/jlaffaye/ftp"
+
+	"steri-connect-go/internal/adapters"
+	"steri-connect-go/internal/database"
+)
+
+// ImportProtocolArchive walks the protocol archive on the MELAnet Box and stores every
+// completed protocol as a cycle. Cycles already present with the same device cycle
+// number are left untouched, so the import can be repeated safely. onProgress is
+// called after each file; the import stops early when ctx is cancelled.
+func (a *MelagAdapter) ImportProtocolArchive(ctx context.Context, onProgress func(adapters.ImportProgress)) (adapters.ImportProgress, error) {
+	var progress adapters.ImportProgress
+
+	a.stateMutex.RLock()
+	if a.ftpClient == nil || a.state != adapters.StateConnected {
+		a.stateMutex.RUnlock()
+		return progress, fmt.Errorf("device is not connected")
+	}
+	ftpClient := a.ftpClient
+	a.stateMutex.RUnlock()
+
+	a.ftpMutex.Lock()
+	files, err := a.listArchive(ftpClient)
+	a.ftpMutex.Unlock()
+	if err != nil {
+		return progress, fmt.Errorf("failed to list protocol archive: %w", err)
+	}
+	progress.Total = len(files)
+
+	a.logger.Info("Importing protocol archive",
+		"device_id", a.deviceID,
+		"archive_dir", a.archiveDir,
+		"files", progress.Total)
+
+	for _, filePath := range files {
+		if err := ctx.Err(); err != nil {
+			return progress, err
+		}
+
+		// Lock per file so cycle status polling is not blocked for the whole import
+		a.ftpMutex.Lock()
+		protocol, err := a.downloadProtocol(ftpClient, filePath)
+		a.ftpMutex.Unlock()
+
+		progress.Processed++
+		switch {
+		case err != nil:
+			progress.Failed++
+			a.logger.Warn("Skipping unreadable protocol file",
+				"device_id", a.deviceID,
+				"file", filePath,
+				"error", err)
+		case !protocol.IsCompleted():
+			progress.Skipped++
+		default:
+			cycle, err := protocol.ToCycle(a.deviceID)
+			if err != nil {
+				progress.Failed++
+				a.logger.Warn("Skipping incomplete protocol file",
+					"device_id", a.deviceID,
+					"file", filePath,
+					"error", err)
+				break
+			}
+
+			imported, err := database.ImportCycle(cycle)
+			if err != nil {
+				return progress, fmt.Errorf("failed to store protocol %s: %w", filePath, err)
+			}
+			if imported {
+				progress.Imported++
+			} else {
+				progress.Duplicates++
+			}
+		}
+
+		if onProgress != nil {
+			onProgress(progress)
+		}
+	}
+
+	a.logger.Info("Protocol archive import finished",
+		"device_id", a.deviceID,
+		"imported", progress.Imported,
+		"duplicates", progress.Duplicates,
+		"skipped", progress.Skipped,
+		"failed", progress.Failed)
+
+	return progress, nil
+}
+
+// listArchive returns the paths of all protocol files below archiveDir, oldest first
+// Status files are left out, they only describe the running cycle. The caller must hold ftpMutex.
+func (a *MelagAdapter) listArchive(ftpClient *ftp.ServerConn) ([]string, error) {
+	type archiveFile struct {
+		path  string
+		entry *ftp.Entry
+	}
+
+	var files []archiveFile
+	walker := ftpClient.Walk(a.archiveDir)
+	for walker.Next() {
+		entry := walker.Stat()
+		if entry.Type != ftp.EntryTypeFile || !IsProtocolFileName(entry.Name) {
+			continue
+		}
+		if strings.EqualFold(path.Ext(entry.Name), statusFileExt) {
+			continue
+		}
+		files = append(files, archiveFile{path: walker.Path(), entry: entry})
+	}
+	if err := walker.Err(); err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(files, func(i, j int) bool {
+		if files[i].entry.Time.Equal(files[j].entry.Time) {
+			return files[i].path < files[j].path
+		}
+		return files[i].entry.Time.Before(files[j].entry.Time)
+	})
+
+	paths := make([]string, len(files))
+	for i, file := range files {
+		paths[i] = file.path
+	}
+	return paths, nil
+}
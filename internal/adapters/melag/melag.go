@@ -1,13 +1,22 @@
 package melag
 
 import (
+	"bytes"
+	"crypto/tls"
+	"errors"
 	"fmt"
+	"net"
+	"net/textproto"
+	"path"
+	"sort"
+	"strconv"
 	"sync"
 	"time"
 
 	"github.com/jlaffaye/ftp"
 
 	"steri-connect-go/internal/adapters"
+	"steri-connect-go/internal/config"
 	"steri-connect-go/internal/database"
 	"steri-connect-go/internal/logging"
 )
@@ -23,7 +32,55 @@ type MelagAdapter struct {
 	ftpUsername  string
 	ftpPassword  string
 	ftpTimeout   time.Duration
+	ftpDisableEPSV bool        // PASV only (FTPModePASV)
+	tlsConfig    *tls.Config  // nil = plain FTP
+	tlsImplicit  bool         // TLS from connect instead of AUTH TLS
 	logger       *logging.Logger
+
+	// ftpMutex serializes commands on ftpClient (ftp.ServerConn is not safe for concurrent use)
+	ftpMutex      sync.Mutex
+	protocolDir   string
+	commandDir    string
+	archiveDir    string
+	ackTimeout    time.Duration
+	ackPollInterval time.Duration
+	consumedFiles map[string]fileStamp // protocol files already read, keyed by path
+	lastStatus    *adapters.CycleStatus
+	resumed       *resumedCycle // cycle followed again after a restart (see ResumeCycle)
+	reportedInfo  adapters.DeviceInfo  // model/serial/firmware from the latest protocol file
+}
+
+// fileStamp identifies a version of a protocol file on the MELAnet Box
+type fileStamp struct {
+	size    uint64
+	modTime time.Time
+}
+
+// resumeClockTolerance is the difference allowed between the box and the service clock
+// when deciding whether a file was written after a resumed cycle started
+const resumeClockTolerance = 5 * time.Minute
+
+// resumedCycle identifies a cycle that was running before the service restarted
+type resumedCycle struct {
+	cycleNumber string // Device cycle number, "" until known
+	startedAt   time.Time
+}
+
+// writtenSince reports whether a file may have been written for the cycle
+func (r *resumedCycle) writtenSince(modTime time.Time) bool {
+	return !modTime.Before(r.startedAt.Add(-resumeClockTolerance))
+}
+
+// matches reports whether a protocol belongs to the cycle, by cycle number if known,
+// else by start time
+func (r *resumedCycle) matches(protocol *ProtocolFile) bool {
+	if r.cycleNumber != "" {
+		return protocol.DeviceCycleNumber() == r.cycleNumber
+	}
+	if protocol.StartTime != nil {
+		return r.writtenSince(*protocol.StartTime)
+	}
+	return true
 }
 
 // NewMelagAdapter creates a new Melag adapter instance
@@ -37,10 +94,28 @@ func NewMelagAdapter(device *database.Device) (*MelagAdapter, error) {
 
 	logger := logging.Get()
 
-	// Default FTP credentials (can be configured later)
+	// Default FTP credentials, overridden by per-device connection settings (ApplyConnectionSettings)
 	ftpUsername := "melanet"
 	ftpPassword := "melanet"
 
+	melagConfig := config.Get().Devices.Melag
+	protocolDir := melagConfig.ProtocolDir
+	if protocolDir == "" {
+		protocolDir = "/"
+	}
+	commandDir := melagConfig.CommandDir
+	if commandDir == "" {
+		commandDir = protocolDir
+	}
+	archiveDir := melagConfig.ArchiveDir
+	if archiveDir == "" {
+		archiveDir = protocolDir
+	}
+	ackTimeout := time.Duration(melagConfig.AckTimeout) * time.Second
+	if ackTimeout <= 0 {
+		ackTimeout = 10 * time.Second
+	}
+
 	adapter := &MelagAdapter{
 		deviceID:    device.ID,
 		device:      device,
@@ -50,6 +125,11 @@ func NewMelagAdapter(device *database.Device) (*MelagAdapter, error) {
 		ftpPassword: ftpPassword,
 		ftpTimeout:  10 * time.Second,
 		logger:      logger,
+		protocolDir: protocolDir,
+		commandDir:  commandDir,
+		archiveDir:  archiveDir,
+		ackTimeout:  ackTimeout,
+		ackPollInterval: time.Second,
 	}
 
 	return adapter, nil
@@ -66,7 +146,7 @@ func (a *MelagAdapter) Connect() error {
 		"host", a.ftpHost)
 
 	// Create FTP connection
-	conn, err := ftp.Dial(a.ftpHost, ftp.DialWithTimeout(a.ftpTimeout))
+	conn, err := ftp.Dial(a.ftpHost, a.dialOptions()...)
 	if err != nil {
 		errorMsg := fmt.Sprintf("Failed to connect to FTP server: %v", err)
 		a.setStateWithError(adapters.StateError, errorMsg)
@@ -89,6 +169,19 @@ func (a *MelagAdapter) Connect() error {
 		return fmt.Errorf("ftp authentication failed: %w", err)
 	}
 
+	// Files already on the box belong to earlier cycles; remember them once so
+	// only files written from now on are reported as cycle status
+	a.ftpMutex.Lock()
+	if a.consumedFiles == nil {
+		if err := a.seedConsumedFiles(conn); err != nil {
+			a.logger.Warn("Failed to list existing protocol files",
+				"device_id", a.deviceID,
+				"protocol_dir", a.protocolDir,
+				"error", err)
+		}
+	}
+	a.ftpMutex.Unlock()
+
 	// Store connection
 	a.stateMutex.Lock()
 	a.ftpClient = conn
@@ -141,6 +234,59 @@ func (a *MelagAdapter) IsConnected() bool {
 	return a.state == adapters.StateConnected && a.ftpClient != nil
 }
 
+// CheckHealth sends a NOOP on the FTP control connection to detect sessions the box dropped
+// silently. A session that fails or does not answer within the FTP timeout is closed and the
+// adapter changes to ERROR.
+func (a *MelagAdapter) CheckHealth() error {
+	a.stateMutex.RLock()
+	if a.ftpClient == nil || a.state != adapters.StateConnected {
+		a.stateMutex.RUnlock()
+		return fmt.Errorf("device is not connected")
+	}
+	ftpClient := a.ftpClient
+	timeout := a.ftpTimeout
+	a.stateMutex.RUnlock()
+
+	a.ftpMutex.Lock()
+	defer a.ftpMutex.Unlock()
+
+	result := make(chan error, 1)
+	go func() {
+		result <- ftpClient.NoOp()
+	}()
+
+	var err error
+	closed := false
+	select {
+	case err = <-result:
+	case <-time.After(timeout):
+		// Closing the connection ends the pending NOOP
+		ftpClient.Quit()
+		<-result
+		closed = true
+		err = fmt.Errorf("no answer within %s", timeout)
+	}
+	if err == nil {
+		return nil
+	}
+
+	// Keep a session opened since the check started (Disconnect/Connect)
+	a.stateMutex.Lock()
+	current := a.ftpClient == ftpClient
+	if current {
+		if !closed {
+			ftpClient.Quit()
+		}
+		a.ftpClient = nil
+	}
+	a.stateMutex.Unlock()
+	if current {
+		a.setStateWithError(adapters.StateError, fmt.Sprintf("FTP session lost: %v", err))
+	}
+
+	return fmt.Errorf("ftp health check failed: %w", err)
+}
+
 // GetDeviceID returns the device ID
 func (a *MelagAdapter) GetDeviceID() int {
 	return a.deviceID
@@ -153,6 +299,98 @@ func (a *MelagAdapter) GetConnectionState() adapters.ConnectionState {
 	return a.state
 }
 
+// SetEndpoint overrides the FTP host:port used by the next Connect (e.g. for the device simulator)
+func (a *MelagAdapter) SetEndpoint(addr string) {
+	a.stateMutex.Lock()
+	defer a.stateMutex.Unlock()
+	a.ftpHost = addr
+}
+
+// ApplyConnectionSettings configures port, credentials, timeout, FTP mode and TLS
+// from the device's stored settings. Takes effect on the next Connect.
+func (a *MelagAdapter) ApplyConnectionSettings(settings *database.DeviceConnectionSettings) error {
+	if settings == nil {
+		return nil
+	}
+
+	password, err := settings.Password()
+	if err != nil {
+		return fmt.Errorf("failed to read device password: %w", err)
+	}
+
+	var tlsConfig *tls.Config
+	tlsImplicit := false
+	switch settings.TLSMode {
+	case "", database.TLSModeNone:
+	case database.TLSModeExplicit, database.TLSModeImplicit:
+		tlsConfig = &tls.Config{
+			ServerName:         a.device.IP,
+			InsecureSkipVerify: settings.TLSSkipVerify, // MELAnet Boxes typically use self-signed certificates
+			MinVersion:         tls.VersionTLS12,
+		}
+		tlsImplicit = settings.TLSMode == database.TLSModeImplicit
+	default:
+		return fmt.Errorf("unsupported TLS mode: %s", settings.TLSMode)
+	}
+
+	disableEPSV := false
+	switch settings.FTPMode {
+	case "", database.FTPModePassive:
+	case database.FTPModePASV:
+		disableEPSV = true
+	case database.FTPModeActive:
+		return fmt.Errorf("active FTP mode is not supported")
+	default:
+		return fmt.Errorf("unsupported FTP mode: %s", settings.FTPMode)
+	}
+
+	port := settings.Port
+	if port == 0 {
+		port = 21
+		if tlsImplicit {
+			port = 990
+		}
+	}
+
+	a.stateMutex.Lock()
+	defer a.stateMutex.Unlock()
+
+	a.ftpHost = net.JoinHostPort(a.device.IP, strconv.Itoa(port))
+	if settings.Username != "" {
+		a.ftpUsername = settings.Username
+	}
+	if password != "" {
+		a.ftpPassword = password
+	}
+	if settings.TimeoutSeconds > 0 {
+		a.ftpTimeout = time.Duration(settings.TimeoutSeconds) * time.Second
+	}
+	a.ftpDisableEPSV = disableEPSV
+	a.tlsConfig = tlsConfig
+	a.tlsImplicit = tlsImplicit
+
+	return nil
+}
+
+// dialOptions builds the FTP dial options for the configured connection settings
+func (a *MelagAdapter) dialOptions() []ftp.DialOption {
+	a.stateMutex.RLock()
+	defer a.stateMutex.RUnlock()
+
+	options := []ftp.DialOption{
+		ftp.DialWithTimeout(a.ftpTimeout),
+		ftp.DialWithDisabledEPSV(a.ftpDisableEPSV),
+	}
+	if a.tlsConfig != nil {
+		if a.tlsImplicit {
+			options = append(options, ftp.DialWithTLS(a.tlsConfig))
+		} else {
+			options = append(options, ftp.DialWithExplicitTLS(a.tlsConfig))
+		}
+	}
+	return options
+}
+
 // GetFTPClient returns the FTP client (for internal use)
 func (a *MelagAdapter) GetFTPClient() *ftp.ServerConn {
 	a.stateMutex.RLock()
@@ -184,8 +422,9 @@ func (a *MelagAdapter) setStateWithError(state adapters.ConnectionState, errorMs
 }
 
 // StartCycle starts a sterilization cycle on the Melag device
-// Note: This is an MVP implementation with placeholder for actual FTP protocol command
-// The actual protocol format will need to be determined from MELAnet Box documentation
+// A start request file is uploaded to the MELAnet Box and the call blocks until the
+// box acknowledges it or ackTimeout expires. Refusals are reported as ErrProgramRejected,
+// ErrDoorOpen or ErrDeviceBusy, a missing acknowledgement as ErrAckTimeout.
 func (a *MelagAdapter) StartCycle(params adapters.CycleStartParams) error {
 	a.stateMutex.RLock()
 	if a.ftpClient == nil || a.state != adapters.StateConnected {
@@ -200,41 +439,109 @@ func (a *MelagAdapter) StartCycle(params adapters.CycleStartParams) error {
 		"device_name", a.device.Name,
 		"program", params.Program)
 
-	// MVP: Placeholder for actual FTP command
-	// TODO: Implement actual MELAnet Box FTP protocol for cycle start
-	// This will require:
-	// 1. MELAnet Box documentation for cycle start command format
-	// 2. FTP file upload or command execution protocol
-	// 3. Protocol file format understanding
-
-	// For MVP, we'll simulate a successful cycle start
-	// In production, this would be something like:
-	// - Upload a cycle start protocol file via FTP
-	// - Send a command via FTP protocol
-	// - Monitor FTP directory for cycle status files
-
-	// Example placeholder implementation:
-	// This is a placeholder - actual implementation requires MELAnet Box protocol docs
-	_ = ftpClient // Use ftpClient to avoid unused variable error
-
-	// Simulate successful cycle start for MVP
-	// In production, replace this with actual FTP command:
-	// err := ftpClient.UploadProtocolFile(params)
-	// if err != nil {
-	//     return fmt.Errorf("failed to start cycle: %w", err)
-	// }
-
-	a.logger.Info("Cycle start command sent successfully",
+	jobID := startJobID(time.Now())
+	requestPath := path.Join(a.commandDir, jobID+requestFileExt)
+	ackPath := path.Join(a.commandDir, jobID+ackFileExt)
+
+	a.ftpMutex.Lock()
+	err := ftpClient.Stor(requestPath, bytes.NewReader(buildStartRequest(jobID, params)))
+	a.ftpMutex.Unlock()
+	if err != nil {
+		return fmt.Errorf("failed to upload start request: %w", err)
+	}
+
+	a.logger.Debug("Start request uploaded, waiting for acknowledgement",
 		"device_id", a.deviceID,
-		"program", params.Program,
-		"note", "MVP placeholder - actual protocol implementation required")
+		"file", requestPath,
+		"timeout", a.ackTimeout)
+
+	ackErr := a.waitForStartAck(ftpClient, ackPath)
+	if errors.Is(ackErr, ErrAckTimeout) {
+		// Withdraw the request so the box does not start the cycle later on
+		a.ftpMutex.Lock()
+		if err := ftpClient.Delete(requestPath); err != nil {
+			a.logger.Warn("Failed to withdraw unacknowledged start request",
+				"device_id", a.deviceID,
+				"file", requestPath,
+				"error", err)
+		}
+		a.ftpMutex.Unlock()
+	}
+	if ackErr != nil {
+		a.logger.Warn("Cycle start refused",
+			"device_id", a.deviceID,
+			"program", params.Program,
+			"error", ackErr)
+		return ackErr
+	}
+
+	// Forget the status of the previous cycle so it is not reported for the new one
+	a.ftpMutex.Lock()
+	a.lastStatus = nil
+	a.resumed = nil
+	a.ftpMutex.Unlock()
+
+	a.logger.Info("Cycle start acknowledged by device",
+		"device_id", a.deviceID,
+		"program", params.Program)
 
 	return nil
 }
 
+// waitForStartAck polls for the acknowledgement file until it appears or ackTimeout expires
+func (a *MelagAdapter) waitForStartAck(ftpClient *ftp.ServerConn, ackPath string) error {
+	deadline := time.Now().Add(a.ackTimeout)
+	for {
+		a.ftpMutex.Lock()
+		found, ackErr, err := a.readStartAck(ftpClient, ackPath)
+		a.ftpMutex.Unlock()
+		if err != nil {
+			return fmt.Errorf("failed to read acknowledgement: %w", err)
+		}
+		if found {
+			return ackErr
+		}
+
+		if time.Now().After(deadline) {
+			return fmt.Errorf("%w within %s", ErrAckTimeout, a.ackTimeout)
+		}
+		time.Sleep(a.ackPollInterval)
+	}
+}
+
+// readStartAck downloads and removes the acknowledgement file if it exists
+// ackErr carries the device's answer, err a transfer failure. The caller must hold ftpMutex.
+func (a *MelagAdapter) readStartAck(ftpClient *ftp.ServerConn, ackPath string) (found bool, ackErr error, err error) {
+	response, err := ftpClient.Retr(ackPath)
+	if err != nil {
+		// 550: the box has not written the acknowledgement yet
+		var protoErr *textproto.Error
+		if errors.As(err, &protoErr) && protoErr.Code == ftp.StatusFileUnavailable {
+			return false, nil, nil
+		}
+		return false, nil, err
+	}
+
+	ackErr = parseStartAck(response)
+	if err := response.Close(); err != nil {
+		return false, nil, err
+	}
+
+	if err := ftpClient.Delete(ackPath); err != nil {
+		a.logger.Warn("Failed to remove acknowledgement file",
+			"device_id", a.deviceID,
+			"file", ackPath,
+			"error", err)
+	}
+
+	return true, ackErr, nil
+}
+
 // GetCycleStatus retrieves the current status of a running cycle
-// Note: This is an MVP implementation with placeholder for actual FTP protocol status retrieval
-// The actual protocol format will need to be determined from MELAnet Box documentation
+// The MELAnet Box directory is listed and every protocol/status file that has been
+// written or rewritten since the last call is downloaded and parsed in order of
+// modification time. A completed protocol is returned as soon as it is seen; if no
+// new file is available the last known status is returned with Stale set.
 func (a *MelagAdapter) GetCycleStatus() (adapters.CycleStatus, error) {
 	a.stateMutex.RLock()
 	if a.ftpClient == nil || a.state != adapters.StateConnected {
@@ -244,48 +551,179 @@ func (a *MelagAdapter) GetCycleStatus() (adapters.CycleStatus, error) {
 	ftpClient := a.ftpClient
 	a.stateMutex.RUnlock()
 
-	// MVP: Placeholder for actual FTP status retrieval
-	// TODO: Implement actual MELAnet Box FTP protocol for cycle status
-	// This will require:
-	// 1. MELAnet Box documentation for status file format
-	// 2. FTP file download or status command protocol
-	// 3. Protocol file parsing (likely XML or similar format)
-
-	// For MVP, we'll return a placeholder status
-	// In production, this would be something like:
-	// - Download status file via FTP
-	// - Parse status file format
-	// - Extract phase, temperature, pressure, progress
-
-	// Example placeholder implementation:
-	// This is a placeholder - actual implementation requires MELAnet Box protocol docs
-	_ = ftpClient // Use ftpClient to avoid unused variable error
-
-	// Simulate cycle status for MVP
-	// In production, replace this with actual FTP status retrieval:
-	// statusFile, err := ftpClient.Retr("status.xml")
-	// if err != nil {
-	//     return nil, fmt.Errorf("failed to retrieve status: %w", err)
-	// }
-	// defer statusFile.Close()
-	// status, err := parseStatusFile(statusFile)
-	// if err != nil {
-	//     return nil, fmt.Errorf("failed to parse status: %w", err)
-	// }
-
-	// Placeholder status - will be replaced with real implementation
-	status := adapters.CycleStatus{
-		Phase:           "RUNNING",
-		ProgressPercent: 50, // Placeholder
-		IsRunning:       true,
-	}
-
-	a.logger.Debug("Retrieved cycle status (MVP placeholder)",
-		"device_id", a.deviceID,
-		"phase", status.Phase,
-		"progress", status.ProgressPercent,
-		"note", "MVP placeholder - actual protocol implementation required")
+	a.ftpMutex.Lock()
+	defer a.ftpMutex.Unlock()
+
+	entries, err := a.listProtocolFiles(ftpClient)
+	if err != nil {
+		return adapters.CycleStatus{}, fmt.Errorf("failed to list protocol files: %w", err)
+	}
+
+	fresh := false
+	for _, entry := range entries {
+		filePath := path.Join(a.protocolDir, entry.Name)
+		stamp := fileStamp{size: entry.Size, modTime: entry.Time}
+		if consumed, ok := a.consumedFiles[filePath]; ok && consumed == stamp {
+			continue
+		}
+		a.consumedFiles[filePath] = stamp
+
+		protocol, err := a.downloadProtocol(ftpClient, filePath)
+		if err != nil {
+			a.logger.Warn("Skipping unreadable protocol file",
+				"device_id", a.deviceID,
+				"file", filePath,
+				"error", err)
+			continue
+		}
+
+		if a.resumed != nil {
+			if !a.resumed.matches(protocol) {
+				a.logger.Debug("Skipping protocol file of another cycle",
+					"device_id", a.deviceID,
+					"file", filePath,
+					"cycle_number", protocol.DeviceCycleNumber())
+				continue
+			}
+			a.resumed.cycleNumber = protocol.DeviceCycleNumber()
+			if protocol.IsCompleted() {
+				a.resumed = nil
+			}
+		}
+
+		status := protocol.ToCycleStatus()
+		a.lastStatus = &status
+		fresh = true
+
+		a.logger.Debug("Consumed protocol file",
+			"device_id", a.deviceID,
+			"file", filePath,
+			"phase", status.Phase,
+			"result", status.Result)
+
+		if !status.IsRunning {
+			// Report the finished cycle before looking at newer files
+			break
+		}
+	}
+
+	if a.lastStatus == nil {
+		// Nothing written for the cycle yet
+		return adapters.CycleStatus{
+			Phase:     "STARTING",
+			IsRunning: true,
+			Stale:     true,
+		}, nil
+	}
 
+	status := *a.lastStatus
+	status.Stale = !fresh
 	return status, nil
 }
 
+// listProtocolFiles lists protocol and status files in the protocol directory, oldest first
+// The caller must hold ftpMutex.
+func (a *MelagAdapter) listProtocolFiles(ftpClient *ftp.ServerConn) ([]*ftp.Entry, error) {
+	entries, err := ftpClient.List(a.protocolDir)
+	if err != nil {
+		return nil, err
+	}
+
+	files := make([]*ftp.Entry, 0, len(entries))
+	for _, entry := range entries {
+		if entry.Type == ftp.EntryTypeFile && IsProtocolFileName(entry.Name) {
+			files = append(files, entry)
+		}
+	}
+
+	sort.SliceStable(files, func(i, j int) bool {
+		if files[i].Time.Equal(files[j].Time) {
+			return files[i].Name < files[j].Name
+		}
+		return files[i].Time.Before(files[j].Time)
+	})
+
+	return files, nil
+}
+
+// seedConsumedFiles marks all files currently on the box as consumed, except files that
+// may belong to a resumed cycle
+// The caller must hold ftpMutex.
+func (a *MelagAdapter) seedConsumedFiles(ftpClient *ftp.ServerConn) error {
+	a.consumedFiles = make(map[string]fileStamp)
+
+	entries, err := a.listProtocolFiles(ftpClient)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if a.resumed != nil && a.resumed.writtenSince(entry.Time) {
+			continue
+		}
+		a.consumedFiles[path.Join(a.protocolDir, entry.Name)] = fileStamp{size: entry.Size, modTime: entry.Time}
+	}
+	return nil
+}
+
+// ResumeCycle follows a cycle that was running before the service restarted
+// Files written since the cycle started are read again, so a status or protocol the box
+// wrote while the service was down is reported; files of other cycles are skipped.
+func (a *MelagAdapter) ResumeCycle(cycleNumber string, startedAt time.Time) {
+	a.ftpMutex.Lock()
+	defer a.ftpMutex.Unlock()
+
+	a.resumed = &resumedCycle{cycleNumber: cycleNumber, startedAt: startedAt}
+	a.lastStatus = nil
+	for filePath, stamp := range a.consumedFiles {
+		if a.resumed.writtenSince(stamp.modTime) {
+			delete(a.consumedFiles, filePath)
+		}
+	}
+}
+
+// downloadProtocol retrieves and parses a single protocol file
+// The caller must hold ftpMutex.
+func (a *MelagAdapter) downloadProtocol(ftpClient *ftp.ServerConn, filePath string) (*ProtocolFile, error) {
+	response, err := ftpClient.Retr(filePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to retrieve %s: %w", filePath, err)
+	}
+
+	protocol, parseErr := ParseProtocolFile(path.Base(filePath), response, time.Local)
+	if err := response.Close(); err != nil && parseErr == nil {
+		return nil, fmt.Errorf("failed to close transfer of %s: %w", filePath, err)
+	}
+	if parseErr != nil {
+		return nil, parseErr
+	}
+
+	// Remember what the device reports about itself
+	if protocol.Model != "" {
+		a.reportedInfo.Model = protocol.Model
+	}
+	if protocol.SerialNumber != "" {
+		a.reportedInfo.SerialNumber = protocol.SerialNumber
+	}
+	if protocol.Firmware != "" {
+		a.reportedInfo.Firmware = protocol.Firmware
+	}
+
+	return protocol, nil
+}
+
+// GetDeviceInfo returns model, serial number and firmware as reported in the latest
+// protocol file, falling back to the values stored for the device
+func (a *MelagAdapter) GetDeviceInfo() (adapters.DeviceInfo, error) {
+	a.ftpMutex.Lock()
+	info := a.reportedInfo
+	a.ftpMutex.Unlock()
+
+	info.Manufacturer = a.device.Manufacturer
+	if info.Model == "" {
+		info.Model = a.device.Model
+	}
+	if info.SerialNumber == "" {
+		info.SerialNumber = a.device.Serial
+	}
+	return info, nil
+}
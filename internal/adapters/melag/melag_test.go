@@ -0,0 +1,466 @@
+package melag
+
+import (
+	"context"
+	"errors"
+	"os"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"steri-connect-go/internal/adapters"
+	"steri-connect-go/internal/database"
+	"steri-connect-go/internal/logging"
+	"steri-connect-go/internal/simulator"
+)
+
+const sampleStatusFile = `MELAG Cliniclave 45
+Seriennummer: 201945123
+Charge: 0042
+Programm: Universal-Programm
+Datum: 16.10.2026
+Startzeit: 08:15:02
+Zeit: 08:27:32
+Phase: Sterilisation
+Temperatur: 134,2 °C
+Druck: 2.12 bar
+Restzeit: 00:12:30
+Fortschritt: 45 %
+`
+
+const sampleProtocolOK = `MELAG Cliniclave 45
+Seriennummer: 201945123
+Charge: 0042
+Programm: Universal-Programm
+Datum: 16.10.2026
+Startzeit: 08:15:02
+Endzeit: 08:52:40
+Phase: Trocknung
+Temperatur: 121.0 °C
+Druck: 1.01 bar
+Ergebnis: Programm erfolgreich beendet
+`
+
+const sampleProtocolNOK = `MELAG Cliniclave 45
+Charge: 0043
+Programm: Schnell-Programm B
+Datum: 16.10.2026
+Startzeit: 23:50:00
+Endzeit: 00:10:12
+Phase: Trocknung
+Ergebnis: Programm abgebrochen
+Fehler: 27 Trockenzeit überschritten
+`
+
+func TestMain(m *testing.M) {
+	if err := logging.Init(logging.Config{Level: "ERROR", Format: "text", Output: "stdout"}); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+func TestParseProtocolFile(t *testing.T) {
+	protocol, err := ParseProtocolFile("0042.sta", strings.NewReader(sampleStatusFile), time.UTC)
+	if err != nil {
+		t.Fatalf("Failed to parse status file: %v", err)
+	}
+
+	if protocol.CycleNumber != "0042" || protocol.SerialNumber != "201945123" || protocol.Model != "Cliniclave 45" {
+		t.Errorf("Unexpected identifiers: cycle %q serial %q model %q", protocol.CycleNumber, protocol.SerialNumber, protocol.Model)
+	}
+	if protocol.Temperature == nil || *protocol.Temperature != 134.2 {
+		t.Errorf("Expected temperature 134.2, got %v", protocol.Temperature)
+	}
+	if protocol.TimeRemaining == nil || *protocol.TimeRemaining != 12*time.Minute+30*time.Second {
+		t.Errorf("Expected 12m30s remaining, got %v", protocol.TimeRemaining)
+	}
+	if protocol.IsCompleted() {
+		t.Errorf("Status file without result must not be completed")
+	}
+
+	status := protocol.ToCycleStatus()
+	if status.Phase != "Sterilisation" || !status.IsRunning || status.ProgressPercent != 45 {
+		t.Errorf("Unexpected cycle status: %+v", status)
+	}
+	if expected := time.Date(2026, 10, 16, 8, 27, 32, 0, time.UTC); status.MeasuredAt == nil || !status.MeasuredAt.Equal(expected) {
+		t.Errorf("Expected reading time %v, got %v", expected, status.MeasuredAt)
+	}
+
+	protocol, err = ParseProtocolFile("0043.pro", strings.NewReader(sampleProtocolNOK), time.UTC)
+	if err != nil {
+		t.Fatalf("Failed to parse protocol file: %v", err)
+	}
+	if protocol.Result != "NOK" || protocol.ErrorCode != "27" || protocol.ErrorDescription != "Trockenzeit überschritten" {
+		t.Errorf("Unexpected result fields: %+v", protocol)
+	}
+	if protocol.EndTime == nil || protocol.EndTime.Sub(*protocol.StartTime) != 20*time.Minute+12*time.Second {
+		t.Errorf("Expected end time on the following day, got start %v end %v", protocol.StartTime, protocol.EndTime)
+	}
+	if status := protocol.ToCycleStatus(); status.Phase != "FAILED" || status.IsRunning {
+		t.Errorf("Expected FAILED status, got %+v", status)
+	}
+
+	if _, err := ParseProtocolFile("empty.txt", strings.NewReader("MELAG\n"), time.UTC); err == nil {
+		t.Errorf("Expected error for file without fields")
+	}
+}
+
+func TestGetCycleStatusFromFTP(t *testing.T) {
+	server := newTestFTPServer(t)
+	base := time.Now().Add(-time.Hour).Truncate(time.Second)
+
+	// Protocol of an earlier cycle must not be reported for the current one
+	server.PutFile("/0041.pro", sampleProtocolOK, base)
+	server.PutFile("/readme.html", "<html></html>", base)
+
+	adapter := newTestAdapter(t, server)
+
+	status, err := adapter.GetCycleStatus()
+	if err != nil {
+		t.Fatalf("GetCycleStatus failed: %v", err)
+	}
+	if status.Phase != "STARTING" || !status.IsRunning || !status.Stale {
+		t.Errorf("Expected stale STARTING before any new file, got %+v", status)
+	}
+	if server.RetrCount("/0041.pro") != 0 {
+		t.Errorf("Existing protocol file must not be downloaded")
+	}
+
+	server.PutFile("/0042.sta", sampleStatusFile, base.Add(time.Minute))
+	status, err = adapter.GetCycleStatus()
+	if err != nil {
+		t.Fatalf("GetCycleStatus failed: %v", err)
+	}
+	if status.Phase != "Sterilisation" || status.Temperature == nil || *status.Temperature != 134.2 || status.Stale {
+		t.Errorf("Unexpected status from status file: %+v", status)
+	}
+
+	// Unchanged file is not downloaded again, last status is reported
+	status, err = adapter.GetCycleStatus()
+	if err != nil {
+		t.Fatalf("GetCycleStatus failed: %v", err)
+	}
+	if status.Phase != "Sterilisation" || !status.Stale {
+		t.Errorf("Expected cached status marked stale, got %+v", status)
+	}
+	if got := server.RetrCount("/0042.sta"); got != 1 {
+		t.Errorf("Expected status file to be downloaded once, got %d", got)
+	}
+
+	server.PutFile("/0042.pro", sampleProtocolOK, base.Add(2*time.Minute))
+	status, err = adapter.GetCycleStatus()
+	if err != nil {
+		t.Fatalf("GetCycleStatus failed: %v", err)
+	}
+	if status.Phase != "COMPLETED" || status.IsRunning || status.Result != "OK" || status.ProgressPercent != 100 {
+		t.Errorf("Expected completed cycle, got %+v", status)
+	}
+}
+
+func TestResumeCycleReportsProtocolWrittenDuringDowntime(t *testing.T) {
+	// Cycle 0042 started before the restart and ended while the service was down;
+	// cycle 0041 ended shortly before it started
+	started := time.Date(2026, 10, 16, 8, 15, 2, 0, time.Local)
+	previous := strings.NewReplacer("Charge: 0042", "Charge: 0041", "Startzeit: 08:15:02", "Startzeit: 07:30:00").Replace(sampleProtocolOK)
+
+	tests := []struct {
+		name        string
+		cycleNumber string
+		beforeSeed  bool
+	}{
+		{name: "known cycle number", cycleNumber: "0042"},
+		{name: "unknown cycle number", cycleNumber: ""},
+		{name: "resumed before connect", cycleNumber: "0042", beforeSeed: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newTestFTPServer(t)
+			server.PutFile("/0040.pro", sampleProtocolNOK, started.Add(-time.Hour))
+			server.PutFile("/0041.pro", previous, started.Add(-2*time.Minute))
+			server.PutFile("/0042.pro", sampleProtocolOK, started.Add(37*time.Minute))
+
+			adapter, err := NewMelagAdapter(&database.Device{ID: 1, Name: "Test", Manufacturer: "Melag", IP: "127.0.0.1"})
+			if err != nil {
+				t.Fatalf("Failed to create adapter: %v", err)
+			}
+			adapter.SetEndpoint(server.Addr())
+			if tt.beforeSeed {
+				adapter.ResumeCycle(tt.cycleNumber, started)
+			}
+			if err := adapter.Connect(); err != nil {
+				t.Fatalf("Failed to connect to test FTP server: %v", err)
+			}
+			t.Cleanup(func() { adapter.Disconnect() })
+			if !tt.beforeSeed {
+				adapter.ResumeCycle(tt.cycleNumber, started)
+			}
+
+			status, err := adapter.GetCycleStatus()
+			if err != nil {
+				t.Fatalf("GetCycleStatus failed: %v", err)
+			}
+			if status.Phase != "COMPLETED" || status.CycleNumber != "0042" || status.Result != "OK" || status.Stale {
+				t.Errorf("Expected completed cycle 0042, got %+v", status)
+			}
+			if server.RetrCount("/0040.pro") != 0 {
+				t.Errorf("Protocol written before the resumed cycle must not be downloaded")
+			}
+
+			// A new cycle is no longer filtered by the resumed one
+			server.PutFile("/0043.pro", sampleProtocolNOK, started.Add(time.Hour))
+			status, err = adapter.GetCycleStatus()
+			if err != nil {
+				t.Fatalf("GetCycleStatus failed: %v", err)
+			}
+			if status.Phase != "FAILED" || status.CycleNumber != "0043" {
+				t.Errorf("Expected failed cycle 0043 after the resumed one, got %+v", status)
+			}
+		})
+	}
+}
+
+func TestGetCycleStatusReportsFailure(t *testing.T) {
+	server := newTestFTPServer(t)
+	adapter := newTestAdapter(t, server)
+
+	server.PutFile("/0043.txt", sampleProtocolNOK, time.Now())
+	status, err := adapter.GetCycleStatus()
+	if err != nil {
+		t.Fatalf("GetCycleStatus failed: %v", err)
+	}
+	if status.Phase != "FAILED" || status.Result != "NOK" || status.ErrorCode != "27" {
+		t.Errorf("Expected failed cycle with error code, got %+v", status)
+	}
+}
+
+func TestGetCycleStatusRequiresConnection(t *testing.T) {
+	adapter, err := NewMelagAdapter(&database.Device{ID: 1, Name: "Test", Manufacturer: "Melag", IP: "127.0.0.1"})
+	if err != nil {
+		t.Fatalf("Failed to create adapter: %v", err)
+	}
+	if _, err := adapter.GetCycleStatus(); err == nil {
+		t.Errorf("Expected error when not connected")
+	}
+}
+
+// newTestFTPServer starts a simulated MELAnet Box FTP server on a free port
+func newTestFTPServer(t *testing.T) *simulator.FTPServer {
+	t.Helper()
+
+	server := simulator.NewFTPServer(simulator.MelagUsername, simulator.MelagPassword)
+	if err := server.Start("127.0.0.1:0"); err != nil {
+		t.Fatalf("Failed to start test FTP server: %v", err)
+	}
+	t.Cleanup(func() { server.Close() })
+	return server
+}
+
+// newTestAdapter creates an adapter connected to the test server
+func newTestAdapter(t *testing.T, server *simulator.FTPServer) *MelagAdapter {
+	t.Helper()
+
+	adapter, err := NewMelagAdapter(&database.Device{ID: 1, Name: "Test", Manufacturer: "Melag", IP: "127.0.0.1"})
+	if err != nil {
+		t.Fatalf("Failed to create adapter: %v", err)
+	}
+	adapter.SetEndpoint(server.Addr())
+
+	if err := adapter.Connect(); err != nil {
+		t.Fatalf("Failed to connect to test FTP server: %v", err)
+	}
+	t.Cleanup(func() { adapter.Disconnect() })
+
+	if adapter.GetConnectionState() != adapters.StateConnected {
+		t.Fatalf("Expected CONNECTED state, got %s", adapter.GetConnectionState())
+	}
+	return adapter
+}
+
+func TestStartCycleAcknowledgement(t *testing.T) {
+	tests := []struct {
+		name    string
+		ack     string
+		wantErr error
+	}{
+		{name: "accepted", ack: "Status: OK\r\n"},
+		{name: "door open", ack: "Status: TUER_OFFEN\r\n", wantErr: ErrDoorOpen},
+		{name: "busy", ack: "Status: Belegt\r\n", wantErr: ErrDeviceBusy},
+		{name: "rejected", ack: "Status: ABGELEHNT\r\nFehler: 12 Programm unbekannt\r\n", wantErr: ErrProgramRejected},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := newTestFTPServer(t)
+			var request string
+			server.OnStore(func(name string, data []byte) {
+				request = string(data)
+				server.PutFile(strings.TrimSuffix(name, requestFileExt)+ackFileExt, tt.ack, time.Now())
+			})
+
+			adapter := newTestAdapter(t, server)
+			adapter.ackPollInterval = 10 * time.Millisecond
+
+			temperature := 134.0
+			err := adapter.StartCycle(adapters.CycleStartParams{Program: "Universal-Programm", Temperature: &temperature})
+			if tt.wantErr == nil && err != nil {
+				t.Fatalf("Expected start to be accepted, got %v", err)
+			}
+			if tt.wantErr != nil && !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Expected %v, got %v", tt.wantErr, err)
+			}
+
+			if !strings.Contains(request, "Programm: Universal-Programm") || !strings.Contains(request, "Temperatur: 134.0") {
+				t.Errorf("Unexpected start request:\n%s", request)
+			}
+		})
+	}
+}
+
+func TestStartCycleAckTimeout(t *testing.T) {
+	server := newTestFTPServer(t)
+	var requestName string
+	server.OnStore(func(name string, data []byte) { requestName = name })
+
+	adapter := newTestAdapter(t, server)
+	adapter.ackTimeout = 50 * time.Millisecond
+	adapter.ackPollInterval = 10 * time.Millisecond
+
+	err := adapter.StartCycle(adapters.CycleStartParams{Program: "Universal-Programm"})
+	if !errors.Is(err, ErrAckTimeout) {
+		t.Fatalf("Expected ErrAckTimeout, got %v", err)
+	}
+	if requestName == "" || server.HasFile(requestName) {
+		t.Errorf("Expected unacknowledged start request %q to be withdrawn", requestName)
+	}
+}
+
+func TestImportProtocolArchive(t *testing.T) {
+	if err := database.InitializeDatabase(t.TempDir() + "/import.db"); err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	t.Cleanup(func() { database.Close() })
+
+	device, err := database.CreateDevice(&database.Device{Name: "Test", Manufacturer: "Melag", IP: "127.0.0.1", Type: "Steri"})
+	if err != nil {
+		t.Fatalf("Failed to create device: %v", err)
+	}
+
+	server := newTestFTPServer(t)
+	archived := time.Date(2026, 10, 16, 9, 0, 0, 0, time.UTC)
+	server.PutFile("/2026/10/0042.pro", sampleProtocolOK, archived)
+	server.PutFile("/2026/10/0043.pro", sampleProtocolNOK, archived.Add(time.Hour))
+	server.PutFile("/2026/10/0044.pro", sampleStatusFile, archived.Add(2*time.Hour))
+	server.PutFile("/0045.sta", sampleStatusFile, archived.Add(3*time.Hour))
+
+	adapter := newTestAdapter(t, server)
+	adapter.deviceID = device.ID
+
+	var updates int
+	progress, err := adapter.ImportProtocolArchive(context.Background(), func(adapters.ImportProgress) { updates++ })
+	if err != nil {
+		t.Fatalf("Import failed: %v", err)
+	}
+	if progress.Total != 3 || progress.Imported != 2 || progress.Skipped != 1 || updates != 3 {
+		t.Errorf("Unexpected first import progress %+v after %d updates", progress, updates)
+	}
+
+	cycles, err := database.GetDeviceCycles(device.ID)
+	if err != nil {
+		t.Fatalf("Failed to load cycles: %v", err)
+	}
+	if len(cycles) != 2 {
+		t.Fatalf("Expected 2 imported cycles, got %d", len(cycles))
+	}
+	for _, cycle := range cycles {
+		switch cycle.DeviceCycleNumber {
+		case "0042":
+			if cycle.Result != "OK" || cycle.EndTS == nil {
+				t.Errorf("Unexpected OK cycle %+v", cycle)
+			}
+		case "0043":
+			if cycle.Result != "NOK" || cycle.ErrorCode != "27" || cycle.EndTS == nil || !cycle.EndTS.After(cycle.StartTS) {
+				t.Errorf("Unexpected NOK cycle %+v", cycle)
+			}
+		default:
+			t.Errorf("Unexpected cycle number %q", cycle.DeviceCycleNumber)
+		}
+	}
+
+	// A second run must not create duplicates
+	progress, err = adapter.ImportProtocolArchive(context.Background(), nil)
+	if err != nil {
+		t.Fatalf("Second import failed: %v", err)
+	}
+	if progress.Imported != 0 || progress.Duplicates != 2 {
+		t.Errorf("Expected only duplicates on second import, got %+v", progress)
+	}
+}
+
+func TestApplyConnectionSettings(t *testing.T) {
+	if err := database.SetSecretKey([]byte("0123456789abcdef0123456789abcdef")); err != nil {
+		t.Fatalf("Failed to set secret key: %v", err)
+	}
+
+	server := simulator.NewFTPServer("praxis", "geheim")
+	if err := server.Start("127.0.0.1:0"); err != nil {
+		t.Fatalf("Failed to start test FTP server: %v", err)
+	}
+	t.Cleanup(func() { server.Close() })
+	_, port, _ := strings.Cut(server.Addr(), ":")
+
+	adapter, err := NewMelagAdapter(&database.Device{ID: 1, Name: "Test", Manufacturer: "Melag", IP: "127.0.0.1"})
+	if err != nil {
+		t.Fatalf("Failed to create adapter: %v", err)
+	}
+
+	// Default melanet credentials are refused by this box
+	adapter.SetEndpoint(server.Addr())
+	if err := adapter.Connect(); err == nil {
+		t.Fatalf("Expected login with default credentials to fail")
+	}
+
+	settings := &database.DeviceConnectionSettings{DeviceID: 1, Username: "praxis", TimeoutSeconds: 3, FTPMode: database.FTPModePASV}
+	settings.Port, _ = strconv.Atoi(port)
+	if err := settings.SetPassword("geheim"); err != nil {
+		t.Fatalf("Failed to set password: %v", err)
+	}
+	if err := adapter.ApplyConnectionSettings(settings); err != nil {
+		t.Fatalf("Failed to apply settings: %v", err)
+	}
+	if err := adapter.Connect(); err != nil {
+		t.Fatalf("Expected connect with device settings, got %v", err)
+	}
+	t.Cleanup(func() { adapter.Disconnect() })
+
+	if _, err := adapter.GetCycleStatus(); err != nil {
+		t.Errorf("Expected PASV data connection to work, got %v", err)
+	}
+
+	settings.FTPMode = database.FTPModeActive
+	if err := adapter.ApplyConnectionSettings(settings); err == nil {
+		t.Errorf("Expected active FTP mode to be rejected")
+	}
+}
+
+func TestCheckHealthDetectsSilentDrop(t *testing.T) {
+	server := newTestFTPServer(t)
+	adapter := newTestAdapter(t, server)
+	adapter.ftpTimeout = 200 * time.Millisecond
+
+	if err := adapter.CheckHealth(); err != nil {
+		t.Fatalf("Expected healthy session, got %v", err)
+	}
+
+	server.SetUnresponsive(true)
+	if err := adapter.CheckHealth(); err == nil {
+		t.Fatal("Expected health check to fail on an unresponsive session")
+	}
+	if adapter.IsConnected() || adapter.GetConnectionState() != adapters.StateError {
+		t.Errorf("Expected ERROR state after lost session, got %s", adapter.GetConnectionState())
+	}
+	if err := adapter.CheckHealth(); err == nil {
+		t.Errorf("Expected health check to fail while disconnected")
+	}
+}
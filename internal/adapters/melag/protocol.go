@@ -0,0 +1,351 @@
+package melag
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"steri-connect-go/internal/adapters"
+	"steri-connect-go/internal/database"
+)
+
+// MELAnet Box protocol files are plain text files with one "Key: Value" pair
+// per line. The box writes a status file (*.sta) that is rewritten while a
+// cycle is running and a protocol file (*.pro / *.txt) once the cycle has
+// finished. Keys are written in German by the device firmware; the English
+// equivalents are accepted as well so hand-written sample files work.
+//
+// Example:
+//
+//	MELAG Cliniclave 45
+//	Seriennummer: 201945123
+//	Charge: 0042
+//	Programm: Universal-Programm
+//	Datum: 16.10.2026
+//	Startzeit: 08:15:02
+//	Zeit: 08:27:32
+//	Phase: Sterilisation
+//	Temperatur: 134.2 °C
+//	Druck: 2.12 bar
+//	Restzeit: 00:12:30
+//	Fortschritt: 45 %
+//	Ergebnis: OK
+//	Fehler: 27 Trockenzeit überschritten
+
+// Protocol file extensions written by the MELAnet Box
+const (
+	statusFileExt   = ".sta"
+	protocolFileExt = ".pro"
+	textFileExt     = ".txt"
+)
+
+// ProtocolFile holds the values parsed from a MELAnet Box protocol or status file
+type ProtocolFile struct {
+	Name             string         `json:"name"`
+	Model            string         `json:"model,omitempty"`
+	SerialNumber     string         `json:"serial_number,omitempty"`
+	Firmware         string         `json:"firmware,omitempty"`
+	CycleNumber      string         `json:"cycle_number,omitempty"`
+	Program          string         `json:"program,omitempty"`
+	StartTime        *time.Time     `json:"start_time,omitempty"`
+	EndTime          *time.Time     `json:"end_time,omitempty"`
+	StatusTime       *time.Time     `json:"status_time,omitempty"` // Time the status file was written (Zeit)
+	Phase            string         `json:"phase,omitempty"`
+	Temperature      *float64       `json:"temperature,omitempty"`
+	Pressure         *float64       `json:"pressure,omitempty"`
+	TimeRemaining    *time.Duration `json:"time_remaining,omitempty"`
+	ProgressPercent  *int           `json:"progress_percent,omitempty"`
+	Result           string         `json:"result,omitempty"` // "OK" or "NOK", empty while running
+	ErrorCode        string         `json:"error_code,omitempty"`
+	ErrorDescription string         `json:"error_description,omitempty"`
+}
+
+// IsProtocolFileName returns true if the file name looks like a MELAnet Box status or protocol file
+func IsProtocolFileName(name string) bool {
+	switch strings.ToLower(path.Ext(name)) {
+	case statusFileExt, protocolFileExt, textFileExt:
+		return true
+	}
+	return false
+}
+
+// IsCompleted returns true if the protocol describes a finished cycle
+func (p *ProtocolFile) IsCompleted() bool {
+	return p.Result != ""
+}
+
+// ParseProtocolFile parses a MELAnet Box protocol or status file
+func ParseProtocolFile(name string, r io.Reader, loc *time.Location) (*ProtocolFile, error) {
+	if loc == nil {
+		loc = time.Local
+	}
+
+	protocol := &ProtocolFile{Name: name}
+	var date, startTime, endTime, statusTime string
+	fields := 0
+
+	scanner := bufio.NewScanner(r)
+	for scanner.Scan() {
+		line := strings.TrimSpace(strings.TrimPrefix(scanner.Text(), "\ufeff"))
+		if line == "" {
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, ":")
+		if !ok {
+			// Header lines such as "MELAG Cliniclave 45" carry no value except the model
+			if model, isHeader := cutPrefixFold(line, "MELAG "); isHeader && protocol.Model == "" {
+				protocol.Model = strings.TrimSpace(model)
+			}
+			continue
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		value = strings.TrimSpace(value)
+
+		var err error
+		switch key {
+		case "seriennummer", "serial", "serial number":
+			protocol.SerialNumber = value
+		case "software", "softwareversion", "firmware", "version":
+			protocol.Firmware = value
+		case "charge", "chargennummer", "zyklus", "cycle", "cycle number":
+			protocol.CycleNumber = value
+		case "programm", "program":
+			protocol.Program = value
+		case "datum", "date":
+			date = value
+		case "startzeit", "start", "start time":
+			startTime = value
+		case "endzeit", "ende", "end", "end time":
+			endTime = value
+		case "zeit", "uhrzeit", "time", "status time":
+			statusTime = value
+		case "phase":
+			protocol.Phase = value
+		case "temperatur", "temperature":
+			protocol.Temperature, err = parseMeasurement(value)
+		case "druck", "pressure":
+			protocol.Pressure, err = parseMeasurement(value)
+		case "restzeit", "remaining", "time remaining":
+			protocol.TimeRemaining, err = parseClockDuration(value)
+		case "fortschritt", "progress":
+			protocol.ProgressPercent, err = parsePercent(value)
+		case "ergebnis", "result":
+			protocol.Result = parseResult(value)
+		case "fehler", "error":
+			protocol.ErrorCode, protocol.ErrorDescription = parseError(value)
+		default:
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s value %q in %s: %w", key, value, name, err)
+		}
+		fields++
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read protocol file %s: %w", name, err)
+	}
+	if fields == 0 {
+		return nil, fmt.Errorf("protocol file %s contains no known fields", name)
+	}
+
+	var err error
+	if protocol.StartTime, err = parseDateTime(date, startTime, loc); err != nil {
+		return nil, fmt.Errorf("invalid start time in %s: %w", name, err)
+	}
+	if protocol.EndTime, err = parseDateTime(date, endTime, loc); err != nil {
+		return nil, fmt.Errorf("invalid end time in %s: %w", name, err)
+	}
+	if protocol.StatusTime, err = parseDateTime(date, statusTime, loc); err != nil {
+		return nil, fmt.Errorf("invalid status time in %s: %w", name, err)
+	}
+	// Cycles running over midnight end on the following day
+	if protocol.StartTime != nil && protocol.EndTime != nil && protocol.EndTime.Before(*protocol.StartTime) {
+		next := protocol.EndTime.AddDate(0, 0, 1)
+		protocol.EndTime = &next
+	}
+	if protocol.StartTime != nil && protocol.StatusTime != nil && protocol.StatusTime.Before(*protocol.StartTime) {
+		next := protocol.StatusTime.AddDate(0, 0, 1)
+		protocol.StatusTime = &next
+	}
+
+	return protocol, nil
+}
+
+// ToCycleStatus converts the protocol into the adapter-neutral cycle status
+func (p *ProtocolFile) ToCycleStatus() adapters.CycleStatus {
+	status := adapters.CycleStatus{
+		Phase:         p.Phase,
+		Temperature:   p.Temperature,
+		Pressure:      p.Pressure,
+		TimeRemaining: p.TimeRemaining,
+		IsRunning:     !p.IsCompleted(),
+		Result:        p.Result,
+		ErrorCode:     p.ErrorCode,
+		Error:         p.ErrorDescription,
+		CycleNumber:   p.DeviceCycleNumber(),
+		MeasuredAt:    p.StatusTime,
+	}
+
+	if p.ProgressPercent != nil {
+		status.ProgressPercent = *p.ProgressPercent
+	}
+
+	switch p.Result {
+	case "OK":
+		status.Phase = "COMPLETED"
+		status.ProgressPercent = 100
+	case "NOK":
+		status.Phase = "FAILED"
+	default:
+		if status.Phase == "" {
+			status.Phase = "RUNNING"
+		}
+	}
+
+	return status
+}
+
+// DeviceCycleNumber returns the cycle number, falling back to the file name without extension
+func (p *ProtocolFile) DeviceCycleNumber() string {
+	if p.CycleNumber != "" {
+		return p.CycleNumber
+	}
+	return strings.TrimSuffix(p.Name, path.Ext(p.Name))
+}
+
+// ToCycle converts a completed protocol into a cycle record for the given device
+func (p *ProtocolFile) ToCycle(deviceID int) (*database.Cycle, error) {
+	if !p.IsCompleted() {
+		return nil, fmt.Errorf("protocol %s has no result", p.Name)
+	}
+	if p.StartTime == nil {
+		return nil, fmt.Errorf("protocol %s has no start time", p.Name)
+	}
+
+	status := p.ToCycleStatus()
+	cycle := &database.Cycle{
+		DeviceID:          deviceID,
+		Program:           p.Program,
+		StartTS:           *p.StartTime,
+		EndTS:             p.EndTime,
+		Result:            p.Result,
+		ErrorCode:         p.ErrorCode,
+		ErrorDescription:  p.ErrorDescription,
+		Phase:             status.Phase,
+		Temperature:       p.Temperature,
+		Pressure:          p.Pressure,
+		DeviceCycleNumber: p.DeviceCycleNumber(),
+	}
+	if p.Result == "OK" {
+		progress := 100
+		cycle.ProgressPercent = &progress
+	}
+
+	return cycle, nil
+}
+
+// parseMeasurement parses values like "134.2 °C", "2,12 bar" or "134.2"
+func parseMeasurement(value string) (*float64, error) {
+	number := strings.Fields(value)
+	if len(number) == 0 {
+		return nil, nil
+	}
+	v, err := strconv.ParseFloat(strings.Replace(number[0], ",", ".", 1), 64)
+	if err != nil {
+		return nil, err
+	}
+	return &v, nil
+}
+
+// parsePercent parses values like "45 %" or "45%"
+func parsePercent(value string) (*int, error) {
+	value = strings.TrimSpace(strings.TrimSuffix(strings.TrimSpace(value), "%"))
+	if value == "" {
+		return nil, nil
+	}
+	v, err := strconv.Atoi(value)
+	if err != nil {
+		return nil, err
+	}
+	if v < 0 || v > 100 {
+		return nil, fmt.Errorf("progress out of range")
+	}
+	return &v, nil
+}
+
+// parseClockDuration parses durations written as "hh:mm:ss" or "mm:ss"
+func parseClockDuration(value string) (*time.Duration, error) {
+	if value == "" {
+		return nil, nil
+	}
+	parts := strings.Split(value, ":")
+	if len(parts) < 2 || len(parts) > 3 {
+		return nil, fmt.Errorf("expected hh:mm:ss or mm:ss")
+	}
+
+	var total time.Duration
+	units := []time.Duration{time.Second, time.Minute, time.Hour}
+	for i := range parts {
+		n, err := strconv.Atoi(strings.TrimSpace(parts[len(parts)-1-i]))
+		if err != nil {
+			return nil, err
+		}
+		total += time.Duration(n) * units[i]
+	}
+	return &total, nil
+}
+
+// parseResult maps the result line to "OK" or "NOK"
+func parseResult(value string) string {
+	lower := strings.ToLower(value)
+	switch {
+	case lower == "":
+		return ""
+	case strings.Contains(lower, "nicht"), strings.Contains(lower, "abgebrochen"), strings.Contains(lower, "fail"):
+		return "NOK"
+	case lower == "ok", strings.Contains(lower, "erfolgreich"), strings.Contains(lower, "success"):
+		return "OK"
+	default:
+		return "NOK"
+	}
+}
+
+// parseError splits "27 Trockenzeit überschritten" into code and description
+func parseError(value string) (string, string) {
+	code, description, _ := strings.Cut(value, " ")
+	if _, err := strconv.Atoi(code); err != nil {
+		return "", value
+	}
+	return code, strings.TrimSpace(description)
+}
+
+// parseDateTime combines the protocol date ("dd.mm.yyyy" or "yyyy-mm-dd") with a clock time
+func parseDateTime(date, clock string, loc *time.Location) (*time.Time, error) {
+	if clock == "" {
+		return nil, nil
+	}
+	if date == "" {
+		return nil, fmt.Errorf("time %q without date", clock)
+	}
+
+	layouts := []string{"02.01.2006 15:04:05", "02.01.2006 15:04", "2006-01-02 15:04:05", "2006-01-02 15:04"}
+	for _, layout := range layouts {
+		if t, err := time.ParseInLocation(layout, date+" "+clock, loc); err == nil {
+			return &t, nil
+		}
+	}
+	return nil, fmt.Errorf("unrecognized date/time %q %q", date, clock)
+}
+
+// cutPrefixFold is strings.CutPrefix ignoring case
+func cutPrefixFold(s, prefix string) (string, bool) {
+	if len(s) < len(prefix) || !strings.EqualFold(s[:len(prefix)], prefix) {
+		return s, false
+	}
+	return s[len(prefix):], true
+}
@@ -0,0 +1,42 @@
+package melag
+
+import (
+	"fmt"
+
+	"steri-connect-go/internal/adapters"
+	"steri-connect-go/internal/database"
+)
+
+func init() {
+	adapters.Register(adapters.Registration{
+		Manufacturer: "Melag",
+		Capabilities: []adapters.Capability{
+			adapters.CapabilityCycleStart,
+			adapters.CapabilityCycleStatus,
+			adapters.CapabilityProtocolImport,
+			adapters.CapabilityDeviceInfo,
+		},
+		New: newAdapter,
+	})
+}
+
+// newAdapter creates a Melag adapter with the device's stored connection settings
+func newAdapter(device *database.Device, opts adapters.FactoryOptions) (adapters.DeviceAdapter, error) {
+	adapter, err := NewMelagAdapter(device)
+	if err != nil {
+		return nil, err
+	}
+
+	settings, err := database.GetDeviceConnectionSettings(device.ID)
+	if err != nil && err != database.ErrConnectionSettingsNotFound {
+		return nil, fmt.Errorf("failed to load connection settings: %w", err)
+	}
+	if err := adapter.ApplyConnectionSettings(settings); err != nil {
+		return nil, fmt.Errorf("invalid connection settings: %w", err)
+	}
+
+	if opts.Endpoint != "" {
+		adapter.SetEndpoint(opts.Endpoint)
+	}
+	return adapter, nil
+}
@@ -0,0 +1,88 @@
+package adapters
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+
+	"steri-connect-go/internal/database"
+)
+
+// Capability is a feature a device family supports
+type Capability string
+
+const (
+	CapabilityCycleStart     Capability = "cycle_start"     // Cycles can be started remotely
+	CapabilityCycleStatus    Capability = "cycle_status"    // Running cycles report phase/progress/result
+	CapabilityReachability   Capability = "reachability"    // Only network reachability is monitored
+	CapabilityProtocolImport Capability = "protocol_import" // Historical cycle protocols can be imported
+	CapabilityDeviceInfo     Capability = "device_info"     // Model, serial number and firmware can be read
+)
+
+// FactoryOptions holds manager-supplied settings for creating an adapter
+type FactoryOptions struct {
+	// Endpoint overrides the host:port the adapter talks to ("" = derive from device IP),
+	// used by the device simulator
+	Endpoint string
+}
+
+// Factory creates an adapter for a device of the registered manufacturer
+type Factory func(device *database.Device, opts FactoryOptions) (DeviceAdapter, error)
+
+// Registration describes a device family
+type Registration struct {
+	Manufacturer string       // Matches database.Device.Manufacturer
+	Capabilities []Capability // Features all devices of this family support
+	New          Factory
+}
+
+// HasCapability reports whether the device family declares the capability
+func (r Registration) HasCapability(capability Capability) bool {
+	for _, c := range r.Capabilities {
+		if c == capability {
+			return true
+		}
+	}
+	return false
+}
+
+var registry = make(map[string]Registration)
+var registryMutex sync.RWMutex
+
+// Register makes a device family available to the device manager
+// Manufacturer packages call it from init(); registering a manufacturer twice panics.
+func Register(registration Registration) {
+	if registration.Manufacturer == "" || registration.New == nil {
+		panic("adapters: Register requires manufacturer and factory")
+	}
+
+	registryMutex.Lock()
+	defer registryMutex.Unlock()
+
+	if _, exists := registry[registration.Manufacturer]; exists {
+		panic(fmt.Sprintf("adapters: manufacturer %s registered twice", registration.Manufacturer))
+	}
+	registry[registration.Manufacturer] = registration
+}
+
+// Lookup returns the registration for a manufacturer
+func Lookup(manufacturer string) (Registration, bool) {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	registration, exists := registry[manufacturer]
+	return registration, exists
+}
+
+// Manufacturers returns all registered manufacturers in alphabetical order
+func Manufacturers() []string {
+	registryMutex.RLock()
+	defer registryMutex.RUnlock()
+
+	manufacturers := make([]string, 0, len(registry))
+	for manufacturer := range registry {
+		manufacturers = append(manufacturers, manufacturer)
+	}
+	sort.Strings(manufacturers)
+	return manufacturers
+}
@@ -0,0 +1,44 @@
+package adapters
+
+import (
+	"testing"
+
+	"steri-connect-go/internal/database"
+)
+
+func TestRegistry(t *testing.T) {
+	factory := func(device *database.Device, opts FactoryOptions) (DeviceAdapter, error) {
+		return nil, nil
+	}
+	Register(Registration{
+		Manufacturer: "TestVendor",
+		Capabilities: []Capability{CapabilityReachability},
+		New:          factory,
+	})
+
+	registration, exists := Lookup("TestVendor")
+	if !exists {
+		t.Fatalf("Expected TestVendor to be registered")
+	}
+	if !registration.HasCapability(CapabilityReachability) || registration.HasCapability(CapabilityCycleStart) {
+		t.Errorf("Unexpected capabilities %v", registration.Capabilities)
+	}
+	if _, exists := Lookup("Unknown"); exists {
+		t.Errorf("Expected unknown manufacturer not to be registered")
+	}
+
+	found := false
+	for _, manufacturer := range Manufacturers() {
+		found = found || manufacturer == "TestVendor"
+	}
+	if !found {
+		t.Errorf("Expected TestVendor in %v", Manufacturers())
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Errorf("Expected duplicate registration to panic")
+		}
+	}()
+	Register(Registration{Manufacturer: "TestVendor", New: factory})
+}
@@ -0,0 +1,314 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"steri-connect-go/internal/csv"
+	"steri-connect-go/internal/database"
+	"steri-connect-go/internal/logging"
+	"steri-connect-go/internal/pdf"
+)
+
+const (
+	defaultAuditPageSize = 100
+	maxAuditPageSize     = 1000
+)
+
+// ListAuditResponse represents one page of audit log entries
+type ListAuditResponse struct {
+	Entries    []database.AuditLog `json:"entries"`
+	Limit      int                 `json:"limit"`
+	NextCursor int                 `json:"next_cursor,omitempty"` // Pass as cursor for the next page, absent on the last page
+}
+
+// AuditExportResponse represents an audit log export with the state of the hash chain
+type AuditExportResponse struct {
+	ExportedAt time.Time                  `json:"exported_at"`
+	Filter     string                     `json:"filter,omitempty"`
+	Chain      *database.AuditChainReport `json:"chain"`
+	Count      int                        `json:"count"`
+	Entries    []database.AuditLog        `json:"entries"`
+}
+
+// ListAuditHandler handles GET /api/audit requests
+// Entries are returned newest first; next_cursor leads to the following page.
+func ListAuditHandler(w http.ResponseWriter, r *http.Request) {
+	filter, ok := parseAuditFilter(w, r)
+	if !ok {
+		return
+	}
+	if filter.Limit == 0 {
+		filter.Limit = defaultAuditPageSize
+	}
+	if filter.Limit > maxAuditPageSize {
+		filter.Limit = maxAuditPageSize
+	}
+
+	// One extra entry tells whether another page follows
+	limit := filter.Limit
+	filter.Limit++
+	entries, ok := queryAuditLogs(w, filter)
+	if !ok {
+		return
+	}
+
+	response := ListAuditResponse{Entries: entries, Limit: limit}
+	if len(entries) > limit {
+		response.Entries = entries[:limit]
+		response.NextCursor = entries[limit-1].ID
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// ExportAuditCSVHandler handles GET /api/audit/export/csv requests
+func ExportAuditCSVHandler(w http.ResponseWriter, r *http.Request) {
+	filter, ok := parseAuditFilter(w, r)
+	if !ok {
+		return
+	}
+	entries, ok := queryAuditLogs(w, filter)
+	if !ok {
+		return
+	}
+
+	csvBytes, err := csv.GenerateAuditCSV(entries)
+	if err != nil {
+		logging.Get().Error("Failed to generate audit CSV", "error", err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to generate CSV",
+		})
+		return
+	}
+
+	filename := fmt.Sprintf("audit-log-%s.csv", time.Now().Format("2006-01-02"))
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	w.WriteHeader(http.StatusOK)
+	w.Write(csvBytes)
+}
+
+// ExportAuditJSONHandler handles GET /api/audit/export/json requests
+func ExportAuditJSONHandler(w http.ResponseWriter, r *http.Request) {
+	filter, ok := parseAuditFilter(w, r)
+	if !ok {
+		return
+	}
+	entries, ok := queryAuditLogs(w, filter)
+	if !ok {
+		return
+	}
+	chain, ok := verifyAuditChain(w)
+	if !ok {
+		return
+	}
+
+	filename := fmt.Sprintf("audit-log-%s.json", time.Now().Format("2006-01-02"))
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(AuditExportResponse{
+		ExportedAt: time.Now(),
+		Filter:     describeAuditFilter(r),
+		Chain:      chain,
+		Count:      len(entries),
+		Entries:    entries,
+	})
+}
+
+// ExportAuditPDFHandler handles GET /api/audit/export/pdf requests
+func ExportAuditPDFHandler(w http.ResponseWriter, r *http.Request) {
+	logger := logging.Get()
+
+	filter, ok := parseAuditFilter(w, r)
+	if !ok {
+		return
+	}
+	entries, ok := queryAuditLogs(w, filter)
+	if !ok {
+		return
+	}
+	chain, ok := verifyAuditChain(w)
+	if !ok {
+		return
+	}
+
+	pdfBytes, err := pdf.GenerateAuditPDF(entries, chain, describeAuditFilter(r))
+	if err != nil {
+		logger.Error("Failed to generate audit PDF", "error", err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to generate PDF",
+		})
+		return
+	}
+
+	filename := fmt.Sprintf("audit-log-%s.pdf", time.Now().Format("2006-01-02"))
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	w.Header().Set("Content-Length", fmt.Sprintf("%d", len(pdfBytes)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(pdfBytes)
+}
+
+// VerifyAuditHandler handles GET /api/audit/verify requests
+// Recomputes the audit log hash chain and reports the first broken link.
+func VerifyAuditHandler(w http.ResponseWriter, r *http.Request) {
+	report, ok := verifyAuditChain(w)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(report)
+}
+
+// verifyAuditChain verifies the audit log hash chain, writing a 500 response if that fails
+func verifyAuditChain(w http.ResponseWriter) (*database.AuditChainReport, bool) {
+	logger := logging.Get()
+
+	report, err := database.VerifyAuditChain()
+	if err != nil {
+		logger.Error("Failed to verify audit log", "error", err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to verify audit log",
+		})
+		return nil, false
+	}
+
+	if report.Valid {
+		logger.Info("Audit log verified", "entries", report.Entries, "verified", report.Verified, "legacy_entries", report.LegacyEntries)
+	} else {
+		logger.Error("Audit log integrity violated",
+			"entry_id", report.FirstBroken.ID,
+			"reason", report.FirstBroken.Reason,
+			"entries", report.Entries)
+	}
+
+	return report, true
+}
+
+// queryAuditLogs queries the audit log, writing a 500 response if that fails
+func queryAuditLogs(w http.ResponseWriter, filter database.AuditLogFilter) ([]database.AuditLog, bool) {
+	entries, err := database.QueryAuditLogs(filter)
+	if err != nil {
+		logging.Get().Error("Failed to retrieve audit logs", "error", err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to retrieve audit logs",
+		})
+		return nil, false
+	}
+	return entries, true
+}
+
+// parseAuditFilter reads the audit log filters from the query string,
+// writing a 400 response if a value is invalid
+func parseAuditFilter(w http.ResponseWriter, r *http.Request) (database.AuditLogFilter, bool) {
+	query := r.URL.Query()
+	filter := database.AuditLogFilter{
+		EntityType: strings.TrimSpace(query.Get("entity_type")),
+		User:       strings.TrimSpace(query.Get("user")),
+		Search:     strings.TrimSpace(query.Get("q")),
+	}
+
+	invalid := func(code, message string) (database.AuditLogFilter, bool) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   code,
+			Message: message,
+		})
+		return database.AuditLogFilter{}, false
+	}
+
+	// Several actions as comma-separated list or repeated parameter
+	for _, value := range query["action"] {
+		for _, action := range strings.Split(value, ",") {
+			if action = strings.TrimSpace(action); action != "" {
+				filter.Actions = append(filter.Actions, action)
+			}
+		}
+	}
+
+	if entityIDStr := query.Get("entity_id"); entityIDStr != "" {
+		entityID, err := strconv.Atoi(entityIDStr)
+		if err != nil {
+			return invalid("invalid_entity_id", "Entity ID must be an integer")
+		}
+		filter.EntityID = &entityID
+	}
+
+	if startDateStr := query.Get("start_date"); startDateStr != "" {
+		startDate, err := time.Parse(time.RFC3339, startDateStr)
+		if err != nil {
+			// Try alternative format
+			startDate, err = time.ParseInLocation("2006-01-02", startDateStr, time.Local)
+			if err != nil {
+				return invalid("invalid_start_date", "Start date must be in RFC3339 or YYYY-MM-DD format")
+			}
+		}
+		filter.From = &startDate
+	}
+
+	if endDateStr := query.Get("end_date"); endDateStr != "" {
+		endDate, err := time.Parse(time.RFC3339, endDateStr)
+		if err != nil {
+			// Try alternative format, including the whole day
+			endDate, err = time.ParseInLocation("2006-01-02", endDateStr, time.Local)
+			if err != nil {
+				return invalid("invalid_end_date", "End date must be in RFC3339 or YYYY-MM-DD format")
+			}
+			endDate = endDate.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		}
+		filter.To = &endDate
+	}
+
+	if cursorStr := query.Get("cursor"); cursorStr != "" {
+		cursor, err := strconv.Atoi(cursorStr)
+		if err != nil || cursor < 1 {
+			return invalid("invalid_cursor", "Cursor must be a positive integer")
+		}
+		filter.BeforeID = cursor
+	}
+
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 1 {
+			return invalid("invalid_limit", "Limit must be a positive integer")
+		}
+		filter.Limit = limit
+	}
+
+	return filter, true
+}
+
+// describeAuditFilter returns the filter parameters of an export request for the export header
+func describeAuditFilter(r *http.Request) string {
+	query := r.URL.Query()
+	var parts []string
+	for _, key := range []string{"action", "entity_type", "entity_id", "user", "start_date", "end_date", "q", "cursor", "limit"} {
+		if values, ok := query[key]; ok {
+			parts = append(parts, fmt.Sprintf("%s=%s", key, strings.Join(values, ",")))
+		}
+	}
+	return strings.Join(parts, " ")
+}
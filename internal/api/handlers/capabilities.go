@@ -0,0 +1,176 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"steri-connect-go/internal/adapters"
+	"steri-connect-go/internal/database"
+	"steri-connect-go/internal/devices"
+	"steri-connect-go/internal/logging"
+)
+
+// deviceCapabilities returns what a device supports: from its loaded adapter, or as
+// declared by its device family while no adapter is loaded
+func deviceCapabilities(device *database.Device) []adapters.Capability {
+	if deviceManager := devices.GetManager(); deviceManager != nil {
+		if capabilities := deviceManager.GetCapabilities(device.ID); capabilities != nil {
+			return capabilities
+		}
+	}
+
+	registration, exists := adapters.Lookup(device.Manufacturer)
+	if !exists {
+		return []adapters.Capability{}
+	}
+	return append([]adapters.Capability{}, registration.Capabilities...)
+}
+
+// capabilityAdapter returns the adapter of a device that supports the capability and is
+// connected. Otherwise it writes the error response and returns false:
+// 501 if the device does not support the capability, 503 if no adapter is loaded and
+// 409 if the device supports it but is not connected right now.
+func capabilityAdapter(w http.ResponseWriter, device *database.Device, capability adapters.Capability) (adapters.DeviceAdapter, bool) {
+	registration, exists := adapters.Lookup(device.Manufacturer)
+	if !exists || !registration.HasCapability(capability) {
+		writeCapabilityNotSupported(w, device, capability)
+		return nil, false
+	}
+
+	deviceManager := devices.GetManager()
+	if deviceManager == nil {
+		logging.Get().Error("Device manager not initialized")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "service_unavailable",
+			Message: "Device manager not initialized",
+		})
+		return nil, false
+	}
+
+	adapter := deviceManager.GetAdapter(device.ID)
+	if adapter == nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "device_not_connected",
+			Message: fmt.Sprintf("Device %d is not connected", device.ID),
+		})
+		return nil, false
+	}
+
+	if !deviceManager.HasCapability(device.ID, capability) {
+		writeCapabilityNotSupported(w, device, capability)
+		return nil, false
+	}
+
+	if !adapter.IsConnected() {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "device_not_ready",
+			Message: fmt.Sprintf("Device %d supports %s but is not connected (state %s)", device.ID, capability, adapter.GetConnectionState()),
+		})
+		return nil, false
+	}
+
+	return adapter, true
+}
+
+// writeCapabilityNotSupported writes the 501 response for an unsupported capability
+func writeCapabilityNotSupported(w http.ResponseWriter, device *database.Device, capability adapters.Capability) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusNotImplemented)
+	json.NewEncoder(w).Encode(ErrorResponse{
+		Error:   "capability_not_supported",
+		Message: fmt.Sprintf("Device %d (%s) does not support %s", device.ID, device.Manufacturer, capability),
+	})
+}
+
+// GetDeviceInfoHandler handles GET /api/devices/{id}/info requests
+// Returns model, serial number and firmware as reported by the device.
+func GetDeviceInfoHandler(w http.ResponseWriter, r *http.Request) {
+	logger := logging.Get()
+
+	deviceID, err := extractDeviceIDFromInfoPath(r.URL.Path)
+	if err != nil {
+		logger.Warn("Failed to extract device ID from info path", "error", err, "path", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "invalid_device_id",
+			Message: "Invalid device ID in URL path",
+		})
+		return
+	}
+
+	device, ok := lookupDevice(w, deviceID)
+	if !ok {
+		return
+	}
+
+	adapter, ok := capabilityAdapter(w, device, adapters.CapabilityDeviceInfo)
+	if !ok {
+		return
+	}
+
+	info, err := adapter.(adapters.DeviceInfoProvider).GetDeviceInfo()
+	if err != nil {
+		logger.Error("Failed to get device info", "error", err, "device_id", deviceID)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadGateway)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "device_error",
+			Message: "Failed to read device info: " + err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(info)
+}
+
+// lookupDevice loads a device, writing a 404/500 error response if that fails
+func lookupDevice(w http.ResponseWriter, deviceID int) (*database.Device, bool) {
+	device, err := database.GetDevice(deviceID)
+	if err == database.ErrDeviceNotFound {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "device_not_found",
+			Message: fmt.Sprintf("Device with ID %d not found", deviceID),
+		})
+		return nil, false
+	}
+	if err != nil {
+		logging.Get().Error("Failed to get device", "error", err, "device_id", deviceID)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to retrieve device",
+		})
+		return nil, false
+	}
+	return device, true
+}
+
+// extractDeviceIDFromInfoPath extracts device ID from URL path like "/devices/1/info"
+func extractDeviceIDFromInfoPath(path string) (int, error) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) != 3 || parts[0] != "devices" || parts[2] != "info" {
+		return 0, fmt.Errorf("invalid path format: expected /devices/{id}/info")
+	}
+
+	id, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, fmt.Errorf("invalid device ID: %w", err)
+	}
+
+	return id, nil
+}
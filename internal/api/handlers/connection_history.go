@@ -0,0 +1,137 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"steri-connect-go/internal/database"
+	"steri-connect-go/internal/logging"
+)
+
+// Limits of the connection history
+const (
+	defaultConnectionHistoryLimit = 100
+	maxConnectionHistoryLimit     = 1000
+)
+
+// ConnectionHistoryResponse represents the connection state changes of a device
+type ConnectionHistoryResponse struct {
+	DeviceID    int                                   `json:"device_id"`
+	Current     *database.DeviceConnectionState       `json:"current,omitempty"`
+	Transitions []database.DeviceConnectionTransition `json:"transitions"`
+}
+
+// GetDeviceConnectionHistoryHandler handles GET /api/devices/{id}/connection-history requests
+// Returns the current connection state and its changes, newest first.
+// Query parameters: from, to (RFC3339 or YYYY-MM-DD), limit (default 100, max 1000).
+func GetDeviceConnectionHistoryHandler(w http.ResponseWriter, r *http.Request) {
+	logger := logging.Get()
+
+	deviceID, err := extractDeviceIDFromConnectionHistoryPath(r.URL.Path)
+	if err != nil {
+		logger.Warn("Failed to extract device ID from connection history path", "error", err, "path", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "invalid_device_id",
+			Message: "Invalid device ID in URL path",
+		})
+		return
+	}
+
+	if _, ok := lookupDevice(w, deviceID); !ok {
+		return
+	}
+
+	query := r.URL.Query()
+	var from, to *time.Time
+	if fromStr := query.Get("from"); fromStr != "" {
+		t, err := parseReachabilityTime(fromStr)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{
+				Error:   "invalid_from",
+				Message: "from must be in RFC3339 or YYYY-MM-DD format",
+			})
+			return
+		}
+		from = &t
+	}
+	if toStr := query.Get("to"); toStr != "" {
+		t, err := parseReachabilityTime(toStr)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{
+				Error:   "invalid_to",
+				Message: "to must be in RFC3339 or YYYY-MM-DD format",
+			})
+			return
+		}
+		to = &t
+	}
+
+	limit := defaultConnectionHistoryLimit
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err = strconv.Atoi(limitStr)
+		if err != nil || limit < 1 || limit > maxConnectionHistoryLimit {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{
+				Error:   "invalid_limit",
+				Message: fmt.Sprintf("limit must be between 1 and %d", maxConnectionHistoryLimit),
+			})
+			return
+		}
+	}
+
+	response := ConnectionHistoryResponse{DeviceID: deviceID}
+	current, err := database.GetDeviceConnectionState(deviceID)
+	if err != nil && err != database.ErrConnectionStateNotFound {
+		logger.Error("Failed to get connection state", "error", err, "device_id", deviceID)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to retrieve connection state",
+		})
+		return
+	}
+	response.Current = current
+
+	response.Transitions, err = database.GetDeviceConnectionHistory(deviceID, from, to, limit)
+	if err != nil {
+		logger.Error("Failed to get connection history", "error", err, "device_id", deviceID)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to retrieve connection history",
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// extractDeviceIDFromConnectionHistoryPath extracts device ID from URL path like "/devices/1/connection-history"
+func extractDeviceIDFromConnectionHistoryPath(path string) (int, error) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) != 3 || parts[0] != "devices" || parts[2] != "connection-history" {
+		return 0, fmt.Errorf("invalid path format: expected /devices/{id}/connection-history")
+	}
+
+	id, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, fmt.Errorf("invalid device ID: %w", err)
+	}
+
+	return id, nil
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"fmt"
+
+	"steri-connect-go/internal/database"
+)
+
+// ConnectionSettingsRequest represents per-device connection settings in a device update
+// Omitted fields keep their current value; an empty password removes the stored password.
+type ConnectionSettingsRequest struct {
+	Port           *int    `json:"port,omitempty"`
+	Username       *string `json:"username,omitempty"`
+	Password       *string `json:"password,omitempty"`
+	TimeoutSeconds *int    `json:"timeout_seconds,omitempty"`
+	FTPMode        *string `json:"ftp_mode,omitempty"` // "passive" or "pasv"
+	TLSMode        *string `json:"tls_mode,omitempty"` // "none", "explicit" or "implicit"
+	TLSSkipVerify  *bool   `json:"tls_skip_verify,omitempty"`
+}
+
+// ConnectionSettingsResponse represents the connection settings returned for a device
+// The password itself is never returned.
+type ConnectionSettingsResponse struct {
+	Port           int    `json:"port,omitempty"`
+	Username       string `json:"username,omitempty"`
+	PasswordSet    bool   `json:"password_set"`
+	TimeoutSeconds int    `json:"timeout_seconds,omitempty"`
+	FTPMode        string `json:"ftp_mode,omitempty"`
+	TLSMode        string `json:"tls_mode,omitempty"`
+	TLSSkipVerify  bool   `json:"tls_skip_verify,omitempty"`
+	Updated        string `json:"updated"`
+}
+
+// validateConnectionSettingsRequest validates the connection settings of a device update
+func validateConnectionSettingsRequest(req *ConnectionSettingsRequest) error {
+	if req.Port != nil && (*req.Port < 0 || *req.Port > 65535) {
+		return fmt.Errorf("connection.port must be between 1 and 65535 (0 for default)")
+	}
+	if req.TimeoutSeconds != nil && (*req.TimeoutSeconds < 0 || *req.TimeoutSeconds > 300) {
+		return fmt.Errorf("connection.timeout_seconds must be between 1 and 300 (0 for default)")
+	}
+	if req.FTPMode != nil {
+		switch *req.FTPMode {
+		case "", database.FTPModePassive, database.FTPModePASV:
+		case database.FTPModeActive:
+			return fmt.Errorf("connection.ftp_mode 'active' is not supported, use 'passive' or 'pasv'")
+		default:
+			return fmt.Errorf("connection.ftp_mode must be 'passive' or 'pasv'")
+		}
+	}
+	if req.TLSMode != nil {
+		switch *req.TLSMode {
+		case "", database.TLSModeNone, database.TLSModeExplicit, database.TLSModeImplicit:
+		default:
+			return fmt.Errorf("connection.tls_mode must be 'none', 'explicit' or 'implicit'")
+		}
+	}
+	return nil
+}
+
+// applyConnectionSettingsRequest merges the request into the device's stored settings and saves them
+func applyConnectionSettingsRequest(deviceID int, req *ConnectionSettingsRequest) (*database.DeviceConnectionSettings, error) {
+	settings, err := database.GetDeviceConnectionSettings(deviceID)
+	if err == database.ErrConnectionSettingsNotFound {
+		settings = &database.DeviceConnectionSettings{DeviceID: deviceID}
+	} else if err != nil {
+		return nil, err
+	}
+
+	if req.Port != nil {
+		settings.Port = *req.Port
+	}
+	if req.Username != nil {
+		settings.Username = *req.Username
+	}
+	if req.Password != nil {
+		if err := settings.SetPassword(*req.Password); err != nil {
+			return nil, err
+		}
+	}
+	if req.TimeoutSeconds != nil {
+		settings.TimeoutSeconds = *req.TimeoutSeconds
+	}
+	if req.FTPMode != nil {
+		settings.FTPMode = *req.FTPMode
+	}
+	if req.TLSMode != nil {
+		settings.TLSMode = *req.TLSMode
+	}
+	if req.TLSSkipVerify != nil {
+		settings.TLSSkipVerify = *req.TLSSkipVerify
+	}
+
+	if err := database.SaveDeviceConnectionSettings(settings); err != nil {
+		return nil, err
+	}
+	return settings, nil
+}
+
+// connectionSettingsResponse converts stored settings into their masked API representation
+func connectionSettingsResponse(settings *database.DeviceConnectionSettings) *ConnectionSettingsResponse {
+	if settings == nil {
+		return nil
+	}
+	return &ConnectionSettingsResponse{
+		Port:           settings.Port,
+		Username:       settings.Username,
+		PasswordSet:    settings.PasswordSet(),
+		TimeoutSeconds: settings.TimeoutSeconds,
+		FTPMode:        settings.FTPMode,
+		TLSMode:        settings.TLSMode,
+		TLSSkipVerify:  settings.TLSSkipVerify,
+		Updated:        settings.Updated.Format("2006-01-02T15:04:05Z07:00"),
+	}
+}
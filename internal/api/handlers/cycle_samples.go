@@ -0,0 +1,130 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"steri-connect-go/internal/database"
+	"steri-connect-go/internal/logging"
+)
+
+// CycleSamplesResponse represents the process value curve of a cycle
+type CycleSamplesResponse struct {
+	CycleID  int                    `json:"cycle_id"`
+	Interval float64                `json:"interval_s"` // Downsampling interval applied, 0 = all stored samples
+	Total    int                    `json:"total"`      // Samples stored for the cycle
+	Count    int                    `json:"count"`      // Samples returned
+	Samples  []database.CycleSample `json:"samples"`
+}
+
+// GetCycleSamplesHandler handles GET /api/cycles/{id}/samples requests
+// Returns the recorded process values of a cycle, oldest first.
+// Query parameters: interval (seconds between returned samples) or max_points (approximate
+// number of samples); phase boundaries and the last sample are always returned.
+func GetCycleSamplesHandler(w http.ResponseWriter, r *http.Request) {
+	logger := logging.Get()
+
+	cycleID, err := extractCycleIDFromSamplesPath(r.URL.Path)
+	if err != nil {
+		logger.Warn("Failed to extract cycle ID from samples path", "error", err, "path", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "invalid_cycle_id",
+			Message: "Invalid cycle ID in URL path",
+		})
+		return
+	}
+
+	query := r.URL.Query()
+	var interval time.Duration
+	if intervalStr := query.Get("interval"); intervalStr != "" {
+		seconds, err := strconv.ParseFloat(intervalStr, 64)
+		if err != nil || seconds <= 0 || math.IsInf(seconds, 0) {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{
+				Error:   "invalid_interval",
+				Message: "interval must be a positive number of seconds",
+			})
+			return
+		}
+		interval = time.Duration(seconds * float64(time.Second))
+	}
+	maxPoints := 0
+	if maxPointsStr := query.Get("max_points"); maxPointsStr != "" {
+		maxPoints, err = strconv.Atoi(maxPointsStr)
+		if err != nil || maxPoints < 2 {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{
+				Error:   "invalid_max_points",
+				Message: "max_points must be an integer of at least 2",
+			})
+			return
+		}
+	}
+	if interval > 0 && maxPoints > 0 {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "invalid_parameters",
+			Message: "Use either interval or max_points, not both",
+		})
+		return
+	}
+
+	if _, ok := lookupCycle(w, cycleID); !ok {
+		return
+	}
+
+	samples, err := database.GetCycleSamples(cycleID)
+	if err != nil {
+		logger.Error("Failed to get cycle samples", "error", err, "cycle_id", cycleID)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to retrieve cycle samples",
+		})
+		return
+	}
+
+	// Spread max_points evenly over the recorded time span
+	if maxPoints > 0 && len(samples) > maxPoints {
+		span := samples[len(samples)-1].Timestamp.Sub(samples[0].Timestamp)
+		interval = span / time.Duration(maxPoints-1)
+	}
+
+	response := CycleSamplesResponse{
+		CycleID:  cycleID,
+		Interval: interval.Seconds(),
+		Total:    len(samples),
+		Samples:  database.DownsampleCycleSamples(samples, interval),
+	}
+	response.Count = len(response.Samples)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// extractCycleIDFromSamplesPath extracts cycle ID from URL path like "/cycles/42/samples"
+func extractCycleIDFromSamplesPath(path string) (int, error) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) != 3 || parts[0] != "cycles" || parts[2] != "samples" {
+		return 0, fmt.Errorf("invalid path format: expected /cycles/{id}/samples")
+	}
+
+	id, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, fmt.Errorf("invalid cycle ID: %w", err)
+	}
+
+	return id, nil
+}
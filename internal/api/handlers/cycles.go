@@ -8,10 +8,12 @@ import (
 	"strings"
 	"time"
 
+	"steri-connect-go/internal/config"
 	"steri-connect-go/internal/database"
 	"steri-connect-go/internal/logging"
 	"steri-connect-go/internal/pdf"
 	"steri-connect-go/internal/csv"
+	"steri-connect-go/internal/validation"
 )
 
 // ListCyclesResponse represents the response for listing cycles
@@ -133,18 +135,49 @@ func ListCyclesHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Filtering by result
 	if result := r.URL.Query().Get("result"); result != "" {
-		if result != "OK" && result != "NOK" {
+		if result != "OK" && result != "NOK" && result != database.CycleResultUnknown {
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusBadRequest)
 			json.NewEncoder(w).Encode(ErrorResponse{
 				Error:   "invalid_result",
-				Message: "Result must be 'OK' or 'NOK'",
+				Message: "Result must be 'OK', 'NOK' or 'UNKNOWN'",
 			})
 			return
 		}
 		options.Result = &result
 	}
 
+	// Filtering by instrument set in the load
+	if setIDStr := r.URL.Query().Get("set_id"); setIDStr != "" {
+		setID, err := strconv.Atoi(setIDStr)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{
+				Error:   "invalid_set_id",
+				Message: "Set ID must be an integer",
+			})
+			return
+		}
+		options.SetID = &setID
+	}
+
+	// Filtering by release state
+	if releaseStatus := r.URL.Query().Get("release_status"); releaseStatus != "" {
+		switch releaseStatus {
+		case database.ReleaseStatusCompleted, database.ReleaseStatusPending, database.ReleaseStatusReleased, database.ReleaseStatusRejected:
+			options.ReleaseStatus = &releaseStatus
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{
+				Error:   "invalid_release_status",
+				Message: "Release status must be 'completed', 'pending_release', 'released' or 'rejected'",
+			})
+			return
+		}
+	}
+
 	// Retrieve cycles from database
 	cycles, totalCount, err := database.GetAllCycles(options)
 	if err != nil {
@@ -308,8 +341,64 @@ func ExportCyclePDFHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	loads, err := database.GetCycleLoads(cycleID)
+	if err != nil {
+		logger.Error("Failed to get cycle loads", "error", err, "cycle_id", cycleID)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to retrieve cycle loads",
+		})
+		return
+	}
+
+	releases, err := database.GetCycleReleases(cycleID)
+	if err != nil {
+		logger.Error("Failed to get cycle release history", "error", err, "cycle_id", cycleID)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to retrieve release history",
+		})
+		return
+	}
+
+	samples, err := database.GetCycleSamples(cycleID)
+	if err != nil {
+		logger.Error("Failed to get cycle samples", "error", err, "cycle_id", cycleID)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to retrieve cycle samples",
+		})
+		return
+	}
+
+	// The phase table shows the hold as the parameter validation counts it
+	var hold *validation.Hold
+	if h, ok := validation.FindHold(config.Get().Validation.Profiles, cycle.Program, samples); ok {
+		hold = &h
+	}
+
+	validation, err := database.GetCycleValidation(cycleID)
+	if err == database.ErrValidationNotFound {
+		validation = nil
+	} else if err != nil {
+		logger.Error("Failed to get cycle validation", "error", err, "cycle_id", cycleID)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to retrieve cycle validation",
+		})
+		return
+	}
+
 	// Generate PDF
-	pdfBytes, err := pdf.GenerateCyclePDF(cycle)
+	pdfBytes, err := pdf.GenerateCyclePDF(cycle, loads, releases, samples, validation, hold)
 	if err != nil {
 		logger.Error("Failed to generate PDF", "error", err, "cycle_id", cycleID)
 		w.Header().Set("Content-Type", "application/json")
@@ -443,18 +532,49 @@ func ExportCyclesCSVHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Filtering by result
 	if result := r.URL.Query().Get("result"); result != "" {
-		if result != "OK" && result != "NOK" {
+		if result != "OK" && result != "NOK" && result != database.CycleResultUnknown {
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusBadRequest)
 			json.NewEncoder(w).Encode(ErrorResponse{
 				Error:   "invalid_result",
-				Message: "Result must be 'OK' or 'NOK'",
+				Message: "Result must be 'OK', 'NOK' or 'UNKNOWN'",
 			})
 			return
 		}
 		options.Result = &result
 	}
 
+	// Filtering by instrument set in the load
+	if setIDStr := r.URL.Query().Get("set_id"); setIDStr != "" {
+		setID, err := strconv.Atoi(setIDStr)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{
+				Error:   "invalid_set_id",
+				Message: "Set ID must be an integer",
+			})
+			return
+		}
+		options.SetID = &setID
+	}
+
+	// Filtering by release state
+	if releaseStatus := r.URL.Query().Get("release_status"); releaseStatus != "" {
+		switch releaseStatus {
+		case database.ReleaseStatusCompleted, database.ReleaseStatusPending, database.ReleaseStatusReleased, database.ReleaseStatusRejected:
+			options.ReleaseStatus = &releaseStatus
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{
+				Error:   "invalid_release_status",
+				Message: "Release status must be 'completed', 'pending_release', 'released' or 'rejected'",
+			})
+			return
+		}
+	}
+
 	// Retrieve cycles from database
 	cycles, _, err := database.GetAllCycles(options)
 	if err != nil {
@@ -603,18 +723,49 @@ func ExportCyclesJSONHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Filtering by result
 	if result := r.URL.Query().Get("result"); result != "" {
-		if result != "OK" && result != "NOK" {
+		if result != "OK" && result != "NOK" && result != database.CycleResultUnknown {
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusBadRequest)
 			json.NewEncoder(w).Encode(ErrorResponse{
 				Error:   "invalid_result",
-				Message: "Result must be 'OK' or 'NOK'",
+				Message: "Result must be 'OK', 'NOK' or 'UNKNOWN'",
 			})
 			return
 		}
 		options.Result = &result
 	}
 
+	// Filtering by instrument set in the load
+	if setIDStr := r.URL.Query().Get("set_id"); setIDStr != "" {
+		setID, err := strconv.Atoi(setIDStr)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{
+				Error:   "invalid_set_id",
+				Message: "Set ID must be an integer",
+			})
+			return
+		}
+		options.SetID = &setID
+	}
+
+	// Filtering by release state
+	if releaseStatus := r.URL.Query().Get("release_status"); releaseStatus != "" {
+		switch releaseStatus {
+		case database.ReleaseStatusCompleted, database.ReleaseStatusPending, database.ReleaseStatusReleased, database.ReleaseStatusRejected:
+			options.ReleaseStatus = &releaseStatus
+		default:
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{
+				Error:   "invalid_release_status",
+				Message: "Release status must be 'completed', 'pending_release', 'released' or 'rejected'",
+			})
+			return
+		}
+	}
+
 	// Retrieve cycles from database
 	cycles, totalCount, err := database.GetAllCycles(options)
 	if err != nil {
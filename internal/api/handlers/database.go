@@ -308,8 +308,8 @@ func getTableData(tableName string, limit, offset int, deviceID, startDate, endD
 
 	switch tableName {
 	case "devices":
-		columns = []string{"id", "name", "model", "manufacturer", "ip", "serial", "type", "location", "created", "updated"}
-		query = "SELECT id, name, model, manufacturer, ip, serial, type, location, created, updated FROM devices WHERE 1=1"
+		columns = []string{"id", "name", "model", "manufacturer", "ip", "serial", "type", "location", "last_seen", "created", "updated"}
+		query = "SELECT id, name, model, manufacturer, ip, serial, type, location, last_seen, created, updated FROM devices WHERE 1=1"
 		if deviceID != "" {
 			query += " AND id = ?"
 			args = append(args, deviceID)
@@ -336,8 +336,8 @@ func getTableData(tableName string, limit, offset int, deviceID, startDate, endD
 		args = append(args, limit, offset)
 
 	case "rdg_status":
-		columns = []string{"id", "device_id", "timestamp", "reachable"}
-		query = "SELECT id, device_id, timestamp, reachable FROM rdg_status WHERE 1=1"
+		columns = []string{"id", "device_id", "timestamp", "reachable", "rtt_ms", "packet_loss", "probes", "method"}
+		query = "SELECT id, device_id, timestamp, reachable, rtt_ms, packet_loss, probes, method FROM rdg_status WHERE 1=1"
 		if deviceID != "" {
 			query += " AND device_id = ?"
 			args = append(args, deviceID)
@@ -354,8 +354,8 @@ func getTableData(tableName string, limit, offset int, deviceID, startDate, endD
 		args = append(args, limit, offset)
 
 	case "audit_log":
-		columns = []string{"id", "timestamp", "action", "entity_type", "entity_id", "user", "details", "hash"}
-		query = "SELECT id, timestamp, action, entity_type, entity_id, user, details, hash FROM audit_log WHERE 1=1"
+		columns = []string{"id", "timestamp", "action", "entity_type", "entity_id", "user", "details", "prev_hash", "hash"}
+		query = "SELECT id, timestamp, action, entity_type, entity_id, user, details, prev_hash, hash FROM audit_log WHERE 1=1"
 		if deviceID != "" {
 			query += " AND entity_id = ? AND entity_type = 'device'"
 			args = append(args, deviceID)
@@ -432,6 +432,7 @@ func getTableSchema(tableName string) TableSchemaResponse {
 				{Name: "serial", Type: "TEXT", Nullable: true},
 				{Name: "type", Type: "TEXT", Nullable: false},
 				{Name: "location", Type: "TEXT", Nullable: true},
+				{Name: "last_seen", Type: "DATETIME", Nullable: true},
 				{Name: "created", Type: "DATETIME", Nullable: false},
 				{Name: "updated", Type: "DATETIME", Nullable: false},
 			},
@@ -462,6 +463,10 @@ func getTableSchema(tableName string) TableSchemaResponse {
 				{Name: "device_id", Type: "INTEGER", Nullable: false},
 				{Name: "timestamp", Type: "DATETIME", Nullable: false},
 				{Name: "reachable", Type: "INTEGER", Nullable: false},
+				{Name: "rtt_ms", Type: "REAL", Nullable: true},
+				{Name: "packet_loss", Type: "REAL", Nullable: true},
+				{Name: "probes", Type: "INTEGER", Nullable: true},
+				{Name: "method", Type: "TEXT", Nullable: true},
 			},
 		},
 		"audit_log": {
@@ -474,6 +479,7 @@ func getTableSchema(tableName string) TableSchemaResponse {
 				{Name: "entity_id", Type: "INTEGER", Nullable: true},
 				{Name: "user", Type: "TEXT", Nullable: false},
 				{Name: "details", Type: "TEXT", Nullable: true},
+				{Name: "prev_hash", Type: "TEXT", Nullable: true},
 				{Name: "hash", Type: "TEXT", Nullable: false},
 			},
 		},
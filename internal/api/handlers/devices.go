@@ -8,7 +8,9 @@ import (
 	"strconv"
 	"strings"
 
+	"steri-connect-go/internal/adapters"
 	"steri-connect-go/internal/database"
+	"steri-connect-go/internal/devices"
 	"steri-connect-go/internal/logging"
 )
 
@@ -35,6 +37,8 @@ type CreateDeviceResponse struct {
 	Location     string    `json:"location,omitempty"`
 	Created      string    `json:"created"`
 	Updated      string    `json:"updated"`
+	Connection   *ConnectionSettingsResponse `json:"connection,omitempty"` // Melag devices with stored settings
+	Capabilities []adapters.Capability `json:"capabilities,omitempty"` // Supported features (GET /api/devices/{id})
 }
 
 // ErrorResponse represents an error response
@@ -117,6 +121,13 @@ func CreateDeviceHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Connect the new device in the background
+	if deviceManager := devices.GetManager(); deviceManager != nil {
+		if err := deviceManager.AddDevice(createdDevice); err != nil {
+			logger.Warn("Failed to add device adapter", "error", err, "device_id", createdDevice.ID)
+		}
+	}
+
 	// Log audit entry
 	deviceID := createdDevice.ID
 	details := map[string]interface{}{
@@ -164,6 +175,7 @@ type UpdateDeviceRequest struct {
 	Serial   string `json:"serial,omitempty"`
 	Type     string `json:"type,omitempty"` // "Steri" or "RDG"
 	Location string `json:"location,omitempty"`
+	Connection *ConnectionSettingsRequest `json:"connection,omitempty"` // Melag devices only
 }
 
 // GetDeviceHandler handles GET /api/devices/{id} requests
@@ -229,6 +241,16 @@ func GetDeviceHandler(w http.ResponseWriter, r *http.Request) {
 		Updated:      device.Updated.Format("2006-01-02T15:04:05Z07:00"),
 	}
 
+	response.Capabilities = deviceCapabilities(device)
+
+	// Connection settings are returned without the password
+	settings, err := database.GetDeviceConnectionSettings(deviceID)
+	if err == nil {
+		response.Connection = connectionSettingsResponse(settings)
+	} else if err != database.ErrConnectionSettingsNotFound {
+		logger.Warn("Failed to get connection settings", "error", err, "device_id", deviceID)
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
 	json.NewEncoder(w).Encode(response)
@@ -286,6 +308,39 @@ func UpdateDeviceHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Connection settings only apply to Melag devices (FTP connection to the MELAnet Box)
+	if req.Connection != nil {
+		existingDevice, err := database.GetDevice(deviceID)
+		if err == database.ErrDeviceNotFound {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(ErrorResponse{
+				Error:   "device_not_found",
+				Message: fmt.Sprintf("Device with ID %d not found", deviceID),
+			})
+			return
+		}
+		if err != nil {
+			logger.Error("Failed to get device", "error", err, "device_id", deviceID)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ErrorResponse{
+				Error:   "internal_error",
+				Message: "Failed to retrieve device",
+			})
+			return
+		}
+		if existingDevice.Manufacturer != "Melag" {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{
+				Error:   "validation_error",
+				Message: "connection settings are only supported for Melag devices",
+			})
+			return
+		}
+	}
+
 	// Build update struct
 	updates := &database.Device{
 		Name:     req.Name,
@@ -296,8 +351,13 @@ func UpdateDeviceHandler(w http.ResponseWriter, r *http.Request) {
 		Location: req.Location,
 	}
 
-	// Update device in database
-	updatedDevice, err := database.UpdateDevice(deviceID, updates)
+	// Update device in database (a connection-only update leaves the device row untouched)
+	var updatedDevice *database.Device
+	if req.Connection != nil && *updates == (database.Device{}) {
+		updatedDevice, err = database.GetDevice(deviceID)
+	} else {
+		updatedDevice, err = database.UpdateDevice(deviceID, updates)
+	}
 	if err != nil {
 		logger.Error("Failed to update device", "error", err, "device_id", deviceID)
 
@@ -330,6 +390,31 @@ func UpdateDeviceHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Store connection settings (password encrypted at rest)
+	var settings *database.DeviceConnectionSettings
+	if req.Connection != nil {
+		settings, err = applyConnectionSettingsRequest(deviceID, req.Connection)
+		if err != nil {
+			logger.Error("Failed to save connection settings", "error", err, "device_id", deviceID)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ErrorResponse{
+				Error:   "internal_error",
+				Message: "Failed to save connection settings",
+			})
+			return
+		}
+	}
+
+	// Reconnect with the new address or connection settings
+	if req.Connection != nil || req.IP != "" {
+		if deviceManager := devices.GetManager(); deviceManager != nil {
+			if err := deviceManager.ReloadDevice(deviceID); err != nil {
+				logger.Warn("Failed to reload device adapter", "error", err, "device_id", deviceID)
+			}
+		}
+	}
+
 	// Log audit entry
 	details := map[string]interface{}{
 		"device_id":    updatedDevice.ID,
@@ -338,6 +423,11 @@ func UpdateDeviceHandler(w http.ResponseWriter, r *http.Request) {
 		"ip":           updatedDevice.IP,
 		"type":         updatedDevice.Type,
 	}
+	if req.Connection != nil {
+		// Never log the password itself
+		details["connection_updated"] = true
+		details["password_changed"] = req.Connection.Password != nil
+	}
 
 	if err := database.LogAudit(database.ActionDeviceUpdated, "device", &deviceID, "", details); err != nil {
 		logger.Warn("Failed to create audit log", "error", err)
@@ -362,6 +452,7 @@ func UpdateDeviceHandler(w http.ResponseWriter, r *http.Request) {
 		Location:     updatedDevice.Location,
 		Created:      updatedDevice.Created.Format("2006-01-02T15:04:05Z07:00"),
 		Updated:      updatedDevice.Updated.Format("2006-01-02T15:04:05Z07:00"),
+		Connection:   connectionSettingsResponse(settings),
 	}
 
 	w.Header().Set("Content-Type", "application/json")
@@ -399,6 +490,10 @@ func validateUpdateDeviceRequest(req *UpdateDeviceRequest) error {
 		}
 	}
 
+	if req.Connection != nil {
+		return validateConnectionSettingsRequest(req.Connection)
+	}
+
 	return nil
 }
 
@@ -500,10 +595,50 @@ func DeleteDeviceHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Recall evidence must be kept; check before the device stops being monitored
+	inUse, err := database.HasRecallCases(deviceID)
+	if err != nil {
+		logger.Error("Failed to check recall cases of device", "error", err, "device_id", deviceID)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to delete device",
+		})
+		return
+	}
+	if inUse {
+		writeDeviceInUse(w, device)
+		return
+	}
+
+	// Stop supervising and disconnect before the device is gone
+	deviceManager := devices.GetManager()
+	removed := false
+	if deviceManager != nil && deviceManager.GetAdapter(deviceID) != nil {
+		if err := deviceManager.RemoveDevice(deviceID); err != nil {
+			logger.Warn("Failed to remove device adapter", "error", err, "device_id", deviceID)
+		} else {
+			removed = true
+		}
+	}
+
 	// Delete device from database
 	if err := database.DeleteDevice(deviceID); err != nil {
 		logger.Error("Failed to delete device", "error", err, "device_id", deviceID)
 
+		// The device stays, so it is monitored again
+		if removed {
+			if err := deviceManager.RestoreDevice(deviceID); err != nil {
+				logger.Error("Failed to restore device adapter", "error", err, "device_id", deviceID)
+			}
+		}
+
+		if err == database.ErrDeviceInUse {
+			writeDeviceInUse(w, device)
+			return
+		}
+
 		if err == database.ErrDeviceNotFound {
 			w.Header().Set("Content-Type", "application/json")
 			w.WriteHeader(http.StatusNotFound)
@@ -547,6 +682,16 @@ func DeleteDeviceHandler(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(http.StatusNoContent)
 }
 
+// writeDeviceInUse writes the 409 response for a device that recall cases refer to
+func writeDeviceInUse(w http.ResponseWriter, device *database.Device) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusConflict)
+	json.NewEncoder(w).Encode(ErrorResponse{
+		Error:   "device_in_use",
+		Message: fmt.Sprintf("Device %s is part of a recall case and cannot be deleted", device.Name),
+	})
+}
+
 // validateCreateDeviceRequest validates the create device request
 func validateCreateDeviceRequest(req *CreateDeviceRequest) error {
 	if req.Name == "" {
@@ -556,8 +701,8 @@ func validateCreateDeviceRequest(req *CreateDeviceRequest) error {
 	if req.Manufacturer == "" {
 		return fmt.Errorf("manufacturer is required")
 	}
-	if req.Manufacturer != "Melag" && req.Manufacturer != "Getinge" {
-		return fmt.Errorf("manufacturer must be 'Melag' or 'Getinge'")
+	if _, registered := adapters.Lookup(req.Manufacturer); !registered {
+		return fmt.Errorf("manufacturer must be one of: %s", strings.Join(adapters.Manufacturers(), ", "))
 	}
 
 	if req.IP == "" {
@@ -586,6 +731,10 @@ type DeviceStatusResponse struct {
 	HealthStatus  string     `json:"health_status"`
 	Manufacturer  string     `json:"manufacturer"`
 	IP            string     `json:"ip"`
+	State               string  `json:"state"`
+	StateSince          *string `json:"state_since,omitempty"`
+	LastError           string  `json:"last_error,omitempty"`
+	ConsecutiveFailures int     `json:"consecutive_failures"`
 	ConnectionType   *string `json:"connection_type,omitempty"`
 	LastCycleStatus  *string `json:"last_cycle_status,omitempty"`
 	ICMPReachable *bool      `json:"icmp_reachable,omitempty"`
@@ -650,6 +799,9 @@ func GetDeviceStatusHandler(w http.ResponseWriter, r *http.Request) {
 		HealthStatus: status.HealthStatus,
 		Manufacturer: status.Manufacturer,
 		IP:           status.IP,
+		State:               status.State,
+		LastError:           status.LastError,
+		ConsecutiveFailures: status.ConsecutiveFailures,
 	}
 
 	// Format LastSeen timestamp
@@ -657,6 +809,10 @@ func GetDeviceStatusHandler(w http.ResponseWriter, r *http.Request) {
 		lastSeenStr := status.LastSeen.Format("2006-01-02T15:04:05Z07:00")
 		response.LastSeen = &lastSeenStr
 	}
+	if status.StateSince != nil {
+		sinceStr := status.StateSince.Format("2006-01-02T15:04:05Z07:00")
+		response.StateSince = &sinceStr
+	}
 
 	// Melag-specific fields
 	if status.Manufacturer == "Melag" {
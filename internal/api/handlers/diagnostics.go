@@ -7,6 +7,7 @@ import (
 	"strconv"
 	"time"
 
+	"steri-connect-go/internal/adapters"
 	"steri-connect-go/internal/database"
 	"steri-connect-go/internal/devices"
 	"steri-connect-go/internal/logging"
@@ -22,6 +23,7 @@ type DiagnosticsResponse struct {
 	ProtocolInfo                ProtocolDiagnostics      `json:"protocol_info"`
 	RecentErrors                []ErrorLogEntry          `json:"recent_errors"`
 	ConnectionHistory           []ConnectionHistoryEntry `json:"connection_history"`
+	Reconnect                   *devices.ReconnectStatus `json:"reconnect,omitempty"` // Retry schedule of the connection supervisor
 	Timestamp                   time.Time                `json:"timestamp"`
 }
 
@@ -56,8 +58,10 @@ type GetingeDiagnostics struct {
 
 // PingEntry represents a single ping result
 type PingEntry struct {
-	Timestamp time.Time `json:"timestamp"`
-	Reachable bool      `json:"reachable"`
+	Timestamp  time.Time `json:"timestamp"`
+	Reachable  bool      `json:"reachable"`
+	RTTMs      *float64  `json:"rtt_ms,omitempty"`
+	PacketLoss *float64  `json:"packet_loss,omitempty"`
 }
 
 // ErrorLogEntry represents an error log entry
@@ -67,12 +71,15 @@ type ErrorLogEntry struct {
 	Details   string    `json:"details"`
 }
 
-// ConnectionHistoryEntry represents a connection attempt
+// ConnectionHistoryEntry represents a change of the device's connection state
 type ConnectionHistoryEntry struct {
-	Timestamp time.Time `json:"timestamp"`
-	Success   bool      `json:"success"`
-	Action    string    `json:"action"`
-	Details   string    `json:"details"`
+	Timestamp  time.Time `json:"timestamp"`
+	Success    bool      `json:"success"` // true if the device became connected
+	Action     string    `json:"action"`  // Reason of the change ("connect", "disconnect", "probe", "health_check", "shutdown")
+	FromState  string    `json:"from_state,omitempty"`
+	ToState    string    `json:"to_state"`
+	Error      string    `json:"error,omitempty"`
+	DurationMs *int64    `json:"duration_ms,omitempty"` // Time spent in FromState
 }
 
 // DiagnosticsHandler handles GET /api/diagnostics/{deviceId} requests
@@ -140,6 +147,14 @@ func DiagnosticsHandler(w http.ResponseWriter, r *http.Request) {
 	// Get connection attempt history
 	connectionHistory := getConnectionHistory(deviceID)
 
+	// Get retry schedule of the connection supervisor
+	var reconnect *devices.ReconnectStatus
+	if deviceManager := devices.GetManager(); deviceManager != nil {
+		if status, ok := deviceManager.GetReconnectStatus(deviceID); ok {
+			reconnect = &status
+		}
+	}
+
 	response := DiagnosticsResponse{
 		DeviceID:                    device.ID,
 		DeviceName:                  device.Name,
@@ -149,6 +164,7 @@ func DiagnosticsHandler(w http.ResponseWriter, r *http.Request) {
 		ProtocolInfo:                protocolInfo,
 		RecentErrors:                recentErrors,
 		ConnectionHistory:           connectionHistory,
+		Reconnect:                   reconnect,
 		Timestamp:                   time.Now(),
 	}
 
@@ -178,6 +194,20 @@ func performConnectionTest(deviceID int, manufacturer string) ConnectionTestResu
 		}
 	}
 
+	// Check a connected session in place; reconnecting would drop it
+	if checker, ok := adapter.(adapters.HealthChecker); ok && adapter.IsConnected() {
+		err := checker.CheckHealth()
+		result := ConnectionTestResult{
+			Success:   err == nil,
+			Timestamp: time.Now(),
+			Duration:  time.Since(startTime).String(),
+		}
+		if err != nil {
+			result.Error = err.Error()
+		}
+		return result
+	}
+
 	// Try to connect
 	err := adapter.Connect()
 	duration := time.Since(startTime)
@@ -245,8 +275,10 @@ func getProtocolDiagnostics(deviceID int, manufacturer string) ProtocolDiagnosti
 			pingEntries := make([]PingEntry, 0, len(pingHistory))
 			for _, status := range pingHistory {
 				pingEntries = append(pingEntries, PingEntry{
-					Timestamp: status.Timestamp,
-					Reachable: status.Reachable,
+					Timestamp:  status.Timestamp,
+					Reachable:  status.Reachable,
+					RTTMs:      status.RTTMs,
+					PacketLoss: status.PacketLoss,
 				})
 			}
 
@@ -291,31 +323,24 @@ func getRecentErrors(deviceID int) []ErrorLogEntry {
 	return errors
 }
 
-// getConnectionHistory gets connection attempt history
+// getConnectionHistory gets the latest connection state changes
 func getConnectionHistory(deviceID int) []ConnectionHistoryEntry {
-	// Get audit logs for this device
-	auditLogs, err := database.GetAuditLogs("device", &deviceID, 20)
+	transitions, err := database.GetDeviceConnectionHistory(deviceID, nil, nil, 20)
 	if err != nil {
 		return []ConnectionHistoryEntry{}
 	}
 
-	history := make([]ConnectionHistoryEntry, 0)
-	for _, log := range auditLogs {
-		// Include device-related actions
-		if log.Action == "device_added" || log.Action == "device_updated" || 
-		   log.Action == "rdg_status_update" || log.Action == "cycle_started" {
-			success := true
-			if log.Action == "rdg_status_update" {
-				// Parse details to determine success
-				// Simplified - assume success if action exists
-			}
-			history = append(history, ConnectionHistoryEntry{
-				Timestamp: log.Timestamp,
-				Success:   success,
-				Action:    log.Action,
-				Details:   log.Details,
-			})
-		}
+	history := make([]ConnectionHistoryEntry, 0, len(transitions))
+	for _, transition := range transitions {
+		history = append(history, ConnectionHistoryEntry{
+			Timestamp:  transition.Timestamp,
+			Success:    transition.ToState == database.ConnectionStateConnected,
+			Action:     transition.Reason,
+			FromState:  transition.FromState,
+			ToState:    transition.ToState,
+			Error:      transition.Error,
+			DurationMs: transition.DurationMs,
+		})
 	}
 
 	return history
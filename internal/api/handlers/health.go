@@ -7,7 +7,6 @@ import (
 	"time"
 
 	"steri-connect-go/internal/database"
-	"steri-connect-go/internal/devices"
 	"steri-connect-go/internal/api/websocket"
 )
 
@@ -68,24 +67,18 @@ func HealthHandler(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	// Get device connectivity summary
+	// Get device connectivity summary from the connection states recorded by the device manager
 	deviceSummary := DeviceStatusSummary{}
-	deviceManager := devices.GetManager()
-	if deviceManager != nil {
-		allDevices, err := database.GetAllDevices()
-		if err == nil {
-			deviceSummary.Total = len(allDevices)
-			for _, device := range allDevices {
-				status, err := database.GetDeviceStatus(device.ID)
-				if err == nil {
-					if status.Connected {
-						deviceSummary.Online++
-					} else {
-						deviceSummary.Offline++
-					}
-				} else {
-					deviceSummary.Offline++
-				}
+	if states, err := database.GetDeviceConnectionStates(); err == nil {
+		deviceSummary.Total = len(states)
+		for _, state := range states {
+			switch state.State {
+			case database.ConnectionStateConnected:
+				deviceSummary.Online++
+			case database.ConnectionStateError:
+				deviceSummary.Error++
+			default:
+				deviceSummary.Offline++
 			}
 		}
 	}
@@ -110,7 +103,7 @@ func HealthHandler(w http.ResponseWriter, r *http.Request) {
 	status := "ok"
 	if !dbStatus.Connected {
 		status = "error"
-	} else if deviceSummary.Total > 0 && deviceSummary.Offline > deviceSummary.Online {
+	} else if deviceSummary.Total > 0 && deviceSummary.Offline+deviceSummary.Error > deviceSummary.Online {
 		status = "degraded"
 	}
 
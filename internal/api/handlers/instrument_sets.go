@@ -0,0 +1,513 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"steri-connect-go/internal/database"
+	"steri-connect-go/internal/logging"
+)
+
+// Kinds of instrument sets
+var validInstrumentSetKinds = map[string]bool{
+	"set":  true,
+	"tray": true,
+}
+
+// CreateInstrumentSetRequest represents the request body for creating an instrument set
+type CreateInstrumentSetRequest struct {
+	Code        string `json:"code"`
+	Name        string `json:"name"`
+	Kind        string `json:"kind,omitempty"` // "set" (default) or "tray"
+	Description string `json:"description,omitempty"`
+}
+
+// UpdateInstrumentSetRequest represents the request body for updating an instrument set
+// Fields that are omitted keep their value.
+type UpdateInstrumentSetRequest struct {
+	Code        *string `json:"code,omitempty"`
+	Name        *string `json:"name,omitempty"`
+	Kind        *string `json:"kind,omitempty"`
+	Description *string `json:"description,omitempty"`
+}
+
+// InstrumentSetCycle is a cycle an instrument set was processed in, with its load entry
+type InstrumentSetCycle struct {
+	database.CycleWithDevice
+	Load database.CycleLoad `json:"load"`
+}
+
+// InstrumentSetCyclesResponse represents the cycles an instrument set was processed in
+type InstrumentSetCyclesResponse struct {
+	Set        *database.InstrumentSet `json:"set"`
+	Cycles     []InstrumentSetCycle    `json:"cycles"`
+	TotalCount int                     `json:"total_count"`
+	Limit      int                     `json:"limit,omitempty"`
+	Offset     int                     `json:"offset,omitempty"`
+}
+
+// ListInstrumentSetsHandler handles GET /api/instrument-sets requests
+// The optional query parameter code returns only the set with that code (barcode scan).
+func ListInstrumentSetsHandler(w http.ResponseWriter, r *http.Request) {
+	sets, err := database.GetInstrumentSets(r.URL.Query().Get("code"))
+	if err != nil {
+		logging.Get().Error("Failed to retrieve instrument sets", "error", err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to retrieve instrument sets",
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(sets)
+}
+
+// CreateInstrumentSetHandler handles POST /api/instrument-sets requests
+func CreateInstrumentSetHandler(w http.ResponseWriter, r *http.Request) {
+	logger := logging.Get()
+
+	var req CreateInstrumentSetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid JSON in request body",
+		})
+		return
+	}
+
+	set := &database.InstrumentSet{
+		Code:        strings.TrimSpace(req.Code),
+		Name:        strings.TrimSpace(req.Name),
+		Kind:        req.Kind,
+		Description: req.Description,
+	}
+	if set.Kind == "" {
+		set.Kind = "set"
+	}
+	if err := validateInstrumentSet(set); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "validation_error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	created, err := database.CreateInstrumentSet(set)
+	if err == database.ErrDuplicateInstrumentSet {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "duplicate_instrument_set",
+			Message: fmt.Sprintf("Instrument set with code %s already exists", set.Code),
+		})
+		return
+	}
+	if err != nil {
+		logger.Error("Failed to create instrument set", "error", err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to create instrument set",
+		})
+		return
+	}
+
+	details := map[string]interface{}{
+		"set_id": created.ID,
+		"code":   created.Code,
+		"name":   created.Name,
+		"kind":   created.Kind,
+	}
+	if err := database.LogAudit(database.ActionSetCreated, "instrument_set", &created.ID, "", details); err != nil {
+		logger.Warn("Failed to create audit log", "error", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(created)
+}
+
+// GetInstrumentSetHandler handles GET /api/instrument-sets/{id} requests
+func GetInstrumentSetHandler(w http.ResponseWriter, r *http.Request) {
+	setID, ok := instrumentSetIDFromPath(w, r.URL.Path)
+	if !ok {
+		return
+	}
+
+	set, ok := lookupInstrumentSet(w, setID)
+	if !ok {
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(set)
+}
+
+// UpdateInstrumentSetHandler handles PUT /api/instrument-sets/{id} requests
+func UpdateInstrumentSetHandler(w http.ResponseWriter, r *http.Request) {
+	logger := logging.Get()
+
+	setID, ok := instrumentSetIDFromPath(w, r.URL.Path)
+	if !ok {
+		return
+	}
+
+	var req UpdateInstrumentSetRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid JSON in request body",
+		})
+		return
+	}
+
+	set, ok := lookupInstrumentSet(w, setID)
+	if !ok {
+		return
+	}
+	if req.Code != nil {
+		set.Code = strings.TrimSpace(*req.Code)
+	}
+	if req.Name != nil {
+		set.Name = strings.TrimSpace(*req.Name)
+	}
+	if req.Kind != nil {
+		set.Kind = *req.Kind
+	}
+	if req.Description != nil {
+		set.Description = *req.Description
+	}
+	if err := validateInstrumentSet(set); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "validation_error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	updated, err := database.UpdateInstrumentSet(set)
+	if err == database.ErrDuplicateInstrumentSet {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "duplicate_instrument_set",
+			Message: fmt.Sprintf("Instrument set with code %s already exists", set.Code),
+		})
+		return
+	}
+	if err == database.ErrInstrumentSetNotFound {
+		writeInstrumentSetNotFound(w, setID)
+		return
+	}
+	if err != nil {
+		logger.Error("Failed to update instrument set", "error", err, "set_id", setID)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to update instrument set",
+		})
+		return
+	}
+
+	details := map[string]interface{}{
+		"set_id": updated.ID,
+		"code":   updated.Code,
+		"name":   updated.Name,
+		"kind":   updated.Kind,
+	}
+	if err := database.LogAudit(database.ActionSetUpdated, "instrument_set", &setID, "", details); err != nil {
+		logger.Warn("Failed to create audit log", "error", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(updated)
+}
+
+// DeleteInstrumentSetHandler handles DELETE /api/instrument-sets/{id} requests
+// Sets that were part of a load are kept for traceability (409).
+func DeleteInstrumentSetHandler(w http.ResponseWriter, r *http.Request) {
+	logger := logging.Get()
+
+	setID, ok := instrumentSetIDFromPath(w, r.URL.Path)
+	if !ok {
+		return
+	}
+
+	set, ok := lookupInstrumentSet(w, setID)
+	if !ok {
+		return
+	}
+
+	err := database.DeleteInstrumentSet(setID)
+	if err == database.ErrInstrumentSetInUse {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "instrument_set_in_use",
+			Message: fmt.Sprintf("Instrument set %s was part of a cycle load and cannot be deleted", set.Code),
+		})
+		return
+	}
+	if err == database.ErrInstrumentSetNotFound {
+		writeInstrumentSetNotFound(w, setID)
+		return
+	}
+	if err != nil {
+		logger.Error("Failed to delete instrument set", "error", err, "set_id", setID)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to delete instrument set",
+		})
+		return
+	}
+
+	details := map[string]interface{}{
+		"set_id": set.ID,
+		"code":   set.Code,
+		"name":   set.Name,
+	}
+	if err := database.LogAudit(database.ActionSetDeleted, "instrument_set", &setID, "", details); err != nil {
+		logger.Warn("Failed to create audit log", "error", err)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetInstrumentSetCyclesHandler handles GET /api/instrument-sets/{id}/cycles requests
+// Returns the cycles the set was processed in, newest first.
+// Query parameters: result ("OK", "NOK" or "UNKNOWN"), limit, offset.
+func GetInstrumentSetCyclesHandler(w http.ResponseWriter, r *http.Request) {
+	setID, ok := instrumentSetIDFromPath(w, r.URL.Path)
+	if !ok {
+		return
+	}
+
+	set, ok := lookupInstrumentSet(w, setID)
+	if !ok {
+		return
+	}
+
+	options := database.CycleListOptions{
+		SetID:     &setID,
+		SortBy:    "start_ts",
+		SortOrder: "DESC",
+	}
+	query := r.URL.Query()
+	if result := query.Get("result"); result != "" {
+		if result != "OK" && result != "NOK" && result != database.CycleResultUnknown {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{
+				Error:   "invalid_result",
+				Message: "Result must be 'OK', 'NOK' or 'UNKNOWN'",
+			})
+			return
+		}
+		options.Result = &result
+	}
+	if limitStr := query.Get("limit"); limitStr != "" {
+		limit, err := strconv.Atoi(limitStr)
+		if err != nil || limit < 0 {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{
+				Error:   "invalid_limit",
+				Message: "Limit must be a positive integer",
+			})
+			return
+		}
+		options.Limit = limit
+	}
+	if offsetStr := query.Get("offset"); offsetStr != "" {
+		offset, err := strconv.Atoi(offsetStr)
+		if err != nil || offset < 0 {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{
+				Error:   "invalid_offset",
+				Message: "Offset must be a non-negative integer",
+			})
+			return
+		}
+		options.Offset = offset
+	}
+
+	cycles, totalCount, ok := instrumentSetCycles(w, setID, options)
+	if !ok {
+		return
+	}
+
+	response := InstrumentSetCyclesResponse{
+		Set:        set,
+		Cycles:     cycles,
+		TotalCount: totalCount,
+	}
+	if options.Limit > 0 {
+		response.Limit = options.Limit
+		response.Offset = options.Offset
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// GetInstrumentSetLastCycleHandler handles GET /api/instrument-sets/{id}/last-cycle requests
+// Returns the most recent cycle with result OK that the set was processed in.
+func GetInstrumentSetLastCycleHandler(w http.ResponseWriter, r *http.Request) {
+	setID, ok := instrumentSetIDFromPath(w, r.URL.Path)
+	if !ok {
+		return
+	}
+
+	set, ok := lookupInstrumentSet(w, setID)
+	if !ok {
+		return
+	}
+
+	passed := "OK"
+	cycles, _, ok := instrumentSetCycles(w, setID, database.CycleListOptions{
+		SetID:     &setID,
+		Result:    &passed,
+		SortBy:    "start_ts",
+		SortOrder: "DESC",
+		Limit:     1,
+	})
+	if !ok {
+		return
+	}
+	if len(cycles) == 0 {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "no_passed_cycle",
+			Message: fmt.Sprintf("Instrument set %s was not part of a passed cycle", set.Code),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(cycles[0])
+}
+
+// instrumentSetCycles loads the cycles of an instrument set together with its load entries
+// Writes a 500 error response and returns false if that fails.
+func instrumentSetCycles(w http.ResponseWriter, setID int, options database.CycleListOptions) ([]InstrumentSetCycle, int, bool) {
+	logger := logging.Get()
+
+	cycles, totalCount, err := database.GetAllCycles(options)
+	if err == nil {
+		var loads []database.CycleLoad
+		loads, err = database.GetInstrumentSetLoads(setID)
+		if err == nil {
+			loadsByCycle := make(map[int]database.CycleLoad, len(loads))
+			for _, load := range loads {
+				loadsByCycle[load.CycleID] = load
+			}
+
+			result := make([]InstrumentSetCycle, 0, len(cycles))
+			for _, cycle := range cycles {
+				result = append(result, InstrumentSetCycle{
+					CycleWithDevice: cycle,
+					Load:            loadsByCycle[cycle.ID],
+				})
+			}
+			return result, totalCount, true
+		}
+	}
+
+	logger.Error("Failed to retrieve instrument set cycles", "error", err, "set_id", setID)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusInternalServerError)
+	json.NewEncoder(w).Encode(ErrorResponse{
+		Error:   "internal_error",
+		Message: "Failed to retrieve instrument set cycles",
+	})
+	return nil, 0, false
+}
+
+// validateInstrumentSet validates code, name and kind of an instrument set
+func validateInstrumentSet(set *database.InstrumentSet) error {
+	if set.Code == "" {
+		return fmt.Errorf("code is required")
+	}
+	if set.Name == "" {
+		return fmt.Errorf("name is required")
+	}
+	if !validInstrumentSetKinds[set.Kind] {
+		return fmt.Errorf("kind must be 'set' or 'tray'")
+	}
+	return nil
+}
+
+// lookupInstrumentSet loads an instrument set, writing a 404/500 error response if that fails
+func lookupInstrumentSet(w http.ResponseWriter, setID int) (*database.InstrumentSet, bool) {
+	set, err := database.GetInstrumentSet(setID)
+	if err == database.ErrInstrumentSetNotFound {
+		writeInstrumentSetNotFound(w, setID)
+		return nil, false
+	}
+	if err != nil {
+		logging.Get().Error("Failed to get instrument set", "error", err, "set_id", setID)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to retrieve instrument set",
+		})
+		return nil, false
+	}
+	return set, true
+}
+
+// writeInstrumentSetNotFound writes the 404 response for an unknown instrument set
+func writeInstrumentSetNotFound(w http.ResponseWriter, setID int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusNotFound)
+	json.NewEncoder(w).Encode(ErrorResponse{
+		Error:   "instrument_set_not_found",
+		Message: fmt.Sprintf("Instrument set with ID %d not found", setID),
+	})
+}
+
+// instrumentSetIDFromPath extracts the set ID from paths like "/instrument-sets/3" or
+// "/instrument-sets/3/cycles", writing a 400 error response if it is invalid
+func instrumentSetIDFromPath(w http.ResponseWriter, path string) (int, bool) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) >= 2 && parts[0] == "instrument-sets" {
+		if id, err := strconv.Atoi(parts[1]); err == nil {
+			return id, true
+		}
+	}
+
+	logging.Get().Warn("Failed to extract instrument set ID from path", "path", path)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(ErrorResponse{
+		Error:   "invalid_set_id",
+		Message: "Invalid instrument set ID in URL path",
+	})
+	return 0, false
+}
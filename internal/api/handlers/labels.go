@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"steri-connect-go/internal/config"
+	"steri-connect-go/internal/database"
+	"steri-connect-go/internal/labels"
+	"steri-connect-go/internal/logging"
+	"steri-connect-go/internal/pdf"
+)
+
+// CycleLabelsResponse represents the labels of a cycle (format=json)
+type CycleLabelsResponse struct {
+	CycleID int            `json:"cycle_id"`
+	Labels  []labels.Label `json:"labels"`
+}
+
+// CycleLabelsHandler handles GET /api/cycles/{id}/labels requests
+// Query parameters: copies (default 1), packaging (default from config),
+// format ("pdf", "zpl" or "json", default "pdf") and symbology ("code128" or "datamatrix").
+func CycleLabelsHandler(w http.ResponseWriter, r *http.Request) {
+	logger := logging.Get()
+	cfg := config.Get().Labels
+
+	parts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+	if len(parts) != 3 || parts[0] != "cycles" || parts[2] != "labels" {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "invalid_path",
+			Message: "Invalid path format: expected /cycles/{id}/labels",
+		})
+		return
+	}
+	cycleID, err := strconv.Atoi(parts[1])
+	if err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "invalid_cycle_id",
+			Message: "Invalid cycle ID in URL path",
+		})
+		return
+	}
+
+	query := r.URL.Query()
+	copies := 1
+	if value := query.Get("copies"); value != "" {
+		copies, err = strconv.Atoi(value)
+		if err != nil {
+			copies = 0 // Rejected by labels.Build
+		}
+	}
+	format := query.Get("format")
+	if format == "" {
+		format = "pdf"
+	}
+	symbology := query.Get("symbology")
+	if symbology == "" {
+		symbology = cfg.Symbology
+	}
+	if (format != "pdf" && format != "zpl" && format != "json") || (symbology != "code128" && symbology != "datamatrix") {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "validation_error",
+			Message: "format must be 'pdf', 'zpl' or 'json' and symbology 'code128' or 'datamatrix'",
+		})
+		return
+	}
+
+	cycle, err := database.GetCycleWithDevice(cycleID)
+	if err == database.ErrCycleNotFound {
+		writeCycleNotFound(w, cycleID)
+		return
+	}
+	if err != nil {
+		logger.Error("Failed to get cycle", "error", err, "cycle_id", cycleID)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to retrieve cycle",
+		})
+		return
+	}
+
+	items, err := labels.Build(cycle, cfg, strings.TrimSpace(query.Get("packaging")), copies)
+	switch {
+	case err == labels.ErrCycleNotReleased:
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "cycle_not_released",
+			Message: fmt.Sprintf("Cycle %d has not been released", cycleID),
+		})
+		return
+	case err == labels.ErrUnknownPackaging:
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "unknown_packaging",
+			Message: fmt.Sprintf("Packaging type %q is not configured", query.Get("packaging")),
+		})
+		return
+	case err == labels.ErrInvalidCopies:
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "validation_error",
+			Message: err.Error(),
+		})
+		return
+	case err != nil:
+		logger.Error("Failed to build labels", "error", err, "cycle_id", cycleID)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to build labels",
+		})
+		return
+	}
+
+	if format == "json" {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusOK)
+		json.NewEncoder(w).Encode(CycleLabelsResponse{CycleID: cycleID, Labels: items})
+		return
+	}
+
+	var content []byte
+	var contentType string
+	if format == "zpl" {
+		content, err = labels.GenerateZPL(items, cfg, symbology)
+		contentType = "application/zpl"
+	} else {
+		content, err = pdf.GenerateLabelsPDF(items, cfg, symbology)
+		contentType = "application/pdf"
+	}
+	if err != nil {
+		logger.Error("Failed to generate labels", "error", err, "cycle_id", cycleID, "format", format)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to generate labels",
+		})
+		return
+	}
+
+	filename := fmt.Sprintf("cycle-%d-labels.%s", cycleID, format)
+	w.Header().Set("Content-Type", contentType)
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	w.Header().Set("Content-Length", fmt.Sprintf("%d", len(content)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(content)
+}
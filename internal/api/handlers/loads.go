@@ -0,0 +1,351 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"steri-connect-go/internal/database"
+	"steri-connect-go/internal/logging"
+)
+
+// AssignLoadRequest represents the request body for adding an instrument set to a cycle load
+// The set is identified by set_id or by set_code (e.g. scanned from the tray).
+type AssignLoadRequest struct {
+	SetID    int    `json:"set_id,omitempty"`
+	SetCode  string `json:"set_code,omitempty"`
+	Quantity int    `json:"quantity,omitempty"` // Defaults to 1
+	Operator string `json:"operator"`
+	Notes    string `json:"notes,omitempty"`
+}
+
+// CycleLoadsResponse represents the load of a cycle
+type CycleLoadsResponse struct {
+	CycleID       int                  `json:"cycle_id"`
+	Loads         []database.CycleLoad `json:"loads"`
+	TotalQuantity int                  `json:"total_quantity"`
+}
+
+// ListCycleLoadsHandler handles GET /api/cycles/{id}/loads requests
+// Returns the instrument sets that were in the cycle.
+func ListCycleLoadsHandler(w http.ResponseWriter, r *http.Request) {
+	cycleID, _, err := parseCycleLoadsPath(r.URL.Path)
+	if err != nil {
+		writeInvalidCycleLoadsPath(w, r, err)
+		return
+	}
+
+	if _, ok := lookupCycle(w, cycleID); !ok {
+		return
+	}
+
+	loads, err := database.GetCycleLoads(cycleID)
+	if err != nil {
+		logging.Get().Error("Failed to retrieve cycle loads", "error", err, "cycle_id", cycleID)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to retrieve cycle loads",
+		})
+		return
+	}
+
+	response := CycleLoadsResponse{
+		CycleID: cycleID,
+		Loads:   loads,
+	}
+	for _, load := range loads {
+		response.TotalQuantity += load.Quantity
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(response)
+}
+
+// AssignCycleLoadHandler handles POST /api/cycles/{id}/loads requests
+func AssignCycleLoadHandler(w http.ResponseWriter, r *http.Request) {
+	logger := logging.Get()
+
+	cycleID, _, err := parseCycleLoadsPath(r.URL.Path)
+	if err != nil {
+		writeInvalidCycleLoadsPath(w, r, err)
+		return
+	}
+
+	var req AssignLoadRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid JSON in request body",
+		})
+		return
+	}
+	req.Operator = strings.TrimSpace(req.Operator)
+	if req.Quantity == 0 {
+		req.Quantity = 1
+	}
+	if err := validateAssignLoadRequest(&req); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "validation_error",
+			Message: err.Error(),
+		})
+		return
+	}
+
+	if _, ok := lookupCycle(w, cycleID); !ok {
+		return
+	}
+
+	setID := req.SetID
+	if setID == 0 {
+		sets, err := database.GetInstrumentSets(strings.TrimSpace(req.SetCode))
+		if err != nil {
+			logger.Error("Failed to look up instrument set by code", "error", err, "code", req.SetCode)
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusInternalServerError)
+			json.NewEncoder(w).Encode(ErrorResponse{
+				Error:   "internal_error",
+				Message: "Failed to retrieve instrument set",
+			})
+			return
+		}
+		if len(sets) == 0 {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusNotFound)
+			json.NewEncoder(w).Encode(ErrorResponse{
+				Error:   "instrument_set_not_found",
+				Message: fmt.Sprintf("Instrument set with code %s not found", req.SetCode),
+			})
+			return
+		}
+		setID = sets[0].ID
+	}
+
+	load, err := database.AssignLoad(&database.CycleLoad{
+		CycleID:  cycleID,
+		SetID:    setID,
+		Quantity: req.Quantity,
+		Operator: req.Operator,
+		Notes:    req.Notes,
+	})
+	switch {
+	case err == database.ErrInstrumentSetNotFound:
+		writeInstrumentSetNotFound(w, setID)
+		return
+	case err == database.ErrCycleNotFound:
+		writeCycleNotFound(w, cycleID)
+		return
+	case err == database.ErrLoadLocked:
+		writeLoadLocked(w, cycleID)
+		return
+	case err == database.ErrLoadAlreadyAssigned:
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "load_already_assigned",
+			Message: fmt.Sprintf("Instrument set %d is already part of cycle %d", setID, cycleID),
+		})
+		return
+	case err != nil:
+		logger.Error("Failed to assign load", "error", err, "cycle_id", cycleID, "set_id", setID)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to assign load",
+		})
+		return
+	}
+
+	details := map[string]interface{}{
+		"load_id":  load.ID,
+		"cycle_id": cycleID,
+		"set_id":   load.SetID,
+		"set_code": load.SetCode,
+		"set_name": load.SetName,
+		"quantity": load.Quantity,
+	}
+	if load.Notes != "" {
+		details["notes"] = load.Notes
+	}
+	if err := database.LogAudit(database.ActionLoadAssigned, "cycle", &cycleID, load.Operator, details); err != nil {
+		logger.Warn("Failed to create audit log", "error", err)
+	}
+
+	logger.Info("Instrument set assigned to cycle load",
+		"cycle_id", cycleID,
+		"set_id", load.SetID,
+		"set_code", load.SetCode,
+		"quantity", load.Quantity,
+		"operator", load.Operator)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(load)
+}
+
+// RemoveCycleLoadHandler handles DELETE /api/cycles/{id}/loads/{load_id} requests
+// The query parameter operator names the person removing the entry (audit log).
+func RemoveCycleLoadHandler(w http.ResponseWriter, r *http.Request) {
+	logger := logging.Get()
+
+	cycleID, loadID, err := parseCycleLoadsPath(r.URL.Path)
+	if err == nil && loadID == 0 {
+		err = fmt.Errorf("missing load ID")
+	}
+	if err != nil {
+		writeInvalidCycleLoadsPath(w, r, err)
+		return
+	}
+
+	operator := strings.TrimSpace(r.URL.Query().Get("operator"))
+	if operator == "" {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "validation_error",
+			Message: "operator is required",
+		})
+		return
+	}
+
+	load, err := database.RemoveCycleLoad(cycleID, loadID)
+	if err == database.ErrLoadNotFound {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "load_not_found",
+			Message: fmt.Sprintf("Load %d not found in cycle %d", loadID, cycleID),
+		})
+		return
+	}
+	if err == database.ErrLoadLocked {
+		writeLoadLocked(w, cycleID)
+		return
+	}
+	if err != nil {
+		logger.Error("Failed to remove load", "error", err, "cycle_id", cycleID, "load_id", loadID)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to remove load",
+		})
+		return
+	}
+
+	details := map[string]interface{}{
+		"load_id":     load.ID,
+		"cycle_id":    cycleID,
+		"set_id":      load.SetID,
+		"set_code":    load.SetCode,
+		"set_name":    load.SetName,
+		"quantity":    load.Quantity,
+		"assigned_by": load.Operator,
+	}
+	if err := database.LogAudit(database.ActionLoadRemoved, "cycle", &cycleID, operator, details); err != nil {
+		logger.Warn("Failed to create audit log", "error", err)
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// validateAssignLoadRequest validates the load assignment request
+func validateAssignLoadRequest(req *AssignLoadRequest) error {
+	if req.SetID == 0 && strings.TrimSpace(req.SetCode) == "" {
+		return fmt.Errorf("set_id or set_code is required")
+	}
+	if req.SetID < 0 {
+		return fmt.Errorf("set_id must be a positive integer")
+	}
+	if req.Quantity < 1 {
+		return fmt.Errorf("quantity must be at least 1")
+	}
+	if req.Operator == "" {
+		return fmt.Errorf("operator is required")
+	}
+	return nil
+}
+
+// lookupCycle loads a cycle, writing a 404/500 error response if that fails
+func lookupCycle(w http.ResponseWriter, cycleID int) (*database.Cycle, bool) {
+	cycle, err := database.GetCycle(cycleID)
+	if err == database.ErrCycleNotFound {
+		writeCycleNotFound(w, cycleID)
+		return nil, false
+	}
+	if err != nil {
+		logging.Get().Error("Failed to get cycle", "error", err, "cycle_id", cycleID)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to retrieve cycle",
+		})
+		return nil, false
+	}
+	return cycle, true
+}
+
+// writeCycleNotFound writes the 404 response for an unknown cycle
+func writeCycleNotFound(w http.ResponseWriter, cycleID int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusNotFound)
+	json.NewEncoder(w).Encode(ErrorResponse{
+		Error:   "cycle_not_found",
+		Message: fmt.Sprintf("Cycle with ID %d not found", cycleID),
+	})
+}
+
+// writeLoadLocked writes the 409 response for a change to the load of a decided cycle
+func writeLoadLocked(w http.ResponseWriter, cycleID int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusConflict)
+	json.NewEncoder(w).Encode(ErrorResponse{
+		Error:   "load_locked",
+		Message: fmt.Sprintf("Cycle %d has already been released or rejected, its load cannot be changed", cycleID),
+	})
+}
+
+// writeInvalidCycleLoadsPath writes the 400 response for a malformed loads path
+func writeInvalidCycleLoadsPath(w http.ResponseWriter, r *http.Request, err error) {
+	logging.Get().Warn("Invalid cycle loads path", "error", err, "path", r.URL.Path)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(ErrorResponse{
+		Error:   "invalid_path",
+		Message: "Invalid path format: expected /cycles/{id}/loads or /cycles/{id}/loads/{load_id}",
+	})
+}
+
+// parseCycleLoadsPath extracts cycle ID and optional load ID from paths like
+// "/cycles/5/loads" or "/cycles/5/loads/2" (load ID 0 if absent)
+func parseCycleLoadsPath(path string) (int, int, error) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) < 3 || len(parts) > 4 || parts[0] != "cycles" || parts[2] != "loads" {
+		return 0, 0, fmt.Errorf("invalid path format: expected /cycles/{id}/loads")
+	}
+
+	cycleID, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, 0, fmt.Errorf("invalid cycle ID: %w", err)
+	}
+
+	loadID := 0
+	if len(parts) == 4 {
+		loadID, err = strconv.Atoi(parts[3])
+		if err != nil {
+			return 0, 0, fmt.Errorf("invalid load ID: %w", err)
+		}
+	}
+
+	return cycleID, loadID, nil
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
 	"net/http"
 	"strconv"
@@ -74,87 +75,31 @@ func StartCycleHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Get device to verify it exists and is a Melag device
-	device, err := database.GetDevice(deviceID)
-	if err != nil {
-		if err == database.ErrDeviceNotFound {
-			w.Header().Set("Content-Type", "application/json")
-			w.WriteHeader(http.StatusNotFound)
-			json.NewEncoder(w).Encode(ErrorResponse{
-				Error:   "device_not_found",
-				Message: fmt.Sprintf("Device with ID %d not found", deviceID),
-			})
-			return
-		}
-
-		logger.Error("Failed to get device", "error", err, "device_id", deviceID)
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(ErrorResponse{
-			Error:   "internal_error",
-			Message: "Failed to retrieve device",
-		})
-		return
-	}
-
-	// Verify device is a Melag device
-	if device.Manufacturer != "Melag" {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusBadRequest)
-		json.NewEncoder(w).Encode(ErrorResponse{
-			Error:   "invalid_device_type",
-			Message: fmt.Sprintf("Device %d is not a Melag device", deviceID),
-		})
-		return
-	}
-
-	// Get device manager (global instance)
-	deviceManager := devices.GetManager()
-	if deviceManager == nil {
-		logger.Error("Device manager not initialized")
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusServiceUnavailable)
-		json.NewEncoder(w).Encode(ErrorResponse{
-			Error:   "service_unavailable",
-			Message: "Device manager not initialized",
-		})
-		return
-	}
-
-	adapter := deviceManager.GetAdapter(deviceID)
-	if adapter == nil {
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusServiceUnavailable)
-		json.NewEncoder(w).Encode(ErrorResponse{
-			Error:   "device_not_connected",
-			Message: fmt.Sprintf("Device %d is not connected", deviceID),
-		})
+	// Get device and check it can start cycles remotely
+	device, ok := lookupDevice(w, deviceID)
+	if !ok {
 		return
 	}
 
-	// Cast to MelagAdapter
-	melagAdapter, ok := adapter.(*melag.MelagAdapter)
+	adapter, ok := capabilityAdapter(w, device, adapters.CapabilityCycleStart)
 	if !ok {
-		logger.Error("Failed to cast adapter to MelagAdapter", "device_id", deviceID)
-		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
-		json.NewEncoder(w).Encode(ErrorResponse{
-			Error:   "internal_error",
-			Message: "Invalid adapter type",
-		})
 		return
 	}
+	cycleStarter := adapter.(adapters.CycleStarter)
+	deviceManager := devices.GetManager()
 
-	// Verify device is connected
-	if !melagAdapter.IsConnected() {
+	// Only one cycle per device at a time
+	if err := deviceManager.BeginCycleStart(deviceID); err != nil {
+		logger.Warn("Refusing second cycle on device", "error", err, "device_id", deviceID)
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusServiceUnavailable)
+		w.WriteHeader(http.StatusConflict)
 		json.NewEncoder(w).Encode(ErrorResponse{
-			Error:   "device_not_connected",
-			Message: fmt.Sprintf("Device %d is not connected", deviceID),
+			Error:   "cycle_running",
+			Message: err.Error(),
 		})
 		return
 	}
+	defer deviceManager.EndCycleStart(deviceID)
 
 	// Start cycle on device
 	startParams := adapters.CycleStartParams{
@@ -164,7 +109,7 @@ func StartCycleHandler(w http.ResponseWriter, r *http.Request) {
 		Duration:    req.Duration,
 	}
 
-	if err := melagAdapter.StartCycle(startParams); err != nil {
+	if err := cycleStarter.StartCycle(startParams); err != nil {
 		logger.Error("Failed to start cycle", "error", err, "device_id", deviceID)
 
 		// Create cycle record with FAILED status
@@ -181,16 +126,17 @@ func StartCycleHandler(w http.ResponseWriter, r *http.Request) {
 			logger.Error("Failed to create cycle record", "error", createErr)
 		}
 
+		status, code := cycleStartErrorStatus(err)
 		w.Header().Set("Content-Type", "application/json")
-		w.WriteHeader(http.StatusInternalServerError)
+		w.WriteHeader(status)
 		json.NewEncoder(w).Encode(ErrorResponse{
-			Error:   "cycle_start_failed",
+			Error:   code,
 			Message: "Failed to start cycle: " + err.Error(),
 		})
 		return
 	}
 
-	// Create cycle record in database
+	// Device acknowledged the start request; only now persist the cycle as STARTING
 	now := time.Now()
 	cycle := &database.Cycle{
 		DeviceID:      deviceID,
@@ -248,7 +194,9 @@ func StartCycleHandler(w http.ResponseWriter, r *http.Request) {
 		"program", req.Program)
 
 	// Start cycle status polling
-	deviceManager.StartCyclePolling(createdCycle.ID, deviceID)
+	if err := deviceManager.StartCyclePolling(createdCycle.ID, deviceID); err != nil {
+		logger.Error("Failed to start cycle status polling", "error", err, "cycle_id", createdCycle.ID, "device_id", deviceID)
+	}
 
 	// Return cycle information
 	response := StartCycleResponse{
@@ -265,6 +213,22 @@ func StartCycleHandler(w http.ResponseWriter, r *http.Request) {
 	json.NewEncoder(w).Encode(response)
 }
 
+// cycleStartErrorStatus maps a StartCycle error to the HTTP status and error code returned to the client
+func cycleStartErrorStatus(err error) (int, string) {
+	switch {
+	case errors.Is(err, melag.ErrDoorOpen):
+		return http.StatusConflict, "door_open"
+	case errors.Is(err, melag.ErrDeviceBusy):
+		return http.StatusConflict, "device_busy"
+	case errors.Is(err, melag.ErrProgramRejected):
+		return http.StatusUnprocessableEntity, "program_rejected"
+	case errors.Is(err, melag.ErrAckTimeout):
+		return http.StatusGatewayTimeout, "ack_timeout"
+	default:
+		return http.StatusInternalServerError, "cycle_start_failed"
+	}
+}
+
 // extractMelagDeviceID extracts device ID from URL path like "/melag/1/start"
 func extractMelagDeviceID(path string) (int, error) {
 	// Path will be "/melag/1/start" after StripPrefix
@@ -626,3 +590,113 @@ func extractMelagDeviceIDAndCycleID(path string) (int, int, error) {
 	return deviceID, cycleID, nil
 }
 
+
+// StartProtocolImportHandler handles POST /api/melag/{id}/import requests
+// The import runs in the background; progress is reported via WebSocket and GET /api/melag/{id}/import
+func StartProtocolImportHandler(w http.ResponseWriter, r *http.Request) {
+	logger := logging.Get()
+
+	deviceID, err := extractMelagDeviceIDFromImportPath(r.URL.Path)
+	if err != nil {
+		logger.Warn("Failed to extract device ID from import path", "error", err, "path", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "invalid_device_id",
+			Message: "Invalid device ID in URL path",
+		})
+		return
+	}
+
+	device, ok := lookupDevice(w, deviceID)
+	if !ok {
+		return
+	}
+	if _, ok := capabilityAdapter(w, device, adapters.CapabilityProtocolImport); !ok {
+		return
+	}
+
+	job, err := devices.GetManager().StartProtocolImport(deviceID)
+	if err != nil {
+		status := http.StatusInternalServerError
+		errorCode := "import_failed"
+		switch {
+		case errors.Is(err, devices.ErrImportRunning):
+			status, errorCode = http.StatusConflict, "import_running"
+		case errors.Is(err, devices.ErrDeviceNotConnected):
+			status, errorCode = http.StatusServiceUnavailable, "device_not_connected"
+		case errors.Is(err, devices.ErrImportNotSupported):
+			status, errorCode = http.StatusNotImplemented, "capability_not_supported"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   errorCode,
+			Message: err.Error(),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusAccepted)
+	json.NewEncoder(w).Encode(job)
+}
+
+// GetProtocolImportHandler handles GET /api/melag/{id}/import requests
+func GetProtocolImportHandler(w http.ResponseWriter, r *http.Request) {
+	logger := logging.Get()
+
+	deviceID, err := extractMelagDeviceIDFromImportPath(r.URL.Path)
+	if err != nil {
+		logger.Warn("Failed to extract device ID from import path", "error", err, "path", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "invalid_device_id",
+			Message: "Invalid device ID in URL path",
+		})
+		return
+	}
+
+	deviceManager := devices.GetManager()
+	if deviceManager == nil {
+		logger.Error("Device manager not initialized")
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusServiceUnavailable)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "service_unavailable",
+			Message: "Device manager not initialized",
+		})
+		return
+	}
+
+	job, exists := deviceManager.GetProtocolImport(deviceID)
+	if !exists {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "import_not_found",
+			Message: fmt.Sprintf("No protocol import has been started for device %d", deviceID),
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(job)
+}
+
+// extractMelagDeviceIDFromImportPath extracts device ID from URL path like "/melag/1/import"
+func extractMelagDeviceIDFromImportPath(path string) (int, error) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) != 3 || parts[0] != "melag" || parts[2] != "import" {
+		return 0, fmt.Errorf("invalid path format: expected /melag/{id}/import")
+	}
+
+	id, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, fmt.Errorf("invalid device ID: %w", err)
+	}
+
+	return id, nil
+}
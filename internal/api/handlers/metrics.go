@@ -9,7 +9,6 @@ import (
 	"steri-connect-go/internal/api/middleware"
 	"steri-connect-go/internal/config"
 	"steri-connect-go/internal/database"
-	"steri-connect-go/internal/devices"
 )
 
 // MetricsResponse represents the system metrics response
@@ -43,15 +42,10 @@ func MetricsHandler(w http.ResponseWriter, r *http.Request) {
 
 	// Get active device connections
 	activeConnections := 0
-	deviceManager := devices.GetManager()
-	if deviceManager != nil {
-		allDevices, err := database.GetAllDevices()
-		if err == nil {
-			for _, device := range allDevices {
-				status, err := database.GetDeviceStatus(device.ID)
-				if err == nil && status.Connected {
-					activeConnections++
-				}
+	if states, err := database.GetDeviceConnectionStates(); err == nil {
+		for _, state := range states {
+			if state.State == database.ConnectionStateConnected {
+				activeConnections++
 			}
 		}
 	}
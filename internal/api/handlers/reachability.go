@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"steri-connect-go/internal/adapters"
+	"steri-connect-go/internal/database"
+	"steri-connect-go/internal/logging"
+)
+
+// Limits of the reachability report
+const (
+	defaultReachabilityWindow  = 7 * 24 * time.Hour
+	maxReachabilityWindow      = 31 * 24 * time.Hour
+	defaultReachabilityOutages = 5
+	maxReachabilityOutages     = 50
+)
+
+// GetDeviceReachabilityHandler handles GET /api/devices/{id}/reachability requests
+// Returns availability per hour and day, RTT percentiles and the longest outages.
+// Query parameters: from, to (RFC3339 or YYYY-MM-DD; default the last 7 days), outages (default 5).
+func GetDeviceReachabilityHandler(w http.ResponseWriter, r *http.Request) {
+	logger := logging.Get()
+
+	deviceID, err := extractDeviceIDFromReachabilityPath(r.URL.Path)
+	if err != nil {
+		logger.Warn("Failed to extract device ID from reachability path", "error", err, "path", r.URL.Path)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "invalid_device_id",
+			Message: "Invalid device ID in URL path",
+		})
+		return
+	}
+
+	device, ok := lookupDevice(w, deviceID)
+	if !ok {
+		return
+	}
+
+	registration, exists := adapters.Lookup(device.Manufacturer)
+	if !exists || !registration.HasCapability(adapters.CapabilityReachability) {
+		writeCapabilityNotSupported(w, device, adapters.CapabilityReachability)
+		return
+	}
+
+	query := r.URL.Query()
+	to := time.Now()
+	if toStr := query.Get("to"); toStr != "" {
+		to, err = parseReachabilityTime(toStr)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{
+				Error:   "invalid_to",
+				Message: "to must be in RFC3339 or YYYY-MM-DD format",
+			})
+			return
+		}
+	}
+	from := to.Add(-defaultReachabilityWindow)
+	if fromStr := query.Get("from"); fromStr != "" {
+		from, err = parseReachabilityTime(fromStr)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{
+				Error:   "invalid_from",
+				Message: "from must be in RFC3339 or YYYY-MM-DD format",
+			})
+			return
+		}
+	}
+	if !from.Before(to) || to.Sub(from) > maxReachabilityWindow {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "invalid_range",
+			Message: fmt.Sprintf("from must be before to and the range at most %d days", int(maxReachabilityWindow.Hours()/24)),
+		})
+		return
+	}
+
+	maxOutages := defaultReachabilityOutages
+	if outagesStr := query.Get("outages"); outagesStr != "" {
+		maxOutages, err = strconv.Atoi(outagesStr)
+		if err != nil || maxOutages < 0 || maxOutages > maxReachabilityOutages {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{
+				Error:   "invalid_outages",
+				Message: fmt.Sprintf("outages must be between 0 and %d", maxReachabilityOutages),
+			})
+			return
+		}
+	}
+
+	history, err := database.GetRDGStatusHistoryRange(deviceID, from, to)
+	if err != nil {
+		logger.Error("Failed to get reachability history", "error", err, "device_id", deviceID)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to retrieve reachability history",
+		})
+		return
+	}
+
+	report := database.SummarizeReachability(deviceID, history, from, to, maxOutages)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(report)
+}
+
+// parseReachabilityTime parses RFC3339 or a date (midnight local time)
+func parseReachabilityTime(value string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t, nil
+	}
+	return time.ParseInLocation("2006-01-02", value, time.Local)
+}
+
+// extractDeviceIDFromReachabilityPath extracts device ID from URL path like "/devices/1/reachability"
+func extractDeviceIDFromReachabilityPath(path string) (int, error) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) != 3 || parts[0] != "devices" || parts[2] != "reachability" {
+		return 0, fmt.Errorf("invalid path format: expected /devices/{id}/reachability")
+	}
+
+	id, err := strconv.Atoi(parts[1])
+	if err != nil {
+		return 0, fmt.Errorf("invalid device ID: %w", err)
+	}
+
+	return id, nil
+}
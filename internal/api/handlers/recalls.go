@@ -0,0 +1,424 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"steri-connect-go/internal/config"
+	"steri-connect-go/internal/csv"
+	"steri-connect-go/internal/database"
+	"steri-connect-go/internal/logging"
+	"steri-connect-go/internal/pdf"
+)
+
+// OpenRecallRequest represents the request body for opening a recall case
+type OpenRecallRequest struct {
+	DeviceID      int    `json:"device_id"`
+	FailedCycleID int    `json:"failed_cycle_id"` // Cycle in which the indicator failed
+	User          string `json:"user"`
+	Description   string `json:"description,omitempty"`
+}
+
+// RecallNoteRequest represents the request body for annotating a recall case
+type RecallNoteRequest struct {
+	User string `json:"user"`
+	Note string `json:"note"`
+}
+
+// CloseRecallRequest represents the request body for closing a recall case
+type CloseRecallRequest struct {
+	User       string `json:"user"`
+	Resolution string `json:"resolution"`
+}
+
+// ListRecallsHandler handles GET /api/recalls requests
+// Optional query parameters: status ("open" or "closed") and device_id.
+func ListRecallsHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+
+	status := query.Get("status")
+	if status != "" && status != database.RecallStatusOpen && status != database.RecallStatusClosed {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "invalid_status",
+			Message: "Status must be 'open' or 'closed'",
+		})
+		return
+	}
+
+	var deviceID *int
+	if deviceIDStr := query.Get("device_id"); deviceIDStr != "" {
+		id, err := strconv.Atoi(deviceIDStr)
+		if err != nil {
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(ErrorResponse{
+				Error:   "invalid_device_id",
+				Message: "Device ID must be an integer",
+			})
+			return
+		}
+		deviceID = &id
+	}
+
+	recalls, err := database.GetRecallCases(status, deviceID)
+	if err != nil {
+		logging.Get().Error("Failed to retrieve recall cases", "error", err)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to retrieve recall cases",
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(recalls)
+}
+
+// PreviewRecallHandler handles GET /api/recalls/preview?device_id=&failed_cycle_id= requests
+// Returns the recall window and affected cycles without opening a case.
+func PreviewRecallHandler(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	deviceID, errDevice := strconv.Atoi(query.Get("device_id"))
+	failedCycleID, errCycle := strconv.Atoi(query.Get("failed_cycle_id"))
+	if errDevice != nil || errCycle != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "validation_error",
+			Message: "device_id and failed_cycle_id are required integers",
+		})
+		return
+	}
+
+	window, err := database.FindRecallWindow(deviceID, failedCycleID, config.Get().Recall.TestPrograms)
+	if err != nil {
+		writeRecallWindowError(w, err, failedCycleID)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(window)
+}
+
+// OpenRecallHandler handles POST /api/recalls requests
+func OpenRecallHandler(w http.ResponseWriter, r *http.Request) {
+	logger := logging.Get()
+
+	var req OpenRecallRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid JSON in request body",
+		})
+		return
+	}
+
+	req.User = strings.TrimSpace(req.User)
+	if req.DeviceID <= 0 || req.FailedCycleID <= 0 || req.User == "" {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "validation_error",
+			Message: "device_id, failed_cycle_id and user are required",
+		})
+		return
+	}
+
+	recall, err := database.OpenRecallCase(req.DeviceID, req.FailedCycleID, config.Get().Recall.TestPrograms, req.User, strings.TrimSpace(req.Description))
+	if err != nil {
+		writeRecallWindowError(w, err, req.FailedCycleID)
+		return
+	}
+
+	details := map[string]interface{}{
+		"device_id":       recall.DeviceID,
+		"failed_cycle_id": recall.FailedCycleID,
+		"affected_cycles": recall.AffectedCycles,
+	}
+	if recall.LastPassedCycleID != nil {
+		details["last_passed_cycle_id"] = *recall.LastPassedCycleID
+	}
+	if err := database.LogAudit(database.ActionRecallOpened, "recall", &recall.ID, recall.OpenedBy, details); err != nil {
+		logger.Warn("Failed to create audit log", "error", err)
+	}
+
+	logger.Info("Recall case opened",
+		"recall_id", recall.ID,
+		"device_id", recall.DeviceID,
+		"failed_cycle_id", recall.FailedCycleID,
+		"affected_cycles", recall.AffectedCycles,
+		"user", recall.OpenedBy)
+
+	writeRecallReport(w, recall.ID, http.StatusCreated)
+}
+
+// GetRecallHandler handles GET /api/recalls/{id} requests
+func GetRecallHandler(w http.ResponseWriter, r *http.Request) {
+	recallID, ok := recallIDFromPath(w, r.URL.Path)
+	if !ok {
+		return
+	}
+
+	writeRecallReport(w, recallID, http.StatusOK)
+}
+
+// AddRecallNoteHandler handles POST /api/recalls/{id}/notes requests
+func AddRecallNoteHandler(w http.ResponseWriter, r *http.Request) {
+	logger := logging.Get()
+
+	recallID, ok := recallIDFromPath(w, r.URL.Path)
+	if !ok {
+		return
+	}
+
+	var req RecallNoteRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid JSON in request body",
+		})
+		return
+	}
+
+	req.User = strings.TrimSpace(req.User)
+	req.Note = strings.TrimSpace(req.Note)
+	if req.User == "" || req.Note == "" {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "validation_error",
+			Message: "user and note are required",
+		})
+		return
+	}
+
+	note, err := database.AddRecallNote(recallID, req.User, req.Note)
+	if err != nil {
+		writeRecallError(w, err, recallID, "Failed to add recall note")
+		return
+	}
+
+	details := map[string]interface{}{
+		"note_id": note.ID,
+		"note":    note.Note,
+	}
+	if err := database.LogAudit(database.ActionRecallAnnotated, "recall", &recallID, note.User, details); err != nil {
+		logger.Warn("Failed to create audit log", "error", err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(note)
+}
+
+// CloseRecallHandler handles POST /api/recalls/{id}/close requests
+func CloseRecallHandler(w http.ResponseWriter, r *http.Request) {
+	logger := logging.Get()
+
+	recallID, ok := recallIDFromPath(w, r.URL.Path)
+	if !ok {
+		return
+	}
+
+	var req CloseRecallRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid JSON in request body",
+		})
+		return
+	}
+
+	req.User = strings.TrimSpace(req.User)
+	req.Resolution = strings.TrimSpace(req.Resolution)
+	if req.User == "" || req.Resolution == "" {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "validation_error",
+			Message: "user and resolution are required",
+		})
+		return
+	}
+
+	recall, err := database.CloseRecallCase(recallID, req.User, req.Resolution)
+	if err != nil {
+		writeRecallError(w, err, recallID, "Failed to close recall case")
+		return
+	}
+
+	details := map[string]interface{}{
+		"resolution":      recall.Resolution,
+		"affected_cycles": recall.AffectedCycles,
+	}
+	if err := database.LogAudit(database.ActionRecallClosed, "recall", &recallID, recall.ClosedBy, details); err != nil {
+		logger.Warn("Failed to create audit log", "error", err)
+	}
+
+	logger.Info("Recall case closed", "recall_id", recallID, "user", recall.ClosedBy)
+
+	writeRecallReport(w, recallID, http.StatusOK)
+}
+
+// ExportRecallPDFHandler handles GET /api/recalls/{id}/export/pdf requests
+func ExportRecallPDFHandler(w http.ResponseWriter, r *http.Request) {
+	recallID, ok := recallIDFromPath(w, r.URL.Path)
+	if !ok {
+		return
+	}
+
+	report, err := database.GetRecallReport(recallID)
+	if err != nil {
+		writeRecallError(w, err, recallID, "Failed to retrieve recall case")
+		return
+	}
+
+	pdfBytes, err := pdf.GenerateRecallPDF(report)
+	if err != nil {
+		logging.Get().Error("Failed to generate recall PDF", "error", err, "recall_id", recallID)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to generate PDF",
+		})
+		return
+	}
+
+	filename := fmt.Sprintf("recall-%d-report.pdf", recallID)
+	w.Header().Set("Content-Type", "application/pdf")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	w.Header().Set("Content-Length", fmt.Sprintf("%d", len(pdfBytes)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(pdfBytes)
+}
+
+// ExportRecallCSVHandler handles GET /api/recalls/{id}/export/csv requests
+func ExportRecallCSVHandler(w http.ResponseWriter, r *http.Request) {
+	recallID, ok := recallIDFromPath(w, r.URL.Path)
+	if !ok {
+		return
+	}
+
+	report, err := database.GetRecallReport(recallID)
+	if err != nil {
+		writeRecallError(w, err, recallID, "Failed to retrieve recall case")
+		return
+	}
+
+	csvBytes, err := csv.GenerateRecallCSV(report)
+	if err != nil {
+		logging.Get().Error("Failed to generate recall CSV", "error", err, "recall_id", recallID)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to generate CSV",
+		})
+		return
+	}
+
+	filename := fmt.Sprintf("recall-%d-cycles.csv", recallID)
+	w.Header().Set("Content-Type", "text/csv; charset=utf-8")
+	w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=\"%s\"", filename))
+	w.Header().Set("Content-Length", fmt.Sprintf("%d", len(csvBytes)))
+	w.WriteHeader(http.StatusOK)
+	w.Write(csvBytes)
+}
+
+// writeRecallReport writes the recall case with affected cycles and notes
+func writeRecallReport(w http.ResponseWriter, recallID int, status int) {
+	report, err := database.GetRecallReport(recallID)
+	if err != nil {
+		writeRecallError(w, err, recallID, "Failed to retrieve recall case")
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(report)
+}
+
+// writeRecallWindowError writes the error response for a failed recall window lookup
+func writeRecallWindowError(w http.ResponseWriter, err error, failedCycleID int) {
+	switch err {
+	case database.ErrCycleNotFound:
+		writeCycleNotFound(w, failedCycleID)
+	case database.ErrFailedCycleNotOnDevice:
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "validation_error",
+			Message: fmt.Sprintf("Cycle %d does not belong to the given device", failedCycleID),
+		})
+	default:
+		logging.Get().Error("Failed to determine recall window", "error", err, "failed_cycle_id", failedCycleID)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to determine recall window",
+		})
+	}
+}
+
+// writeRecallError writes the error response for a recall case operation
+func writeRecallError(w http.ResponseWriter, err error, recallID int, message string) {
+	w.Header().Set("Content-Type", "application/json")
+	switch err {
+	case database.ErrRecallNotFound:
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "recall_not_found",
+			Message: fmt.Sprintf("Recall case with ID %d not found", recallID),
+		})
+	case database.ErrRecallClosed:
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "recall_closed",
+			Message: fmt.Sprintf("Recall case %d is closed", recallID),
+		})
+	default:
+		logging.Get().Error(message, "error", err, "recall_id", recallID)
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "internal_error",
+			Message: message,
+		})
+	}
+}
+
+// recallIDFromPath extracts the recall ID from paths like "/recalls/3" or
+// "/recalls/3/export/pdf", writing a 400 error response if it is invalid
+func recallIDFromPath(w http.ResponseWriter, path string) (int, bool) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) >= 2 && parts[0] == "recalls" {
+		if id, err := strconv.Atoi(parts[1]); err == nil {
+			return id, true
+		}
+	}
+
+	logging.Get().Warn("Failed to extract recall ID from path", "path", path)
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(ErrorResponse{
+		Error:   "invalid_recall_id",
+		Message: "Invalid recall ID in URL path",
+	})
+	return 0, false
+}
@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"steri-connect-go/internal/api/websocket"
+	"steri-connect-go/internal/database"
+	"steri-connect-go/internal/logging"
+)
+
+// Release actions and the release state they lead to
+var releaseActions = map[string]string{
+	"submit":  database.ReleaseStatusPending,
+	"release": database.ReleaseStatusReleased,
+	"reject":  database.ReleaseStatusRejected,
+}
+
+// Audit action (also used as WebSocket event name) per target release state
+var releaseAuditActions = map[string]database.AuditAction{
+	database.ReleaseStatusPending:  database.ActionReleaseRequest,
+	database.ReleaseStatusReleased: database.ActionCycleReleased,
+	database.ReleaseStatusRejected: database.ActionCycleRejected,
+}
+
+// CycleReleaseRequest represents the request body for a release transition
+type CycleReleaseRequest struct {
+	Action    string                     `json:"action"` // "submit", "release" or "reject"
+	User      string                     `json:"user"`
+	Reason    string                     `json:"reason,omitempty"`    // Required for "reject"
+	Checklist *database.ReleaseChecklist `json:"checklist,omitempty"` // Required (all true) for "release"
+}
+
+// CycleReleaseResponse represents the release state and history of a cycle
+type CycleReleaseResponse struct {
+	CycleID       int                     `json:"cycle_id"`
+	Result        string                  `json:"result,omitempty"`
+	ReleaseStatus string                  `json:"release_status,omitempty"`
+	History       []database.CycleRelease `json:"history"`
+}
+
+// GetCycleReleaseHandler handles GET /api/cycles/{id}/release requests
+func GetCycleReleaseHandler(w http.ResponseWriter, r *http.Request) {
+	cycleID, ok := releaseCycleIDFromPath(w, r.URL.Path)
+	if !ok {
+		return
+	}
+
+	cycle, ok := lookupCycle(w, cycleID)
+	if !ok {
+		return
+	}
+
+	writeCycleRelease(w, cycle)
+}
+
+// CycleReleaseHandler handles POST /api/cycles/{id}/release requests
+// Moves the cycle through the release workflow:
+// completed -(submit)-> pending_release -(release|reject)-> released|rejected
+func CycleReleaseHandler(w http.ResponseWriter, r *http.Request) {
+	logger := logging.Get()
+
+	cycleID, ok := releaseCycleIDFromPath(w, r.URL.Path)
+	if !ok {
+		return
+	}
+
+	var req CycleReleaseRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "invalid_request",
+			Message: "Invalid JSON in request body",
+		})
+		return
+	}
+
+	toStatus, valid := releaseActions[req.Action]
+	if !valid {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "validation_error",
+			Message: "action must be 'submit', 'release' or 'reject'",
+		})
+		return
+	}
+
+	cycle, ok := lookupCycle(w, cycleID)
+	if !ok {
+		return
+	}
+
+	release, err := database.TransitionCycleRelease(&database.CycleRelease{
+		CycleID:   cycleID,
+		ToStatus:  toStatus,
+		User:      req.User,
+		Reason:    req.Reason,
+		Checklist: req.Checklist,
+	})
+	switch {
+	case err == database.ErrReleaseUserRequired || err == database.ErrReleaseReasonRequired || err == database.ErrReleaseChecklistIncomplete:
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusBadRequest)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "validation_error",
+			Message: err.Error(),
+		})
+		return
+	case err == database.ErrCycleNotFound:
+		writeCycleNotFound(w, cycleID)
+		return
+	case err == database.ErrInvalidReleaseTransition:
+		current := cycle.ReleaseStatus
+		if current == "" {
+			current = "running"
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "invalid_release_transition",
+			Message: fmt.Sprintf("Cannot %s cycle %d in release state %s", req.Action, cycleID, current),
+		})
+		return
+	case err == database.ErrCycleNotPassed:
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "cycle_not_passed",
+			Message: fmt.Sprintf("Cycle %d has result %q and can only be rejected", cycleID, cycle.Result),
+		})
+		return
+	case err != nil:
+		logger.Error("Failed to change cycle release status", "error", err, "cycle_id", cycleID, "action", req.Action)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to change cycle release status",
+		})
+		return
+	}
+
+	action := releaseAuditActions[release.ToStatus]
+	data := map[string]interface{}{
+		"cycle_id":       cycleID,
+		"device_id":      cycle.DeviceID,
+		"from_status":    release.FromStatus,
+		"release_status": release.ToStatus,
+		"user":           release.User,
+	}
+	if release.Reason != "" {
+		data["reason"] = release.Reason
+	}
+	if release.Checklist != nil {
+		data["checklist"] = release.Checklist
+	}
+
+	if err := database.LogAudit(action, "cycle", &cycleID, release.User, data); err != nil {
+		logger.Warn("Failed to create audit log", "error", err)
+	}
+
+	if err := websocket.BroadcastEvent(websocket.Event{Event: string(action), Data: data}); err != nil {
+		logger.Warn("Failed to broadcast release event", "cycle_id", cycleID, "error", err)
+	}
+
+	logger.Info("Cycle release status changed",
+		"cycle_id", cycleID,
+		"from_status", release.FromStatus,
+		"release_status", release.ToStatus,
+		"user", release.User)
+
+	cycle.ReleaseStatus = release.ToStatus
+	writeCycleRelease(w, cycle)
+}
+
+// writeCycleRelease writes the release state and history of a cycle
+func writeCycleRelease(w http.ResponseWriter, cycle *database.Cycle) {
+	history, err := database.GetCycleReleases(cycle.ID)
+	if err != nil {
+		logging.Get().Error("Failed to get cycle release history", "error", err, "cycle_id", cycle.ID)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to retrieve release history",
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(CycleReleaseResponse{
+		CycleID:       cycle.ID,
+		Result:        cycle.Result,
+		ReleaseStatus: cycle.ReleaseStatus,
+		History:       history,
+	})
+}
+
+// releaseCycleIDFromPath extracts the cycle ID from "/cycles/{id}/release",
+// writing a 400 response if the path is invalid
+func releaseCycleIDFromPath(w http.ResponseWriter, path string) (int, bool) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) == 3 && parts[0] == "cycles" && parts[2] == "release" {
+		if id, err := strconv.Atoi(parts[1]); err == nil {
+			return id, true
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(ErrorResponse{
+		Error:   "invalid_cycle_id",
+		Message: "Invalid cycle ID in URL path",
+	})
+	return 0, false
+}
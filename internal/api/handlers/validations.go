@@ -0,0 +1,141 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"steri-connect-go/internal/config"
+	"steri-connect-go/internal/database"
+	"steri-connect-go/internal/logging"
+	"steri-connect-go/internal/validation"
+)
+
+// GetCycleValidationHandler handles GET /api/cycles/{id}/validation requests
+// Returns the parameter validation verdict of a cycle with the reason for every rule.
+func GetCycleValidationHandler(w http.ResponseWriter, r *http.Request) {
+	logger := logging.Get()
+
+	cycleID, ok := validationCycleIDFromPath(w, r.URL.Path)
+	if !ok {
+		return
+	}
+	if _, ok := lookupCycle(w, cycleID); !ok {
+		return
+	}
+
+	result, err := database.GetCycleValidation(cycleID)
+	if err == database.ErrValidationNotFound {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusNotFound)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "validation_not_found",
+			Message: fmt.Sprintf("Cycle %d has not been validated", cycleID),
+		})
+		return
+	}
+	if err != nil {
+		logger.Error("Failed to get cycle validation", "error", err, "cycle_id", cycleID)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to retrieve cycle validation",
+		})
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(result)
+}
+
+// ValidateCycleHandler handles POST /api/cycles/{id}/validation requests
+// Validates a finished cycle again with the currently configured profiles, e.g. after a
+// profile was added or corrected, and replaces the stored verdict.
+func ValidateCycleHandler(w http.ResponseWriter, r *http.Request) {
+	logger := logging.Get()
+
+	cycleID, ok := validationCycleIDFromPath(w, r.URL.Path)
+	if !ok {
+		return
+	}
+
+	cfg := config.Get().Validation
+	if !cfg.Enabled {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "validation_disabled",
+			Message: "Parameter validation is disabled in the configuration",
+		})
+		return
+	}
+
+	cycle, ok := lookupCycle(w, cycleID)
+	if !ok {
+		return
+	}
+	if cycle.EndTS == nil {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusConflict)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "cycle_running",
+			Message: fmt.Sprintf("Cycle %d is still running", cycleID),
+		})
+		return
+	}
+
+	result, err := validation.ValidateCycle(cycleID, cfg)
+	if err != nil {
+		logger.Error("Failed to validate cycle", "error", err, "cycle_id", cycleID)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(http.StatusInternalServerError)
+		json.NewEncoder(w).Encode(ErrorResponse{
+			Error:   "internal_error",
+			Message: "Failed to validate cycle",
+		})
+		return
+	}
+
+	details := map[string]interface{}{
+		"cycle_id":        cycleID,
+		"profile":         result.Profile,
+		"result":          result.Result,
+		"reasons":         result.Reasons,
+		"previous_result": cycle.ValidationResult,
+	}
+	if err := database.LogAudit(database.ActionCycleValidated, "cycle", &cycleID, "", details); err != nil {
+		logger.Warn("Failed to create audit log", "error", err)
+	}
+
+	logger.Info("Cycle validated",
+		"cycle_id", cycleID,
+		"profile", result.Profile,
+		"validation_result", result.Result)
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusOK)
+	json.NewEncoder(w).Encode(result)
+}
+
+// validationCycleIDFromPath extracts the cycle ID from "/cycles/{id}/validation",
+// writing a 400 response if the path is invalid
+func validationCycleIDFromPath(w http.ResponseWriter, path string) (int, bool) {
+	parts := strings.Split(strings.Trim(path, "/"), "/")
+	if len(parts) == 3 && parts[0] == "cycles" && parts[2] == "validation" {
+		if id, err := strconv.Atoi(parts[1]); err == nil {
+			return id, true
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusBadRequest)
+	json.NewEncoder(w).Encode(ErrorResponse{
+		Error:   "invalid_cycle_id",
+		Message: "Invalid cycle ID in URL path",
+	})
+	return 0, false
+}
@@ -49,12 +49,27 @@ func SetupRouter() http.Handler {
 	// GET /api/devices/{id} - Get device by ID
 	// PUT /api/devices/{id} - Update device
 	// DELETE /api/devices/{id} - Delete device
+	// GET /api/devices/{id}/info - Get model/serial/firmware reported by the device
+	// GET /api/devices/{id}/reachability - Get availability, RTT percentiles and outages
+	// GET /api/devices/{id}/connection-history - Get connection state changes
 	apiHandler.HandleFunc("/devices/", func(w http.ResponseWriter, r *http.Request) {
 		// Check if this is a status endpoint
 		if strings.HasSuffix(r.URL.Path, "/status") && r.Method == http.MethodGet {
 			handlers.GetDeviceStatusHandler(w, r)
 			return
 		}
+		if strings.HasSuffix(r.URL.Path, "/info") && r.Method == http.MethodGet {
+			handlers.GetDeviceInfoHandler(w, r)
+			return
+		}
+		if strings.HasSuffix(r.URL.Path, "/reachability") && r.Method == http.MethodGet {
+			handlers.GetDeviceReachabilityHandler(w, r)
+			return
+		}
+		if strings.HasSuffix(r.URL.Path, "/connection-history") && r.Method == http.MethodGet {
+			handlers.GetDeviceConnectionHistoryHandler(w, r)
+			return
+		}
 
 		// Regular device CRUD operations
 		switch r.Method {
@@ -73,6 +88,8 @@ func SetupRouter() http.Handler {
 	// POST /api/melag/{id}/start - Start cycle
 	// GET /api/melag/{id}/status - Get device and cycle status
 	// GET /api/melag/{id}/cycles/{cycle_id} - Get cycle details
+	// POST /api/melag/{id}/import - Start historical protocol import
+	// GET /api/melag/{id}/import - Get protocol import progress
 	apiHandler.HandleFunc("/melag/", func(w http.ResponseWriter, r *http.Request) {
 		if strings.HasSuffix(r.URL.Path, "/start") && r.Method == http.MethodPost {
 			handlers.StartCycleHandler(w, r)
@@ -82,6 +99,17 @@ func SetupRouter() http.Handler {
 			handlers.GetMelagStatusHandler(w, r)
 			return
 		}
+		if strings.HasSuffix(r.URL.Path, "/import") {
+			switch r.Method {
+			case http.MethodPost:
+				handlers.StartProtocolImportHandler(w, r)
+			case http.MethodGet:
+				handlers.GetProtocolImportHandler(w, r)
+			default:
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			}
+			return
+		}
 		// Check if this is a cycle retrieval endpoint: /melag/{id}/cycles/{cycle_id}
 		if strings.Contains(r.URL.Path, "/cycles/") && r.Method == http.MethodGet {
 			handlers.GetMelagCycleHandler(w, r)
@@ -97,9 +125,74 @@ func SetupRouter() http.Handler {
 	// GET /api/cycles/{id}/export/pdf - Export cycle protocol as PDF
 	// GET /api/cycles/export/csv - Export cycles as CSV
 	// GET /api/cycles/export/json - Export cycles as JSON
-	apiHandler.HandleFunc("/cycles", func(w http.ResponseWriter, r *http.Request) {
+	// GET /api/cycles/{id}/loads - List instrument sets in the cycle load
+	// POST /api/cycles/{id}/loads - Assign an instrument set to the cycle load
+	// DELETE /api/cycles/{id}/loads/{load_id} - Remove an instrument set from the cycle load
+	// GET /api/cycles/{id}/release - Get release state and history
+	// POST /api/cycles/{id}/release - Submit, release or reject the cycle load
+	// GET /api/cycles/{id}/labels - Labels for a released cycle (PDF, ZPL or JSON)
+	// GET /api/cycles/{id}/samples - Recorded process values (temperature/pressure curve)
+	// GET /api/cycles/{id}/validation - Parameter validation verdict and reasons
+	// POST /api/cycles/{id}/validation - Validate the cycle again with the current profiles
+	cyclesHandler := func(w http.ResponseWriter, r *http.Request) {
+		pathParts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		// Check if this is the release endpoint: /cycles/{id}/release
+		if len(pathParts) == 3 && pathParts[0] == "cycles" && pathParts[2] == "release" {
+			switch r.Method {
+			case http.MethodGet:
+				handlers.GetCycleReleaseHandler(w, r)
+			case http.MethodPost:
+				handlers.CycleReleaseHandler(w, r)
+			default:
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			}
+			return
+		}
+		// Check if this is the label endpoint: /cycles/{id}/labels
+		if len(pathParts) == 3 && pathParts[0] == "cycles" && pathParts[2] == "labels" {
+			if r.Method == http.MethodGet {
+				handlers.CycleLabelsHandler(w, r)
+			} else {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			}
+			return
+		}
+		// Check if this is the samples endpoint: /cycles/{id}/samples
+		if len(pathParts) == 3 && pathParts[0] == "cycles" && pathParts[2] == "samples" {
+			if r.Method == http.MethodGet {
+				handlers.GetCycleSamplesHandler(w, r)
+			} else {
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			}
+			return
+		}
+		// Check if this is the validation endpoint: /cycles/{id}/validation
+		if len(pathParts) == 3 && pathParts[0] == "cycles" && pathParts[2] == "validation" {
+			switch r.Method {
+			case http.MethodGet:
+				handlers.GetCycleValidationHandler(w, r)
+			case http.MethodPost:
+				handlers.ValidateCycleHandler(w, r)
+			default:
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			}
+			return
+		}
+		// Check if this is a load endpoint: /cycles/{id}/loads[/{load_id}]
+		if len(pathParts) >= 3 && pathParts[0] == "cycles" && pathParts[2] == "loads" {
+			switch {
+			case r.Method == http.MethodGet && len(pathParts) == 3:
+				handlers.ListCycleLoadsHandler(w, r)
+			case r.Method == http.MethodPost && len(pathParts) == 3:
+				handlers.AssignCycleLoadHandler(w, r)
+			case r.Method == http.MethodDelete && len(pathParts) == 4:
+				handlers.RemoveCycleLoadHandler(w, r)
+			default:
+				w.WriteHeader(http.StatusMethodNotAllowed)
+			}
+			return
+		}
 		if r.Method == http.MethodGet {
-			pathParts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
 			// Check if this is a running cycles endpoint: /cycles/running
 			if len(pathParts) >= 2 && pathParts[0] == "cycles" && pathParts[1] == "running" {
 				handlers.GetRunningCyclesHandler(w, r)
@@ -129,6 +222,124 @@ func SetupRouter() http.Handler {
 		} else {
 			w.WriteHeader(http.StatusMethodNotAllowed)
 		}
+	}
+	apiHandler.HandleFunc("/cycles", cyclesHandler)
+	apiHandler.HandleFunc("/cycles/", cyclesHandler)
+
+	// Instrument set (tray) management and traceability endpoints
+	// GET /api/instrument-sets - List instrument sets (optional ?code= for barcode lookup)
+	// POST /api/instrument-sets - Create instrument set
+	apiHandler.HandleFunc("/instrument-sets", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			handlers.CreateInstrumentSetHandler(w, r)
+		case http.MethodGet:
+			handlers.ListInstrumentSetsHandler(w, r)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+
+	// GET /api/instrument-sets/{id} - Get instrument set by ID
+	// PUT /api/instrument-sets/{id} - Update instrument set
+	// DELETE /api/instrument-sets/{id} - Delete instrument set (only if never loaded)
+	// GET /api/instrument-sets/{id}/cycles - List cycles the set was processed in
+	// GET /api/instrument-sets/{id}/last-cycle - Get the last passed cycle of the set
+	apiHandler.HandleFunc("/instrument-sets/", func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/cycles") && r.Method == http.MethodGet {
+			handlers.GetInstrumentSetCyclesHandler(w, r)
+			return
+		}
+		if strings.HasSuffix(r.URL.Path, "/last-cycle") && r.Method == http.MethodGet {
+			handlers.GetInstrumentSetLastCycleHandler(w, r)
+			return
+		}
+
+		switch r.Method {
+		case http.MethodGet:
+			handlers.GetInstrumentSetHandler(w, r)
+		case http.MethodPut:
+			handlers.UpdateInstrumentSetHandler(w, r)
+		case http.MethodDelete:
+			handlers.DeleteInstrumentSetHandler(w, r)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+
+	// Audit log endpoints
+	// GET /api/audit - List audit log entries (filters, cursor pagination)
+	// GET /api/audit/verify - Verify the audit log hash chain
+	// GET /api/audit/export/csv - Export audit log entries as CSV
+	// GET /api/audit/export/json - Export audit log entries with chain state as JSON
+	// GET /api/audit/export/pdf - Export audit log report as PDF
+	apiHandler.HandleFunc("/audit", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method == http.MethodGet {
+			handlers.ListAuditHandler(w, r)
+		} else {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+	apiHandler.HandleFunc("/audit/", func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodGet {
+			w.WriteHeader(http.StatusMethodNotAllowed)
+			return
+		}
+		pathParts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		switch {
+		case len(pathParts) == 1:
+			handlers.ListAuditHandler(w, r)
+		case len(pathParts) == 2 && pathParts[1] == "verify":
+			handlers.VerifyAuditHandler(w, r)
+		case len(pathParts) == 3 && pathParts[1] == "export" && pathParts[2] == "csv":
+			handlers.ExportAuditCSVHandler(w, r)
+		case len(pathParts) == 3 && pathParts[1] == "export" && pathParts[2] == "json":
+			handlers.ExportAuditJSONHandler(w, r)
+		case len(pathParts) == 3 && pathParts[1] == "export" && pathParts[2] == "pdf":
+			handlers.ExportAuditPDFHandler(w, r)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	})
+
+	// Recall endpoints (cycles affected by a failed indicator)
+	// GET /api/recalls - List recall cases (optional ?status= and ?device_id=)
+	// POST /api/recalls - Open a recall case for a failed indicator cycle
+	apiHandler.HandleFunc("/recalls", func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodPost:
+			handlers.OpenRecallHandler(w, r)
+		case http.MethodGet:
+			handlers.ListRecallsHandler(w, r)
+		default:
+			w.WriteHeader(http.StatusMethodNotAllowed)
+		}
+	})
+
+	// GET /api/recalls/preview - Determine the affected cycles without opening a case
+	// GET /api/recalls/{id} - Get recall case with affected cycles and notes
+	// POST /api/recalls/{id}/notes - Annotate recall case
+	// POST /api/recalls/{id}/close - Close recall case
+	// GET /api/recalls/{id}/export/pdf - Export recall report as PDF
+	// GET /api/recalls/{id}/export/csv - Export affected cycles as CSV
+	apiHandler.HandleFunc("/recalls/", func(w http.ResponseWriter, r *http.Request) {
+		pathParts := strings.Split(strings.Trim(r.URL.Path, "/"), "/")
+		switch {
+		case len(pathParts) == 2 && pathParts[1] == "preview" && r.Method == http.MethodGet:
+			handlers.PreviewRecallHandler(w, r)
+		case len(pathParts) == 2 && r.Method == http.MethodGet:
+			handlers.GetRecallHandler(w, r)
+		case len(pathParts) == 3 && pathParts[2] == "notes" && r.Method == http.MethodPost:
+			handlers.AddRecallNoteHandler(w, r)
+		case len(pathParts) == 3 && pathParts[2] == "close" && r.Method == http.MethodPost:
+			handlers.CloseRecallHandler(w, r)
+		case len(pathParts) == 4 && pathParts[2] == "export" && pathParts[3] == "pdf" && r.Method == http.MethodGet:
+			handlers.ExportRecallPDFHandler(w, r)
+		case len(pathParts) == 4 && pathParts[2] == "export" && pathParts[3] == "csv" && r.Method == http.MethodGet:
+			handlers.ExportRecallCSVHandler(w, r)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
 	})
 
 	// Apply metrics middleware to track API requests
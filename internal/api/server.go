@@ -7,6 +7,7 @@ import (
 	"net/http"
 	"time"
 
+	"steri-connect-go/internal/config"
 	"steri-connect-go/internal/logging"
 )
 
@@ -26,7 +27,7 @@ func NewServer(port int, bindAddr string, handler http.Handler) *Server {
 			Addr:         fmt.Sprintf("%s:%d", bindAddr, port),
 			Handler:      handler,
 			ReadTimeout:  15 * time.Second,
-			WriteTimeout: 15 * time.Second,
+			WriteTimeout: config.ServerWriteTimeout * time.Second,
 			IdleTimeout:  60 * time.Second,
 		},
 	}
@@ -0,0 +1,86 @@
+package barcode
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestCode128(t *testing.T) {
+	for i, pattern := range code128Patterns {
+		sum := 0
+		for _, width := range pattern {
+			sum += int(width - '0')
+		}
+		if want := 11; i == code128Stop {
+			want = 13
+			if sum != want {
+				t.Errorf("stop pattern has %d modules, want %d", sum, want)
+			}
+		} else if sum != want {
+			t.Errorf("pattern %d has %d modules, want %d", i, sum, want)
+		}
+	}
+
+	// Start B, "PJJ123C", check symbol (104 + 48*1 + 42*2 + 42*3 + 17*4 + 18*5 + 19*6 + 35*7) % 103
+	symbols, err := Code128Symbols("PJJ123C")
+	if err != nil {
+		t.Fatalf("Code128Symbols failed: %v", err)
+	}
+	want := []int{104, 48, 42, 42, 17, 18, 19, 35, 55}
+	if len(symbols) != len(want) {
+		t.Fatalf("Expected %d symbols, got %v", len(want), symbols)
+	}
+	for i := range want {
+		if symbols[i] != want[i] {
+			t.Fatalf("Expected symbols %v, got %v", want, symbols)
+		}
+	}
+
+	modules, err := Code128("PJJ123C")
+	if err != nil {
+		t.Fatalf("Code128 failed: %v", err)
+	}
+	if len(modules) != 11*len(want)+13 || !modules[0] || !modules[len(modules)-1] {
+		t.Errorf("Unexpected module sequence of length %d", len(modules))
+	}
+
+	if _, err := Code128("Ä"); err == nil {
+		t.Error("Expected error for character outside code set B")
+	}
+}
+
+func TestDataMatrix(t *testing.T) {
+	// Example from ISO/IEC 16022: "123456" in a 10x10 symbol
+	codewords, size, err := DataMatrixCodewords("123456")
+	if err != nil {
+		t.Fatalf("DataMatrixCodewords failed: %v", err)
+	}
+	if size != 10 {
+		t.Errorf("Expected 10x10 symbol, got %dx%d", size, size)
+	}
+	want := []byte{142, 164, 186, 114, 25, 5, 88, 102}
+	if !bytes.Equal(codewords, want) {
+		t.Errorf("Expected codewords %v, got %v", want, codewords)
+	}
+
+	matrix, err := DataMatrix("123456")
+	if err != nil {
+		t.Fatalf("DataMatrix failed: %v", err)
+	}
+	for i := 0; i < 10; i++ {
+		if !matrix[i][0] || !matrix[9][i] {
+			t.Fatalf("Finder pattern broken at %d", i)
+		}
+		if matrix[0][i] != (i%2 == 0) || matrix[i][9] != (i%2 == 1) {
+			t.Fatalf("Clock track broken at %d", i)
+		}
+	}
+
+	matrix, err = DataMatrix("4711/12/20270416/sterile goods label")
+	if err != nil {
+		t.Fatalf("DataMatrix failed: %v", err)
+	}
+	if len(matrix) != 22 {
+		t.Errorf("Expected 22x22 symbol, got %dx%d", len(matrix), len(matrix))
+	}
+}
@@ -0,0 +1,286 @@
+package barcode
+
+import "fmt"
+
+// dataMatrixSize describes a square ECC 200 symbol with a single Reed-Solomon block
+type dataMatrixSize struct {
+	size       int // Modules per side including finder patterns
+	regionSize int // Modules per side of one data region
+	dataWords  int
+	eccWords   int
+}
+
+var dataMatrixSizes = []dataMatrixSize{
+	{10, 8, 3, 5},
+	{12, 10, 5, 7},
+	{14, 12, 8, 10},
+	{16, 14, 12, 12},
+	{18, 16, 18, 14},
+	{20, 18, 22, 18},
+	{22, 20, 30, 20},
+	{24, 22, 36, 24},
+	{26, 24, 44, 28},
+	{32, 14, 62, 36},
+	{36, 16, 86, 42},
+	{40, 18, 114, 48},
+	{44, 20, 144, 56},
+	{48, 22, 174, 68},
+}
+
+const dataMatrixPad = 129
+
+// DataMatrixCodewords returns the data and error correction codewords of data
+// encoded in ASCII mode, and the symbol size chosen for them
+func DataMatrixCodewords(data string) ([]byte, int, error) {
+	encoded, err := dataMatrixEncodeASCII(data)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var symbol *dataMatrixSize
+	for i := range dataMatrixSizes {
+		if len(encoded) <= dataMatrixSizes[i].dataWords {
+			symbol = &dataMatrixSizes[i]
+			break
+		}
+	}
+	if symbol == nil {
+		return nil, 0, fmt.Errorf("data too long for Data Matrix: %d codewords", len(encoded))
+	}
+
+	// Pad to symbol capacity: 129 first, then pseudo-random values (253-state randomizing)
+	first := len(encoded)
+	for i := first; i < symbol.dataWords; i++ {
+		if i == first {
+			encoded = append(encoded, dataMatrixPad)
+			continue
+		}
+		pad := dataMatrixPad + (149*(i+1))%253 + 1
+		if pad > 254 {
+			pad -= 254
+		}
+		encoded = append(encoded, byte(pad))
+	}
+
+	return append(encoded, reedSolomon(encoded, symbol.eccWords)...), symbol.size, nil
+}
+
+// DataMatrix encodes data as a square ECC 200 Data Matrix symbol
+// The result is indexed [row][column] (true = dark module), without quiet zone.
+func DataMatrix(data string) ([][]bool, error) {
+	codewords, size, err := DataMatrixCodewords(data)
+	if err != nil {
+		return nil, err
+	}
+
+	var symbol dataMatrixSize
+	for _, s := range dataMatrixSizes {
+		if s.size == size {
+			symbol = s
+		}
+	}
+
+	regions := size / (symbol.regionSize + 2)
+	mapping := dataMatrixPlacement(regions * symbol.regionSize)
+
+	matrix := make([][]bool, size)
+	for row := range matrix {
+		matrix[row] = make([]bool, size)
+	}
+
+	block := symbol.regionSize + 2
+	for row := 0; row < size; row++ {
+		for col := 0; col < size; col++ {
+			r, c := row%block, col%block
+			switch {
+			case c == 0 || r == block-1: // Solid "L" finder
+				matrix[row][col] = true
+			case r == 0: // Alternating top clock track
+				matrix[row][col] = c%2 == 0
+			case c == block-1: // Alternating right clock track
+				matrix[row][col] = r%2 == 1
+			default:
+				mappingRow := (row/block)*symbol.regionSize + r - 1
+				mappingCol := (col/block)*symbol.regionSize + c - 1
+				value := mapping[mappingRow][mappingCol]
+				if value < 10 {
+					matrix[row][col] = value == 1
+					continue
+				}
+				codeword := codewords[value/10-1]
+				bit := uint(value % 10)
+				matrix[row][col] = codeword&(1<<(8-bit)) != 0
+			}
+		}
+	}
+
+	return matrix, nil
+}
+
+// dataMatrixEncodeASCII encodes data in ASCII mode (digit pairs in one codeword)
+func dataMatrixEncodeASCII(data string) ([]byte, error) {
+	if data == "" {
+		return nil, fmt.Errorf("no data to encode")
+	}
+
+	var encoded []byte
+	for i := 0; i < len(data); i++ {
+		c := data[i]
+		switch {
+		case isDigit(c) && i+1 < len(data) && isDigit(data[i+1]):
+			encoded = append(encoded, 130+(c-'0')*10+(data[i+1]-'0'))
+			i++
+		case c < 128:
+			encoded = append(encoded, c+1)
+		default:
+			encoded = append(encoded, 235, c-127) // Upper shift
+		}
+	}
+	return encoded, nil
+}
+
+func isDigit(c byte) bool {
+	return c >= '0' && c <= '9'
+}
+
+// dataMatrixPlacement returns the ECC 200 module placement for a mapping matrix
+// of n x n modules. Each entry is 10*codeword+bit (codeword 1-based, bit 1 = MSB),
+// or 0/1 for the fixed light/dark modules in the lower right corner.
+func dataMatrixPlacement(n int) [][]int {
+	nrow, ncol := n, n
+	array := make([][]int, nrow)
+	for row := range array {
+		array[row] = make([]int, ncol)
+	}
+
+	module := func(row, col, chr, bit int) {
+		if row < 0 {
+			row += nrow
+			col += 4 - ((nrow + 4) % 8)
+		}
+		if col < 0 {
+			col += ncol
+			row += 4 - ((ncol + 4) % 8)
+		}
+		array[row][col] = 10*chr + bit
+	}
+	utah := func(row, col, chr int) {
+		module(row-2, col-2, chr, 1)
+		module(row-2, col-1, chr, 2)
+		module(row-1, col-2, chr, 3)
+		module(row-1, col-1, chr, 4)
+		module(row-1, col, chr, 5)
+		module(row, col-2, chr, 6)
+		module(row, col-1, chr, 7)
+		module(row, col, chr, 8)
+	}
+	corner := func(chr int, positions [8][2]int) {
+		for i, p := range positions {
+			module(p[0], p[1], chr, i+1)
+		}
+	}
+
+	chr, row, col := 1, 4, 0
+	for row < nrow || col < ncol {
+		if row == nrow && col == 0 {
+			corner(chr, [8][2]int{{nrow - 1, 0}, {nrow - 1, 1}, {nrow - 1, 2}, {0, ncol - 2}, {0, ncol - 1}, {1, ncol - 1}, {2, ncol - 1}, {3, ncol - 1}})
+			chr++
+		}
+		if row == nrow-2 && col == 0 && ncol%4 != 0 {
+			corner(chr, [8][2]int{{nrow - 3, 0}, {nrow - 2, 0}, {nrow - 1, 0}, {0, ncol - 4}, {0, ncol - 3}, {0, ncol - 2}, {0, ncol - 1}, {1, ncol - 1}})
+			chr++
+		}
+		if row == nrow-2 && col == 0 && ncol%8 == 4 {
+			corner(chr, [8][2]int{{nrow - 3, 0}, {nrow - 2, 0}, {nrow - 1, 0}, {0, ncol - 2}, {0, ncol - 1}, {1, ncol - 1}, {2, ncol - 1}, {3, ncol - 1}})
+			chr++
+		}
+		if row == nrow+4 && col == 2 && ncol%8 == 0 {
+			corner(chr, [8][2]int{{nrow - 1, 0}, {nrow - 1, ncol - 1}, {0, ncol - 3}, {0, ncol - 2}, {0, ncol - 1}, {1, ncol - 3}, {1, ncol - 2}, {1, ncol - 1}})
+			chr++
+		}
+
+		// Sweep upward diagonally
+		for {
+			if row < nrow && col >= 0 && array[row][col] == 0 {
+				utah(row, col, chr)
+				chr++
+			}
+			row -= 2
+			col += 2
+			if row < 0 || col >= ncol {
+				break
+			}
+		}
+		row++
+		col += 3
+
+		// Sweep downward diagonally
+		for {
+			if row >= 0 && col < ncol && array[row][col] == 0 {
+				utah(row, col, chr)
+				chr++
+			}
+			row += 2
+			col -= 2
+			if row >= nrow || col < 0 {
+				break
+			}
+		}
+		row += 3
+		col++
+	}
+
+	if array[nrow-1][ncol-1] == 0 {
+		array[nrow-1][ncol-1] = 1
+		array[nrow-2][ncol-2] = 1
+	}
+
+	return array
+}
+
+// reedSolomon computes the ECC 200 error correction codewords over GF(256)
+// with the field polynomial x^8+x^5+x^3+x^2+1
+func reedSolomon(data []byte, eccWords int) []byte {
+	var exp [256]int
+	var log [256]int
+	value := 1
+	for i := 0; i < 255; i++ {
+		exp[i] = value
+		log[value] = i
+		value <<= 1
+		if value >= 256 {
+			value ^= 0x12d
+		}
+	}
+	multiply := func(a, b int) int {
+		if a == 0 || b == 0 {
+			return 0
+		}
+		return exp[(log[a]+log[b])%255]
+	}
+
+	// Generator polynomial (x-2^1)(x-2^2)...(x-2^eccWords)
+	poly := make([]int, eccWords+1)
+	poly[0] = 1
+	for i := 1; i <= eccWords; i++ {
+		poly[i] = poly[i-1]
+		for j := i - 1; j >= 1; j-- {
+			poly[j] = poly[j-1] ^ multiply(poly[j], exp[i])
+		}
+		poly[0] = multiply(poly[0], exp[i])
+	}
+
+	ecc := make([]int, eccWords+1)
+	for _, d := range data {
+		k := ecc[0] ^ int(d)
+		for j := 0; j < eccWords; j++ {
+			ecc[j] = ecc[j+1] ^ multiply(k, poly[eccWords-j-1])
+		}
+	}
+
+	result := make([]byte, eccWords)
+	for i := range result {
+		result[i] = byte(ecc[i])
+	}
+	return result
+}
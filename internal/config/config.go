@@ -18,6 +18,10 @@ type Config struct {
 	Auth     AuthConfig     `yaml:"auth"`
 	Devices  DevicesConfig  `yaml:"devices"`
 	TestUI   TestUIConfig   `yaml:"test_ui"`
+	Simulator SimulatorConfig `yaml:"simulator"`
+	Recall   RecallConfig   `yaml:"recall"`
+	Labels   LabelsConfig   `yaml:"labels"`
+	Validation ValidationConfig `yaml:"validation"`
 }
 
 // ServerConfig represents server configuration
@@ -28,7 +32,9 @@ type ServerConfig struct {
 
 // DatabaseConfig represents database configuration
 type DatabaseConfig struct {
-	Path string `yaml:"path"`
+	Path          string `yaml:"path"`
+	SecretKeyFile string `yaml:"secret_key_file"` // Key for device secrets and audit log hashes, kept apart from the database
+	SecretKey     string `yaml:"-"`               // Base64 key from SECRET_KEY, takes precedence over the key file
 }
 
 // LoggingConfig represents logging configuration
@@ -51,17 +57,82 @@ type AuthConfig struct {
 type DevicesConfig struct {
 	Melag  MelagConfig  `yaml:"melag"`
 	Getinge GetingeConfig `yaml:"getinge"`
+	Reconnect ReconnectConfig `yaml:"reconnect"`
+	CycleWatchdog CycleWatchdogConfig `yaml:"cycle_watchdog"`
+	CycleSamples CycleSamplesConfig `yaml:"cycle_samples"`
+}
+
+// CycleSamplesConfig controls how often the process values of running cycles are stored
+// A status read is stored if min_interval has passed since the last stored sample, the
+// phase changed, or a value moved by more than its delta (0 = deltas not considered).
+type CycleSamplesConfig struct {
+	MinInterval      int     `yaml:"min_interval"`      // Seconds between stored samples (0 = every status read)
+	TemperatureDelta float64 `yaml:"temperature_delta"` // °C
+	PressureDelta    float64 `yaml:"pressure_delta"`    // bar
+}
+
+// CycleWatchdogConfig controls the detection of stale cycles: cycles the device stopped
+// reporting on, or that run longer than their program can
+type CycleWatchdogConfig struct {
+	CheckInterval      int            `yaml:"check_interval"`       // Seconds between checks
+	StatusTimeout      int            `yaml:"status_timeout"`       // Minutes without a status report (0 = no limit)
+	MaxDuration        int            `yaml:"max_duration"`         // Minutes a program not listed below may run (0 = no limit)
+	ProgramMaxDuration map[string]int `yaml:"program_max_duration"` // Program name -> maximum run time in minutes
+}
+
+// ReconnectConfig controls how the device manager re-establishes lost connections
+// The delay before attempt n is initial_delay * multiplier^(n-1), capped at max_delay and
+// varied by ±jitter so devices do not reconnect in lockstep.
+type ReconnectConfig struct {
+	InitialDelay        int     `yaml:"initial_delay"`         // Seconds before the first retry
+	MaxDelay            int     `yaml:"max_delay"`             // Upper bound of the delay in seconds
+	Multiplier          float64 `yaml:"multiplier"`            // Growth factor per failed attempt
+	Jitter              float64 `yaml:"jitter"`                // Random variation as a fraction of the delay (0-1)
+	HealthCheckInterval int     `yaml:"health_check_interval"` // Seconds between session checks (FTP NOOP), 0 disables
 }
 
 // MelagConfig represents Melag device configuration
 type MelagConfig struct {
-	StatusPollInterval int `yaml:"status_poll_interval"`
+	StatusPollInterval int    `yaml:"status_poll_interval"`
+	ProtocolDir        string `yaml:"protocol_dir"` // MELAnet Box directory holding protocol/status files
+	CommandDir         string `yaml:"command_dir"`  // MELAnet Box directory for start requests (defaults to protocol_dir)
+	AckTimeout         int    `yaml:"ack_timeout"`  // Seconds to wait for a start acknowledgement
+	ArchiveDir         string `yaml:"archive_dir"`  // MELAnet Box protocol archive walked by the import (defaults to protocol_dir)
 }
 
 // GetingeConfig represents Getinge device configuration
 type GetingeConfig struct {
-	PingInterval int `yaml:"ping_interval"`
-	PingTimeout  int `yaml:"ping_timeout"`
+	PingInterval int                 `yaml:"ping_interval"`
+	PingTimeout  int                 `yaml:"ping_timeout"`
+	ProbeMethod  string              `yaml:"probe_method"` // "icmp" (echo request) or "tcp" (connect to tcp_ports)
+	ProbeCount   int                 `yaml:"probe_count"`  // Echo requests per probe, used for packet loss
+	TCPFallback  bool                `yaml:"tcp_fallback"` // Probe tcp_ports when ICMP sockets are not permitted
+	TCPPorts     []int               `yaml:"tcp_ports"`
+	Modbus       GetingeModbusConfig `yaml:"modbus"`
+}
+
+// GetingeModbusConfig represents the Modbus TCP status interface of Getinge washer-disinfectors
+type GetingeModbusConfig struct {
+	Enabled          bool                   `yaml:"enabled"` // Read cycle status over Modbus TCP instead of reachability only
+	Port             int                    `yaml:"port"`
+	UnitID           int                    `yaml:"unit_id"`
+	Timeout          int                    `yaml:"timeout"`           // Seconds per request
+	PollInterval     int                    `yaml:"poll_interval"`     // Seconds between status reads while waiting for a cycle
+	TemperatureScale float64                `yaml:"temperature_scale"` // Factor applied to the temperature register (0.1 = tenths of °C)
+	A0Scale          float64                `yaml:"a0_scale"`          // Factor applied to the A0 register
+	Registers        GetingeModbusRegisters `yaml:"registers"`
+	Phases           map[int]string         `yaml:"phases"`            // Phase register value -> phase name (0 = idle)
+	Programs         map[int]string         `yaml:"programs"`          // Program register value -> program name
+}
+
+// GetingeModbusRegisters holds the holding register addresses (0-based) of the status values
+type GetingeModbusRegisters struct {
+	Program     int `yaml:"program"`
+	Phase       int `yaml:"phase"`
+	Temperature int `yaml:"temperature"` // Signed
+	A0Value     int `yaml:"a0_value"`
+	Result      int `yaml:"result"`      // 0 = none/running, 1 = passed, 2 = failed
+	ErrorCode   int `yaml:"error_code"`
 }
 
 // TestUIConfig represents Test UI configuration
@@ -70,6 +141,71 @@ type TestUIConfig struct {
 	RequireAuth bool `yaml:"require_auth"`
 }
 
+// SimulatorConfig represents the built-in device simulator configuration (enabled with --simulate)
+type SimulatorConfig struct {
+	MelagAddress   string  `yaml:"melag_address"`   // FTP endpoint of the simulated MELAnet Box
+	GetingeAddress string  `yaml:"getinge_address"` // Modbus TCP endpoint of the simulated Getinge washer
+	TimeScale      float64 `yaml:"time_scale"`      // Device clock speed-up factor
+	StatusInterval int     `yaml:"status_interval"` // Seconds between status file updates
+	WasherInterval int     `yaml:"washer_interval"` // Seconds between cycles the simulated washer starts itself (0 = never)
+}
+
+// RecallConfig represents the recall query configuration
+type RecallConfig struct {
+	TestPrograms []string `yaml:"test_programs"` // Programs counted as test cycles (case-insensitive, whole words of the program name)
+}
+
+// LabelsConfig represents the sterile goods label configuration
+type LabelsConfig struct {
+	Symbology        string          `yaml:"symbology"`         // "code128" or "datamatrix"
+	WidthMM          float64         `yaml:"width_mm"`          // Label size
+	HeightMM         float64         `yaml:"height_mm"`
+	DotsPerMM        int             `yaml:"dots_per_mm"`       // Zebra printer resolution (8 = 203 dpi, 12 = 300 dpi)
+	DefaultPackaging string          `yaml:"default_packaging"` // Packaging type used when the request names none
+	Packaging        []PackagingRule `yaml:"packaging"`         // Expiry rule per packaging type
+}
+
+// PackagingRule represents the storage period of sterile goods in one packaging type
+type PackagingRule struct {
+	Type         string `yaml:"type"`          // Identifier used in requests, e.g. "pouch"
+	Name         string `yaml:"name"`          // Printed on the label
+	ExpiryMonths int    `yaml:"expiry_months"` // Storage period after sterilization
+	ExpiryDays   int    `yaml:"expiry_days"`
+}
+
+// ValidationConfig controls the parameter validation of finished cycles (EN ISO 17665 / EN 13060)
+type ValidationConfig struct {
+	Enabled  bool                `yaml:"enabled"`
+	Profiles []ValidationProfile `yaml:"profiles"` // Programs without a profile are reported as not validated
+}
+
+// ValidationProfile holds the sterilisation parameters the cycles of some programs must reach
+type ValidationProfile struct {
+	Name                     string   `yaml:"name"`
+	Programs                 []string `yaml:"programs"`                  // Program names (case-insensitive)
+	SterilisationTemperature float64  `yaml:"sterilisation_temperature"` // °C, lower limit of the sterilisation temperature band
+	TemperatureBand          float64  `yaml:"temperature_band"`          // K above the sterilisation temperature
+	HoldTime                 int      `yaml:"hold_time"`                 // Minimum seconds within the band
+	PressureMin              float64  `yaml:"pressure_min"`              // bar during the hold (0 = no lower limit)
+	PressureMax              float64  `yaml:"pressure_max"`              // bar during the hold (0 = no upper limit)
+	GaugePressure            bool     `yaml:"gauge_pressure"`            // Device reports pressure relative to the atmosphere
+	SaturationTolerance      float64  `yaml:"saturation_tolerance"`      // K between measured and saturated steam temperature (0 = not checked)
+	MaxEquilibrationTime     int      `yaml:"max_equilibration_time"`    // Seconds (0 = not checked)
+	MaxSampleGap             int      `yaml:"max_sample_gap"`            // Seconds without a sample that interrupt the hold
+}
+
+// ServerWriteTimeout is the time in seconds the HTTP server allows for answering a request
+// Requests waiting for a device, like a Melag start, must finish within it.
+const ServerWriteTimeout = 15
+
+// ackTimeoutMargin is the time in seconds a Melag start needs besides waiting for the
+// acknowledgement (connecting, uploading the request, storing the cycle)
+const ackTimeoutMargin = 5
+
+// DefaultSecretKeyFile is the secret key file next to config.yaml. Keeping it out of the
+// database directory means a copy of the database alone does not include the key.
+const DefaultSecretKeyFile = "./config/secret.key"
+
 var globalConfig *Config
 
 // Load loads configuration from file and environment variables
@@ -118,7 +254,8 @@ func getDefaults() *Config {
 			BindAddress: "127.0.0.1",
 		},
 		Database: DatabaseConfig{
-			Path: "./data/steri-connect.db",
+			Path:          "./data/steri-connect.db",
+			SecretKeyFile: DefaultSecretKeyFile,
 		},
 		Logging: LoggingConfig{
 			Level:         "INFO",
@@ -134,16 +271,100 @@ func getDefaults() *Config {
 		Devices: DevicesConfig{
 			Melag: MelagConfig{
 				StatusPollInterval: 2,
+				ProtocolDir:        "/",
+				AckTimeout:         10,
 			},
 			Getinge: GetingeConfig{
 				PingInterval: 15,
 				PingTimeout:  5,
+				ProbeMethod:  "icmp",
+				ProbeCount:   3,
+				TCPPorts:     []int{80, 443, 22},
+				Modbus: GetingeModbusConfig{
+					Port:             502,
+					UnitID:           1,
+					Timeout:          3,
+					PollInterval:     5,
+					TemperatureScale: 0.1,
+					A0Scale:          1,
+					Registers: GetingeModbusRegisters{
+						Program:     0,
+						Phase:       1,
+						Temperature: 2,
+						A0Value:     3,
+						Result:      4,
+						ErrorCode:   5,
+					},
+					Phases: map[int]string{
+						1: "Vorspülen",
+						2: "Reinigen",
+						3: "Neutralisieren",
+						4: "Zwischenspülen",
+						5: "Thermische Desinfektion",
+						6: "Trocknen",
+					},
+				},
+			},
+			Reconnect: ReconnectConfig{
+				InitialDelay:        5,
+				MaxDelay:            300,
+				Multiplier:          2,
+				Jitter:              0.2,
+				HealthCheckInterval: 30,
+			},
+			CycleWatchdog: CycleWatchdogConfig{
+				CheckInterval: 60,
+				StatusTimeout: 10,
+				MaxDuration:   180,
+			},
+			CycleSamples: CycleSamplesConfig{
+				MinInterval: 0,
 			},
 		},
 		TestUI: TestUIConfig{
 			Enabled:     true,
 			RequireAuth: false,
 		},
+		Simulator: SimulatorConfig{
+			MelagAddress:   "127.0.0.1:2121",
+			GetingeAddress: "127.0.0.1:2180",
+			TimeScale:      30,
+			StatusInterval: 1,
+			WasherInterval: 120,
+		},
+		Recall: RecallConfig{
+			TestPrograms: []string{"Bowie-Dick", "Helix"},
+		},
+		Labels: LabelsConfig{
+			Symbology:        "code128",
+			WidthMM:          60,
+			HeightMM:         40,
+			DotsPerMM:        8,
+			DefaultPackaging: "pouch",
+			Packaging: []PackagingRule{
+				{Type: "pouch", Name: "Sterilization pouch", ExpiryMonths: 6},
+				{Type: "double_pouch", Name: "Double pouch", ExpiryMonths: 6},
+				{Type: "wrap", Name: "Sterilization wrap", ExpiryMonths: 6},
+				{Type: "container", Name: "Sterilization container", ExpiryMonths: 6},
+			},
+		},
+		Validation: ValidationConfig{
+			Enabled: true,
+			Profiles: []ValidationProfile{
+				{
+					Name:                     "134 °C / 3:30 min",
+					Programs:                 []string{"Universal-Programm", "Schnell-Programm B"},
+					SterilisationTemperature: 134,
+					TemperatureBand:          3,
+					HoldTime:                 210,
+					PressureMin:              3.0,
+					PressureMax:              3.35,
+					SaturationTolerance:      2,
+					MaxEquilibrationTime:     15,
+					MaxSampleGap:             10,
+				},
+			},
+		},
 	}
 }
 
@@ -166,6 +387,14 @@ func applyEnvOverrides(cfg *Config) {
 		cfg.Database.Path = dbPath
 	}
 
+	// Secret key for device credentials
+	if secretKey := os.Getenv("SECRET_KEY"); secretKey != "" {
+		cfg.Database.SecretKey = secretKey
+	}
+	if secretKeyFile := os.Getenv("SECRET_KEY_FILE"); secretKeyFile != "" {
+		cfg.Database.SecretKeyFile = secretKeyFile
+	}
+
 	// Log level
 	if logLevel := os.Getenv("LOG_LEVEL"); logLevel != "" {
 		cfg.Logging.Level = strings.ToUpper(logLevel)
@@ -246,6 +475,12 @@ func validate(cfg *Config) error {
 		return fmt.Errorf("invalid Melag polling interval: %d (must be >= 1)", cfg.Devices.Melag.StatusPollInterval)
 	}
 
+	// Validate Melag start acknowledgement timeout, the answer must reach the client
+	if maxAck := ServerWriteTimeout - ackTimeoutMargin; cfg.Devices.Melag.AckTimeout < 1 || cfg.Devices.Melag.AckTimeout > maxAck {
+		return fmt.Errorf("invalid Melag ack timeout: %d (must be between 1 and %d, the server write timeout is %d seconds)",
+			cfg.Devices.Melag.AckTimeout, maxAck, ServerWriteTimeout)
+	}
+
 	// Validate Getinge ping interval
 	if cfg.Devices.Getinge.PingInterval < 1 {
 		return fmt.Errorf("invalid Getinge ping interval: %d (must be >= 1)", cfg.Devices.Getinge.PingInterval)
@@ -256,6 +491,197 @@ func validate(cfg *Config) error {
 		return fmt.Errorf("invalid Getinge ping timeout: %d (must be >= 1)", cfg.Devices.Getinge.PingTimeout)
 	}
 
+	// Validate Getinge reachability probe
+	switch cfg.Devices.Getinge.ProbeMethod {
+	case "icmp", "tcp":
+	default:
+		return fmt.Errorf("invalid Getinge probe method: %s (must be icmp or tcp)", cfg.Devices.Getinge.ProbeMethod)
+	}
+	if cfg.Devices.Getinge.ProbeCount < 1 || cfg.Devices.Getinge.ProbeCount > 10 {
+		return fmt.Errorf("invalid Getinge probe count: %d (must be between 1 and 10)", cfg.Devices.Getinge.ProbeCount)
+	}
+	if cfg.Devices.Getinge.ProbeMethod == "tcp" || cfg.Devices.Getinge.TCPFallback {
+		if len(cfg.Devices.Getinge.TCPPorts) == 0 {
+			return fmt.Errorf("Getinge TCP probe requires at least one port in tcp_ports")
+		}
+		for _, port := range cfg.Devices.Getinge.TCPPorts {
+			if port < 1 || port > 65535 {
+				return fmt.Errorf("invalid Getinge TCP probe port: %d (must be between 1 and 65535)", port)
+			}
+		}
+	}
+
+	// Validate reconnect backoff
+	reconnect := cfg.Devices.Reconnect
+	if reconnect.InitialDelay < 1 {
+		return fmt.Errorf("invalid reconnect initial delay: %d (must be >= 1)", reconnect.InitialDelay)
+	}
+	if reconnect.MaxDelay < reconnect.InitialDelay {
+		return fmt.Errorf("invalid reconnect max delay: %d (must be >= initial_delay)", reconnect.MaxDelay)
+	}
+	if reconnect.Multiplier < 1 {
+		return fmt.Errorf("invalid reconnect multiplier: %g (must be >= 1)", reconnect.Multiplier)
+	}
+	if reconnect.Jitter < 0 || reconnect.Jitter > 1 {
+		return fmt.Errorf("invalid reconnect jitter: %g (must be between 0 and 1)", reconnect.Jitter)
+	}
+	if reconnect.HealthCheckInterval < 0 {
+		return fmt.Errorf("invalid health check interval: %d (must be >= 0)", reconnect.HealthCheckInterval)
+	}
+
+	// Validate stale cycle watchdog
+	watchdog := cfg.Devices.CycleWatchdog
+	if watchdog.CheckInterval < 1 {
+		return fmt.Errorf("invalid cycle watchdog check interval: %d (must be >= 1)", watchdog.CheckInterval)
+	}
+	if watchdog.StatusTimeout < 0 {
+		return fmt.Errorf("invalid cycle watchdog status timeout: %d (must be >= 0)", watchdog.StatusTimeout)
+	}
+	if watchdog.MaxDuration < 0 {
+		return fmt.Errorf("invalid cycle watchdog max duration: %d (must be >= 0)", watchdog.MaxDuration)
+	}
+	for program, minutes := range watchdog.ProgramMaxDuration {
+		if minutes < 1 {
+			return fmt.Errorf("invalid max duration for program %q: %d (must be >= 1)", program, minutes)
+		}
+	}
+
+	// Validate cycle sample downsampling
+	samples := cfg.Devices.CycleSamples
+	if samples.MinInterval < 0 {
+		return fmt.Errorf("invalid cycle sample min interval: %d (must be >= 0)", samples.MinInterval)
+	}
+	if samples.TemperatureDelta < 0 || samples.PressureDelta < 0 {
+		return fmt.Errorf("invalid cycle sample deltas: temperature %g, pressure %g (must be >= 0)", samples.TemperatureDelta, samples.PressureDelta)
+	}
+
+	// Validate Getinge Modbus settings
+	if modbus := cfg.Devices.Getinge.Modbus; modbus.Enabled {
+		if modbus.Port < 1 || modbus.Port > 65535 {
+			return fmt.Errorf("invalid Getinge Modbus port: %d (must be between 1 and 65535)", modbus.Port)
+		}
+		if modbus.UnitID < 0 || modbus.UnitID > 255 {
+			return fmt.Errorf("invalid Getinge Modbus unit ID: %d (must be between 0 and 255)", modbus.UnitID)
+		}
+		if modbus.PollInterval < 1 {
+			return fmt.Errorf("invalid Getinge Modbus poll interval: %d (must be >= 1)", modbus.PollInterval)
+		}
+		registers := []int{
+			modbus.Registers.Program, modbus.Registers.Phase, modbus.Registers.Temperature,
+			modbus.Registers.A0Value, modbus.Registers.Result, modbus.Registers.ErrorCode,
+		}
+		low, high := registers[0], registers[0]
+		for _, register := range registers {
+			if register < 0 || register > 65535 {
+				return fmt.Errorf("invalid Getinge Modbus register address: %d (must be between 0 and 65535)", register)
+			}
+			if register < low {
+				low = register
+			}
+			if register > high {
+				high = register
+			}
+		}
+		// All status values are read with a single request
+		if high-low >= 125 {
+			return fmt.Errorf("Getinge Modbus registers span %d addresses (must be within 125)", high-low+1)
+		}
+	}
+
+	// Validate recall test programs
+	for _, program := range cfg.Recall.TestPrograms {
+		if strings.TrimSpace(program) == "" {
+			return fmt.Errorf("recall test programs cannot contain empty names")
+		}
+	}
+
+	// Validate label settings
+	switch cfg.Labels.Symbology {
+	case "code128", "datamatrix":
+	default:
+		return fmt.Errorf("invalid label symbology: %s (must be code128 or datamatrix)", cfg.Labels.Symbology)
+	}
+	if cfg.Labels.WidthMM < 20 || cfg.Labels.HeightMM < 15 {
+		return fmt.Errorf("invalid label size: %gx%g mm (must be at least 20x15 mm)", cfg.Labels.WidthMM, cfg.Labels.HeightMM)
+	}
+	if cfg.Labels.DotsPerMM < 6 || cfg.Labels.DotsPerMM > 24 {
+		return fmt.Errorf("invalid label printer resolution: %d dots/mm (must be between 6 and 24)", cfg.Labels.DotsPerMM)
+	}
+	packagingTypes := make(map[string]bool)
+	for _, rule := range cfg.Labels.Packaging {
+		if strings.TrimSpace(rule.Type) == "" {
+			return fmt.Errorf("label packaging types cannot be empty")
+		}
+		if packagingTypes[rule.Type] {
+			return fmt.Errorf("duplicate label packaging type: %s", rule.Type)
+		}
+		packagingTypes[rule.Type] = true
+		if rule.ExpiryMonths < 0 || rule.ExpiryDays < 0 || rule.ExpiryMonths+rule.ExpiryDays == 0 {
+			return fmt.Errorf("invalid expiry for packaging type %s (expiry_months or expiry_days must be positive)", rule.Type)
+		}
+	}
+	if cfg.Labels.DefaultPackaging != "" && !packagingTypes[cfg.Labels.DefaultPackaging] {
+		return fmt.Errorf("label default packaging %s is not a configured packaging type", cfg.Labels.DefaultPackaging)
+	}
+
+	// Validate cycle validation profiles
+	if cfg.Validation.Enabled {
+		if err := validateValidationProfiles(cfg.Validation.Profiles, cfg.Devices.CycleSamples); err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+
+// validateValidationProfiles checks the cycle validation profiles; every program may belong to
+// one profile only, and samples must be stored often enough to measure the hold time
+func validateValidationProfiles(profiles []ValidationProfile, samples CycleSamplesConfig) error {
+	names := make(map[string]bool)
+	programs := make(map[string]string)
+	for _, profile := range profiles {
+		if strings.TrimSpace(profile.Name) == "" {
+			return fmt.Errorf("validation profile names cannot be empty")
+		}
+		if names[profile.Name] {
+			return fmt.Errorf("duplicate validation profile: %s", profile.Name)
+		}
+		names[profile.Name] = true
+
+		if len(profile.Programs) == 0 {
+			return fmt.Errorf("validation profile %s has no programs", profile.Name)
+		}
+		for _, program := range profile.Programs {
+			key := strings.ToLower(strings.TrimSpace(program))
+			if key == "" {
+				return fmt.Errorf("validation profile %s has an empty program name", profile.Name)
+			}
+			if other, exists := programs[key]; exists {
+				return fmt.Errorf("program %q is in validation profiles %s and %s", program, other, profile.Name)
+			}
+			programs[key] = profile.Name
+		}
+
+		if profile.SterilisationTemperature <= 0 || profile.TemperatureBand <= 0 {
+			return fmt.Errorf("invalid temperatures in validation profile %s (sterilisation_temperature and temperature_band must be > 0)", profile.Name)
+		}
+		if profile.HoldTime < 1 {
+			return fmt.Errorf("invalid hold time in validation profile %s: %d (must be >= 1)", profile.Name, profile.HoldTime)
+		}
+		if profile.PressureMin < 0 || profile.PressureMax < 0 || (profile.PressureMax > 0 && profile.PressureMax < profile.PressureMin) {
+			return fmt.Errorf("invalid pressure band in validation profile %s: %g-%g bar", profile.Name, profile.PressureMin, profile.PressureMax)
+		}
+		if profile.SaturationTolerance < 0 || profile.MaxEquilibrationTime < 0 {
+			return fmt.Errorf("invalid limits in validation profile %s (saturation_tolerance and max_equilibration_time must be >= 0)", profile.Name)
+		}
+		if profile.MaxSampleGap < 1 {
+			return fmt.Errorf("invalid max sample gap in validation profile %s: %d (must be >= 1)", profile.Name, profile.MaxSampleGap)
+		}
+		if samples.MinInterval >= profile.MaxSampleGap {
+			return fmt.Errorf("cycle sample min interval %d s must be below max_sample_gap %d s of validation profile %s",
+				samples.MinInterval, profile.MaxSampleGap, profile.Name)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,62 @@
+package csv
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+
+	"steri-connect-go/internal/database"
+)
+
+// GenerateAuditCSV generates a CSV file of audit log entries
+// Previous hash and hash are included so the chain can be checked outside the system.
+func GenerateAuditCSV(entries []database.AuditLog) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	header := []string{
+		"ID",
+		"Timestamp",
+		"Action",
+		"Entity Type",
+		"Entity ID",
+		"User",
+		"Details",
+		"Previous Hash",
+		"Hash",
+	}
+
+	if err := writer.Write(header); err != nil {
+		return nil, fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	for _, entry := range entries {
+		entityID := ""
+		if entry.EntityID != nil {
+			entityID = fmt.Sprintf("%d", *entry.EntityID)
+		}
+
+		row := []string{
+			fmt.Sprintf("%d", entry.ID),
+			entry.Timestamp.Format("2006-01-02 15:04:05"),
+			entry.Action,
+			entry.EntityType,
+			entityID,
+			entry.User,
+			entry.Details,
+			entry.PrevHash,
+			entry.Hash,
+		}
+
+		if err := writer.Write(row); err != nil {
+			return nil, fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, fmt.Errorf("failed to flush CSV: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
@@ -27,9 +27,12 @@ func GenerateCyclesCSV(cycles []database.CycleWithDevice) ([]byte, error) {
 		"Progress (%)",
 		"Temperature (°C)",
 		"Pressure (bar)",
+		"A0 Value",
 		"Result",
+		"Validation Result",
 		"Error Code",
 		"Error Description",
+		"Release Status",
 	}
 
 	if err := writer.Write(header); err != nil {
@@ -50,9 +53,12 @@ func GenerateCyclesCSV(cycles []database.CycleWithDevice) ([]byte, error) {
 			formatNullableInt(cycle.ProgressPercent),
 			formatNullableFloat(cycle.Temperature),
 			formatNullableFloat(cycle.Pressure),
+			formatNullableFloat(cycle.A0Value),
 			cycle.Result,
+			cycle.ValidationResult,
 			cycle.ErrorCode,
 			cycle.ErrorDescription,
+			cycle.ReleaseStatus,
 		}
 
 		if err := writer.Write(row); err != nil {
@@ -0,0 +1,74 @@
+package csv
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"strings"
+
+	"steri-connect-go/internal/database"
+)
+
+// GenerateRecallCSV generates a CSV file listing the cycles affected by a recall case
+// One row per affected cycle; the instrument sets of the load are joined as "code x quantity".
+func GenerateRecallCSV(report *database.RecallReport) ([]byte, error) {
+	var buf bytes.Buffer
+	writer := csv.NewWriter(&buf)
+
+	header := []string{
+		"Recall ID",
+		"Recall Status",
+		"Cycle ID",
+		"Device Name",
+		"Program",
+		"Start Time",
+		"End Time",
+		"Result",
+		"Release Status",
+		"Operators",
+		"Instrument Sets",
+		"Failed Indicator Cycle",
+	}
+
+	if err := writer.Write(header); err != nil {
+		return nil, fmt.Errorf("failed to write CSV header: %w", err)
+	}
+
+	for _, cycle := range report.Cycles {
+		sets := make([]string, 0, len(cycle.Loads))
+		for _, load := range cycle.Loads {
+			sets = append(sets, fmt.Sprintf("%s x %d", load.SetCode, load.Quantity))
+		}
+
+		failed := ""
+		if cycle.ID == report.Case.FailedCycleID {
+			failed = "yes"
+		}
+
+		row := []string{
+			fmt.Sprintf("%d", report.Case.ID),
+			report.Case.Status,
+			fmt.Sprintf("%d", cycle.ID),
+			cycle.DeviceName,
+			cycle.Program,
+			cycle.StartTS.Format("2006-01-02 15:04:05"),
+			formatNullableTime(cycle.EndTS),
+			cycle.Result,
+			cycle.ReleaseStatus,
+			strings.Join(cycle.Operators, "; "),
+			strings.Join(sets, "; "),
+			failed,
+		}
+
+		if err := writer.Write(row); err != nil {
+			return nil, fmt.Errorf("failed to write CSV row: %w", err)
+		}
+	}
+
+	writer.Flush()
+	if err := writer.Error(); err != nil {
+		return nil, fmt.Errorf("failed to flush CSV: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
@@ -1,10 +1,15 @@
 package database
 
 import (
+	"crypto/hmac"
 	"crypto/sha256"
+	"database/sql"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
+	"sync"
 	"time"
 )
 
@@ -19,10 +24,61 @@ const (
 	ActionCycleUpdated    AuditAction = "cycle_updated"
 	ActionCycleCompleted  AuditAction = "cycle_completed"
 	ActionCycleFailed     AuditAction = "cycle_failed"
+	ActionCycleInterrupted AuditAction = "cycle_interrupted"
+	ActionCycleStale      AuditAction = "cycle_stale"
+	ActionCycleValidated  AuditAction = "cycle_validated"
 	ActionRDGStatusUpdate AuditAction = "rdg_status_update"
+	ActionCyclesImported  AuditAction = "cycles_imported"
+	ActionSetCreated      AuditAction = "instrument_set_created"
+	ActionSetUpdated      AuditAction = "instrument_set_updated"
+	ActionSetDeleted      AuditAction = "instrument_set_deleted"
+	ActionLoadAssigned    AuditAction = "load_assigned"
+	ActionLoadRemoved     AuditAction = "load_removed"
+	ActionReleaseRequest  AuditAction = "cycle_release_requested"
+	ActionCycleReleased   AuditAction = "cycle_released"
+	ActionCycleRejected   AuditAction = "cycle_rejected"
+	ActionRecallOpened    AuditAction = "recall_opened"
+	ActionRecallAnnotated AuditAction = "recall_annotated"
+	ActionRecallClosed    AuditAction = "recall_closed"
 )
 
+// Reasons reported for a broken audit chain
+const (
+	AuditChainHashMismatch     = "hash_mismatch"       // Entry content changed after it was written
+	AuditChainPrevHashMismatch = "prev_hash_mismatch"  // Entry deleted, inserted or moved before this one
+	AuditChainUnchained        = "unchained"           // Entry without chain link after the chain started
+	AuditChainMissingEntries   = "missing_entries"     // Newest entries deleted
+	AuditChainStartMissing     = "chain_start_missing" // Record of the chain start removed
+)
+
+// AuditChainBreak describes the first audit log entry that does not fit the hash chain
+type AuditChainBreak struct {
+	ID       int    `json:"id"`
+	Reason   string `json:"reason"`
+	Expected string `json:"expected,omitempty"`
+	Actual   string `json:"actual,omitempty"`
+}
+
+// AuditChainReport is the result of verifying the audit log hash chain
+type AuditChainReport struct {
+	Valid         bool             `json:"valid"`
+	Entries       int              `json:"entries"`
+	Verified      int              `json:"verified"`
+	LegacyEntries int              `json:"legacy_entries"` // Written before the keyed chain started, cannot be verified
+	ChainStartID  int              `json:"chain_start_id"` // First entry of the keyed chain
+	LastID        int              `json:"last_id,omitempty"`
+	LastHash      string           `json:"last_hash,omitempty"` // Head of the chain, can be recorded elsewhere as an anchor
+	FirstBroken   *AuditChainBreak `json:"first_broken,omitempty"`
+	VerifiedAt    time.Time        `json:"verified_at"`
+}
+
+// auditMu serializes audit writes so that each entry links to its predecessor
+var auditMu sync.Mutex
+
 // LogAudit writes an audit log entry to the database
+// The entry hash covers the hash of the previous entry (hash chain), so changing,
+// deleting or reordering entries is detected by VerifyAuditChain. Hashes are keyed with
+// the secret key, which must be loaded (see InitSecretKey).
 func LogAudit(action AuditAction, entityType string, entityID *int, user string, details map[string]interface{}) error {
 	if db == nil {
 		return fmt.Errorf("database not initialized")
@@ -38,16 +94,40 @@ func LogAudit(action AuditAction, entityType string, entityID *int, user string,
 		detailsJSON = string(jsonBytes)
 	}
 
+	key, err := auditHashKey()
+	if err != nil {
+		return fmt.Errorf("failed to hash audit log entry: %w", err)
+	}
+
+	auditMu.Lock()
+	defer auditMu.Unlock()
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// The first entry links to the empty hash
+	var prevHash sql.NullString
+	err = tx.QueryRow(`SELECT hash FROM audit_log ORDER BY id DESC LIMIT 1`).Scan(&prevHash)
+	if err != nil && err != sql.ErrNoRows {
+		return fmt.Errorf("failed to get previous audit hash: %w", err)
+	}
+
+	// Without monotonic clock reading the stored timestamp reads back identically
+	timestamp := time.Now().Round(0)
+
 	// Calculate hash for integrity verification
-	hash := calculateAuditHash(action, entityType, entityID, user, detailsJSON, time.Now())
+	hash := calculateAuditHash(key, prevHash.String, timestamp, string(action), entityType, entityID, user, detailsJSON)
 
 	// Insert audit log entry
 	query := `
-		INSERT INTO audit_log (timestamp, action, entity_type, entity_id, user, details, hash)
-		VALUES (?, ?, ?, ?, ?, ?, ?)
+		INSERT INTO audit_log (timestamp, action, entity_type, entity_id, user, details, prev_hash, hash)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?)
 	`
 
-	result, err := db.Exec(query, time.Now(), string(action), entityType, entityID, user, detailsJSON, hash)
+	result, err := tx.Exec(query, timestamp, string(action), entityType, entityID, user, detailsJSON, prevHash.String, hash)
 	if err != nil {
 		return fmt.Errorf("failed to insert audit log: %w", err)
 	}
@@ -61,58 +141,233 @@ func LogAudit(action AuditAction, entityType string, entityID *int, user string,
 		return fmt.Errorf("unexpected rows affected: %d", rowsAffected)
 	}
 
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit audit log: %w", err)
+	}
+
 	return nil
 }
 
-// calculateAuditHash calculates a hash for audit log integrity verification
-func calculateAuditHash(action AuditAction, entityType string, entityID *int, user string, details string, timestamp time.Time) string {
-	// Create hash input from all fields
-	hashInput := fmt.Sprintf("%s|%s|%v|%s|%s|%s",
-		string(action),
+// calculateAuditHash calculates the chained HMAC-SHA256 of an audit log entry
+func calculateAuditHash(key []byte, prevHash string, timestamp time.Time, action, entityType string, entityID *int, user string, details string) string {
+	id := ""
+	if entityID != nil {
+		id = strconv.Itoa(*entityID)
+	}
+
+	// JSON array keeps the field boundaries unambiguous
+	hashInput, _ := json.Marshal([]string{
+		prevHash,
+		timestamp.UTC().Format(time.RFC3339Nano),
+		action,
 		entityType,
-		entityID,
+		id,
 		user,
 		details,
-		timestamp.Format(time.RFC3339Nano))
+	})
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write(hashInput)
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// VerifyAuditChain recomputes the hash chain of the audit log and reports the first broken link
+// Entries before the recorded chain start are counted as legacy but cannot be verified;
+// every entry after it must be chained.
+func VerifyAuditChain() (*AuditChainReport, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	key, err := auditHashKey()
+	if err != nil {
+		return nil, fmt.Errorf("failed to verify audit chain: %w", err)
+	}
+
+	report := &AuditChainReport{}
+	err = db.QueryRow(`SELECT start_id FROM audit_chain WHERE id = 1`).Scan(&report.ChainStartID)
+	if err == sql.ErrNoRows {
+		// Without the start every entry would pass as legacy
+		report.FirstBroken = &AuditChainBreak{Reason: AuditChainStartMissing}
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to get audit chain start: %w", err)
+	}
+
+	rows, err := db.Query(`
+		SELECT id, timestamp, action, entity_type, entity_id, user, details, prev_hash, hash
+		FROM audit_log
+		ORDER BY id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query audit logs: %w", err)
+	}
+	defer rows.Close()
+
+	lastHash := ""
+	for rows.Next() {
+		var log AuditLog
+		var entityType, user, details, prevHash, hash sql.NullString
+		err := rows.Scan(
+			&log.ID,
+			&log.Timestamp,
+			&log.Action,
+			&entityType,
+			&log.EntityID,
+			&user,
+			&details,
+			&prevHash,
+			&hash,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan audit log: %w", err)
+		}
+
+		report.Entries++
+		report.LastID = log.ID
+		if report.FirstBroken != nil {
+			continue
+		}
+
+		switch {
+		case log.ID < report.ChainStartID:
+			report.LegacyEntries++
+		case !prevHash.Valid:
+			report.FirstBroken = &AuditChainBreak{ID: log.ID, Reason: AuditChainUnchained}
+		case prevHash.String != lastHash:
+			report.FirstBroken = &AuditChainBreak{
+				ID:       log.ID,
+				Reason:   AuditChainPrevHashMismatch,
+				Expected: lastHash,
+				Actual:   prevHash.String,
+			}
+		default:
+			expected := calculateAuditHash(key, prevHash.String, log.Timestamp, log.Action, entityType.String, log.EntityID, user.String, details.String)
+			if expected != hash.String {
+				report.FirstBroken = &AuditChainBreak{
+					ID:       log.ID,
+					Reason:   AuditChainHashMismatch,
+					Expected: expected,
+					Actual:   hash.String,
+				}
+				continue
+			}
+			report.Verified++
+		}
+		lastHash = hash.String
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating audit logs: %w", err)
+	}
+
+	// AUTOINCREMENT never reuses IDs: a higher sequence means the newest entries were deleted
+	if report.FirstBroken == nil {
+		var seq int
+		err := db.QueryRow(`SELECT seq FROM sqlite_sequence WHERE name = 'audit_log'`).Scan(&seq)
+		if err != nil && err != sql.ErrNoRows {
+			return nil, fmt.Errorf("failed to get audit log sequence: %w", err)
+		}
+		if seq > report.LastID {
+			report.FirstBroken = &AuditChainBreak{
+				ID:       report.LastID + 1,
+				Reason:   AuditChainMissingEntries,
+				Expected: strconv.Itoa(seq),
+				Actual:   strconv.Itoa(report.LastID),
+			}
+		}
+	}
+
+	report.Valid = report.FirstBroken == nil
+	if report.Valid {
+		report.LastHash = lastHash
+	}
+	report.VerifiedAt = time.Now()
 
-	// Calculate SHA256 hash
-	hasher := sha256.New()
-	hasher.Write([]byte(hashInput))
-	hashBytes := hasher.Sum(nil)
+	return report, nil
+}
 
-	// Return hex-encoded hash
-	return hex.EncodeToString(hashBytes)
+// AuditLogFilter holds the filters for querying the audit log
+type AuditLogFilter struct {
+	Actions    []string   // Any of these actions (nil = all)
+	EntityType string     // e.g. "device", "cycle" (empty = all)
+	EntityID   *int
+	User       string     // Exact user name, case-insensitive (empty = all)
+	From       *time.Time // Entries at or after this time
+	To         *time.Time // Entries at or before this time
+	Search     string     // Text contained in details, case-insensitive (empty = all)
+	BeforeID   int        // Cursor: only entries older than this ID (0 = start with the newest)
+	Limit      int        // Maximum number of entries (0 = no limit)
 }
 
 // GetAuditLogs retrieves audit logs with optional filters
 func GetAuditLogs(entityType string, entityID *int, limit int) ([]AuditLog, error) {
+	return QueryAuditLogs(AuditLogFilter{EntityType: entityType, EntityID: entityID, Limit: limit})
+}
+
+// QueryAuditLogs retrieves the audit log entries matching filter, newest first
+// Paging uses the ID of the last returned entry as BeforeID of the next query,
+// which stays stable while new entries are written.
+func QueryAuditLogs(filter AuditLogFilter) ([]AuditLog, error) {
 	if db == nil {
 		return nil, fmt.Errorf("database not initialized")
 	}
 
 	query := `
-		SELECT id, timestamp, action, entity_type, entity_id, user, details, hash
+		SELECT id, timestamp, action, entity_type, entity_id, user, details, prev_hash, hash
 		FROM audit_log
 		WHERE 1=1
 	`
 
 	args := []interface{}{}
 
-	if entityType != "" {
+	if len(filter.Actions) > 0 {
+		query += " AND action IN (?" + strings.Repeat(", ?", len(filter.Actions)-1) + ")"
+		for _, action := range filter.Actions {
+			args = append(args, action)
+		}
+	}
+
+	if filter.EntityType != "" {
 		query += " AND entity_type = ?"
-		args = append(args, entityType)
+		args = append(args, filter.EntityType)
 	}
 
-	if entityID != nil {
+	if filter.EntityID != nil {
 		query += " AND entity_id = ?"
-		args = append(args, *entityID)
+		args = append(args, *filter.EntityID)
 	}
 
-	query += " ORDER BY timestamp DESC"
+	if filter.User != "" {
+		query += " AND LOWER(user) = LOWER(?)"
+		args = append(args, filter.User)
+	}
 
-	if limit > 0 {
+	// Timestamps are stored as text in local time, so compare in local time
+	if filter.From != nil {
+		query += " AND timestamp >= ?"
+		args = append(args, filter.From.Local())
+	}
+
+	if filter.To != nil {
+		query += " AND timestamp <= ?"
+		args = append(args, filter.To.Local())
+	}
+
+	if filter.Search != "" {
+		query += " AND LOWER(details) LIKE ? ESCAPE '\\'"
+		search := strings.NewReplacer("\\", "\\\\", "%", "\\%", "_", "\\_").Replace(strings.ToLower(filter.Search))
+		args = append(args, "%"+search+"%")
+	}
+
+	if filter.BeforeID > 0 {
+		query += " AND id < ?"
+		args = append(args, filter.BeforeID)
+	}
+
+	query += " ORDER BY id DESC"
+
+	if filter.Limit > 0 {
 		query += " LIMIT ?"
-		args = append(args, limit)
+		args = append(args, filter.Limit)
 	}
 
 	rows, err := db.Query(query, args...)
@@ -121,22 +376,29 @@ func GetAuditLogs(entityType string, entityID *int, limit int) ([]AuditLog, erro
 	}
 	defer rows.Close()
 
-	var logs []AuditLog
+	logs := []AuditLog{}
 	for rows.Next() {
 		var log AuditLog
+		var entityType, user, details, prevHash, hash sql.NullString
 		err := rows.Scan(
 			&log.ID,
 			&log.Timestamp,
 			&log.Action,
-			&log.EntityType,
+			&entityType,
 			&log.EntityID,
-			&log.User,
-			&log.Details,
-			&log.Hash,
+			&user,
+			&details,
+			&prevHash,
+			&hash,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan audit log: %w", err)
 		}
+		log.EntityType = entityType.String
+		log.User = user.String
+		log.Details = details.String
+		log.PrevHash = prevHash.String
+		log.Hash = hash.String
 		logs = append(logs, log)
 	}
 
@@ -146,4 +408,3 @@ func GetAuditLogs(entityType string, entityID *int, limit int) ([]AuditLog, erro
 
 	return logs, nil
 }
-
@@ -0,0 +1,199 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestVerifyAuditChain(t *testing.T) {
+	if err := InitializeDatabase(filepath.Join(t.TempDir(), "audit.db")); err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer Close()
+	if err := SetSecretKey([]byte("0123456789abcdef0123456789abcdef")); err != nil {
+		t.Fatalf("Failed to set secret key: %v", err)
+	}
+
+	// Entry from before the keyed chain, as in a database migrated from an older version
+	if _, err := db.Exec(`INSERT INTO audit_log (action, entity_type, user, details, hash) VALUES ('device_added', 'device', 'system', '', 'legacy')`); err != nil {
+		t.Fatalf("Failed to insert legacy entry: %v", err)
+	}
+	if _, err := db.Exec(`DROP TRIGGER audit_chain_no_update; UPDATE audit_chain SET start_id = 2`); err != nil {
+		t.Fatalf("Failed to move chain start: %v", err)
+	}
+
+	for i := 1; i <= 4; i++ {
+		id := i
+		if err := LogAudit(ActionCycleStarted, "cycle", &id, "operator", map[string]interface{}{"program": "Universal"}); err != nil {
+			t.Fatalf("LogAudit failed: %v", err)
+		}
+	}
+
+	report, err := VerifyAuditChain()
+	if err != nil {
+		t.Fatalf("VerifyAuditChain failed: %v", err)
+	}
+	if !report.Valid || report.Entries != 5 || report.Verified != 4 || report.LegacyEntries != 1 || report.ChainStartID != 2 || report.LastHash == "" {
+		t.Fatalf("Expected valid chain of 4 entries after 1 legacy entry, got %+v", report)
+	}
+
+	// Hashes cannot be verified with another key
+	if err := SetSecretKey([]byte("fedcba9876543210fedcba9876543210")); err != nil {
+		t.Fatalf("Failed to set secret key: %v", err)
+	}
+	report, err = VerifyAuditChain()
+	if err != nil {
+		t.Fatalf("VerifyAuditChain failed: %v", err)
+	}
+	if report.Valid || report.FirstBroken == nil || report.FirstBroken.ID != 2 || report.FirstBroken.Reason != AuditChainHashMismatch {
+		t.Fatalf("Expected hash mismatch with another key, got %+v", report.FirstBroken)
+	}
+	if err := SetSecretKey([]byte("0123456789abcdef0123456789abcdef")); err != nil {
+		t.Fatalf("Failed to set secret key: %v", err)
+	}
+
+	if _, err := db.Exec(`UPDATE audit_log SET user = 'intruder' WHERE id = 3`); err == nil {
+		t.Fatal("Expected update of audit log entry to be rejected")
+	}
+
+	// Tampering with direct database access, bypassing the triggers
+	if _, err := db.Exec(`DROP TRIGGER audit_log_no_update; DROP TRIGGER audit_log_no_delete`); err != nil {
+		t.Fatalf("Failed to drop triggers: %v", err)
+	}
+
+	if _, err := db.Exec(`UPDATE audit_log SET user = 'intruder' WHERE id = 3`); err != nil {
+		t.Fatalf("Failed to change entry: %v", err)
+	}
+	report, err = VerifyAuditChain()
+	if err != nil {
+		t.Fatalf("VerifyAuditChain failed: %v", err)
+	}
+	if report.Valid || report.FirstBroken == nil || report.FirstBroken.ID != 3 || report.FirstBroken.Reason != AuditChainHashMismatch {
+		t.Fatalf("Expected hash mismatch at entry 3, got %+v", report.FirstBroken)
+	}
+
+	if _, err := db.Exec(`UPDATE audit_log SET user = 'operator' WHERE id = 3`); err != nil {
+		t.Fatalf("Failed to restore entry: %v", err)
+	}
+	if _, err := db.Exec(`DELETE FROM audit_log WHERE id = 4`); err != nil {
+		t.Fatalf("Failed to delete entry: %v", err)
+	}
+	report, err = VerifyAuditChain()
+	if err != nil {
+		t.Fatalf("VerifyAuditChain failed: %v", err)
+	}
+	if report.FirstBroken == nil || report.FirstBroken.ID != 5 || report.FirstBroken.Reason != AuditChainPrevHashMismatch {
+		t.Fatalf("Expected broken link at entry 5 after deleting entry 4, got %+v", report.FirstBroken)
+	}
+
+	// Deleting the newest entry leaves a valid chain, but the sequence reveals it
+	if _, err := db.Exec(`DELETE FROM audit_log WHERE id = 5`); err != nil {
+		t.Fatalf("Failed to delete entry: %v", err)
+	}
+	report, err = VerifyAuditChain()
+	if err != nil {
+		t.Fatalf("VerifyAuditChain failed: %v", err)
+	}
+	if report.FirstBroken == nil || report.FirstBroken.ID != 4 || report.FirstBroken.Reason != AuditChainMissingEntries {
+		t.Fatalf("Expected missing entries after 3, got %+v", report.FirstBroken)
+	}
+}
+
+func TestQueryAuditLogs(t *testing.T) {
+	if err := InitializeDatabase(filepath.Join(t.TempDir(), "audit.db")); err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer Close()
+	if err := SetSecretKey([]byte("0123456789abcdef0123456789abcdef")); err != nil {
+		t.Fatalf("Failed to set secret key: %v", err)
+	}
+
+	for i := 1; i <= 5; i++ {
+		id := i
+		action, user := ActionCycleStarted, "m.mueller"
+		if i%2 == 0 {
+			action, user = ActionCycleReleased, "A.Schmidt"
+		}
+		note := "10 sets wet"
+		if i >= 4 {
+			note = "10% of sets wet"
+		}
+		if err := LogAudit(action, "cycle", &id, user, map[string]interface{}{"note": note}); err != nil {
+			t.Fatalf("LogAudit failed: %v", err)
+		}
+	}
+
+	logs, err := QueryAuditLogs(AuditLogFilter{Limit: 2})
+	if err != nil {
+		t.Fatalf("QueryAuditLogs failed: %v", err)
+	}
+	if len(logs) != 2 || logs[0].ID != 5 || logs[1].ID != 4 {
+		t.Fatalf("Expected entries 5 and 4, got %+v", logs)
+	}
+
+	logs, err = QueryAuditLogs(AuditLogFilter{BeforeID: logs[1].ID, Limit: 2})
+	if err != nil {
+		t.Fatalf("QueryAuditLogs failed: %v", err)
+	}
+	if len(logs) != 2 || logs[0].ID != 3 || logs[1].ID != 2 {
+		t.Fatalf("Expected entries 3 and 2 on the second page, got %+v", logs)
+	}
+
+	logs, err = QueryAuditLogs(AuditLogFilter{User: "a.schmidt", Actions: []string{string(ActionCycleReleased)}})
+	if err != nil {
+		t.Fatalf("QueryAuditLogs failed: %v", err)
+	}
+	if len(logs) != 2 {
+		t.Fatalf("Expected 2 releases by a.schmidt, got %d", len(logs))
+	}
+
+	// % is matched literally, not as wildcard
+	logs, err = QueryAuditLogs(AuditLogFilter{Search: "10%"})
+	if err != nil {
+		t.Fatalf("QueryAuditLogs failed: %v", err)
+	}
+	if len(logs) != 2 || logs[0].ID != 5 || logs[1].ID != 4 {
+		t.Fatalf("Expected entries 5 and 4 containing '10%%', got %+v", logs)
+	}
+}
+
+func TestVerifyAuditChainReportsUnchainedLeadingEntries(t *testing.T) {
+	if err := InitializeDatabase(filepath.Join(t.TempDir(), "audit.db")); err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer Close()
+	if err := SetSecretKey([]byte("0123456789abcdef0123456789abcdef")); err != nil {
+		t.Fatalf("Failed to set secret key: %v", err)
+	}
+
+	for i := 1; i <= 3; i++ {
+		id := i
+		if err := LogAudit(ActionCycleStarted, "cycle", &id, "operator", nil); err != nil {
+			t.Fatalf("LogAudit failed: %v", err)
+		}
+	}
+
+	// Removing the link of the first entries must not turn them into legacy entries
+	if _, err := db.Exec(`DROP TRIGGER audit_log_no_update; UPDATE audit_log SET prev_hash = NULL WHERE id <= 2`); err != nil {
+		t.Fatalf("Failed to unlink entries: %v", err)
+	}
+	report, err := VerifyAuditChain()
+	if err != nil {
+		t.Fatalf("VerifyAuditChain failed: %v", err)
+	}
+	if report.Valid || report.LegacyEntries != 0 || report.FirstBroken == nil || report.FirstBroken.ID != 1 || report.FirstBroken.Reason != AuditChainUnchained {
+		t.Fatalf("Expected unchained entry 1, got %+v", report)
+	}
+
+	// Without the recorded start the chain cannot be verified
+	if _, err := db.Exec(`DROP TRIGGER audit_chain_no_delete; DELETE FROM audit_chain`); err != nil {
+		t.Fatalf("Failed to delete chain start: %v", err)
+	}
+	report, err = VerifyAuditChain()
+	if err != nil {
+		t.Fatalf("VerifyAuditChain failed: %v", err)
+	}
+	if report.Valid || report.FirstBroken == nil || report.FirstBroken.Reason != AuditChainStartMissing {
+		t.Fatalf("Expected missing chain start, got %+v", report.FirstBroken)
+	}
+}
@@ -0,0 +1,136 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrConnectionSettingsNotFound = errors.New("connection settings not found")
+
+// FTP data connection modes
+const (
+	FTPModePassive = "passive" // EPSV, falling back to PASV
+	FTPModePASV    = "pasv"    // PASV only, for boxes or firewalls that mishandle EPSV
+	FTPModeActive  = "active"  // PORT, not supported by the FTP client
+)
+
+// TLS modes for FTP connections
+const (
+	TLSModeNone     = "none"
+	TLSModeExplicit = "explicit" // AUTH TLS on the control connection (FTPES)
+	TLSModeImplicit = "implicit" // TLS from the first byte (FTPS, usually port 990)
+)
+
+// PasswordSet reports whether a password is stored
+func (s *DeviceConnectionSettings) PasswordSet() bool {
+	return s.secret != ""
+}
+
+// SetPassword encrypts and stores the password; an empty password removes it
+func (s *DeviceConnectionSettings) SetPassword(password string) error {
+	if password == "" {
+		s.secret = ""
+		return nil
+	}
+	secret, err := encryptSecret(password, secretContext(s.DeviceID))
+	if err != nil {
+		return fmt.Errorf("failed to encrypt password: %w", err)
+	}
+	s.secret = secret
+	return nil
+}
+
+// Password returns the decrypted password ("" if none is stored)
+func (s *DeviceConnectionSettings) Password() (string, error) {
+	if s.secret == "" {
+		return "", nil
+	}
+	return decryptSecret(s.secret, secretContext(s.DeviceID))
+}
+
+// GetDeviceConnectionSettings retrieves the connection settings of a device
+func GetDeviceConnectionSettings(deviceID int) (*DeviceConnectionSettings, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	query := `
+		SELECT device_id, port, username, secret, timeout_seconds, ftp_mode, tls_mode, tls_skip_verify, updated
+		FROM device_connection_settings
+		WHERE device_id = ?
+	`
+
+	settings := &DeviceConnectionSettings{}
+	var username, secret, ftpMode, tlsMode sql.NullString
+	err := db.QueryRow(query, deviceID).Scan(
+		&settings.DeviceID,
+		&settings.Port,
+		&username,
+		&secret,
+		&settings.TimeoutSeconds,
+		&ftpMode,
+		&tlsMode,
+		&settings.TLSSkipVerify,
+		&settings.Updated,
+	)
+	if err == sql.ErrNoRows {
+		return nil, ErrConnectionSettingsNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get connection settings: %w", err)
+	}
+
+	settings.Username = username.String
+	settings.secret = secret.String
+	settings.FTPMode = ftpMode.String
+	settings.TLSMode = tlsMode.String
+
+	return settings, nil
+}
+
+// SaveDeviceConnectionSettings creates or replaces the connection settings of a device
+func SaveDeviceConnectionSettings(settings *DeviceConnectionSettings) error {
+	if db == nil {
+		return fmt.Errorf("database not initialized")
+	}
+
+	settings.Updated = time.Now()
+	query := `
+		INSERT INTO device_connection_settings
+			(device_id, port, username, secret, timeout_seconds, ftp_mode, tls_mode, tls_skip_verify, updated)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(device_id) DO UPDATE SET
+			port = excluded.port,
+			username = excluded.username,
+			secret = excluded.secret,
+			timeout_seconds = excluded.timeout_seconds,
+			ftp_mode = excluded.ftp_mode,
+			tls_mode = excluded.tls_mode,
+			tls_skip_verify = excluded.tls_skip_verify,
+			updated = excluded.updated
+	`
+
+	_, err := db.Exec(
+		query,
+		settings.DeviceID,
+		settings.Port,
+		settings.Username,
+		settings.secret,
+		settings.TimeoutSeconds,
+		settings.FTPMode,
+		settings.TLSMode,
+		settings.TLSSkipVerify,
+		settings.Updated,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to save connection settings: %w", err)
+	}
+	return nil
+}
+
+// secretContext binds an encrypted password to its device row
+func secretContext(deviceID int) string {
+	return fmt.Sprintf("device_connection_settings:%d", deviceID)
+}
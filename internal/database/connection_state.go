@@ -0,0 +1,275 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var ErrConnectionStateNotFound = errors.New("connection state not found")
+
+// Connection states as reported by the device adapters (see adapters.ConnectionState)
+const (
+	ConnectionStateDisconnected = "DISCONNECTED"
+	ConnectionStateConnecting   = "CONNECTING"
+	ConnectionStateConnected    = "CONNECTED"
+	ConnectionStateError        = "ERROR"
+)
+
+// Reasons of connection state changes
+const (
+	ConnectionReasonConnect     = "connect"      // Connection attempt
+	ConnectionReasonDisconnect  = "disconnect"   // Requested disconnect
+	ConnectionReasonProbe       = "probe"        // Reachability probe
+	ConnectionReasonHealthCheck = "health_check" // Session check of a connected device
+	ConnectionReasonShutdown    = "shutdown"     // Service stopped
+)
+
+// RecordDeviceConnectionState stores the connection state the device manager observed
+// CONNECTED counts as successful communication (last_seen, failures reset); a non-empty
+// errorText counts as failed attempt. A changed state is recorded in the connection history.
+// Returns the stored state and the transition (nil if the state did not change).
+func RecordDeviceConnectionState(deviceID int, state, reason, errorText string) (*DeviceConnectionState, *DeviceConnectionTransition, error) {
+	if db == nil {
+		return nil, nil, fmt.Errorf("database not initialized")
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	previous, err := scanDeviceConnectionState(tx.QueryRow(`
+		SELECT device_id, state, last_seen, last_error, consecutive_failures, since, updated
+		FROM device_connection_state
+		WHERE device_id = ?
+	`, deviceID))
+	if err == ErrConnectionStateNotFound {
+		previous = nil
+	} else if err != nil {
+		return nil, nil, err
+	}
+
+	now := time.Now().Round(0)
+	current := DeviceConnectionState{DeviceID: deviceID, State: state, Since: now, Updated: now}
+	if previous != nil {
+		current.LastSeen = previous.LastSeen
+		current.LastError = previous.LastError
+		current.ConsecutiveFailures = previous.ConsecutiveFailures
+		if previous.State == state {
+			current.Since = previous.Since
+		}
+	}
+	if state == ConnectionStateConnected {
+		current.LastSeen = &now
+		current.ConsecutiveFailures = 0
+	}
+	if errorText != "" {
+		current.LastError = errorText
+		current.ConsecutiveFailures++
+	}
+
+	var lastError sql.NullString
+	if current.LastError != "" {
+		lastError = sql.NullString{String: current.LastError, Valid: true}
+	}
+	_, err = tx.Exec(`
+		INSERT INTO device_connection_state (device_id, state, last_seen, last_error, consecutive_failures, since, updated)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT(device_id) DO UPDATE SET
+			state = excluded.state,
+			last_seen = excluded.last_seen,
+			last_error = excluded.last_error,
+			consecutive_failures = excluded.consecutive_failures,
+			since = excluded.since,
+			updated = excluded.updated
+	`, deviceID, state, current.LastSeen, lastError, current.ConsecutiveFailures, current.Since, now)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to save connection state: %w", err)
+	}
+
+	if state == ConnectionStateConnected {
+		if _, err := tx.Exec(`UPDATE devices SET last_seen = ? WHERE id = ?`, now, deviceID); err != nil {
+			return nil, nil, fmt.Errorf("failed to update device last seen: %w", err)
+		}
+	}
+
+	var transition *DeviceConnectionTransition
+	if previous == nil || previous.State != state {
+		transition = &DeviceConnectionTransition{
+			DeviceID:  deviceID,
+			ToState:   state,
+			Reason:    reason,
+			Error:     errorText,
+			Timestamp: now,
+		}
+		var fromState sql.NullString
+		if previous != nil {
+			transition.FromState = previous.State
+			fromState = sql.NullString{String: previous.State, Valid: true}
+			duration := now.Sub(previous.Since).Milliseconds()
+			transition.DurationMs = &duration
+		}
+		var transitionError sql.NullString
+		if errorText != "" {
+			transitionError = sql.NullString{String: errorText, Valid: true}
+		}
+		result, err := tx.Exec(`
+			INSERT INTO device_connection_history (device_id, from_state, to_state, reason, error, duration_ms, timestamp)
+			VALUES (?, ?, ?, ?, ?, ?, ?)
+		`, deviceID, fromState, state, reason, transitionError, transition.DurationMs, now)
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to save connection transition: %w", err)
+		}
+		id, err := result.LastInsertId()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to get connection transition ID: %w", err)
+		}
+		transition.ID = int(id)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, nil, fmt.Errorf("failed to commit connection state: %w", err)
+	}
+
+	return &current, transition, nil
+}
+
+// GetDeviceConnectionHistory retrieves the connection state changes of a device, newest first
+// from and to are optional bounds of the transition time; limit caps the number of entries.
+func GetDeviceConnectionHistory(deviceID int, from, to *time.Time, limit int) ([]DeviceConnectionTransition, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	query := `
+		SELECT id, device_id, from_state, to_state, reason, error, duration_ms, timestamp
+		FROM device_connection_history
+		WHERE device_id = ?
+	`
+	args := []interface{}{deviceID}
+	if from != nil {
+		query += " AND timestamp >= ?"
+		args = append(args, from.Local())
+	}
+	if to != nil {
+		query += " AND timestamp < ?"
+		args = append(args, to.Local())
+	}
+	query += " ORDER BY id DESC LIMIT ?"
+	args = append(args, limit)
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query connection history: %w", err)
+	}
+	defer rows.Close()
+
+	history := []DeviceConnectionTransition{}
+	for rows.Next() {
+		var transition DeviceConnectionTransition
+		var fromState, transitionError sql.NullString
+		var duration sql.NullInt64
+		if err := rows.Scan(&transition.ID, &transition.DeviceID, &fromState, &transition.ToState, &transition.Reason,
+			&transitionError, &duration, &transition.Timestamp); err != nil {
+			return nil, fmt.Errorf("failed to scan connection history: %w", err)
+		}
+		transition.FromState = fromState.String
+		transition.Error = transitionError.String
+		if duration.Valid {
+			transition.DurationMs = &duration.Int64
+		}
+		history = append(history, transition)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate connection history: %w", err)
+	}
+
+	return history, nil
+}
+
+// GetDeviceConnectionState retrieves the connection state of a device
+func GetDeviceConnectionState(deviceID int) (*DeviceConnectionState, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	return scanDeviceConnectionState(db.QueryRow(`
+		SELECT device_id, state, last_seen, last_error, consecutive_failures, since, updated
+		FROM device_connection_state
+		WHERE device_id = ?
+	`, deviceID))
+}
+
+// GetDeviceConnectionStates retrieves the connection state of every device, ordered by device ID
+// Devices the manager never connected are DISCONNECTED since they were created.
+func GetDeviceConnectionStates() ([]DeviceConnectionState, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	rows, err := db.Query(`
+		SELECT d.id, d.created, s.state, s.last_seen, s.last_error, s.consecutive_failures, s.since, s.updated
+		FROM devices d
+		LEFT JOIN device_connection_state s ON s.device_id = d.id
+		ORDER BY d.id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query connection states: %w", err)
+	}
+	defer rows.Close()
+
+	states := []DeviceConnectionState{}
+	for rows.Next() {
+		var state DeviceConnectionState
+		var created time.Time
+		var stateName, lastError sql.NullString
+		var lastSeen, since, updated sql.NullTime
+		var failures sql.NullInt64
+		if err := rows.Scan(&state.DeviceID, &created, &stateName, &lastSeen, &lastError, &failures, &since, &updated); err != nil {
+			return nil, fmt.Errorf("failed to scan connection state: %w", err)
+		}
+		if !stateName.Valid {
+			state.State = ConnectionStateDisconnected
+			state.Since = created
+			state.Updated = created
+			states = append(states, state)
+			continue
+		}
+		state.State = stateName.String
+		if lastSeen.Valid {
+			state.LastSeen = &lastSeen.Time
+		}
+		state.LastError = lastError.String
+		state.ConsecutiveFailures = int(failures.Int64)
+		state.Since = since.Time
+		state.Updated = updated.Time
+		states = append(states, state)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate connection states: %w", err)
+	}
+
+	return states, nil
+}
+
+// scanDeviceConnectionState scans a device_connection_state row
+func scanDeviceConnectionState(row interface{ Scan(dest ...interface{}) error }) (*DeviceConnectionState, error) {
+	state := &DeviceConnectionState{}
+	var lastSeen sql.NullTime
+	var lastError sql.NullString
+	err := row.Scan(&state.DeviceID, &state.State, &lastSeen, &lastError, &state.ConsecutiveFailures, &state.Since, &state.Updated)
+	if err == sql.ErrNoRows {
+		return nil, ErrConnectionStateNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan connection state: %w", err)
+	}
+	if lastSeen.Valid {
+		state.LastSeen = &lastSeen.Time
+	}
+	state.LastError = lastError.String
+	return state, nil
+}
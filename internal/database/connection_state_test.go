@@ -0,0 +1,125 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+func TestRecordDeviceConnectionState(t *testing.T) {
+	if err := InitializeDatabase(filepath.Join(t.TempDir(), "state.db")); err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer Close()
+
+	melag, err := CreateDevice(&Device{Name: "Vacuklav", Manufacturer: "Melag", IP: "192.168.1.10", Type: "Steri"})
+	if err != nil {
+		t.Fatalf("Failed to create device: %v", err)
+	}
+	getinge, err := CreateDevice(&Device{Name: "Aquadis", Manufacturer: "Getinge", IP: "192.168.1.11", Type: "RDG"})
+	if err != nil {
+		t.Fatalf("Failed to create device: %v", err)
+	}
+
+	if _, _, err := RecordDeviceConnectionState(melag.ID, ConnectionStateConnected, ConnectionReasonConnect, ""); err != nil {
+		t.Fatalf("RecordDeviceConnectionState failed: %v", err)
+	}
+	connected, err := GetDeviceConnectionState(melag.ID)
+	if err != nil {
+		t.Fatalf("GetDeviceConnectionState failed: %v", err)
+	}
+	if connected.LastSeen == nil {
+		t.Fatal("Expected last_seen after connecting")
+	}
+
+	for i := 0; i < 2; i++ {
+		if _, _, err := RecordDeviceConnectionState(melag.ID, ConnectionStateError, ConnectionReasonConnect, "connection refused"); err != nil {
+			t.Fatalf("RecordDeviceConnectionState failed: %v", err)
+		}
+	}
+	state, transition, err := RecordDeviceConnectionState(melag.ID, ConnectionStateError, ConnectionReasonConnect, "i/o timeout")
+	if err != nil {
+		t.Fatalf("RecordDeviceConnectionState failed: %v", err)
+	}
+	if transition != nil || state.ConsecutiveFailures != 3 || state.LastError != "i/o timeout" {
+		t.Fatalf("Expected 3rd failure without state change, got %+v (transition %+v)", state, transition)
+	}
+	if state.LastSeen == nil || !state.LastSeen.Equal(*connected.LastSeen) || !state.Since.After(connected.Since) {
+		t.Fatalf("Expected last_seen kept and since of the error state, got %+v", state)
+	}
+
+	status, err := GetDeviceStatus(melag.ID)
+	if err != nil {
+		t.Fatalf("GetDeviceStatus failed: %v", err)
+	}
+	if status.Connected || status.State != ConnectionStateError || status.HealthStatus != "degraded" || status.ConsecutiveFailures != 3 {
+		t.Fatalf("Unexpected device status %+v", status)
+	}
+
+	states, err := GetDeviceConnectionStates()
+	if err != nil {
+		t.Fatalf("GetDeviceConnectionStates failed: %v", err)
+	}
+	if len(states) != 2 || states[0].State != ConnectionStateError || states[1].DeviceID != getinge.ID || states[1].State != ConnectionStateDisconnected {
+		t.Fatalf("Unexpected connection states %+v", states)
+	}
+
+	status, err = GetDeviceStatus(getinge.ID)
+	if err != nil {
+		t.Fatalf("GetDeviceStatus failed: %v", err)
+	}
+	if status.Connected || status.HealthStatus != "unhealthy" {
+		t.Fatalf("Expected never connected device to be unhealthy, got %+v", status)
+	}
+}
+
+func TestGetDeviceConnectionHistory(t *testing.T) {
+	if err := InitializeDatabase(filepath.Join(t.TempDir(), "history.db")); err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer Close()
+
+	device, err := CreateDevice(&Device{Name: "Vacuklav", Manufacturer: "Melag", IP: "192.168.1.10", Type: "Steri"})
+	if err != nil {
+		t.Fatalf("Failed to create device: %v", err)
+	}
+
+	steps := []struct{ state, reason, errorText string }{
+		{ConnectionStateConnecting, ConnectionReasonConnect, ""},
+		{ConnectionStateConnected, ConnectionReasonConnect, ""},
+		{ConnectionStateConnected, ConnectionReasonConnect, ""},
+		{ConnectionStateError, ConnectionReasonConnect, "connection reset by peer"},
+		{ConnectionStateError, ConnectionReasonConnect, "connection refused"},
+		{ConnectionStateConnected, ConnectionReasonConnect, ""},
+	}
+	for _, step := range steps {
+		if _, _, err := RecordDeviceConnectionState(device.ID, step.state, step.reason, step.errorText); err != nil {
+			t.Fatalf("RecordDeviceConnectionState failed: %v", err)
+		}
+	}
+
+	history, err := GetDeviceConnectionHistory(device.ID, nil, nil, 10)
+	if err != nil {
+		t.Fatalf("GetDeviceConnectionHistory failed: %v", err)
+	}
+	if len(history) != 4 {
+		t.Fatalf("Expected 4 transitions, got %+v", history)
+	}
+	first, dropped, recovered := history[3], history[1], history[0]
+	if first.FromState != "" || first.DurationMs != nil || first.ToState != ConnectionStateConnecting {
+		t.Errorf("Unexpected first transition %+v", first)
+	}
+	if dropped.FromState != ConnectionStateConnected || dropped.ToState != ConnectionStateError || dropped.Error != "connection reset by peer" {
+		t.Errorf("Unexpected drop %+v", dropped)
+	}
+	if recovered.FromState != ConnectionStateError || recovered.DurationMs == nil || *recovered.DurationMs < 0 {
+		t.Errorf("Expected outage duration on recovery, got %+v", recovered)
+	}
+
+	history, err = GetDeviceConnectionHistory(device.ID, nil, nil, 1)
+	if err != nil {
+		t.Fatalf("GetDeviceConnectionHistory failed: %v", err)
+	}
+	if len(history) != 1 || history[0].ID != recovered.ID {
+		t.Errorf("Expected only the latest transition, got %+v", history)
+	}
+}
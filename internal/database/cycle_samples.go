@@ -0,0 +1,125 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// RecordCycleSample stores the process values of a running cycle
+// Samples of ended cycles are not stored; recorded reports whether a row was inserted.
+func RecordCycleSample(sample *CycleSample) (recorded bool, err error) {
+	if db == nil {
+		return false, fmt.Errorf("database not initialized")
+	}
+
+	var extra sql.NullString
+	if len(sample.Extra) > 0 {
+		extraJSON, err := json.Marshal(sample.Extra)
+		if err != nil {
+			return false, fmt.Errorf("failed to marshal sample values: %w", err)
+		}
+		extra = sql.NullString{String: string(extraJSON), Valid: true}
+	}
+	var phase sql.NullString
+	if sample.Phase != "" {
+		phase = sql.NullString{String: sample.Phase, Valid: true}
+	}
+
+	result, err := db.Exec(`
+		INSERT INTO cycle_samples (cycle_id, ts, phase, temperature, pressure, progress_percent, extra)
+		SELECT ?, ?, ?, ?, ?, ?, ?
+		WHERE EXISTS (SELECT 1 FROM cycles WHERE id = ? AND end_ts IS NULL)
+	`, sample.CycleID, sample.Timestamp, phase, sample.Temperature, sample.Pressure, sample.ProgressPercent, extra, sample.CycleID)
+	if err != nil {
+		return false, fmt.Errorf("failed to record cycle sample: %w", err)
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to check recorded cycle sample: %w", err)
+	}
+	if rows == 0 {
+		return false, nil
+	}
+	id, err := result.LastInsertId()
+	if err != nil {
+		return false, fmt.Errorf("failed to get cycle sample ID: %w", err)
+	}
+	sample.ID = int(id)
+	return true, nil
+}
+
+// GetCycleSamples retrieves the process values recorded for a cycle, oldest first
+func GetCycleSamples(cycleID int) ([]CycleSample, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	rows, err := db.Query(`
+		SELECT id, cycle_id, ts, phase, temperature, pressure, progress_percent, extra
+		FROM cycle_samples
+		WHERE cycle_id = ?
+		ORDER BY ts, id
+	`, cycleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cycle samples: %w", err)
+	}
+	defer rows.Close()
+
+	samples := []CycleSample{}
+	for rows.Next() {
+		var sample CycleSample
+		var phase, extra sql.NullString
+		var temperature, pressure sql.NullFloat64
+		var progress sql.NullInt64
+		if err := rows.Scan(&sample.ID, &sample.CycleID, &sample.Timestamp, &phase, &temperature, &pressure, &progress, &extra); err != nil {
+			return nil, fmt.Errorf("failed to scan cycle sample: %w", err)
+		}
+		sample.Phase = phase.String
+		if temperature.Valid {
+			sample.Temperature = &temperature.Float64
+		}
+		if pressure.Valid {
+			sample.Pressure = &pressure.Float64
+		}
+		if progress.Valid {
+			value := int(progress.Int64)
+			sample.ProgressPercent = &value
+		}
+		if extra.Valid {
+			if err := json.Unmarshal([]byte(extra.String), &sample.Extra); err != nil {
+				return nil, fmt.Errorf("failed to parse values of cycle sample %d: %w", sample.ID, err)
+			}
+		}
+		samples = append(samples, sample)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate cycle samples: %w", err)
+	}
+
+	return samples, nil
+}
+
+// DownsampleCycleSamples reduces samples (oldest first) to at most one per interval and phase
+// The first sample of every phase and the last sample are always kept, so phase
+// boundaries and the end of the curve stay exact. An interval <= 0 keeps all samples.
+func DownsampleCycleSamples(samples []CycleSample, interval time.Duration) []CycleSample {
+	if interval <= 0 || len(samples) < 3 {
+		return samples
+	}
+
+	kept := []CycleSample{samples[0]}
+	bucketStart := samples[0].Timestamp
+	for i := 1; i < len(samples); i++ {
+		sample := samples[i]
+		last := i == len(samples)-1
+		phaseChanged := sample.Phase != samples[i-1].Phase
+		if phaseChanged || last || sample.Timestamp.Sub(bucketStart) >= interval {
+			kept = append(kept, sample)
+			bucketStart = sample.Timestamp
+		}
+	}
+	return kept
+}
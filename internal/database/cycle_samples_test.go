@@ -0,0 +1,98 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRecordCycleSample(t *testing.T) {
+	if err := InitializeDatabase(filepath.Join(t.TempDir(), "samples.db")); err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer Close()
+
+	device, err := CreateDevice(&Device{Name: "Vacuklav", Manufacturer: "Melag", IP: "192.168.1.10", Type: "Steri"})
+	if err != nil {
+		t.Fatalf("Failed to create device: %v", err)
+	}
+	start := time.Now().Round(0)
+	cycle, err := CreateCycle(&Cycle{DeviceID: device.ID, Program: "Universal", StartTS: start, Phase: "Aufheizen"})
+	if err != nil {
+		t.Fatalf("Failed to create cycle: %v", err)
+	}
+
+	temperature, pressure, progress := 134.2, 2.1, 40
+	sample := &CycleSample{
+		CycleID:         cycle.ID,
+		Timestamp:       start.Add(2 * time.Second),
+		Phase:           "Sterilisation",
+		Temperature:     &temperature,
+		Pressure:        &pressure,
+		ProgressPercent: &progress,
+		Extra:           map[string]interface{}{"time_remaining_s": 600},
+	}
+	if recorded, err := RecordCycleSample(sample); err != nil || !recorded || sample.ID == 0 {
+		t.Fatalf("RecordCycleSample failed: recorded %v, id %d, err %v", recorded, sample.ID, err)
+	}
+	if recorded, err := RecordCycleSample(&CycleSample{CycleID: cycle.ID, Timestamp: start.Add(4 * time.Second), Phase: "Trocknung"}); err != nil || !recorded {
+		t.Fatalf("RecordCycleSample without values failed: recorded %v, err %v", recorded, err)
+	}
+
+	if err := UpdateCycleResult(cycle.ID, "OK", start.Add(time.Minute), nil, nil); err != nil {
+		t.Fatalf("UpdateCycleResult failed: %v", err)
+	}
+	if recorded, err := RecordCycleSample(&CycleSample{CycleID: cycle.ID, Timestamp: start.Add(2 * time.Minute)}); err != nil || recorded {
+		t.Errorf("Expected sample of ended cycle to be skipped, got recorded %v, err %v", recorded, err)
+	}
+
+	samples, err := GetCycleSamples(cycle.ID)
+	if err != nil {
+		t.Fatalf("GetCycleSamples failed: %v", err)
+	}
+	if len(samples) != 2 {
+		t.Fatalf("Expected 2 samples, got %+v", samples)
+	}
+	first := samples[0]
+	if !first.Timestamp.Equal(sample.Timestamp) || first.Phase != "Sterilisation" || *first.Temperature != temperature || *first.Pressure != pressure || *first.ProgressPercent != progress {
+		t.Errorf("Unexpected first sample %+v", first)
+	}
+	if first.Extra["time_remaining_s"] != float64(600) {
+		t.Errorf("Expected extra values, got %+v", first.Extra)
+	}
+	if second := samples[1]; second.Temperature != nil || second.ProgressPercent != nil || second.Extra != nil {
+		t.Errorf("Expected sample without values, got %+v", second)
+	}
+}
+
+func TestDownsampleCycleSamples(t *testing.T) {
+	start := time.Date(2025, 11, 22, 10, 0, 0, 0, time.UTC)
+	var samples []CycleSample
+	for i := 0; i < 30; i++ {
+		phase := "Aufheizen"
+		if i >= 13 {
+			phase = "Sterilisation"
+		}
+		samples = append(samples, CycleSample{ID: i + 1, Timestamp: start.Add(time.Duration(i) * 2 * time.Second), Phase: phase})
+	}
+
+	kept := DownsampleCycleSamples(samples, 10*time.Second)
+	var ids []int
+	for _, sample := range kept {
+		ids = append(ids, sample.ID)
+	}
+	// One sample per 10 s within each phase, the phase change (14) and the last sample (30)
+	expected := []int{1, 6, 11, 14, 19, 24, 29, 30}
+	if len(ids) != len(expected) {
+		t.Fatalf("Expected samples %v, got %v", expected, ids)
+	}
+	for i := range expected {
+		if ids[i] != expected[i] {
+			t.Fatalf("Expected samples %v, got %v", expected, ids)
+		}
+	}
+
+	if all := DownsampleCycleSamples(samples, 0); len(all) != len(samples) {
+		t.Errorf("Expected all samples without interval, got %d", len(all))
+	}
+}
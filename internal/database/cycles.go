@@ -10,8 +10,13 @@ import (
 
 var (
 	ErrCycleNotFound = errors.New("cycle not found")
+	ErrCycleEnded    = errors.New("cycle has already ended")
 )
 
+// CycleResultUnknown is the result of a cycle whose outcome the device never reported
+// (closed by the stale cycle watchdog); it is neither passed nor released
+const CycleResultUnknown = "UNKNOWN"
+
 // CreateCycle creates a new cycle in the database
 func CreateCycle(cycle *Cycle) (*Cycle, error) {
 	if db == nil {
@@ -59,7 +64,8 @@ func GetCycle(id int) (*Cycle, error) {
 
 	query := `
 		SELECT id, device_id, program, start_ts, end_ts, result, error_code,
-		       error_description, phase, temperature, pressure, progress_percent
+		       error_description, phase, temperature, pressure, progress_percent,
+		       device_cycle_number, a0_value, release_status, validation_result
 		FROM cycles
 		WHERE id = ?
 	`
@@ -73,6 +79,10 @@ func GetCycle(id int) (*Cycle, error) {
 	var temp sql.NullFloat64
 	var pressure sql.NullFloat64
 	var progress sql.NullInt64
+	var deviceCycleNumber sql.NullString
+	var a0Value sql.NullFloat64
+	var releaseStatus sql.NullString
+	var validationResult sql.NullString
 
 	err := db.QueryRow(query, id).Scan(
 		&cycle.ID,
@@ -87,6 +97,10 @@ func GetCycle(id int) (*Cycle, error) {
 		&temp,
 		&pressure,
 		&progress,
+		&deviceCycleNumber,
+		&a0Value,
+		&releaseStatus,
+		&validationResult,
 	)
 
 	if err == sql.ErrNoRows {
@@ -122,6 +136,19 @@ func GetCycle(id int) (*Cycle, error) {
 		progressVal := int(progress.Int64)
 		cycle.ProgressPercent = &progressVal
 	}
+	if deviceCycleNumber.Valid {
+		cycle.DeviceCycleNumber = deviceCycleNumber.String
+	}
+	if a0Value.Valid {
+		a0 := a0Value.Float64
+		cycle.A0Value = &a0
+	}
+	if releaseStatus.Valid {
+		cycle.ReleaseStatus = releaseStatus.String
+	}
+	if validationResult.Valid {
+		cycle.ValidationResult = validationResult.String
+	}
 
 	return cycle, nil
 }
@@ -135,6 +162,7 @@ func GetCycleWithDevice(id int) (*CycleWithDevice, error) {
 	query := `
 		SELECT c.id, c.device_id, c.program, c.start_ts, c.end_ts, c.result, c.error_code,
 		       c.error_description, c.phase, c.temperature, c.pressure, c.progress_percent,
+		       c.device_cycle_number, c.a0_value, c.release_status, c.validation_result,
 		       d.name as device_name, d.ip as device_ip, d.manufacturer
 		FROM cycles c
 		LEFT JOIN devices d ON c.device_id = d.id
@@ -150,6 +178,10 @@ func GetCycleWithDevice(id int) (*CycleWithDevice, error) {
 	var temp sql.NullFloat64
 	var pressure sql.NullFloat64
 	var progress sql.NullInt64
+	var deviceCycleNumber sql.NullString
+	var a0Value sql.NullFloat64
+	var releaseStatus sql.NullString
+	var validationResult sql.NullString
 
 	err := db.QueryRow(query, id).Scan(
 		&cycle.ID,
@@ -164,6 +196,10 @@ func GetCycleWithDevice(id int) (*CycleWithDevice, error) {
 		&temp,
 		&pressure,
 		&progress,
+		&deviceCycleNumber,
+		&a0Value,
+		&releaseStatus,
+		&validationResult,
 		&cycle.DeviceName,
 		&cycle.DeviceIP,
 		&cycle.Manufacturer,
@@ -204,18 +240,34 @@ func GetCycleWithDevice(id int) (*CycleWithDevice, error) {
 		progressVal := int(progress.Int64)
 		cycle.ProgressPercent = &progressVal
 	}
+	if deviceCycleNumber.Valid {
+		cycle.DeviceCycleNumber = deviceCycleNumber.String
+	}
+	if a0Value.Valid {
+		a0 := a0Value.Float64
+		cycle.A0Value = &a0
+	}
+	if releaseStatus.Valid {
+		cycle.ReleaseStatus = releaseStatus.String
+	}
+	if validationResult.Valid {
+		cycle.ValidationResult = validationResult.String
+	}
 
 	return &cycle, nil
 }
 
 // UpdateCycleStatus updates cycle status and phase information
+// Every call counts as status report of the device (last_status_at), so it must only be called
+// for new readings, not for a status the adapter repeats (see CycleStatus.Stale).
+// Ended cycles are not changed; ErrCycleEnded is returned instead.
 func UpdateCycleStatus(id int, phase string, progress *int, temperature *float64, pressure *float64) error {
 	if db == nil {
 		return fmt.Errorf("database not initialized")
 	}
 
-	setParts := []string{}
-	args := []interface{}{}
+	setParts := []string{"last_status_at = ?"}
+	args := []interface{}{time.Now().Round(0)}
 
 	if phase != "" {
 		setParts = append(setParts, "phase = ?")
@@ -237,10 +289,6 @@ func UpdateCycleStatus(id int, phase string, progress *int, temperature *float64
 		args = append(args, *pressure)
 	}
 
-	if len(setParts) == 0 {
-		return nil // Nothing to update
-	}
-
 	// Build query properly
 	query := "UPDATE cycles SET "
 	for i, part := range setParts {
@@ -249,20 +297,32 @@ func UpdateCycleStatus(id int, phase string, progress *int, temperature *float64
 		}
 		query += part
 	}
-	query += " WHERE id = ?"
+	query += " WHERE id = ? AND end_ts IS NULL"
 
 	// Add cycle ID to args for WHERE clause
 	args = append(args, id)
 
-	_, err := db.Exec(query, args...)
+	res, err := db.Exec(query, args...)
 	if err != nil {
 		return fmt.Errorf("failed to update cycle: %w", err)
 	}
 
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check cycle update: %w", err)
+	}
+	if rows == 0 {
+		if _, err := GetCycle(id); err != nil {
+			return err
+		}
+		return ErrCycleEnded
+	}
+
 	return nil
 }
 
 // UpdateCycleResult updates cycle final result
+// The result of an ended cycle is kept; ErrCycleEnded is returned instead.
 func UpdateCycleResult(id int, result string, endTS time.Time, errorCode *string, errorDesc *string) error {
 	if db == nil {
 		return fmt.Errorf("database not initialized")
@@ -270,15 +330,162 @@ func UpdateCycleResult(id int, result string, endTS time.Time, errorCode *string
 
 	query := `
 		UPDATE cycles
-		SET result = ?, end_ts = ?, error_code = ?, error_description = ?
-		WHERE id = ?
+		SET result = ?, end_ts = ?, error_code = ?, error_description = ?,
+		    release_status = COALESCE(release_status, ?)
+		WHERE id = ? AND end_ts IS NULL
 	`
 
-	_, err := db.Exec(query, result, endTS, errorCode, errorDesc, id)
+	res, err := db.Exec(query, result, endTS, errorCode, errorDesc, ReleaseStatusCompleted, id)
 	if err != nil {
 		return fmt.Errorf("failed to update cycle result: %w", err)
 	}
 
+	rows, err := res.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check cycle result update: %w", err)
+	}
+	if rows == 0 {
+		if _, err := GetCycle(id); err != nil {
+			return err
+		}
+		return ErrCycleEnded
+	}
+
+	return nil
+}
+
+// RunningCycleActivity is the time and latest status report of a running cycle
+type RunningCycleActivity struct {
+	CycleID      int
+	DeviceID     int
+	Program      string
+	Phase        string
+	StartTS      time.Time
+	LastStatusAt *time.Time // nil until the device reported a status
+}
+
+// GetRunningCycleActivity retrieves start and last status report of all running cycles
+func GetRunningCycleActivity() ([]RunningCycleActivity, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	rows, err := db.Query(`
+		SELECT id, device_id, program, phase, start_ts, last_status_at
+		FROM cycles
+		WHERE end_ts IS NULL
+		  AND phase NOT IN ('COMPLETED', 'FAILED')
+		ORDER BY id
+	`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query running cycles: %w", err)
+	}
+	defer rows.Close()
+
+	var activity []RunningCycleActivity
+	for rows.Next() {
+		var cycle RunningCycleActivity
+		var program, phase sql.NullString
+		var lastStatusAt sql.NullTime
+		if err := rows.Scan(&cycle.CycleID, &cycle.DeviceID, &program, &phase, &cycle.StartTS, &lastStatusAt); err != nil {
+			return nil, fmt.Errorf("failed to scan running cycle: %w", err)
+		}
+		cycle.Program = program.String
+		cycle.Phase = phase.String
+		if lastStatusAt.Valid {
+			cycle.LastStatusAt = &lastStatusAt.Time
+		}
+		activity = append(activity, cycle)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("failed to iterate running cycles: %w", err)
+	}
+
+	return activity, nil
+}
+
+// ImportCycle inserts a finished cycle read from the device archive
+// The cycle is skipped if a cycle with the same device cycle number already exists;
+// imported reports whether a row was inserted.
+func ImportCycle(cycle *Cycle) (imported bool, err error) {
+	if db == nil {
+		return false, fmt.Errorf("database not initialized")
+	}
+	if cycle.DeviceCycleNumber == "" {
+		return false, fmt.Errorf("device cycle number is required for import")
+	}
+
+	// Only a duplicate device cycle number is skipped, other constraint violations are errors
+	query := `
+		INSERT INTO cycles (device_id, program, start_ts, end_ts, result, error_code,
+		                    error_description, phase, temperature, pressure,
+		                    progress_percent, device_cycle_number, release_status)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
+		ON CONFLICT (device_id, device_cycle_number) WHERE device_cycle_number IS NOT NULL DO NOTHING
+	`
+
+	result, err := db.Exec(
+		query,
+		cycle.DeviceID,
+		cycle.Program,
+		cycle.StartTS,
+		cycle.EndTS,
+		cycle.Result,
+		sql.NullString{String: cycle.ErrorCode, Valid: cycle.ErrorCode != ""},
+		sql.NullString{String: cycle.ErrorDescription, Valid: cycle.ErrorDescription != ""},
+		cycle.Phase,
+		cycle.Temperature,
+		cycle.Pressure,
+		cycle.ProgressPercent,
+		cycle.DeviceCycleNumber,
+		ReleaseStatusCompleted,
+	)
+	if err != nil {
+		return false, fmt.Errorf("failed to import cycle: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, fmt.Errorf("failed to get rows affected: %w", err)
+	}
+	if rowsAffected == 0 {
+		return false, nil
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return false, fmt.Errorf("failed to get cycle ID: %w", err)
+	}
+	cycle.ID = int(id)
+
+	return true, nil
+}
+
+// SetCycleDeviceCycleNumber records the cycle number the device assigned to a cycle
+func SetCycleDeviceCycleNumber(id int, deviceCycleNumber string) error {
+	if db == nil {
+		return fmt.Errorf("database not initialized")
+	}
+
+	_, err := db.Exec("UPDATE cycles SET device_cycle_number = ? WHERE id = ?", deviceCycleNumber, id)
+	if err != nil {
+		return fmt.Errorf("failed to update device cycle number: %w", err)
+	}
+
+	return nil
+}
+
+// SetCycleA0Value records the A0 value (disinfection dose) reported by a washer-disinfector
+func SetCycleA0Value(id int, a0Value float64) error {
+	if db == nil {
+		return fmt.Errorf("database not initialized")
+	}
+
+	_, err := db.Exec("UPDATE cycles SET a0_value = ? WHERE id = ?", a0Value, id)
+	if err != nil {
+		return fmt.Errorf("failed to update A0 value: %w", err)
+	}
+
 	return nil
 }
 
@@ -290,7 +497,8 @@ func GetDeviceCycles(deviceID int) ([]Cycle, error) {
 
 	query := `
 		SELECT id, device_id, program, start_ts, end_ts, result, error_code,
-		       error_description, phase, temperature, pressure, progress_percent
+		       error_description, phase, temperature, pressure, progress_percent,
+		       device_cycle_number, a0_value, release_status, validation_result
 		FROM cycles
 		WHERE device_id = ?
 		ORDER BY start_ts DESC
@@ -313,6 +521,10 @@ func GetDeviceCycles(deviceID int) ([]Cycle, error) {
 		var temp sql.NullFloat64
 		var pressure sql.NullFloat64
 		var progress sql.NullInt64
+		var deviceCycleNumber sql.NullString
+		var a0Value sql.NullFloat64
+		var releaseStatus sql.NullString
+		var validationResult sql.NullString
 
 		err := rows.Scan(
 			&cycle.ID,
@@ -327,6 +539,10 @@ func GetDeviceCycles(deviceID int) ([]Cycle, error) {
 			&temp,
 			&pressure,
 			&progress,
+			&deviceCycleNumber,
+			&a0Value,
+			&releaseStatus,
+			&validationResult,
 		)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan cycle: %w", err)
@@ -360,6 +576,19 @@ func GetDeviceCycles(deviceID int) ([]Cycle, error) {
 			progressVal := int(progress.Int64)
 			cycle.ProgressPercent = &progressVal
 		}
+		if deviceCycleNumber.Valid {
+			cycle.DeviceCycleNumber = deviceCycleNumber.String
+		}
+		if a0Value.Valid {
+			a0 := a0Value.Float64
+			cycle.A0Value = &a0
+		}
+		if releaseStatus.Valid {
+			cycle.ReleaseStatus = releaseStatus.String
+		}
+		if validationResult.Valid {
+			cycle.ValidationResult = validationResult.String
+		}
 
 		cycles = append(cycles, cycle)
 	}
@@ -380,7 +609,32 @@ type CycleListOptions struct {
 	DeviceID  *int      // Filter by device ID (nil = all devices)
 	StartDate *time.Time // Filter by start date (from)
 	EndDate   *time.Time // Filter by end date (to)
-	Result    *string   // Filter by result: "OK" or "NOK" (nil = all)
+	Result    *string   // Filter by result: "OK", "NOK" or "UNKNOWN" (nil = all)
+	SetID     *int      // Filter by instrument set in the cycle load (nil = all)
+	ReleaseStatus *string // Filter by release state, e.g. "pending_release" (nil = all)
+	Programs  []string  // Filter by program names containing any of these as whole words, case-insensitive (nil = all)
+}
+
+// programWordSeparators separate the words of a program name, e.g. "Bowie-Dick-Test"
+var programWordSeparators = []string{"-", "_", "/", ".", ",", "(", ")"}
+
+// programWords returns an SQL expression of a program column as lower-case words
+// separated and enclosed by single spaces, so a LIKE pattern "% word %" matches whole words
+func programWords(column string) string {
+	expr := "LOWER(" + column + ")"
+	for _, separator := range programWordSeparators {
+		expr = "REPLACE(" + expr + ", '" + separator + "', ' ')"
+	}
+	return "(' ' || " + expr + " || ' ')"
+}
+
+// normalizeProgramWords converts a program name like programWords does
+func normalizeProgramWords(program string) string {
+	program = strings.ToLower(program)
+	for _, separator := range programWordSeparators {
+		program = strings.ReplaceAll(program, separator, " ")
+	}
+	return strings.Join(strings.Fields(program), " ")
 }
 
 // CycleWithDevice represents a cycle with device information
@@ -421,6 +675,24 @@ func GetAllCycles(options CycleListOptions) ([]CycleWithDevice, int, error) {
 		args = append(args, *options.Result)
 	}
 
+	if options.SetID != nil {
+		whereParts = append(whereParts, "c.id IN (SELECT cycle_id FROM cycle_loads WHERE set_id = ?)")
+		args = append(args, *options.SetID)
+	}
+	if options.ReleaseStatus != nil {
+		whereParts = append(whereParts, "c.release_status = ?")
+		args = append(args, *options.ReleaseStatus)
+	}
+
+	if len(options.Programs) > 0 {
+		programParts := make([]string, 0, len(options.Programs))
+		for _, program := range options.Programs {
+			programParts = append(programParts, programWords("c.program")+" LIKE ?")
+			args = append(args, "% "+normalizeProgramWords(program)+" %")
+		}
+		whereParts = append(whereParts, "("+strings.Join(programParts, " OR ")+")")
+	}
+
 	whereClause := ""
 	if len(whereParts) > 0 {
 		whereClause = "WHERE " + strings.Join(whereParts, " AND ")
@@ -463,6 +735,7 @@ func GetAllCycles(options CycleListOptions) ([]CycleWithDevice, int, error) {
 	query := fmt.Sprintf(`
 		SELECT c.id, c.device_id, c.program, c.start_ts, c.end_ts, c.result, c.error_code,
 		       c.error_description, c.phase, c.temperature, c.pressure, c.progress_percent,
+		       c.device_cycle_number, c.a0_value, c.release_status, c.validation_result,
 		       d.name as device_name, d.ip as device_ip, d.manufacturer
 		FROM cycles c
 		LEFT JOIN devices d ON c.device_id = d.id
@@ -495,6 +768,10 @@ func GetAllCycles(options CycleListOptions) ([]CycleWithDevice, int, error) {
 		var temp sql.NullFloat64
 		var pressure sql.NullFloat64
 		var progress sql.NullInt64
+		var deviceCycleNumber sql.NullString
+		var a0Value sql.NullFloat64
+		var releaseStatus sql.NullString
+		var validationResult sql.NullString
 
 		err := rows.Scan(
 			&cycle.ID,
@@ -509,6 +786,10 @@ func GetAllCycles(options CycleListOptions) ([]CycleWithDevice, int, error) {
 			&temp,
 			&pressure,
 			&progress,
+			&deviceCycleNumber,
+			&a0Value,
+			&releaseStatus,
+			&validationResult,
 			&cycle.DeviceName,
 			&cycle.DeviceIP,
 			&cycle.Manufacturer,
@@ -545,6 +826,19 @@ func GetAllCycles(options CycleListOptions) ([]CycleWithDevice, int, error) {
 			progressVal := int(progress.Int64)
 			cycle.ProgressPercent = &progressVal
 		}
+		if deviceCycleNumber.Valid {
+			cycle.DeviceCycleNumber = deviceCycleNumber.String
+		}
+		if a0Value.Valid {
+			a0 := a0Value.Float64
+			cycle.A0Value = &a0
+		}
+		if releaseStatus.Valid {
+			cycle.ReleaseStatus = releaseStatus.String
+		}
+		if validationResult.Valid {
+			cycle.ValidationResult = validationResult.String
+		}
 
 		cycles = append(cycles, cycle)
 	}
@@ -565,6 +859,7 @@ func GetRunningCycles() ([]CycleWithDevice, error) {
 	query := `
 		SELECT c.id, c.device_id, c.program, c.start_ts, c.end_ts, c.result, c.error_code,
 		       c.error_description, c.phase, c.temperature, c.pressure, c.progress_percent,
+		       c.device_cycle_number, c.a0_value, c.release_status, c.validation_result,
 		       d.name as device_name, d.ip as device_ip, d.manufacturer
 		FROM cycles c
 		LEFT JOIN devices d ON c.device_id = d.id
@@ -590,6 +885,10 @@ func GetRunningCycles() ([]CycleWithDevice, error) {
 		var temp sql.NullFloat64
 		var pressure sql.NullFloat64
 		var progress sql.NullInt64
+		var deviceCycleNumber sql.NullString
+		var a0Value sql.NullFloat64
+		var releaseStatus sql.NullString
+		var validationResult sql.NullString
 
 		err := rows.Scan(
 			&cycle.ID,
@@ -604,6 +903,10 @@ func GetRunningCycles() ([]CycleWithDevice, error) {
 			&temp,
 			&pressure,
 			&progress,
+			&deviceCycleNumber,
+			&a0Value,
+			&releaseStatus,
+			&validationResult,
 			&cycle.DeviceName,
 			&cycle.DeviceIP,
 			&cycle.Manufacturer,
@@ -640,6 +943,19 @@ func GetRunningCycles() ([]CycleWithDevice, error) {
 			progressVal := int(progress.Int64)
 			cycle.ProgressPercent = &progressVal
 		}
+		if deviceCycleNumber.Valid {
+			cycle.DeviceCycleNumber = deviceCycleNumber.String
+		}
+		if a0Value.Valid {
+			a0 := a0Value.Float64
+			cycle.A0Value = &a0
+		}
+		if releaseStatus.Valid {
+			cycle.ReleaseStatus = releaseStatus.String
+		}
+		if validationResult.Valid {
+			cycle.ValidationResult = validationResult.String
+		}
 
 		cycles = append(cycles, cycle)
 	}
@@ -0,0 +1,39 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestImportCycleSkipsOnlyDuplicateCycleNumbers(t *testing.T) {
+	if err := InitializeDatabase(filepath.Join(t.TempDir(), "cycles.db")); err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer Close()
+
+	device, err := CreateDevice(&Device{Name: "Steri 1", Manufacturer: "Melag", IP: "192.0.2.10", Type: "Steri"})
+	if err != nil {
+		t.Fatalf("Failed to create device: %v", err)
+	}
+	start := time.Date(2025, 11, 22, 10, 0, 0, 0, time.UTC)
+	end := start.Add(45 * time.Minute)
+
+	imported, err := ImportCycle(&Cycle{DeviceID: device.ID, Program: "134 Universal", StartTS: start, EndTS: &end, Result: "OK", DeviceCycleNumber: "0041"})
+	if err != nil || !imported {
+		t.Fatalf("Expected cycle to be imported, got %v, %v", imported, err)
+	}
+	imported, err = ImportCycle(&Cycle{DeviceID: device.ID, Program: "134 Universal", StartTS: start, EndTS: &end, Result: "OK", DeviceCycleNumber: "0041"})
+	if err != nil || imported {
+		t.Fatalf("Expected duplicate cycle number to be skipped, got %v, %v", imported, err)
+	}
+
+	// Any other constraint violation must not be skipped silently
+	if _, err := db.Exec(`CREATE UNIQUE INDEX idx_test_cycles_start ON cycles(device_id, start_ts)`); err != nil {
+		t.Fatalf("Failed to create index: %v", err)
+	}
+	imported, err = ImportCycle(&Cycle{DeviceID: device.ID, Program: "134 Universal", StartTS: start, EndTS: &end, Result: "OK", DeviceCycleNumber: "0042"})
+	if err == nil || imported {
+		t.Fatalf("Expected error for other constraint violation, got %v, %v", imported, err)
+	}
+}
@@ -11,6 +11,7 @@ import (
 var (
 	ErrDuplicateDevice = errors.New("device with same IP and manufacturer already exists")
 	ErrDeviceNotFound  = errors.New("device not found")
+	ErrDeviceInUse     = errors.New("device is referenced by a recall case")
 )
 
 // CreateDevice creates a new device in the database
@@ -235,6 +236,8 @@ func UpdateDevice(id int, updates *Device) (*Device, error) {
 }
 
 // DeleteDevice deletes a device from the database
+// Cycles and status records are deleted with it. Returns ErrDeviceInUse if a recall case
+// refers to the device or its cycles, because the recall evidence must be kept.
 func DeleteDevice(id int) error {
 	if db == nil {
 		return fmt.Errorf("database not initialized")
@@ -246,11 +249,29 @@ func DeleteDevice(id int) error {
 		return err // Returns ErrDeviceNotFound if not found
 	}
 
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Checked in the same transaction, so no recall case can be opened in between
+	inUse, err := deviceHasRecallCases(tx, id)
+	if err != nil {
+		return err
+	}
+	if inUse {
+		return ErrDeviceInUse
+	}
+
 	// Delete device (CASCADE will handle cycles and rdg_status)
 	query := `DELETE FROM devices WHERE id = ?`
 
-	result, err := db.Exec(query, id)
+	result, err := tx.Exec(query, id)
 	if err != nil {
+		if isForeignKeyConstraintError(err) {
+			return ErrDeviceInUse
+		}
 		return fmt.Errorf("failed to delete device: %w", err)
 	}
 
@@ -264,9 +285,44 @@ func DeleteDevice(id int) error {
 		return ErrDeviceNotFound
 	}
 
+	if err := tx.Commit(); err != nil {
+		if isForeignKeyConstraintError(err) {
+			return ErrDeviceInUse
+		}
+		return fmt.Errorf("failed to commit device deletion: %w", err)
+	}
+
 	return nil
 }
 
+// HasRecallCases reports whether a recall case refers to the device or one of its cycles
+// Such a device cannot be deleted (see DeleteDevice).
+func HasRecallCases(deviceID int) (bool, error) {
+	if db == nil {
+		return false, fmt.Errorf("database not initialized")
+	}
+
+	return deviceHasRecallCases(db, deviceID)
+}
+
+// deviceHasRecallCases checks for recall cases of the device on the database or in a transaction
+func deviceHasRecallCases(q interface {
+	QueryRow(query string, args ...interface{}) *sql.Row
+}, deviceID int) (bool, error) {
+	query := `
+		SELECT EXISTS (SELECT 1 FROM recall_cases WHERE device_id = ?)
+		    OR EXISTS (SELECT 1 FROM recall_case_cycles rc
+		               JOIN cycles c ON rc.cycle_id = c.id
+		               WHERE c.device_id = ?)
+	`
+
+	var inUse bool
+	if err := q.QueryRow(query, deviceID, deviceID).Scan(&inUse); err != nil {
+		return false, fmt.Errorf("failed to check recall cases of device: %w", err)
+	}
+	return inUse, nil
+}
+
 // GetDeviceStatus retrieves the health and connection status of a device
 func GetDeviceStatus(id int) (*DeviceStatus, error) {
 	if db == nil {
@@ -279,67 +335,59 @@ func GetDeviceStatus(id int) (*DeviceStatus, error) {
 		return nil, err // Returns ErrDeviceNotFound if not found
 	}
 
-	// For MVP: Placeholder status calculation
-	// Real connection state will be implemented in Epic 3 (Device Integration)
-	status := &DeviceStatus{
-		DeviceID:     device.ID,
-		Manufacturer: device.Manufacturer,
-		IP:           device.IP,
-		Connected:    false,       // Placeholder - will be real in Epic 3
-		HealthStatus: "unhealthy", // Default, will be calculated below
+	// Connection state as last recorded by the device manager
+	connection, err := GetDeviceConnectionState(id)
+	if err == ErrConnectionStateNotFound {
+		connection = &DeviceConnectionState{DeviceID: id, State: ConnectionStateDisconnected, Since: device.Created}
+	} else if err != nil {
+		return nil, err
 	}
 
-	// Calculate health status based on last communication
-	// For MVP: Use device.Updated as proxy for last_seen
-	// In Epic 3, this will be actual last communication time
-	lastSeen := device.Updated
-	status.LastSeen = &lastSeen
-
-	// Calculate health status based on time since last update
-	timeSinceUpdate := time.Since(device.Updated)
-	if timeSinceUpdate < 5*time.Minute {
-		status.HealthStatus = "healthy"
-	} else if timeSinceUpdate < 15*time.Minute {
-		status.HealthStatus = "degraded"
-	} else {
-		status.HealthStatus = "unhealthy"
+	since := connection.Since
+	status := &DeviceStatus{
+		DeviceID:            device.ID,
+		Manufacturer:        device.Manufacturer,
+		IP:                  device.IP,
+		Connected:           connection.State == ConnectionStateConnected,
+		LastSeen:            connection.LastSeen,
+		HealthStatus:        DeviceHealthStatus(connection, time.Now()),
+		State:               connection.State,
+		StateSince:          &since,
+		LastError:           connection.LastError,
+		ConsecutiveFailures: connection.ConsecutiveFailures,
 	}
 
 	// Manufacturer-specific fields
 	if device.Manufacturer == "Melag" {
-		// Placeholder for Melag-specific status
-		// Will be populated in Epic 3 with actual MELAnet connection status
-		status.ConnectionType = "MELAnet" // Default assumption
-		status.LastCycleStatus = ""       // Will be populated from cycles table in future
+		status.ConnectionType = "MELAnet"
+		status.LastCycleStatus = "" // Will be populated from cycles table in future
 	} else if device.Manufacturer == "Getinge" {
-		// Placeholder for Getinge-specific status
-		// Will be populated in Epic 3 with actual ICMP ping results
-		status.ICMPReachable = false // Default, will be real in Epic 3
-		// LastPingTime will be populated from rdg_status table in Epic 3
+		// Result of the latest reachability probe
+		latest, err := GetLatestRDGStatus(id)
+		if err == nil {
+			status.ICMPReachable = latest.Reachable
+			lastPing := latest.Timestamp
+			status.LastPingTime = &lastPing
+		}
 	}
 
 	return status, nil
 }
 
-// UpdateDeviceConnectionStatus updates the connection status for a device (status stored in rdg_status table for Getinge)
-// This function updates the device's last_seen timestamp
-func UpdateDeviceConnectionStatus(deviceID int, connected bool) error {
-	if db == nil {
-		return fmt.Errorf("database not initialized")
-	}
-
-	// Update last_seen timestamp in devices table
-	// Connection status for Getinge devices is stored in rdg_status table
-	query := `
-		UPDATE devices
-		SET updated = CURRENT_TIMESTAMP
-		WHERE id = ?
-	`
-	_, err := db.Exec(query, deviceID)
-	if err != nil {
-		return fmt.Errorf("failed to update device connection status: %w", err)
+// DeviceHealthStatus derives the health status from a connection state
+// Connected devices are healthy; devices seen within the last 15 minutes or
+// currently connecting are degraded; all others are unhealthy.
+func DeviceHealthStatus(state *DeviceConnectionState, now time.Time) string {
+	switch {
+	case state.State == ConnectionStateConnected:
+		return "healthy"
+	case state.State == ConnectionStateConnecting:
+		return "degraded"
+	case state.LastSeen != nil && now.Sub(*state.LastSeen) < 15*time.Minute:
+		return "degraded"
+	default:
+		return "unhealthy"
 	}
-	return nil
 }
 
 // UpdateDeviceLastSeen updates the last seen timestamp for a device
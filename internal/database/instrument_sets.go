@@ -0,0 +1,202 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+var (
+	ErrInstrumentSetNotFound  = errors.New("instrument set not found")
+	ErrDuplicateInstrumentSet = errors.New("instrument set with same code already exists")
+	ErrInstrumentSetInUse     = errors.New("instrument set is part of a cycle load")
+)
+
+// CreateInstrumentSet creates a new instrument set or tray
+func CreateInstrumentSet(set *InstrumentSet) (*InstrumentSet, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	now := time.Now()
+	query := `
+		INSERT INTO instrument_sets (code, name, kind, description, created, updated)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	result, err := db.Exec(query, set.Code, set.Name, set.Kind, set.Description, now, now)
+	if err != nil {
+		if isUniqueConstraintError(err) {
+			return nil, ErrDuplicateInstrumentSet
+		}
+		return nil, fmt.Errorf("failed to create instrument set: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get instrument set ID: %w", err)
+	}
+
+	return GetInstrumentSet(int(id))
+}
+
+// GetInstrumentSet retrieves an instrument set by ID
+func GetInstrumentSet(id int) (*InstrumentSet, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	query := `
+		SELECT id, code, name, kind, description, created, updated
+		FROM instrument_sets
+		WHERE id = ?
+	`
+
+	set, err := scanInstrumentSet(db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrInstrumentSetNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get instrument set: %w", err)
+	}
+
+	return set, nil
+}
+
+// GetInstrumentSets retrieves all instrument sets ordered by code
+// A non-empty code returns only the set with that code (e.g. a scanned barcode).
+func GetInstrumentSets(code string) ([]InstrumentSet, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	query := `
+		SELECT id, code, name, kind, description, created, updated
+		FROM instrument_sets
+	`
+	args := []interface{}{}
+	if code != "" {
+		query += " WHERE code = ?"
+		args = append(args, code)
+	}
+	query += " ORDER BY code"
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query instrument sets: %w", err)
+	}
+	defer rows.Close()
+
+	sets := []InstrumentSet{}
+	for rows.Next() {
+		set, err := scanInstrumentSet(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan instrument set: %w", err)
+		}
+		sets = append(sets, *set)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating instrument sets: %w", err)
+	}
+
+	return sets, nil
+}
+
+// UpdateInstrumentSet saves code, name, kind and description of an instrument set
+func UpdateInstrumentSet(set *InstrumentSet) (*InstrumentSet, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	query := `
+		UPDATE instrument_sets
+		SET code = ?, name = ?, kind = ?, description = ?, updated = ?
+		WHERE id = ?
+	`
+
+	result, err := db.Exec(query, set.Code, set.Name, set.Kind, set.Description, time.Now(), set.ID)
+	if err != nil {
+		if isUniqueConstraintError(err) {
+			return nil, ErrDuplicateInstrumentSet
+		}
+		return nil, fmt.Errorf("failed to update instrument set: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to check update result: %w", err)
+	}
+	if rowsAffected == 0 {
+		return nil, ErrInstrumentSetNotFound
+	}
+
+	return GetInstrumentSet(set.ID)
+}
+
+// DeleteInstrumentSet deletes an instrument set that was never part of a load
+// Sets with load history are kept for traceability (ErrInstrumentSetInUse).
+func DeleteInstrumentSet(id int) error {
+	if db == nil {
+		return fmt.Errorf("database not initialized")
+	}
+
+	var loads int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM cycle_loads WHERE set_id = ?`, id).Scan(&loads); err != nil {
+		return fmt.Errorf("failed to check instrument set loads: %w", err)
+	}
+	if loads > 0 {
+		return ErrInstrumentSetInUse
+	}
+
+	result, err := db.Exec(`DELETE FROM instrument_sets WHERE id = ?`, id)
+	if err != nil {
+		return fmt.Errorf("failed to delete instrument set: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return fmt.Errorf("failed to check delete result: %w", err)
+	}
+	if rowsAffected == 0 {
+		return ErrInstrumentSetNotFound
+	}
+
+	return nil
+}
+
+// scanInstrumentSet reads one instrument_sets row
+func scanInstrumentSet(row interface {
+	Scan(dest ...interface{}) error
+}) (*InstrumentSet, error) {
+	var set InstrumentSet
+	var description sql.NullString
+
+	err := row.Scan(
+		&set.ID,
+		&set.Code,
+		&set.Name,
+		&set.Kind,
+		&description,
+		&set.Created,
+		&set.Updated,
+	)
+	if err != nil {
+		return nil, err
+	}
+	set.Description = description.String
+
+	return &set, nil
+}
+
+// isUniqueConstraintError reports whether err is an SQLite UNIQUE constraint violation
+func isUniqueConstraintError(err error) bool {
+	return strings.Contains(err.Error(), "UNIQUE constraint failed")
+}
+
+// isForeignKeyConstraintError reports whether err is an SQLite FOREIGN KEY constraint violation
+func isForeignKeyConstraintError(err error) bool {
+	return strings.Contains(err.Error(), "FOREIGN KEY constraint failed")
+}
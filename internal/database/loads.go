@@ -0,0 +1,239 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+)
+
+var (
+	ErrLoadNotFound        = errors.New("load not found")
+	ErrLoadAlreadyAssigned = errors.New("instrument set already assigned to this cycle")
+	ErrLoadLocked          = errors.New("load of a released or rejected cycle cannot be changed")
+)
+
+// AssignLoad adds an instrument set to the load of a cycle
+// Returns ErrCycleNotFound or ErrInstrumentSetNotFound if either does not exist,
+// ErrLoadLocked if the cycle has already been released or rejected.
+func AssignLoad(load *CycleLoad) (*CycleLoad, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	if _, err := GetInstrumentSet(load.SetID); err != nil {
+		return nil, err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	// Checked in the same transaction, so a release cannot slip in between
+	if err := checkLoadEditable(tx, load.CycleID); err != nil {
+		return nil, err
+	}
+
+	query := `
+		INSERT INTO cycle_loads (cycle_id, set_id, quantity, operator, notes, assigned_at)
+		VALUES (?, ?, ?, ?, ?, ?)
+	`
+
+	result, err := tx.Exec(
+		query,
+		load.CycleID,
+		load.SetID,
+		load.Quantity,
+		load.Operator,
+		sql.NullString{String: load.Notes, Valid: load.Notes != ""},
+		time.Now(),
+	)
+	if err != nil {
+		if isUniqueConstraintError(err) {
+			return nil, ErrLoadAlreadyAssigned
+		}
+		return nil, fmt.Errorf("failed to assign load: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get load ID: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit load: %w", err)
+	}
+
+	return GetCycleLoad(int(id))
+}
+
+// GetCycleLoad retrieves a load entry by ID
+func GetCycleLoad(id int) (*CycleLoad, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	query := `
+		SELECT l.id, l.cycle_id, l.set_id, s.code, s.name, s.kind, l.quantity, l.operator, l.notes, l.assigned_at
+		FROM cycle_loads l
+		JOIN instrument_sets s ON l.set_id = s.id
+		WHERE l.id = ?
+	`
+
+	load, err := scanCycleLoad(db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrLoadNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get load: %w", err)
+	}
+
+	return load, nil
+}
+
+// GetCycleLoads retrieves the instrument sets in the load of a cycle
+func GetCycleLoads(cycleID int) ([]CycleLoad, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	query := `
+		SELECT l.id, l.cycle_id, l.set_id, s.code, s.name, s.kind, l.quantity, l.operator, l.notes, l.assigned_at
+		FROM cycle_loads l
+		JOIN instrument_sets s ON l.set_id = s.id
+		WHERE l.cycle_id = ?
+		ORDER BY l.assigned_at, l.id
+	`
+
+	return queryCycleLoads(query, cycleID)
+}
+
+// GetInstrumentSetLoads retrieves all load entries of an instrument set, newest first
+func GetInstrumentSetLoads(setID int) ([]CycleLoad, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	query := `
+		SELECT l.id, l.cycle_id, l.set_id, s.code, s.name, s.kind, l.quantity, l.operator, l.notes, l.assigned_at
+		FROM cycle_loads l
+		JOIN instrument_sets s ON l.set_id = s.id
+		WHERE l.set_id = ?
+		ORDER BY l.assigned_at DESC, l.id DESC
+	`
+
+	return queryCycleLoads(query, setID)
+}
+
+// RemoveCycleLoad removes a load entry from a cycle and returns the removed entry
+// Returns ErrLoadLocked if the cycle has already been released or rejected.
+func RemoveCycleLoad(cycleID, loadID int) (*CycleLoad, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	query := `
+		SELECT l.id, l.cycle_id, l.set_id, s.code, s.name, s.kind, l.quantity, l.operator, l.notes, l.assigned_at
+		FROM cycle_loads l
+		JOIN instrument_sets s ON l.set_id = s.id
+		WHERE l.id = ? AND l.cycle_id = ?
+	`
+
+	load, err := scanCycleLoad(tx.QueryRow(query, loadID, cycleID))
+	if err == sql.ErrNoRows {
+		return nil, ErrLoadNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get load: %w", err)
+	}
+
+	if err := checkLoadEditable(tx, cycleID); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.Exec(`DELETE FROM cycle_loads WHERE id = ?`, loadID); err != nil {
+		return nil, fmt.Errorf("failed to remove load: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit load removal: %w", err)
+	}
+
+	return load, nil
+}
+
+// checkLoadEditable returns ErrLoadLocked once the release decision for the cycle is made
+func checkLoadEditable(tx *sql.Tx, cycleID int) error {
+	var status sql.NullString
+	err := tx.QueryRow(`SELECT release_status FROM cycles WHERE id = ?`, cycleID).Scan(&status)
+	if err == sql.ErrNoRows {
+		return ErrCycleNotFound
+	}
+	if err != nil {
+		return fmt.Errorf("failed to get cycle release status: %w", err)
+	}
+
+	if status.String == ReleaseStatusReleased || status.String == ReleaseStatusRejected {
+		return ErrLoadLocked
+	}
+	return nil
+}
+
+// queryCycleLoads runs a cycle_loads query and reads all rows
+func queryCycleLoads(query string, args ...interface{}) ([]CycleLoad, error) {
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query loads: %w", err)
+	}
+	defer rows.Close()
+
+	loads := []CycleLoad{}
+	for rows.Next() {
+		load, err := scanCycleLoad(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan load: %w", err)
+		}
+		loads = append(loads, *load)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating loads: %w", err)
+	}
+
+	return loads, nil
+}
+
+// scanCycleLoad reads one cycle_loads row joined with its instrument set
+func scanCycleLoad(row interface {
+	Scan(dest ...interface{}) error
+}) (*CycleLoad, error) {
+	var load CycleLoad
+	var notes sql.NullString
+
+	err := row.Scan(
+		&load.ID,
+		&load.CycleID,
+		&load.SetID,
+		&load.SetCode,
+		&load.SetName,
+		&load.SetKind,
+		&load.Quantity,
+		&load.Operator,
+		&notes,
+		&load.AssignedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	load.Notes = notes.String
+
+	return &load, nil
+}
@@ -0,0 +1,136 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCycleLoadTraceability(t *testing.T) {
+	if err := InitializeDatabase(filepath.Join(t.TempDir(), "loads.db")); err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer Close()
+
+	device, err := CreateDevice(&Device{Name: "Steri 1", Manufacturer: "Melag", IP: "192.0.2.10", Type: "Steri"})
+	if err != nil {
+		t.Fatalf("Failed to create device: %v", err)
+	}
+	first, err := CreateCycle(&Cycle{DeviceID: device.ID, Program: "134 Universal", StartTS: time.Now().Add(-2 * time.Hour)})
+	if err != nil {
+		t.Fatalf("Failed to create cycle: %v", err)
+	}
+	second, err := CreateCycle(&Cycle{DeviceID: device.ID, Program: "134 Universal", StartTS: time.Now().Add(-time.Hour)})
+	if err != nil {
+		t.Fatalf("Failed to create cycle: %v", err)
+	}
+	set, err := CreateInstrumentSet(&InstrumentSet{Code: "TRAY-001", Name: "Basic surgery", Kind: "tray"})
+	if err != nil {
+		t.Fatalf("Failed to create instrument set: %v", err)
+	}
+
+	if _, err := CreateInstrumentSet(&InstrumentSet{Code: "TRAY-001", Name: "Duplicate", Kind: "tray"}); err != ErrDuplicateInstrumentSet {
+		t.Errorf("Expected ErrDuplicateInstrumentSet, got %v", err)
+	}
+
+	load, err := AssignLoad(&CycleLoad{CycleID: first.ID, SetID: set.ID, Quantity: 2, Operator: "mmueller"})
+	if err != nil {
+		t.Fatalf("Failed to assign load: %v", err)
+	}
+	if load.SetCode != "TRAY-001" || load.Quantity != 2 {
+		t.Errorf("Unexpected load: %+v", load)
+	}
+	if _, err := AssignLoad(&CycleLoad{CycleID: first.ID, SetID: set.ID, Quantity: 1, Operator: "mmueller"}); err != ErrLoadAlreadyAssigned {
+		t.Errorf("Expected ErrLoadAlreadyAssigned, got %v", err)
+	}
+	if _, err := AssignLoad(&CycleLoad{CycleID: second.ID, SetID: set.ID, Quantity: 1, Operator: "mmueller"}); err != nil {
+		t.Fatalf("Failed to assign load: %v", err)
+	}
+	if _, err := AssignLoad(&CycleLoad{CycleID: 999, SetID: set.ID, Quantity: 1, Operator: "mmueller"}); err != ErrCycleNotFound {
+		t.Errorf("Expected ErrCycleNotFound, got %v", err)
+	}
+
+	// Cycle -> sets
+	loads, err := GetCycleLoads(first.ID)
+	if err != nil || len(loads) != 1 || loads[0].SetID != set.ID {
+		t.Errorf("Unexpected cycle loads: %+v, %v", loads, err)
+	}
+
+	// Set -> cycles
+	cycles, total, err := GetAllCycles(CycleListOptions{SetID: &set.ID})
+	if err != nil || total != 2 || len(cycles) != 2 || cycles[0].ID != second.ID {
+		t.Errorf("Expected both cycles newest first, got %d cycles (total %d), %v", len(cycles), total, err)
+	}
+
+	if err := DeleteInstrumentSet(set.ID); err != ErrInstrumentSetInUse {
+		t.Errorf("Expected ErrInstrumentSetInUse, got %v", err)
+	}
+
+	if _, err := RemoveCycleLoad(second.ID, load.ID); err != ErrLoadNotFound {
+		t.Errorf("Expected ErrLoadNotFound for load of another cycle, got %v", err)
+	}
+	if _, err := RemoveCycleLoad(first.ID, load.ID); err != nil {
+		t.Errorf("Failed to remove load: %v", err)
+	}
+	if loads, _ := GetCycleLoads(first.ID); len(loads) != 0 {
+		t.Errorf("Expected empty load after removal, got %+v", loads)
+	}
+}
+
+func TestCycleLoadLockedAfterReleaseDecision(t *testing.T) {
+	if err := InitializeDatabase(filepath.Join(t.TempDir(), "loads.db")); err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer Close()
+
+	device, err := CreateDevice(&Device{Name: "Steri 1", Manufacturer: "Melag", IP: "192.0.2.10", Type: "Steri"})
+	if err != nil {
+		t.Fatalf("Failed to create device: %v", err)
+	}
+	tray, err := CreateInstrumentSet(&InstrumentSet{Code: "TRAY-001", Name: "Basic surgery", Kind: "tray"})
+	if err != nil {
+		t.Fatalf("Failed to create instrument set: %v", err)
+	}
+	spare, err := CreateInstrumentSet(&InstrumentSet{Code: "TRAY-002", Name: "Extraction", Kind: "tray"})
+	if err != nil {
+		t.Fatalf("Failed to create instrument set: %v", err)
+	}
+
+	for _, decision := range []string{ReleaseStatusReleased, ReleaseStatusRejected} {
+		cycle, err := CreateCycle(&Cycle{DeviceID: device.ID, Program: "134 Universal", StartTS: time.Now().Add(-time.Hour)})
+		if err != nil {
+			t.Fatalf("Failed to create cycle: %v", err)
+		}
+		if err := UpdateCycleResult(cycle.ID, "OK", time.Now(), nil, nil); err != nil {
+			t.Fatalf("Failed to end cycle: %v", err)
+		}
+		load, err := AssignLoad(&CycleLoad{CycleID: cycle.ID, SetID: tray.ID, Quantity: 1, Operator: "mmueller"})
+		if err != nil {
+			t.Fatalf("Failed to assign load: %v", err)
+		}
+
+		// Pending release does not lock the load yet
+		if _, err := TransitionCycleRelease(&CycleRelease{CycleID: cycle.ID, ToStatus: ReleaseStatusPending, User: "a.schmidt"}); err != nil {
+			t.Fatalf("Failed to submit cycle: %v", err)
+		}
+		if _, err := AssignLoad(&CycleLoad{CycleID: cycle.ID, SetID: spare.ID, Quantity: 1, Operator: "mmueller"}); err != nil {
+			t.Fatalf("Failed to assign load while pending: %v", err)
+		}
+
+		release := &CycleRelease{CycleID: cycle.ID, ToStatus: decision, User: "a.schmidt", Reason: "Packaging wet",
+			Checklist: &ReleaseChecklist{ChemicalIndicator: true, PackagingIntact: true, Dry: true}}
+		if _, err := TransitionCycleRelease(release); err != nil {
+			t.Fatalf("Failed to decide release: %v", err)
+		}
+
+		if _, err := AssignLoad(&CycleLoad{CycleID: cycle.ID, SetID: spare.ID, Quantity: 2, Operator: "mmueller"}); err != ErrLoadLocked {
+			t.Errorf("Expected ErrLoadLocked assigning to %s cycle, got %v", decision, err)
+		}
+		if _, err := RemoveCycleLoad(cycle.ID, load.ID); err != ErrLoadLocked {
+			t.Errorf("Expected ErrLoadLocked removing from %s cycle, got %v", decision, err)
+		}
+		if loads, _ := GetCycleLoads(cycle.ID); len(loads) != 2 {
+			t.Errorf("Expected load of %s cycle unchanged, got %+v", decision, loads)
+		}
+	}
+}
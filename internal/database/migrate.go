@@ -0,0 +1,311 @@
+package database
+
+import (
+	"crypto/sha256"
+	"database/sql"
+	"embed"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Migration files are named NNN_description.sql and applied in version order.
+// Applied migrations must never be edited; schema changes go into a new file.
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// ErrSchemaTooNew is returned when the database was migrated by a newer version of the application
+var ErrSchemaTooNew = errors.New("database schema is newer than this application")
+
+// Migration states reported by MigrationStatus
+const (
+	MigrationApplied  = "applied"
+	MigrationPending  = "pending"
+	MigrationModified = "modified" // Applied, but the embedded file has changed since
+	MigrationUnknown  = "unknown"  // Applied by a newer application, not embedded in this binary
+)
+
+// Migration is an embedded schema migration file
+type Migration struct {
+	Version  int
+	Name     string
+	SQL      string
+	Checksum string
+}
+
+// MigrationState is the state of one migration in the database
+type MigrationState struct {
+	Version   int        `json:"version"`
+	Name      string     `json:"name"`
+	State     string     `json:"state"`
+	Checksum  string     `json:"checksum"`
+	AppliedAt *time.Time `json:"applied_at,omitempty"`
+}
+
+// lastUnversionedMigration is the last migration that runMigrations applied before schema_migrations
+// existed. Databases from that time may already have the columns these migrations add.
+const lastUnversionedMigration = 10
+
+// addColumnPattern matches ALTER TABLE ... ADD COLUMN statements (not idempotent in SQLite)
+var addColumnPattern = regexp.MustCompile(`(?im)^\s*ALTER\s+TABLE\s+(\w+)\s+ADD\s+COLUMN\s+(\w+)[^;]*;`)
+
+// loadMigrations reads the embedded migration files, ordered by version
+func loadMigrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, fmt.Errorf("failed to read migrations: %w", err)
+	}
+
+	var migrations []Migration
+	seen := make(map[int]string)
+	for _, entry := range entries {
+		name := entry.Name()
+		prefix, rest, ok := strings.Cut(name, "_")
+		version, err := strconv.Atoi(prefix)
+		if !ok || err != nil || version <= 0 {
+			return nil, fmt.Errorf("invalid migration file name %s: expected NNN_description.sql", name)
+		}
+		if other, exists := seen[version]; exists {
+			return nil, fmt.Errorf("duplicate migration version %d: %s and %s", version, other, name)
+		}
+		seen[version] = name
+
+		content, err := migrationFiles.ReadFile(path.Join("migrations", name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", name, err)
+		}
+		text := normalizeLineEndings(content)
+		migrations = append(migrations, Migration{
+			Version:  version,
+			Name:     strings.TrimSuffix(rest, ".sql"),
+			SQL:      text,
+			Checksum: migrationChecksum(text),
+		})
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// normalizeLineEndings converts CRLF to LF, so a checkout with Windows line endings
+// has the same checksums as the one the migrations were applied from
+func normalizeLineEndings(content []byte) string {
+	return strings.ReplaceAll(string(content), "\r\n", "\n")
+}
+
+// migrationChecksum returns the checksum recorded for an applied migration
+func migrationChecksum(text string) string {
+	sum := sha256.Sum256([]byte(text))
+	return hex.EncodeToString(sum[:])
+}
+
+// runMigrations applies all pending migrations, each in its own transaction
+func runMigrations() error {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+
+	if _, err := db.Exec(`
+		CREATE TABLE IF NOT EXISTS schema_migrations (
+			version INTEGER PRIMARY KEY,
+			name TEXT NOT NULL,
+			checksum TEXT NOT NULL,
+			applied_at DATETIME NOT NULL
+		)
+	`); err != nil {
+		return fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	applied, err := appliedMigrations()
+	if err != nil {
+		return err
+	}
+	if err := checkMigrations(migrations, applied); err != nil {
+		return err
+	}
+
+	for _, m := range migrations {
+		if _, ok := applied[m.Version]; ok {
+			continue
+		}
+		if err := applyMigration(m); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// checkMigrations refuses a schema from a newer application and changed migration files
+func checkMigrations(migrations []Migration, applied map[int]MigrationState) error {
+	known := make(map[int]Migration, len(migrations))
+	latest := 0
+	for _, m := range migrations {
+		known[m.Version] = m
+		latest = m.Version
+	}
+
+	for version, state := range applied {
+		m, ok := known[version]
+		if !ok {
+			return fmt.Errorf("%w: migration %d (%s) is applied, this application supports schema version %d",
+				ErrSchemaTooNew, version, state.Name, latest)
+		}
+		if m.Checksum != state.Checksum {
+			return fmt.Errorf("migration %d (%s) was changed after it was applied (checksum %s, expected %s)",
+				version, m.Name, m.Checksum, state.Checksum)
+		}
+	}
+	return nil
+}
+
+// applyMigration runs one migration and records it in schema_migrations
+func applyMigration(m Migration) error {
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin migration %d: %w", m.Version, err)
+	}
+	defer tx.Rollback()
+
+	script := m.SQL
+	if m.Version <= lastUnversionedMigration {
+		script, err = skipExistingColumns(tx, script)
+		if err != nil {
+			return fmt.Errorf("failed to prepare migration %d (%s): %w", m.Version, m.Name, err)
+		}
+	}
+
+	if _, err := tx.Exec(script); err != nil {
+		return fmt.Errorf("failed to apply migration %d (%s): %w", m.Version, m.Name, err)
+	}
+	if _, err := tx.Exec(`INSERT INTO schema_migrations (version, name, checksum, applied_at) VALUES (?, ?, ?, ?)`,
+		m.Version, m.Name, m.Checksum, time.Now().UTC().Round(0)); err != nil {
+		return fmt.Errorf("failed to record migration %d (%s): %w", m.Version, m.Name, err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit migration %d (%s): %w", m.Version, m.Name, err)
+	}
+	return nil
+}
+
+// skipExistingColumns removes ALTER TABLE ... ADD COLUMN statements for columns that exist
+func skipExistingColumns(tx *sql.Tx, script string) (string, error) {
+	var inspectErr error
+	script = addColumnPattern.ReplaceAllStringFunc(script, func(statement string) string {
+		match := addColumnPattern.FindStringSubmatch(statement)
+		exists, err := columnExists(tx, match[1], match[2])
+		if err != nil {
+			inspectErr = err
+			return statement
+		}
+		if exists {
+			return ""
+		}
+		return statement
+	})
+	return script, inspectErr
+}
+
+// columnExists reports whether a table has a column
+func columnExists(tx *sql.Tx, table, column string) (bool, error) {
+	var count int
+	err := tx.QueryRow(`SELECT COUNT(*) FROM pragma_table_info(?) WHERE name = ?`, table, column).Scan(&count)
+	if err != nil {
+		return false, fmt.Errorf("failed to inspect table %s: %w", table, err)
+	}
+	return count > 0, nil
+}
+
+// appliedMigrations returns the migrations recorded in schema_migrations by version
+func appliedMigrations() (map[int]MigrationState, error) {
+	rows, err := db.Query(`SELECT version, name, checksum, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query schema_migrations: %w", err)
+	}
+	defer rows.Close()
+
+	applied := make(map[int]MigrationState)
+	for rows.Next() {
+		var state MigrationState
+		var appliedAt time.Time
+		if err := rows.Scan(&state.Version, &state.Name, &state.Checksum, &appliedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan schema_migrations: %w", err)
+		}
+		state.State = MigrationApplied
+		state.AppliedAt = &appliedAt
+		applied[state.Version] = state
+	}
+	return applied, rows.Err()
+}
+
+// MigrationStatus returns the state of all embedded and applied migrations, ordered by version
+// It does not change the database; a database without schema_migrations has all migrations pending.
+func MigrationStatus() ([]MigrationState, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	var hasTable int
+	if err := db.QueryRow(`SELECT COUNT(*) FROM sqlite_master WHERE type = 'table' AND name = 'schema_migrations'`).Scan(&hasTable); err != nil {
+		return nil, fmt.Errorf("failed to inspect schema: %w", err)
+	}
+	applied := make(map[int]MigrationState)
+	if hasTable > 0 {
+		applied, err = appliedMigrations()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var states []MigrationState
+	for _, m := range migrations {
+		state, ok := applied[m.Version]
+		delete(applied, m.Version)
+		if !ok {
+			states = append(states, MigrationState{Version: m.Version, Name: m.Name, State: MigrationPending, Checksum: m.Checksum})
+			continue
+		}
+		if state.Checksum != m.Checksum {
+			state.State = MigrationModified
+		}
+		states = append(states, state)
+	}
+	for _, state := range applied {
+		state.State = MigrationUnknown
+		states = append(states, state)
+	}
+
+	sort.Slice(states, func(i, j int) bool {
+		return states[i].Version < states[j].Version
+	})
+	return states, nil
+}
+
+// SchemaVersion returns the highest applied migration version (0 for an empty database)
+func SchemaVersion() (int, error) {
+	if db == nil {
+		return 0, fmt.Errorf("database not initialized")
+	}
+
+	var version sql.NullInt64
+	if err := db.QueryRow(`SELECT MAX(version) FROM schema_migrations`).Scan(&version); err != nil {
+		return 0, fmt.Errorf("failed to query schema version: %w", err)
+	}
+	return int(version.Int64), nil
+}
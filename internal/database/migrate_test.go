@@ -0,0 +1,128 @@
+package database
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestRunMigrations(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "migrate.db")
+	if err := InitializeDatabase(dbPath); err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+
+	migrations, err := loadMigrations()
+	if err != nil {
+		t.Fatalf("loadMigrations failed: %v", err)
+	}
+	latest := migrations[len(migrations)-1].Version
+
+	version, err := SchemaVersion()
+	if err != nil {
+		t.Fatalf("SchemaVersion failed: %v", err)
+	}
+	if version != latest {
+		t.Fatalf("Expected schema version %d, got %d", latest, version)
+	}
+	if err := UpdateDeviceLastSeen(1); err != nil {
+		t.Fatalf("UpdateDeviceLastSeen failed: %v", err)
+	}
+
+	// Reopening applies nothing
+	Close()
+	if err := InitializeDatabase(dbPath); err != nil {
+		t.Fatalf("Failed to reopen database: %v", err)
+	}
+
+	// Migration from a newer application
+	if _, err := db.Exec(`INSERT INTO schema_migrations (version, name, checksum, applied_at) VALUES (?, 'future', 'x', CURRENT_TIMESTAMP)`, latest+1); err != nil {
+		t.Fatalf("Failed to insert migration: %v", err)
+	}
+	states, err := MigrationStatus()
+	if err != nil {
+		t.Fatalf("MigrationStatus failed: %v", err)
+	}
+	if len(states) != len(migrations)+1 || states[len(states)-1].State != MigrationUnknown || states[0].State != MigrationApplied {
+		t.Fatalf("Unexpected migration status %+v", states)
+	}
+	Close()
+	if err := InitializeDatabase(dbPath); !errors.Is(err, ErrSchemaTooNew) {
+		t.Fatalf("Expected ErrSchemaTooNew, got %v", err)
+	}
+
+	if _, err := db.Exec(`DELETE FROM schema_migrations WHERE version = ?`, latest+1); err != nil {
+		t.Fatalf("Failed to delete migration: %v", err)
+	}
+	if _, err := db.Exec(`UPDATE schema_migrations SET checksum = 'changed' WHERE version = 2`); err != nil {
+		t.Fatalf("Failed to change checksum: %v", err)
+	}
+	Close()
+	if err := InitializeDatabase(dbPath); err == nil {
+		t.Fatal("Expected error for changed migration")
+	}
+	Close()
+}
+
+func TestRunMigrationsUnversionedDatabase(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "legacy.db")
+	if err := OpenDatabase(dbPath); err != nil {
+		t.Fatalf("Failed to open database: %v", err)
+	}
+
+	// Schema as created by runMigrations before schema_migrations existed
+	migrations, err := loadMigrations()
+	if err != nil {
+		t.Fatalf("loadMigrations failed: %v", err)
+	}
+	for _, m := range migrations {
+		if m.Version > lastUnversionedMigration {
+			break
+		}
+		if _, err := db.Exec(m.SQL); err != nil {
+			t.Fatalf("Failed to apply migration %s: %v", m.Name, err)
+		}
+	}
+	states, err := MigrationStatus()
+	if err != nil {
+		t.Fatalf("MigrationStatus failed: %v", err)
+	}
+	if len(states) != len(migrations) || states[0].State != MigrationPending {
+		t.Fatalf("Expected all migrations pending, got %+v", states)
+	}
+	Close()
+
+	if err := InitializeDatabase(dbPath); err != nil {
+		t.Fatalf("Failed to migrate unversioned database: %v", err)
+	}
+	defer Close()
+
+	states, err = MigrationStatus()
+	if err != nil {
+		t.Fatalf("MigrationStatus failed: %v", err)
+	}
+	for _, state := range states {
+		if state.State != MigrationApplied || state.AppliedAt == nil {
+			t.Fatalf("Expected all migrations applied, got %+v", state)
+		}
+	}
+}
+
+func TestMigrationChecksumIgnoresLineEndings(t *testing.T) {
+	lf := normalizeLineEndings([]byte("CREATE TABLE a (id INTEGER);\nCREATE INDEX idx_a ON a (id);\n"))
+	crlf := normalizeLineEndings([]byte("CREATE TABLE a (id INTEGER);\r\nCREATE INDEX idx_a ON a (id);\r\n"))
+	if lf != crlf || migrationChecksum(lf) != migrationChecksum(crlf) {
+		t.Errorf("Expected the same migration and checksum for LF and CRLF line endings")
+	}
+}
+
+func TestOpenDatabaseReadOnlyDoesNotCreateDatabase(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "data", "missing.db")
+	if err := OpenDatabaseReadOnly(dbPath); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("Expected os.ErrNotExist for a missing database, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Dir(dbPath)); !errors.Is(err, os.ErrNotExist) {
+		t.Errorf("Database directory must not be created, stat: %v", err)
+	}
+}
@@ -2,11 +2,8 @@
 -- Story: 1.2 - SQLite Database Setup and Schema
 -- Created: 2025-11-21
 
--- Enable foreign key constraints
-PRAGMA foreign_keys = ON;
-
--- Enable WAL mode (Write-Ahead-Logging) for better concurrency
-PRAGMA journal_mode = WAL;
+-- Foreign key constraints and WAL mode are enabled per connection by OpenDatabase
+-- (PRAGMA journal_mode cannot be changed inside the migration transaction).
 
 -- Devices table
 CREATE TABLE IF NOT EXISTS devices (
@@ -0,0 +1,9 @@
+-- Device Cycle Number Migration
+-- Adds the cycle number assigned by the device, used to de-duplicate cycles
+-- imported from the MELAnet Box protocol archive.
+-- Skipped for columns that already exist in databases from before schema_migrations.
+
+ALTER TABLE cycles ADD COLUMN device_cycle_number TEXT;
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_cycles_device_cycle_number
+	ON cycles(device_id, device_cycle_number) WHERE device_cycle_number IS NOT NULL;
@@ -0,0 +1,17 @@
+-- Device Connection Settings Migration
+-- Per-device connection parameters for device adapters (port, credentials, timeout,
+-- FTP data connection mode, TLS). The password is stored AES-GCM encrypted in
+-- `secret` ("v1:" + base64(nonce || ciphertext)); the key lives outside the database.
+
+CREATE TABLE IF NOT EXISTS device_connection_settings (
+    device_id INTEGER PRIMARY KEY,
+    port INTEGER NOT NULL DEFAULT 0,  -- 0 = adapter default (21, 990 for implicit TLS)
+    username TEXT,
+    secret TEXT,  -- Encrypted password
+    timeout_seconds INTEGER NOT NULL DEFAULT 0,
+    ftp_mode TEXT,  -- 'passive' or 'pasv'
+    tls_mode TEXT,  -- 'none', 'explicit' or 'implicit'
+    tls_skip_verify INTEGER NOT NULL DEFAULT 0,  -- 0 or 1 (boolean)
+    updated DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (device_id) REFERENCES devices(id) ON DELETE CASCADE
+);
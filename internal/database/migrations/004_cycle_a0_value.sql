@@ -0,0 +1,6 @@
+-- Cycle A0 Value Migration
+-- Adds the A0 value (thermal disinfection dose per EN ISO 15883) reported by
+-- washer-disinfectors over Modbus TCP.
+-- Skipped for columns that already exist in databases from before schema_migrations.
+
+ALTER TABLE cycles ADD COLUMN a0_value REAL;
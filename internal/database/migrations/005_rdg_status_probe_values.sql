@@ -0,0 +1,7 @@
+-- RDG Status Probe Values Migration
+-- Adds the round-trip time (ms) and packet loss (percent) measured by the
+-- ICMP echo probe of Getinge devices.
+-- Skipped for columns that already exist in databases from before schema_migrations.
+
+ALTER TABLE rdg_status ADD COLUMN rtt_ms REAL;
+ALTER TABLE rdg_status ADD COLUMN packet_loss REAL;
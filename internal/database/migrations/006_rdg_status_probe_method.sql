@@ -0,0 +1,7 @@
+-- RDG Status Probe Method Migration
+-- Adds the number of probes sent and the probe method ("icmp", "icmp_raw", "tcp")
+-- of each reachability check, used by the reachability trend report.
+-- Skipped for columns that already exist in databases from before schema_migrations.
+
+ALTER TABLE rdg_status ADD COLUMN probes INTEGER;
+ALTER TABLE rdg_status ADD COLUMN method TEXT;
@@ -0,0 +1,29 @@
+-- Instrument Sets and Loads Migration
+-- Instrument sets/trays and their assignment to cycles (Beladungszuordnung, FR-011),
+-- so that "which cycle processed set X" and "which sets were in cycle Y" can be traced.
+-- Sets that were part of a load cannot be deleted (traceability).
+
+CREATE TABLE IF NOT EXISTS instrument_sets (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    code TEXT NOT NULL UNIQUE,  -- Set/tray identifier (e.g. barcode on the tray)
+    name TEXT NOT NULL,
+    kind TEXT NOT NULL DEFAULT 'set',  -- 'set' or 'tray'
+    description TEXT,
+    created DATETIME DEFAULT CURRENT_TIMESTAMP,
+    updated DATETIME DEFAULT CURRENT_TIMESTAMP
+);
+
+CREATE TABLE IF NOT EXISTS cycle_loads (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    cycle_id INTEGER NOT NULL,
+    set_id INTEGER NOT NULL,
+    quantity INTEGER NOT NULL DEFAULT 1,
+    operator TEXT NOT NULL,  -- Person who loaded the device
+    notes TEXT,
+    assigned_at DATETIME DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE (cycle_id, set_id),
+    FOREIGN KEY (cycle_id) REFERENCES cycles(id) ON DELETE CASCADE,
+    FOREIGN KEY (set_id) REFERENCES instrument_sets(id) ON DELETE RESTRICT
+);
+
+CREATE INDEX IF NOT EXISTS idx_cycle_loads_set_id ON cycle_loads(set_id);
@@ -0,0 +1,27 @@
+-- Cycle Release Migration
+-- Release workflow (Chargenfreigabe): a finished cycle moves from 'completed' to
+-- 'pending_release' and is then 'released' or 'rejected' by a named user.
+-- cycles.release_status holds the current state (NULL while the cycle is running);
+-- cycle_releases records every transition with the checklist of the release check.
+-- The column is skipped if it already exists in databases from before schema_migrations.
+
+ALTER TABLE cycles ADD COLUMN release_status TEXT;
+
+UPDATE cycles SET release_status = 'completed'
+WHERE release_status IS NULL AND result IS NOT NULL AND result != '';
+
+CREATE TABLE IF NOT EXISTS cycle_releases (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    cycle_id INTEGER NOT NULL,
+    from_status TEXT NOT NULL,
+    to_status TEXT NOT NULL,
+    user TEXT NOT NULL,  -- Person who made the decision
+    reason TEXT,  -- Required for rejections
+    chemical_indicator INTEGER,  -- Checklist: chemical indicator passed (1/0)
+    packaging_intact INTEGER,  -- Checklist: packaging intact (1/0)
+    dry INTEGER,  -- Checklist: load dry (1/0)
+    timestamp DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (cycle_id) REFERENCES cycles(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_cycle_releases_cycle_id ON cycle_releases(cycle_id);
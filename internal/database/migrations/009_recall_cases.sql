@@ -0,0 +1,44 @@
+-- Recall Cases Migration
+-- When a biological indicator fails, all cycles of the device since the last passed
+-- test cycle are affected. A recall case records that window, a snapshot of the
+-- affected cycles, QA notes and the closing decision.
+
+CREATE TABLE IF NOT EXISTS recall_cases (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    device_id INTEGER NOT NULL,
+    failed_cycle_id INTEGER NOT NULL,  -- Cycle with the failed indicator
+    last_passed_cycle_id INTEGER,  -- Last passed test cycle before it (NULL = none, window open)
+    window_start DATETIME,  -- Start of the last passed test cycle
+    window_end DATETIME NOT NULL,  -- Start of the failed cycle
+    status TEXT NOT NULL DEFAULT 'open',  -- 'open' or 'closed'
+    opened_by TEXT NOT NULL,
+    description TEXT,
+    created DATETIME DEFAULT CURRENT_TIMESTAMP,
+    closed_at DATETIME,
+    closed_by TEXT,
+    resolution TEXT,
+    FOREIGN KEY (device_id) REFERENCES devices(id),
+    FOREIGN KEY (failed_cycle_id) REFERENCES cycles(id),
+    FOREIGN KEY (last_passed_cycle_id) REFERENCES cycles(id)
+);
+
+-- Affected cycles as determined when the case was opened
+CREATE TABLE IF NOT EXISTS recall_case_cycles (
+    case_id INTEGER NOT NULL,
+    cycle_id INTEGER NOT NULL,
+    PRIMARY KEY (case_id, cycle_id),
+    FOREIGN KEY (case_id) REFERENCES recall_cases(id) ON DELETE CASCADE,
+    FOREIGN KEY (cycle_id) REFERENCES cycles(id)
+);
+
+CREATE TABLE IF NOT EXISTS recall_case_notes (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    case_id INTEGER NOT NULL,
+    user TEXT NOT NULL,
+    note TEXT NOT NULL,
+    created DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (case_id) REFERENCES recall_cases(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_recall_cases_device_id ON recall_cases(device_id);
+CREATE INDEX IF NOT EXISTS idx_recall_case_notes_case_id ON recall_case_notes(case_id);
@@ -0,0 +1,15 @@
+-- Audit Hash Chain Migration
+-- Each audit_log entry stores the hash of the previous entry (prev_hash, empty for the
+-- first entry); its own hash covers prev_hash, so changed, deleted or reordered entries
+-- break the chain (see VerifyAuditChain). Entries written before this migration have
+-- prev_hash NULL and cannot be verified.
+-- The column is skipped if it already exists in databases from before schema_migrations.
+
+ALTER TABLE audit_log ADD COLUMN prev_hash TEXT;
+
+-- Audit log entries are append-only
+CREATE TRIGGER IF NOT EXISTS audit_log_no_update BEFORE UPDATE ON audit_log
+BEGIN SELECT RAISE(ABORT, 'audit log entries cannot be changed'); END;
+
+CREATE TRIGGER IF NOT EXISTS audit_log_no_delete BEFORE DELETE ON audit_log
+BEGIN SELECT RAISE(ABORT, 'audit log entries cannot be deleted'); END;
@@ -0,0 +1,5 @@
+-- Device Last Seen Migration
+-- Time of the last successful communication with a device (see UpdateDeviceLastSeen),
+-- NULL until the device has been reached.
+
+ALTER TABLE devices ADD COLUMN last_seen DATETIME;
@@ -0,0 +1,15 @@
+-- Device Connection State Migration
+-- Current connection state of each device as seen by the device manager, updated on
+-- every connection attempt, probe and disconnect. Devices without a row have never
+-- been connected by the manager.
+
+CREATE TABLE IF NOT EXISTS device_connection_state (
+    device_id INTEGER PRIMARY KEY,
+    state TEXT NOT NULL,  -- 'DISCONNECTED', 'CONNECTING', 'CONNECTED' or 'ERROR'
+    last_seen DATETIME,  -- Last successful communication
+    last_error TEXT,  -- Error of the last failed attempt
+    consecutive_failures INTEGER NOT NULL DEFAULT 0,  -- Failed attempts since the last success
+    since DATETIME NOT NULL,  -- Time the device entered the current state
+    updated DATETIME DEFAULT CURRENT_TIMESTAMP,
+    FOREIGN KEY (device_id) REFERENCES devices(id) ON DELETE CASCADE
+);
@@ -0,0 +1,17 @@
+-- Device Connection History Migration
+-- Every change of a device's connection state (see device_connection_state), with
+-- what caused it, the error and how long the device was in the previous state.
+
+CREATE TABLE IF NOT EXISTS device_connection_history (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    device_id INTEGER NOT NULL,
+    from_state TEXT,  -- NULL for the first state recorded for the device
+    to_state TEXT NOT NULL,
+    reason TEXT NOT NULL,  -- 'connect', 'disconnect', 'probe' or 'shutdown'
+    error TEXT,
+    duration_ms INTEGER,  -- Time spent in from_state
+    timestamp DATETIME NOT NULL,
+    FOREIGN KEY (device_id) REFERENCES devices(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_device_connection_history_device ON device_connection_history(device_id, timestamp);
@@ -0,0 +1,5 @@
+-- Cycle Last Status Migration
+-- Time the device last reported the status of a running cycle (see UpdateCycleStatus),
+-- NULL until the first report. Used by the stale cycle watchdog.
+
+ALTER TABLE cycles ADD COLUMN last_status_at DATETIME;
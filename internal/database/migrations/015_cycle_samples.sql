@@ -0,0 +1,18 @@
+-- Cycle Samples Migration
+-- Process values of a cycle over time, one row per status read by the device manager
+-- (see cycles.temperature/pressure for the latest values only). extra holds further
+-- device-specific values as JSON, e.g. the A0 value of washer-disinfectors.
+
+CREATE TABLE IF NOT EXISTS cycle_samples (
+    id INTEGER PRIMARY KEY AUTOINCREMENT,
+    cycle_id INTEGER NOT NULL,
+    ts DATETIME NOT NULL,
+    phase TEXT,
+    temperature REAL,  -- °C
+    pressure REAL,  -- bar
+    progress_percent INTEGER,
+    extra TEXT,  -- JSON object, NULL if the device reported nothing else
+    FOREIGN KEY (cycle_id) REFERENCES cycles(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS idx_cycle_samples_cycle ON cycle_samples(cycle_id, ts);
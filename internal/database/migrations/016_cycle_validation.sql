@@ -0,0 +1,15 @@
+-- Cycle Validation Migration
+-- Parameter validation of a cycle's recorded process values against its program profile,
+-- independent of the result reported by the device. cycles.validation_result holds the
+-- verdict for listing and filtering, cycle_validations the reasons.
+
+ALTER TABLE cycles ADD COLUMN validation_result TEXT;  -- 'PASS', 'FAIL' or 'NOT_VALIDATED', NULL if never validated
+
+CREATE TABLE IF NOT EXISTS cycle_validations (
+    cycle_id INTEGER PRIMARY KEY,
+    result TEXT NOT NULL,
+    profile TEXT,  -- Name of the program profile, NULL if the program has none
+    reasons TEXT NOT NULL,  -- JSON array of {rule, passed, message}
+    validated_at DATETIME NOT NULL,
+    FOREIGN KEY (cycle_id) REFERENCES cycles(id) ON DELETE CASCADE
+);
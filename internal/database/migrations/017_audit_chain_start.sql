@@ -0,0 +1,22 @@
+-- Audit Chain Start Migration
+-- Audit log hashes are keyed (HMAC-SHA256 with a key derived from the secret key), so
+-- they cannot be recomputed with database access alone. audit_chain records the first
+-- entry of the keyed chain: every later entry must link to its predecessor, entries
+-- without prev_hash after it are reported as unchained. Earlier entries (without
+-- prev_hash or with unkeyed hashes) are legacy and cannot be verified.
+
+CREATE TABLE IF NOT EXISTS audit_chain (
+    id INTEGER PRIMARY KEY CHECK (id = 1),
+    start_id INTEGER NOT NULL,  -- First audit_log id of the keyed chain
+    started_at DATETIME NOT NULL
+);
+
+INSERT INTO audit_chain (id, start_id, started_at)
+SELECT 1, COALESCE((SELECT seq FROM sqlite_sequence WHERE name = 'audit_log'), (SELECT MAX(id) FROM audit_log), 0) + 1, CURRENT_TIMESTAMP;
+
+-- The chain start is fixed once recorded
+CREATE TRIGGER IF NOT EXISTS audit_chain_no_update BEFORE UPDATE ON audit_chain
+BEGIN SELECT RAISE(ABORT, 'audit chain start cannot be changed'); END;
+
+CREATE TRIGGER IF NOT EXISTS audit_chain_no_delete BEFORE DELETE ON audit_chain
+BEGIN SELECT RAISE(ABORT, 'audit chain start cannot be deleted'); END;
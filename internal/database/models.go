@@ -24,6 +24,10 @@ type DeviceStatus struct {
 	HealthStatus  string    `json:"health_status"` // "healthy", "degraded", "unhealthy"
 	Manufacturer  string    `json:"manufacturer"`
 	IP            string    `json:"ip"`
+	State               string     `json:"state"` // Connection state, see DeviceConnectionState
+	StateSince          *time.Time `json:"state_since,omitempty"`
+	LastError           string     `json:"last_error,omitempty"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
 	
 	// Melag-specific fields
 	ConnectionType   string    `json:"connection_type,omitempty"` // "MELAnet" or "Direct"
@@ -41,13 +45,45 @@ type Cycle struct {
 	Program          string     `json:"program,omitempty" db:"program"`
 	StartTS          time.Time  `json:"start_ts" db:"start_ts"`
 	EndTS            *time.Time `json:"end_ts,omitempty" db:"end_ts"`
-	Result           string     `json:"result,omitempty" db:"result"` // "OK" or "NOK"
+	Result           string     `json:"result,omitempty" db:"result"` // "OK", "NOK" or "UNKNOWN" (see CycleResultUnknown)
 	ErrorCode        string     `json:"error_code,omitempty" db:"error_code"`
 	ErrorDescription string     `json:"error_description,omitempty" db:"error_description"`
 	Phase            string     `json:"phase,omitempty" db:"phase"` // "Aufheizen", "Sterilisation", "Trocknung"
 	Temperature      *float64   `json:"temperature,omitempty" db:"temperature"`
 	Pressure         *float64   `json:"pressure,omitempty" db:"pressure"`
 	ProgressPercent  *int       `json:"progress_percent,omitempty" db:"progress_percent"`
+	DeviceCycleNumber string    `json:"device_cycle_number,omitempty" db:"device_cycle_number"` // Cycle number assigned by the device
+	A0Value          *float64   `json:"a0_value,omitempty" db:"a0_value"` // Disinfection A0 value (washer-disinfectors)
+	ReleaseStatus    string     `json:"release_status,omitempty" db:"release_status"` // "completed", "pending_release", "released", "rejected"
+	ValidationResult string     `json:"validation_result,omitempty" db:"validation_result"` // "PASS", "FAIL", "NOT_VALIDATED" (see CycleValidation)
+}
+
+// CycleSample represents the process values of a cycle at one point in time
+type CycleSample struct {
+	ID              int                    `json:"id" db:"id"`
+	CycleID         int                    `json:"cycle_id" db:"cycle_id"`
+	Timestamp       time.Time              `json:"ts" db:"ts"`
+	Phase           string                 `json:"phase,omitempty" db:"phase"`
+	Temperature     *float64               `json:"temperature,omitempty" db:"temperature"` // °C
+	Pressure        *float64               `json:"pressure,omitempty" db:"pressure"`       // bar
+	ProgressPercent *int                   `json:"progress_percent,omitempty" db:"progress_percent"`
+	Extra           map[string]interface{} `json:"extra,omitempty" db:"extra"` // Device-specific values, e.g. "a0_value"
+}
+
+// CycleValidation represents the check of a cycle's process values against its program profile
+type CycleValidation struct {
+	CycleID     int                `json:"cycle_id" db:"cycle_id"`
+	Result      string             `json:"result" db:"result"` // "PASS", "FAIL" or "NOT_VALIDATED"
+	Profile     string             `json:"profile,omitempty" db:"profile"`
+	Reasons     []ValidationReason `json:"reasons"`
+	ValidatedAt time.Time          `json:"validated_at" db:"validated_at"`
+}
+
+// ValidationReason is the outcome of one validation rule, e.g. the hold time
+type ValidationReason struct {
+	Rule    string `json:"rule"`
+	Passed  bool   `json:"passed"`
+	Message string `json:"message"`
 }
 
 // RDGStatus represents Getinge device reachability status
@@ -56,6 +92,81 @@ type RDGStatus struct {
 	DeviceID  int       `json:"device_id" db:"device_id"`
 	Timestamp time.Time `json:"timestamp" db:"timestamp"`
 	Reachable bool      `json:"reachable" db:"reachable"` // true = reachable, false = unreachable
+	RTTMs     *float64  `json:"rtt_ms,omitempty" db:"rtt_ms"`         // Average round-trip time (only if answered)
+	PacketLoss *float64 `json:"packet_loss,omitempty" db:"packet_loss"` // Unanswered probes in percent
+	Probes    *int      `json:"probes,omitempty" db:"probes"`           // Echo requests (or connection attempts) sent
+	Method    string    `json:"method,omitempty" db:"method"`           // "icmp", "icmp_raw" or "tcp"
+}
+
+// InstrumentSet represents an instrument set or tray that is reprocessed as one unit
+type InstrumentSet struct {
+	ID          int       `json:"id" db:"id"`
+	Code        string    `json:"code" db:"code"` // Unique identifier, e.g. barcode on the tray
+	Name        string    `json:"name" db:"name"`
+	Kind        string    `json:"kind" db:"kind"` // "set" or "tray"
+	Description string    `json:"description,omitempty" db:"description"`
+	Created     time.Time `json:"created" db:"created"`
+	Updated     time.Time `json:"updated" db:"updated"`
+}
+
+// CycleLoad represents an instrument set assigned to the load (Beladung) of a cycle
+type CycleLoad struct {
+	ID         int       `json:"id" db:"id"`
+	CycleID    int       `json:"cycle_id" db:"cycle_id"`
+	SetID      int       `json:"set_id" db:"set_id"`
+	SetCode    string    `json:"set_code"`
+	SetName    string    `json:"set_name"`
+	SetKind    string    `json:"set_kind"`
+	Quantity   int       `json:"quantity" db:"quantity"`
+	Operator   string    `json:"operator" db:"operator"` // Person who loaded the device
+	Notes      string    `json:"notes,omitempty" db:"notes"`
+	AssignedAt time.Time `json:"assigned_at" db:"assigned_at"`
+}
+
+// ReleaseChecklist holds the checks made before a cycle load is released
+type ReleaseChecklist struct {
+	ChemicalIndicator bool `json:"chemical_indicator"` // Chemical indicator changed color as expected
+	PackagingIntact   bool `json:"packaging_intact"`
+	Dry               bool `json:"dry"`
+}
+
+// CycleRelease represents one transition of the cycle release workflow (Chargenfreigabe)
+type CycleRelease struct {
+	ID         int               `json:"id" db:"id"`
+	CycleID    int               `json:"cycle_id" db:"cycle_id"`
+	FromStatus string            `json:"from_status" db:"from_status"`
+	ToStatus   string            `json:"to_status" db:"to_status"`
+	User       string            `json:"user" db:"user"` // Person who made the decision
+	Reason     string            `json:"reason,omitempty" db:"reason"`
+	Checklist  *ReleaseChecklist `json:"checklist,omitempty"`
+	Timestamp  time.Time         `json:"timestamp" db:"timestamp"`
+}
+
+// RecallCase represents a recall of the cycles affected by a failed indicator
+type RecallCase struct {
+	ID                int        `json:"id" db:"id"`
+	DeviceID          int        `json:"device_id" db:"device_id"`
+	FailedCycleID     int        `json:"failed_cycle_id" db:"failed_cycle_id"`
+	LastPassedCycleID *int       `json:"last_passed_cycle_id,omitempty" db:"last_passed_cycle_id"` // nil = no passed test cycle found
+	WindowStart       *time.Time `json:"window_start,omitempty" db:"window_start"`
+	WindowEnd         time.Time  `json:"window_end" db:"window_end"`
+	Status            string     `json:"status" db:"status"` // "open" or "closed"
+	OpenedBy          string     `json:"opened_by" db:"opened_by"`
+	Description       string     `json:"description,omitempty" db:"description"`
+	Created           time.Time  `json:"created" db:"created"`
+	ClosedAt          *time.Time `json:"closed_at,omitempty" db:"closed_at"`
+	ClosedBy          string     `json:"closed_by,omitempty" db:"closed_by"`
+	Resolution        string     `json:"resolution,omitempty" db:"resolution"`
+	AffectedCycles    int        `json:"affected_cycles"`
+}
+
+// RecallNote represents a QA annotation of a recall case
+type RecallNote struct {
+	ID      int       `json:"id" db:"id"`
+	CaseID  int       `json:"case_id" db:"case_id"`
+	User    string    `json:"user" db:"user"`
+	Note    string    `json:"note" db:"note"`
+	Created time.Time `json:"created" db:"created"`
 }
 
 // AuditLog represents an audit trail entry
@@ -67,6 +178,45 @@ type AuditLog struct {
 	EntityID   *int      `json:"entity_id,omitempty" db:"entity_id"`
 	User       string    `json:"user,omitempty" db:"user"`
 	Details    string    `json:"details,omitempty" db:"details"` // JSON string
-	Hash       string    `json:"hash,omitempty" db:"hash"`       // Integrity hash
+	PrevHash   string    `json:"prev_hash,omitempty" db:"prev_hash"` // Hash of the previous entry (empty for the first entry)
+	Hash       string    `json:"hash,omitempty" db:"hash"`       // Integrity hash, chained over PrevHash
+}
+
+
+// DeviceConnectionSettings holds per-device connection parameters (used by the Melag FTP adapter)
+// Zero values mean "use the adapter default".
+type DeviceConnectionSettings struct {
+	DeviceID       int       `json:"device_id" db:"device_id"`
+	Port           int       `json:"port,omitempty" db:"port"`
+	Username       string    `json:"username,omitempty" db:"username"`
+	TimeoutSeconds int       `json:"timeout_seconds,omitempty" db:"timeout_seconds"`
+	FTPMode        string    `json:"ftp_mode,omitempty" db:"ftp_mode"`   // "passive" or "pasv"
+	TLSMode        string    `json:"tls_mode,omitempty" db:"tls_mode"`   // "none", "explicit" or "implicit"
+	TLSSkipVerify  bool      `json:"tls_skip_verify,omitempty" db:"tls_skip_verify"`
+	Updated        time.Time `json:"updated" db:"updated"`
+
+	secret string // AES-GCM encrypted password, see SetPassword/Password
+}
+
+// DeviceConnectionState holds the current connection state of a device as seen by the device manager
+type DeviceConnectionState struct {
+	DeviceID            int        `json:"device_id" db:"device_id"`
+	State               string     `json:"state" db:"state"` // "DISCONNECTED", "CONNECTING", "CONNECTED", "ERROR"
+	LastSeen            *time.Time `json:"last_seen,omitempty" db:"last_seen"`   // Last successful communication
+	LastError           string     `json:"last_error,omitempty" db:"last_error"` // Error of the last failed attempt
+	ConsecutiveFailures int        `json:"consecutive_failures" db:"consecutive_failures"`
+	Since               time.Time  `json:"since" db:"since"` // Time the device entered the current state
+	Updated             time.Time  `json:"updated" db:"updated"`
 }
 
+// DeviceConnectionTransition represents a change of a device's connection state
+type DeviceConnectionTransition struct {
+	ID         int       `json:"id" db:"id"`
+	DeviceID   int       `json:"device_id" db:"device_id"`
+	FromState  string    `json:"from_state,omitempty" db:"from_state"` // Empty for the first recorded state
+	ToState    string    `json:"to_state" db:"to_state"`
+	Reason     string    `json:"reason" db:"reason"` // "connect", "disconnect", "probe", "health_check", "shutdown"
+	Error      string    `json:"error,omitempty" db:"error"`
+	DurationMs *int64    `json:"duration_ms,omitempty" db:"duration_ms"` // Time spent in FromState
+	Timestamp  time.Time `json:"timestamp" db:"timestamp"`
+}
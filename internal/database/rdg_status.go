@@ -1,22 +1,30 @@
 package database
 
 import (
+	"database/sql"
 	"fmt"
 	"time"
 )
 
-// CreateRDGStatus creates a new RDG status entry
-func CreateRDGStatus(deviceID int, reachable bool) (*RDGStatus, error) {
+// CreateRDGStatus creates a new RDG status entry with the probe result
+// ID and Timestamp are set on the returned entry.
+func CreateRDGStatus(status *RDGStatus) (*RDGStatus, error) {
 	if db == nil {
 		return nil, fmt.Errorf("database not initialized")
 	}
 
 	query := `
-		INSERT INTO rdg_status (device_id, timestamp, reachable)
-		VALUES (?, ?, ?)
+		INSERT INTO rdg_status (device_id, timestamp, reachable, rtt_ms, packet_loss, probes, method)
+		VALUES (?, ?, ?, ?, ?, ?, ?)
 	`
 
-	result, err := db.Exec(query, deviceID, time.Now(), reachable)
+	var method interface{}
+	if status.Method != "" {
+		method = status.Method
+	}
+
+	timestamp := time.Now()
+	result, err := db.Exec(query, status.DeviceID, timestamp, status.Reachable, status.RTTMs, status.PacketLoss, status.Probes, method)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create rdg status: %w", err)
 	}
@@ -26,12 +34,10 @@ func CreateRDGStatus(deviceID int, reachable bool) (*RDGStatus, error) {
 		return nil, fmt.Errorf("failed to get last insert id: %w", err)
 	}
 
-	return &RDGStatus{
-		ID:        int(id),
-		DeviceID:  deviceID,
-		Timestamp: time.Now(),
-		Reachable: reachable,
-	}, nil
+	created := *status
+	created.ID = int(id)
+	created.Timestamp = timestamp
+	return &created, nil
 }
 
 // GetLatestRDGStatus retrieves the latest RDG status for a device
@@ -41,29 +47,19 @@ func GetLatestRDGStatus(deviceID int) (*RDGStatus, error) {
 	}
 
 	query := `
-		SELECT id, device_id, timestamp, reachable
+		SELECT id, device_id, timestamp, reachable, rtt_ms, packet_loss, probes, method
 		FROM rdg_status
 		WHERE device_id = ?
 		ORDER BY timestamp DESC
 		LIMIT 1
 	`
 
-	var status RDGStatus
-	var reachableInt int
-
-	err := db.QueryRow(query, deviceID).Scan(
-		&status.ID,
-		&status.DeviceID,
-		&status.Timestamp,
-		&reachableInt,
-	)
+	status, err := scanRDGStatus(db.QueryRow(query, deviceID))
 	if err != nil {
 		return nil, fmt.Errorf("failed to get rdg status: %w", err)
 	}
 
-	status.Reachable = reachableInt == 1
-
-	return &status, nil
+	return status, nil
 }
 
 // GetRDGStatusHistory retrieves RDG status history for a device
@@ -77,7 +73,7 @@ func GetRDGStatusHistory(deviceID int, limit int) ([]RDGStatus, error) {
 	}
 
 	query := `
-		SELECT id, device_id, timestamp, reachable
+		SELECT id, device_id, timestamp, reachable, rtt_ms, packet_loss, probes, method
 		FROM rdg_status
 		WHERE device_id = ?
 		ORDER BY timestamp DESC
@@ -90,23 +86,42 @@ func GetRDGStatusHistory(deviceID int, limit int) ([]RDGStatus, error) {
 	}
 	defer rows.Close()
 
+	return scanRDGStatusRows(rows)
+}
+
+// GetRDGStatusHistoryRange retrieves all RDG status entries of a device in [from, to),
+// oldest first
+func GetRDGStatusHistoryRange(deviceID int, from, to time.Time) ([]RDGStatus, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	query := `
+		SELECT id, device_id, timestamp, reachable, rtt_ms, packet_loss, probes, method
+		FROM rdg_status
+		WHERE device_id = ? AND timestamp >= ? AND timestamp < ?
+		ORDER BY timestamp ASC
+	`
+
+	// Timestamps are stored in local time, compare in the same representation
+	rows, err := db.Query(query, deviceID, from.Local(), to.Local())
+	if err != nil {
+		return nil, fmt.Errorf("failed to query rdg status history: %w", err)
+	}
+	defer rows.Close()
+
+	return scanRDGStatusRows(rows)
+}
+
+// scanRDGStatusRows reads all rows of an rdg_status query
+func scanRDGStatusRows(rows *sql.Rows) ([]RDGStatus, error) {
 	var statuses []RDGStatus
 	for rows.Next() {
-		var status RDGStatus
-		var reachableInt int
-
-		err := rows.Scan(
-			&status.ID,
-			&status.DeviceID,
-			&status.Timestamp,
-			&reachableInt,
-		)
+		status, err := scanRDGStatus(rows)
 		if err != nil {
 			return nil, fmt.Errorf("failed to scan rdg status: %w", err)
 		}
-
-		status.Reachable = reachableInt == 1
-		statuses = append(statuses, status)
+		statuses = append(statuses, *status)
 	}
 
 	if err := rows.Err(); err != nil {
@@ -116,3 +131,41 @@ func GetRDGStatusHistory(deviceID int, limit int) ([]RDGStatus, error) {
 	return statuses, nil
 }
 
+// scanRDGStatus reads one rdg_status row; probe values are missing for entries
+// recorded before they were measured
+func scanRDGStatus(row interface{ Scan(dest ...interface{}) error }) (*RDGStatus, error) {
+	var status RDGStatus
+	var reachableInt int
+	var rttMs, packetLoss sql.NullFloat64
+	var probes sql.NullInt64
+	var method sql.NullString
+
+	err := row.Scan(
+		&status.ID,
+		&status.DeviceID,
+		&status.Timestamp,
+		&reachableInt,
+		&rttMs,
+		&packetLoss,
+		&probes,
+		&method,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	status.Reachable = reachableInt == 1
+	if rttMs.Valid {
+		status.RTTMs = &rttMs.Float64
+	}
+	if packetLoss.Valid {
+		status.PacketLoss = &packetLoss.Float64
+	}
+	if probes.Valid {
+		count := int(probes.Int64)
+		status.Probes = &count
+	}
+	status.Method = method.String
+
+	return &status, nil
+}
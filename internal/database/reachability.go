@@ -0,0 +1,216 @@
+package database
+
+import (
+	"math"
+	"sort"
+	"time"
+)
+
+// ReachabilityReport aggregates the RDG status entries of a device over a time window
+type ReachabilityReport struct {
+	DeviceID            int                  `json:"device_id"`
+	From                time.Time            `json:"from"`
+	To                  time.Time            `json:"to"`
+	Checks              int                  `json:"checks"`               // RDG status entries in the window
+	AvailabilityPercent *float64             `json:"availability_percent"` // Reachable checks in percent (null without checks)
+	PacketLossPercent   *float64             `json:"packet_loss_percent,omitempty"`
+	RTT                 *RTTPercentiles      `json:"rtt_ms,omitempty"`
+	Hourly              []AvailabilityBucket `json:"hourly"`
+	Daily               []AvailabilityBucket `json:"daily"`
+	LongestOutages      []OutageWindow       `json:"longest_outages"`
+}
+
+// AvailabilityBucket aggregates the checks of one hour or day (only periods with checks are listed)
+type AvailabilityBucket struct {
+	Start               time.Time `json:"start"`
+	Checks              int       `json:"checks"`
+	Reachable           int       `json:"reachable"`
+	AvailabilityPercent float64   `json:"availability_percent"`
+	PacketLossPercent   *float64  `json:"packet_loss_percent,omitempty"`
+	AvgRTTMs            *float64  `json:"avg_rtt_ms,omitempty"`
+}
+
+// RTTPercentiles summarises the round-trip times of the answered checks in milliseconds
+type RTTPercentiles struct {
+	Min float64 `json:"min"`
+	Avg float64 `json:"avg"`
+	P50 float64 `json:"p50"`
+	P90 float64 `json:"p90"`
+	P95 float64 `json:"p95"`
+	P99 float64 `json:"p99"`
+	Max float64 `json:"max"`
+}
+
+// OutageWindow is a run of consecutive unreachable checks
+// End is the first reachable check after the outage, or the end of the window while
+// the device is still unreachable (Ongoing).
+type OutageWindow struct {
+	Start           time.Time `json:"start"`
+	End             time.Time `json:"end"`
+	DurationSeconds float64   `json:"duration_seconds"`
+	Checks          int       `json:"checks"`
+	Ongoing         bool      `json:"ongoing"`
+}
+
+// bucketStats accumulates the checks of one aggregation period
+type bucketStats struct {
+	checks, reachable int
+	lossSum           float64
+	lossCount         int
+	rttSum            float64
+	rttCount          int
+}
+
+func (b *bucketStats) add(status RDGStatus) {
+	b.checks++
+	if status.Reachable {
+		b.reachable++
+	}
+	if status.PacketLoss != nil {
+		b.lossSum += *status.PacketLoss
+		b.lossCount++
+	}
+	if status.RTTMs != nil {
+		b.rttSum += *status.RTTMs
+		b.rttCount++
+	}
+}
+
+func (b *bucketStats) bucket(start time.Time) AvailabilityBucket {
+	bucket := AvailabilityBucket{
+		Start:               start,
+		Checks:              b.checks,
+		Reachable:           b.reachable,
+		AvailabilityPercent: round2(float64(b.reachable) * 100 / float64(b.checks)),
+	}
+	if b.lossCount > 0 {
+		loss := round2(b.lossSum / float64(b.lossCount))
+		bucket.PacketLossPercent = &loss
+	}
+	if b.rttCount > 0 {
+		rtt := round2(b.rttSum / float64(b.rttCount))
+		bucket.AvgRTTMs = &rtt
+	}
+	return bucket
+}
+
+// SummarizeReachability builds the reachability report from RDG status entries (oldest first)
+// Hours and days are aggregated in local time. At most maxOutages outage windows are
+// returned, longest first.
+func SummarizeReachability(deviceID int, history []RDGStatus, from, to time.Time, maxOutages int) ReachabilityReport {
+	report := ReachabilityReport{
+		DeviceID:       deviceID,
+		From:           from,
+		To:             to,
+		Checks:         len(history),
+		Hourly:         []AvailabilityBucket{},
+		Daily:          []AvailabilityBucket{},
+		LongestOutages: []OutageWindow{},
+	}
+	if len(history) == 0 {
+		return report
+	}
+
+	var total bucketStats
+	var rtts []float64
+	var hour, day bucketStats
+	var hourStart, dayStart time.Time
+	var outages []OutageWindow
+	var outage *OutageWindow
+
+	for _, status := range history {
+		local := status.Timestamp.Local()
+		statusHour := time.Date(local.Year(), local.Month(), local.Day(), local.Hour(), 0, 0, 0, time.Local)
+		statusDay := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.Local)
+
+		if hour.checks > 0 && !statusHour.Equal(hourStart) {
+			report.Hourly = append(report.Hourly, hour.bucket(hourStart))
+			hour = bucketStats{}
+		}
+		if day.checks > 0 && !statusDay.Equal(dayStart) {
+			report.Daily = append(report.Daily, day.bucket(dayStart))
+			day = bucketStats{}
+		}
+		hourStart, dayStart = statusHour, statusDay
+
+		total.add(status)
+		hour.add(status)
+		day.add(status)
+		if status.RTTMs != nil {
+			rtts = append(rtts, *status.RTTMs)
+		}
+
+		switch {
+		case !status.Reachable && outage == nil:
+			outage = &OutageWindow{Start: status.Timestamp, Checks: 1}
+		case !status.Reachable:
+			outage.Checks++
+		case outage != nil:
+			outage.End = status.Timestamp
+			outages = append(outages, *outage)
+			outage = nil
+		}
+	}
+	report.Hourly = append(report.Hourly, hour.bucket(hourStart))
+	report.Daily = append(report.Daily, day.bucket(dayStart))
+
+	if outage != nil {
+		outage.End = to
+		outage.Ongoing = true
+		outages = append(outages, *outage)
+	}
+
+	summary := total.bucket(from)
+	report.AvailabilityPercent = &summary.AvailabilityPercent
+	report.PacketLossPercent = summary.PacketLossPercent
+	report.RTT = rttPercentiles(rtts)
+
+	for i := range outages {
+		outages[i].DurationSeconds = math.Round(outages[i].End.Sub(outages[i].Start).Seconds())
+	}
+	sort.SliceStable(outages, func(i, j int) bool {
+		return outages[i].DurationSeconds > outages[j].DurationSeconds
+	})
+	if maxOutages >= 0 && len(outages) > maxOutages {
+		outages = outages[:maxOutages]
+	}
+	report.LongestOutages = append(report.LongestOutages, outages...)
+
+	return report
+}
+
+// rttPercentiles returns the RTT summary (nearest-rank percentiles), nil without values
+func rttPercentiles(rtts []float64) *RTTPercentiles {
+	if len(rtts) == 0 {
+		return nil
+	}
+	sorted := append([]float64(nil), rtts...)
+	sort.Float64s(sorted)
+
+	sum := 0.0
+	for _, rtt := range sorted {
+		sum += rtt
+	}
+	percentile := func(p float64) float64 {
+		rank := int(math.Ceil(p / 100 * float64(len(sorted))))
+		if rank < 1 {
+			rank = 1
+		}
+		return sorted[rank-1]
+	}
+
+	return &RTTPercentiles{
+		Min: sorted[0],
+		Avg: round2(sum / float64(len(sorted))),
+		P50: percentile(50),
+		P90: percentile(90),
+		P95: percentile(95),
+		P99: percentile(99),
+		Max: sorted[len(sorted)-1],
+	}
+}
+
+// round2 rounds to two decimal places
+func round2(value float64) float64 {
+	return math.Round(value*100) / 100
+}
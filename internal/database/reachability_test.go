@@ -0,0 +1,62 @@
+package database
+
+import (
+	"testing"
+	"time"
+)
+
+func TestSummarizeReachability(t *testing.T) {
+	start := time.Date(2026, 10, 16, 10, 0, 0, 0, time.Local)
+	rtt := func(ms float64) *float64 { return &ms }
+	loss := func(percent float64) *float64 { return &percent }
+
+	// Two hours of checks every 15 minutes: a 30 minute outage in the first hour and
+	// an ongoing one at the end
+	var history []RDGStatus
+	for i := 0; i < 8; i++ {
+		status := RDGStatus{DeviceID: 2, Timestamp: start.Add(time.Duration(i) * 15 * time.Minute), Reachable: true, RTTMs: rtt(float64(i + 1)), PacketLoss: loss(0)}
+		if i == 1 || i == 2 || i == 7 {
+			status.Reachable = false
+			status.RTTMs = nil
+			status.PacketLoss = loss(100)
+		}
+		history = append(history, status)
+	}
+	to := start.Add(2 * time.Hour)
+
+	report := SummarizeReachability(2, history, start, to, 5)
+
+	if report.Checks != 8 || report.AvailabilityPercent == nil || *report.AvailabilityPercent != 62.5 {
+		t.Errorf("Expected 8 checks with 62.5%% availability, got %d, %v", report.Checks, report.AvailabilityPercent)
+	}
+	if report.PacketLossPercent == nil || *report.PacketLossPercent != 37.5 {
+		t.Errorf("Expected 37.5%% packet loss, got %v", report.PacketLossPercent)
+	}
+
+	// Answered RTTs: 1, 4, 5, 6, 7
+	if report.RTT == nil || report.RTT.Min != 1 || report.RTT.P50 != 5 || report.RTT.P90 != 7 || report.RTT.Max != 7 || report.RTT.Avg != 4.6 {
+		t.Errorf("Unexpected RTT percentiles: %+v", report.RTT)
+	}
+
+	if len(report.Hourly) != 2 || report.Hourly[0].AvailabilityPercent != 50 || report.Hourly[1].AvailabilityPercent != 75 {
+		t.Errorf("Unexpected hourly buckets: %+v", report.Hourly)
+	}
+	if len(report.Daily) != 1 || report.Daily[0].Checks != 8 {
+		t.Errorf("Unexpected daily buckets: %+v", report.Daily)
+	}
+
+	if len(report.LongestOutages) != 2 {
+		t.Fatalf("Expected 2 outages, got %+v", report.LongestOutages)
+	}
+	first := report.LongestOutages[0]
+	if first.DurationSeconds != 1800 || first.Checks != 2 || first.Ongoing || !first.Start.Equal(start.Add(15*time.Minute)) {
+		t.Errorf("Unexpected longest outage: %+v", first)
+	}
+	if second := report.LongestOutages[1]; !second.Ongoing || !second.End.Equal(to) || second.DurationSeconds != 900 {
+		t.Errorf("Unexpected ongoing outage: %+v", second)
+	}
+
+	if limited := SummarizeReachability(2, history, start, to, 1); len(limited.LongestOutages) != 1 {
+		t.Errorf("Expected outages limited to 1, got %d", len(limited.LongestOutages))
+	}
+}
@@ -0,0 +1,464 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Recall case states
+const (
+	RecallStatusOpen   = "open"
+	RecallStatusClosed = "closed"
+)
+
+var (
+	ErrRecallNotFound         = errors.New("recall case not found")
+	ErrRecallClosed           = errors.New("recall case is closed")
+	ErrFailedCycleNotOnDevice = errors.New("failed cycle does not belong to device")
+)
+
+// RecallWindow is the set of cycles affected by a failed indicator on a device
+// It spans all cycles after the last passed test cycle up to and including the failed cycle.
+type RecallWindow struct {
+	FailedCycle    *CycleWithDevice  `json:"failed_cycle"`
+	LastPassedTest *CycleWithDevice  `json:"last_passed_test,omitempty"` // nil = no passed test cycle, window starts with the first cycle
+	Start          *time.Time        `json:"start,omitempty"`
+	End            time.Time         `json:"end"`
+	Cycles         []CycleWithDevice `json:"cycles"`
+}
+
+// RecallCycle is an affected cycle with the load and the operators who loaded it
+type RecallCycle struct {
+	CycleWithDevice
+	Operators []string    `json:"operators"`
+	Loads     []CycleLoad `json:"loads"`
+}
+
+// RecallReport is a recall case with its affected cycles and QA notes
+type RecallReport struct {
+	Case           RecallCase       `json:"case"`
+	FailedCycle    *CycleWithDevice `json:"failed_cycle"`
+	LastPassedTest *CycleWithDevice `json:"last_passed_test,omitempty"`
+	Cycles         []RecallCycle    `json:"cycles"`
+	Notes          []RecallNote     `json:"notes"`
+}
+
+// FindRecallWindow determines the cycles affected by a failed indicator in failedCycleID
+// The window reaches back to the last cycle before it with result OK whose program
+// matches one of testPrograms (e.g. Bowie-Dick); that test cycle itself is not affected.
+func FindRecallWindow(deviceID, failedCycleID int, testPrograms []string) (*RecallWindow, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	failed, err := GetCycleWithDevice(failedCycleID)
+	if err != nil {
+		return nil, err
+	}
+	if failed.DeviceID != deviceID {
+		return nil, ErrFailedCycleNotOnDevice
+	}
+
+	window := &RecallWindow{
+		FailedCycle: failed,
+		End:         failed.StartTS.Local(),
+	}
+
+	if len(testPrograms) > 0 {
+		passed := "OK"
+		tests, _, err := GetAllCycles(CycleListOptions{
+			Limit:     2,
+			SortBy:    "start_ts",
+			SortOrder: "DESC",
+			DeviceID:  &deviceID,
+			EndDate:   &window.End,
+			Result:    &passed,
+			Programs:  testPrograms,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to find last passed test cycle: %w", err)
+		}
+		// The failed cycle may itself be a test cycle the device reported as OK
+		for i := range tests {
+			if tests[i].ID != failedCycleID {
+				window.LastPassedTest = &tests[i]
+				start := tests[i].StartTS.Local()
+				window.Start = &start
+				break
+			}
+		}
+	}
+
+	cycles, _, err := GetAllCycles(CycleListOptions{
+		SortBy:    "start_ts",
+		SortOrder: "ASC",
+		DeviceID:  &deviceID,
+		StartDate: window.Start,
+		EndDate:   &window.End,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get affected cycles: %w", err)
+	}
+
+	// The stored start time may carry more than the bound (monotonic clock suffix),
+	// so the failed cycle itself is added explicitly
+	window.Cycles = []CycleWithDevice{}
+	for _, cycle := range cycles {
+		if (window.LastPassedTest != nil && cycle.ID == window.LastPassedTest.ID) || cycle.ID == failedCycleID {
+			continue
+		}
+		window.Cycles = append(window.Cycles, cycle)
+	}
+	window.Cycles = append(window.Cycles, *failed)
+
+	return window, nil
+}
+
+// OpenRecallCase determines the recall window and records it as a new open recall case
+// The affected cycles are stored with the case, so later cycles do not change it.
+func OpenRecallCase(deviceID, failedCycleID int, testPrograms []string, openedBy, description string) (*RecallCase, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	window, err := FindRecallWindow(deviceID, failedCycleID, testPrograms)
+	if err != nil {
+		return nil, err
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var lastPassedID sql.NullInt64
+	if window.LastPassedTest != nil {
+		lastPassedID = sql.NullInt64{Int64: int64(window.LastPassedTest.ID), Valid: true}
+	}
+
+	query := `
+		INSERT INTO recall_cases (device_id, failed_cycle_id, last_passed_cycle_id, window_start,
+		                          window_end, status, opened_by, description, created)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	result, err := tx.Exec(
+		query,
+		deviceID,
+		failedCycleID,
+		lastPassedID,
+		window.Start,
+		window.End,
+		RecallStatusOpen,
+		openedBy,
+		sql.NullString{String: description, Valid: description != ""},
+		time.Now(),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create recall case: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recall case ID: %w", err)
+	}
+
+	for _, cycle := range window.Cycles {
+		if _, err := tx.Exec(`INSERT INTO recall_case_cycles (case_id, cycle_id) VALUES (?, ?)`, id, cycle.ID); err != nil {
+			return nil, fmt.Errorf("failed to record affected cycle: %w", err)
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit recall case: %w", err)
+	}
+
+	return GetRecallCase(int(id))
+}
+
+// GetRecallCase retrieves a recall case by ID
+func GetRecallCase(id int) (*RecallCase, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	query := recallCaseQuery + " WHERE r.id = ?"
+
+	recall, err := scanRecallCase(db.QueryRow(query, id))
+	if err == sql.ErrNoRows {
+		return nil, ErrRecallNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recall case: %w", err)
+	}
+
+	return recall, nil
+}
+
+// GetRecallCases retrieves recall cases, newest first
+// An empty status or nil deviceID does not filter.
+func GetRecallCases(status string, deviceID *int) ([]RecallCase, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	whereParts := []string{}
+	args := []interface{}{}
+	if status != "" {
+		whereParts = append(whereParts, "r.status = ?")
+		args = append(args, status)
+	}
+	if deviceID != nil {
+		whereParts = append(whereParts, "r.device_id = ?")
+		args = append(args, *deviceID)
+	}
+
+	query := recallCaseQuery
+	if len(whereParts) > 0 {
+		query += " WHERE " + strings.Join(whereParts, " AND ")
+	}
+	query += " ORDER BY r.created DESC, r.id DESC"
+
+	rows, err := db.Query(query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query recall cases: %w", err)
+	}
+	defer rows.Close()
+
+	recalls := []RecallCase{}
+	for rows.Next() {
+		recall, err := scanRecallCase(rows)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan recall case: %w", err)
+		}
+		recalls = append(recalls, *recall)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating recall cases: %w", err)
+	}
+
+	return recalls, nil
+}
+
+// AddRecallNote adds a QA annotation to an open recall case
+func AddRecallNote(caseID int, user, note string) (*RecallNote, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	recall, err := GetRecallCase(caseID)
+	if err != nil {
+		return nil, err
+	}
+	if recall.Status == RecallStatusClosed {
+		return nil, ErrRecallClosed
+	}
+
+	created := time.Now()
+	result, err := db.Exec(`INSERT INTO recall_case_notes (case_id, user, note, created) VALUES (?, ?, ?, ?)`,
+		caseID, user, note, created)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add recall note: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recall note ID: %w", err)
+	}
+
+	return &RecallNote{ID: int(id), CaseID: caseID, User: user, Note: note, Created: created}, nil
+}
+
+// GetRecallNotes retrieves the annotations of a recall case, oldest first
+func GetRecallNotes(caseID int) ([]RecallNote, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	rows, err := db.Query(`
+		SELECT id, case_id, user, note, created
+		FROM recall_case_notes
+		WHERE case_id = ?
+		ORDER BY created, id
+	`, caseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query recall notes: %w", err)
+	}
+	defer rows.Close()
+
+	notes := []RecallNote{}
+	for rows.Next() {
+		var note RecallNote
+		if err := rows.Scan(&note.ID, &note.CaseID, &note.User, &note.Note, &note.Created); err != nil {
+			return nil, fmt.Errorf("failed to scan recall note: %w", err)
+		}
+		notes = append(notes, note)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating recall notes: %w", err)
+	}
+
+	return notes, nil
+}
+
+// CloseRecallCase closes an open recall case with the user's resolution
+func CloseRecallCase(caseID int, user, resolution string) (*RecallCase, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	query := `
+		UPDATE recall_cases
+		SET status = ?, closed_at = ?, closed_by = ?, resolution = ?
+		WHERE id = ? AND status = ?
+	`
+
+	result, err := db.Exec(query, RecallStatusClosed, time.Now(), user, resolution, caseID, RecallStatusOpen)
+	if err != nil {
+		return nil, fmt.Errorf("failed to close recall case: %w", err)
+	}
+
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return nil, fmt.Errorf("failed to check close result: %w", err)
+	}
+	if rowsAffected == 0 {
+		// Either unknown or already closed
+		if _, err := GetRecallCase(caseID); err != nil {
+			return nil, err
+		}
+		return nil, ErrRecallClosed
+	}
+
+	return GetRecallCase(caseID)
+}
+
+// GetRecallReport retrieves a recall case with its affected cycles, their loads and the QA notes
+func GetRecallReport(caseID int) (*RecallReport, error) {
+	recall, err := GetRecallCase(caseID)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &RecallReport{Case: *recall, Cycles: []RecallCycle{}}
+
+	if report.FailedCycle, err = GetCycleWithDevice(recall.FailedCycleID); err != nil {
+		return nil, fmt.Errorf("failed to get failed cycle: %w", err)
+	}
+	if recall.LastPassedCycleID != nil {
+		if report.LastPassedTest, err = GetCycleWithDevice(*recall.LastPassedCycleID); err != nil {
+			return nil, fmt.Errorf("failed to get last passed test cycle: %w", err)
+		}
+	}
+
+	rows, err := db.Query(`
+		SELECT rc.cycle_id
+		FROM recall_case_cycles rc
+		JOIN cycles c ON rc.cycle_id = c.id
+		WHERE rc.case_id = ?
+		ORDER BY c.start_ts, c.id
+	`, caseID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query affected cycles: %w", err)
+	}
+	var cycleIDs []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("failed to scan affected cycle: %w", err)
+		}
+		cycleIDs = append(cycleIDs, id)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating affected cycles: %w", err)
+	}
+
+	for _, id := range cycleIDs {
+		cycle, err := GetCycleWithDevice(id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get affected cycle %d: %w", id, err)
+		}
+		loads, err := GetCycleLoads(id)
+		if err != nil {
+			return nil, err
+		}
+
+		operators := []string{}
+		seen := make(map[string]bool)
+		for _, load := range loads {
+			if !seen[load.Operator] {
+				seen[load.Operator] = true
+				operators = append(operators, load.Operator)
+			}
+		}
+
+		report.Cycles = append(report.Cycles, RecallCycle{CycleWithDevice: *cycle, Operators: operators, Loads: loads})
+	}
+
+	if report.Notes, err = GetRecallNotes(caseID); err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+// recallCaseQuery selects recall cases with the number of affected cycles
+const recallCaseQuery = `
+	SELECT r.id, r.device_id, r.failed_cycle_id, r.last_passed_cycle_id, r.window_start, r.window_end,
+	       r.status, r.opened_by, r.description, r.created, r.closed_at, r.closed_by, r.resolution,
+	       (SELECT COUNT(*) FROM recall_case_cycles rc WHERE rc.case_id = r.id)
+	FROM recall_cases r
+`
+
+// scanRecallCase reads one row of recallCaseQuery
+func scanRecallCase(row interface {
+	Scan(dest ...interface{}) error
+}) (*RecallCase, error) {
+	var recall RecallCase
+	var lastPassedID sql.NullInt64
+	var windowStart, closedAt sql.NullTime
+	var description, closedBy, resolution sql.NullString
+
+	err := row.Scan(
+		&recall.ID,
+		&recall.DeviceID,
+		&recall.FailedCycleID,
+		&lastPassedID,
+		&windowStart,
+		&recall.WindowEnd,
+		&recall.Status,
+		&recall.OpenedBy,
+		&description,
+		&recall.Created,
+		&closedAt,
+		&closedBy,
+		&resolution,
+		&recall.AffectedCycles,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	if lastPassedID.Valid {
+		id := int(lastPassedID.Int64)
+		recall.LastPassedCycleID = &id
+	}
+	if windowStart.Valid {
+		recall.WindowStart = &windowStart.Time
+	}
+	if closedAt.Valid {
+		recall.ClosedAt = &closedAt.Time
+	}
+	recall.Description = description.String
+	recall.ClosedBy = closedBy.String
+	recall.Resolution = resolution.String
+
+	return &recall, nil
+}
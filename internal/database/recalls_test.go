@@ -0,0 +1,188 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestRecallWindow(t *testing.T) {
+	if err := InitializeDatabase(filepath.Join(t.TempDir(), "recalls.db")); err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer Close()
+
+	steri, err := CreateDevice(&Device{Name: "Steri 1", Manufacturer: "Melag", IP: "192.0.2.10", Type: "Steri"})
+	if err != nil {
+		t.Fatalf("Failed to create device: %v", err)
+	}
+	other, err := CreateDevice(&Device{Name: "Steri 2", Manufacturer: "Melag", IP: "192.0.2.11", Type: "Steri"})
+	if err != nil {
+		t.Fatalf("Failed to create device: %v", err)
+	}
+
+	start := time.Now().Add(-24 * time.Hour)
+	cycle := func(deviceID int, program, result string, hours int) *Cycle {
+		c, err := CreateCycle(&Cycle{DeviceID: deviceID, Program: program, StartTS: start.Add(time.Duration(hours) * time.Hour)})
+		if err != nil {
+			t.Fatalf("Failed to create cycle: %v", err)
+		}
+		if err := UpdateCycleResult(c.ID, result, c.StartTS.Add(30*time.Minute), nil, nil); err != nil {
+			t.Fatalf("Failed to complete cycle: %v", err)
+		}
+		return c
+	}
+
+	cycle(steri.ID, "Bowie-Dick-Test", "OK", 0)
+	lastTest := cycle(steri.ID, "Bowie-Dick-Test", "OK", 1)
+	affected := cycle(steri.ID, "Universal-Programm", "OK", 2)
+	cycle(steri.ID, "Bowie-Dick-Test", "NOK", 3) // Failed test does not close the window
+	cycle(other.ID, "Universal-Programm", "OK", 4)
+	failed := cycle(steri.ID, "Universal-Programm", "OK", 5)
+	cycle(steri.ID, "Universal-Programm", "OK", 6) // After the failed indicator
+
+	window, err := FindRecallWindow(steri.ID, failed.ID, []string{"bowie-dick"})
+	if err != nil {
+		t.Fatalf("Failed to find recall window: %v", err)
+	}
+	if window.LastPassedTest == nil || window.LastPassedTest.ID != lastTest.ID {
+		t.Fatalf("Expected last passed test cycle %d, got %+v", lastTest.ID, window.LastPassedTest)
+	}
+	if len(window.Cycles) != 3 || window.Cycles[0].ID != affected.ID || window.Cycles[2].ID != failed.ID {
+		t.Errorf("Expected 3 affected cycles from %d to %d, got %+v", affected.ID, failed.ID, window.Cycles)
+	}
+
+	if _, err := FindRecallWindow(other.ID, failed.ID, nil); err != ErrFailedCycleNotOnDevice {
+		t.Errorf("Expected ErrFailedCycleNotOnDevice, got %v", err)
+	}
+
+	// Without a passed test cycle every earlier cycle is affected
+	if window, err := FindRecallWindow(steri.ID, failed.ID, []string{"Helix"}); err != nil || window.LastPassedTest != nil || len(window.Cycles) != 5 {
+		t.Errorf("Expected open window with 5 cycles, got %+v, %v", window, err)
+	}
+
+	recall, err := OpenRecallCase(steri.ID, failed.ID, []string{"Bowie-Dick"}, "qa.lead", "BI failed")
+	if err != nil {
+		t.Fatalf("Failed to open recall case: %v", err)
+	}
+	if recall.Status != RecallStatusOpen || recall.AffectedCycles != 3 || recall.LastPassedCycleID == nil || *recall.LastPassedCycleID != lastTest.ID {
+		t.Errorf("Unexpected recall case: %+v", recall)
+	}
+
+	if _, err := AddRecallNote(recall.ID, "qa.lead", "Trays 1-3 called back"); err != nil {
+		t.Fatalf("Failed to add note: %v", err)
+	}
+	if _, err := CloseRecallCase(recall.ID, "qa.lead", "All trays reprocessed"); err != nil {
+		t.Fatalf("Failed to close recall case: %v", err)
+	}
+	if _, err := CloseRecallCase(recall.ID, "qa.lead", "again"); err != ErrRecallClosed {
+		t.Errorf("Expected ErrRecallClosed, got %v", err)
+	}
+	if _, err := AddRecallNote(recall.ID, "qa.lead", "late"); err != ErrRecallClosed {
+		t.Errorf("Expected ErrRecallClosed for note on closed case, got %v", err)
+	}
+
+	report, err := GetRecallReport(recall.ID)
+	if err != nil {
+		t.Fatalf("Failed to get recall report: %v", err)
+	}
+	if report.Case.Status != RecallStatusClosed || len(report.Cycles) != 3 || len(report.Notes) != 1 || report.LastPassedTest == nil {
+		t.Errorf("Unexpected recall report: %+v", report)
+	}
+}
+
+func TestRecallWindowMatchesTestProgramsAsWords(t *testing.T) {
+	if err := InitializeDatabase(filepath.Join(t.TempDir(), "recalls.db")); err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer Close()
+
+	steri, err := CreateDevice(&Device{Name: "Steri 1", Manufacturer: "Melag", IP: "192.0.2.10", Type: "Steri"})
+	if err != nil {
+		t.Fatalf("Failed to create device: %v", err)
+	}
+
+	start := time.Now().Add(-24 * time.Hour)
+	cycle := func(program string, hours int) *Cycle {
+		c, err := CreateCycle(&Cycle{DeviceID: steri.ID, Program: program, StartTS: start.Add(time.Duration(hours) * time.Hour)})
+		if err != nil {
+			t.Fatalf("Failed to create cycle: %v", err)
+		}
+		if err := UpdateCycleResult(c.ID, "OK", c.StartTS.Add(30*time.Minute), nil, nil); err != nil {
+			t.Fatalf("Failed to complete cycle: %v", err)
+		}
+		return c
+	}
+
+	lastTest := cycle("Bowie-Dick-Test", 0)
+	cycle("Test Fehler Trocknung", 1) // Simulator failure program, not a test cycle
+	cycle("Vakuumtest", 2)
+	cycle("Helixtest", 3)
+	failed := cycle("Universal-Programm", 4)
+
+	window, err := FindRecallWindow(steri.ID, failed.ID, []string{"Bowie-Dick", "Helix"})
+	if err != nil {
+		t.Fatalf("Failed to find recall window: %v", err)
+	}
+	if window.LastPassedTest == nil || window.LastPassedTest.ID != lastTest.ID || len(window.Cycles) != 4 {
+		t.Errorf("Expected window from Bowie-Dick test %d with 4 cycles, got %+v", lastTest.ID, window)
+	}
+
+	// A configured name matches whole words only
+	window, err = FindRecallWindow(steri.ID, failed.ID, []string{"test"})
+	if err != nil {
+		t.Fatalf("Failed to find recall window: %v", err)
+	}
+	if window.LastPassedTest == nil || window.LastPassedTest.Program != "Test Fehler Trocknung" {
+		t.Errorf("Expected \"Test Fehler Trocknung\" as last test cycle for the word \"test\", got %+v", window.LastPassedTest)
+	}
+}
+
+func TestDeleteDeviceKeepsRecallEvidence(t *testing.T) {
+	if err := InitializeDatabase(filepath.Join(t.TempDir(), "recalls.db")); err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer Close()
+
+	steri, err := CreateDevice(&Device{Name: "Steri 1", Manufacturer: "Melag", IP: "192.0.2.10", Type: "Steri"})
+	if err != nil {
+		t.Fatalf("Failed to create device: %v", err)
+	}
+	spare, err := CreateDevice(&Device{Name: "Steri 2", Manufacturer: "Melag", IP: "192.0.2.11", Type: "Steri"})
+	if err != nil {
+		t.Fatalf("Failed to create device: %v", err)
+	}
+	for _, deviceID := range []int{steri.ID, spare.ID} {
+		if _, err := CreateCycle(&Cycle{DeviceID: deviceID, Program: "Universal-Programm", StartTS: time.Now().Add(-time.Hour)}); err != nil {
+			t.Fatalf("Failed to create cycle: %v", err)
+		}
+	}
+	failed, err := CreateCycle(&Cycle{DeviceID: steri.ID, Program: "Universal-Programm", StartTS: time.Now().Add(-30 * time.Minute)})
+	if err != nil {
+		t.Fatalf("Failed to create cycle: %v", err)
+	}
+	recall, err := OpenRecallCase(steri.ID, failed.ID, nil, "qa", "Indicator failed")
+	if err != nil {
+		t.Fatalf("Failed to open recall case: %v", err)
+	}
+
+	if inUse, err := HasRecallCases(steri.ID); err != nil || !inUse {
+		t.Errorf("Expected device with recall case to be in use, got %v, %v", inUse, err)
+	}
+	if err := DeleteDevice(steri.ID); err != ErrDeviceInUse {
+		t.Fatalf("Expected ErrDeviceInUse, got %v", err)
+	}
+	if _, err := GetDevice(steri.ID); err != nil {
+		t.Errorf("Expected device to be kept, got %v", err)
+	}
+	if report, err := GetRecallReport(recall.ID); err != nil || len(report.Cycles) != 2 {
+		t.Errorf("Expected recall case with its 2 cycles to be kept, got %+v, %v", report, err)
+	}
+
+	if inUse, err := HasRecallCases(spare.ID); err != nil || inUse {
+		t.Errorf("Expected device without recall case not to be in use, got %v, %v", inUse, err)
+	}
+	if err := DeleteDevice(spare.ID); err != nil {
+		t.Errorf("Failed to delete device: %v", err)
+	}
+}
@@ -0,0 +1,197 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Cycle release states (Chargenfreigabe)
+// A cycle has no release state while it is running.
+const (
+	ReleaseStatusCompleted = "completed"       // Cycle finished, release not yet requested
+	ReleaseStatusPending   = "pending_release" // Waiting for the release check
+	ReleaseStatusReleased  = "released"        // Load released for use
+	ReleaseStatusRejected  = "rejected"        // Load must be reprocessed
+)
+
+var (
+	ErrInvalidReleaseTransition   = errors.New("release transition not allowed in current state")
+	ErrCycleNotPassed             = errors.New("only cycles with result OK can be released")
+	ErrReleaseUserRequired        = errors.New("user is required")
+	ErrReleaseReasonRequired      = errors.New("reason is required for a rejection")
+	ErrReleaseChecklistIncomplete = errors.New("all checklist items must be confirmed for a release")
+)
+
+// releaseTransitions lists the allowed target states per release state
+var releaseTransitions = map[string][]string{
+	ReleaseStatusCompleted: {ReleaseStatusPending},
+	ReleaseStatusPending:   {ReleaseStatusReleased, ReleaseStatusRejected},
+}
+
+// IsValidReleaseTransition reports whether a cycle may move from one release state to another
+func IsValidReleaseTransition(from, to string) bool {
+	for _, allowed := range releaseTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+// TransitionCycleRelease moves a cycle to release.ToStatus and records the transition
+// Releasing requires result OK and a fully confirmed checklist, rejecting requires a reason.
+// FromStatus, ID and Timestamp of the returned record are filled in.
+func TransitionCycleRelease(release *CycleRelease) (*CycleRelease, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	release.User = strings.TrimSpace(release.User)
+	release.Reason = strings.TrimSpace(release.Reason)
+	if release.User == "" {
+		return nil, ErrReleaseUserRequired
+	}
+	switch release.ToStatus {
+	case ReleaseStatusRejected:
+		if release.Reason == "" {
+			return nil, ErrReleaseReasonRequired
+		}
+	case ReleaseStatusReleased:
+		checklist := release.Checklist
+		if checklist == nil || !checklist.ChemicalIndicator || !checklist.PackagingIntact || !checklist.Dry {
+			return nil, ErrReleaseChecklistIncomplete
+		}
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return nil, fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	var result, status sql.NullString
+	err = tx.QueryRow(`SELECT result, release_status FROM cycles WHERE id = ?`, release.CycleID).Scan(&result, &status)
+	if err == sql.ErrNoRows {
+		return nil, ErrCycleNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cycle release status: %w", err)
+	}
+
+	if !IsValidReleaseTransition(status.String, release.ToStatus) {
+		return nil, ErrInvalidReleaseTransition
+	}
+	if release.ToStatus == ReleaseStatusReleased && result.String != "OK" {
+		return nil, ErrCycleNotPassed
+	}
+
+	if _, err := tx.Exec(`UPDATE cycles SET release_status = ? WHERE id = ?`, release.ToStatus, release.CycleID); err != nil {
+		return nil, fmt.Errorf("failed to update cycle release status: %w", err)
+	}
+
+	var chemicalIndicator, packagingIntact, dry sql.NullBool
+	if release.Checklist != nil {
+		chemicalIndicator = sql.NullBool{Bool: release.Checklist.ChemicalIndicator, Valid: true}
+		packagingIntact = sql.NullBool{Bool: release.Checklist.PackagingIntact, Valid: true}
+		dry = sql.NullBool{Bool: release.Checklist.Dry, Valid: true}
+	}
+
+	release.FromStatus = status.String
+	release.Timestamp = time.Now()
+	query := `
+		INSERT INTO cycle_releases (cycle_id, from_status, to_status, user, reason,
+		                            chemical_indicator, packaging_intact, dry, timestamp)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+	`
+	res, err := tx.Exec(
+		query,
+		release.CycleID,
+		release.FromStatus,
+		release.ToStatus,
+		release.User,
+		sql.NullString{String: release.Reason, Valid: release.Reason != ""},
+		chemicalIndicator,
+		packagingIntact,
+		dry,
+		release.Timestamp,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to record release transition: %w", err)
+	}
+
+	id, err := res.LastInsertId()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get release ID: %w", err)
+	}
+	release.ID = int(id)
+
+	if err := tx.Commit(); err != nil {
+		return nil, fmt.Errorf("failed to commit release transition: %w", err)
+	}
+
+	return release, nil
+}
+
+// GetCycleReleases retrieves the release transitions of a cycle, oldest first
+func GetCycleReleases(cycleID int) ([]CycleRelease, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	query := `
+		SELECT id, cycle_id, from_status, to_status, user, reason,
+		       chemical_indicator, packaging_intact, dry, timestamp
+		FROM cycle_releases
+		WHERE cycle_id = ?
+		ORDER BY timestamp, id
+	`
+
+	rows, err := db.Query(query, cycleID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query cycle releases: %w", err)
+	}
+	defer rows.Close()
+
+	releases := []CycleRelease{}
+	for rows.Next() {
+		var release CycleRelease
+		var reason sql.NullString
+		var chemicalIndicator, packagingIntact, dry sql.NullBool
+
+		err := rows.Scan(
+			&release.ID,
+			&release.CycleID,
+			&release.FromStatus,
+			&release.ToStatus,
+			&release.User,
+			&reason,
+			&chemicalIndicator,
+			&packagingIntact,
+			&dry,
+			&release.Timestamp,
+		)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan cycle release: %w", err)
+		}
+
+		release.Reason = reason.String
+		if chemicalIndicator.Valid || packagingIntact.Valid || dry.Valid {
+			release.Checklist = &ReleaseChecklist{
+				ChemicalIndicator: chemicalIndicator.Bool,
+				PackagingIntact:   packagingIntact.Bool,
+				Dry:               dry.Bool,
+			}
+		}
+
+		releases = append(releases, release)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error iterating cycle releases: %w", err)
+	}
+
+	return releases, nil
+}
@@ -0,0 +1,111 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCycleReleaseWorkflow(t *testing.T) {
+	if err := InitializeDatabase(filepath.Join(t.TempDir(), "releases.db")); err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer Close()
+
+	device, err := CreateDevice(&Device{Name: "Steri 1", Manufacturer: "Melag", IP: "192.0.2.10", Type: "Steri"})
+	if err != nil {
+		t.Fatalf("Failed to create device: %v", err)
+	}
+	cycle, err := CreateCycle(&Cycle{DeviceID: device.ID, Program: "134 Universal", StartTS: time.Now().Add(-time.Hour)})
+	if err != nil {
+		t.Fatalf("Failed to create cycle: %v", err)
+	}
+
+	// Running cycles cannot be submitted
+	if _, err := TransitionCycleRelease(&CycleRelease{CycleID: cycle.ID, ToStatus: ReleaseStatusPending, User: "mmueller"}); err != ErrInvalidReleaseTransition {
+		t.Errorf("Expected ErrInvalidReleaseTransition for running cycle, got %v", err)
+	}
+
+	if err := UpdateCycleResult(cycle.ID, "OK", time.Now(), nil, nil); err != nil {
+		t.Fatalf("Failed to complete cycle: %v", err)
+	}
+	if completed, _ := GetCycle(cycle.ID); completed.ReleaseStatus != ReleaseStatusCompleted {
+		t.Errorf("Expected release status %s after completion, got %q", ReleaseStatusCompleted, completed.ReleaseStatus)
+	}
+
+	// Release requires a pending release
+	checklist := &ReleaseChecklist{ChemicalIndicator: true, PackagingIntact: true, Dry: true}
+	if _, err := TransitionCycleRelease(&CycleRelease{CycleID: cycle.ID, ToStatus: ReleaseStatusReleased, User: "mmueller", Checklist: checklist}); err != ErrInvalidReleaseTransition {
+		t.Errorf("Expected ErrInvalidReleaseTransition, got %v", err)
+	}
+	if _, err := TransitionCycleRelease(&CycleRelease{CycleID: cycle.ID, ToStatus: ReleaseStatusPending, User: "mmueller"}); err != nil {
+		t.Fatalf("Failed to submit cycle for release: %v", err)
+	}
+
+	if _, err := TransitionCycleRelease(&CycleRelease{CycleID: cycle.ID, ToStatus: ReleaseStatusReleased, User: " "}); err != ErrReleaseUserRequired {
+		t.Errorf("Expected ErrReleaseUserRequired, got %v", err)
+	}
+	incomplete := &ReleaseChecklist{ChemicalIndicator: true, PackagingIntact: true}
+	if _, err := TransitionCycleRelease(&CycleRelease{CycleID: cycle.ID, ToStatus: ReleaseStatusReleased, User: "akoch", Checklist: incomplete}); err != ErrReleaseChecklistIncomplete {
+		t.Errorf("Expected ErrReleaseChecklistIncomplete, got %v", err)
+	}
+	if _, err := TransitionCycleRelease(&CycleRelease{CycleID: cycle.ID, ToStatus: ReleaseStatusRejected, User: "akoch"}); err != ErrReleaseReasonRequired {
+		t.Errorf("Expected ErrReleaseReasonRequired, got %v", err)
+	}
+
+	release, err := TransitionCycleRelease(&CycleRelease{CycleID: cycle.ID, ToStatus: ReleaseStatusReleased, User: "akoch", Checklist: checklist})
+	if err != nil {
+		t.Fatalf("Failed to release cycle: %v", err)
+	}
+	if release.FromStatus != ReleaseStatusPending {
+		t.Errorf("Expected transition from %s, got %s", ReleaseStatusPending, release.FromStatus)
+	}
+
+	// Released is final
+	if _, err := TransitionCycleRelease(&CycleRelease{CycleID: cycle.ID, ToStatus: ReleaseStatusRejected, User: "akoch", Reason: "late"}); err != ErrInvalidReleaseTransition {
+		t.Errorf("Expected ErrInvalidReleaseTransition after release, got %v", err)
+	}
+
+	history, err := GetCycleReleases(cycle.ID)
+	if err != nil || len(history) != 2 {
+		t.Fatalf("Expected 2 release transitions, got %+v, %v", history, err)
+	}
+	if history[0].Checklist != nil || history[1].Checklist == nil || !history[1].Checklist.Dry || history[1].User != "akoch" {
+		t.Errorf("Unexpected release history: %+v", history)
+	}
+
+	released := ReleaseStatusReleased
+	if cycles, total, err := GetAllCycles(CycleListOptions{ReleaseStatus: &released}); err != nil || total != 1 || cycles[0].ReleaseStatus != ReleaseStatusReleased {
+		t.Errorf("Expected released cycle in filtered list, got %d, %v", total, err)
+	}
+}
+
+func TestCycleReleaseRequiresPassedCycle(t *testing.T) {
+	if err := InitializeDatabase(filepath.Join(t.TempDir(), "releases.db")); err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer Close()
+
+	device, err := CreateDevice(&Device{Name: "Steri 1", Manufacturer: "Melag", IP: "192.0.2.10", Type: "Steri"})
+	if err != nil {
+		t.Fatalf("Failed to create device: %v", err)
+	}
+	cycle, err := CreateCycle(&Cycle{DeviceID: device.ID, Program: "134 Universal", StartTS: time.Now().Add(-time.Hour)})
+	if err != nil {
+		t.Fatalf("Failed to create cycle: %v", err)
+	}
+	if err := UpdateCycleResult(cycle.ID, "NOK", time.Now(), nil, nil); err != nil {
+		t.Fatalf("Failed to complete cycle: %v", err)
+	}
+	if _, err := TransitionCycleRelease(&CycleRelease{CycleID: cycle.ID, ToStatus: ReleaseStatusPending, User: "mmueller"}); err != nil {
+		t.Fatalf("Failed to submit cycle for release: %v", err)
+	}
+
+	checklist := &ReleaseChecklist{ChemicalIndicator: true, PackagingIntact: true, Dry: true}
+	if _, err := TransitionCycleRelease(&CycleRelease{CycleID: cycle.ID, ToStatus: ReleaseStatusReleased, User: "akoch", Checklist: checklist}); err != ErrCycleNotPassed {
+		t.Errorf("Expected ErrCycleNotPassed, got %v", err)
+	}
+	if _, err := TransitionCycleRelease(&CycleRelease{CycleID: cycle.ID, ToStatus: ReleaseStatusRejected, User: "akoch", Reason: "Cycle aborted"}); err != nil {
+		t.Errorf("Failed to reject cycle: %v", err)
+	}
+}
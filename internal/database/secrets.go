@@ -0,0 +1,145 @@
+package database
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+)
+
+var (
+	ErrSecretKeyMissing = errors.New("secret key not configured")
+	ErrSecretInvalid    = errors.New("stored secret cannot be decrypted")
+)
+
+// secretKeySize is the AES-256 key length in bytes
+const secretKeySize = 32
+
+// secretPrefix marks the format version of encrypted secrets
+const secretPrefix = "v1:"
+
+var secretKey []byte
+var secretKeyMutex sync.RWMutex
+
+// SetSecretKey sets the AES-256 key used to encrypt device secrets at rest
+func SetSecretKey(key []byte) error {
+	if len(key) != secretKeySize {
+		return fmt.Errorf("secret key must be %d bytes, got %d", secretKeySize, len(key))
+	}
+
+	secretKeyMutex.Lock()
+	defer secretKeyMutex.Unlock()
+	secretKey = append([]byte(nil), key...)
+	return nil
+}
+
+// InitSecretKey loads the secret key from a base64 encoded value or, if empty, from keyFile
+// A new random key is written to keyFile (mode 0600) when the file does not exist yet.
+func InitSecretKey(encodedKey string, keyFile string) error {
+	if encodedKey != "" {
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(encodedKey))
+		if err != nil {
+			return fmt.Errorf("failed to decode secret key: %w", err)
+		}
+		return SetSecretKey(key)
+	}
+
+	data, err := os.ReadFile(keyFile)
+	if err == nil {
+		key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(data)))
+		if err != nil {
+			return fmt.Errorf("failed to decode secret key file %s: %w", keyFile, err)
+		}
+		return SetSecretKey(key)
+	}
+	if !os.IsNotExist(err) {
+		return fmt.Errorf("failed to read secret key file: %w", err)
+	}
+
+	key := make([]byte, secretKeySize)
+	if _, err := rand.Read(key); err != nil {
+		return fmt.Errorf("failed to generate secret key: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(keyFile), 0700); err != nil {
+		return fmt.Errorf("failed to create secret key directory: %w", err)
+	}
+	if err := os.WriteFile(keyFile, []byte(base64.StdEncoding.EncodeToString(key)+"\n"), 0600); err != nil {
+		return fmt.Errorf("failed to write secret key file: %w", err)
+	}
+	return SetSecretKey(key)
+}
+
+// encryptSecret encrypts a secret with AES-GCM; the context (e.g. "device:1") is
+// authenticated so a ciphertext cannot be copied to another row
+func encryptSecret(plaintext string, context string) (string, error) {
+	gcm, err := secretCipher()
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return "", fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	sealed := gcm.Seal(nonce, nonce, []byte(plaintext), []byte(context))
+	return secretPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// decryptSecret reverses encryptSecret
+func decryptSecret(ciphertext string, context string) (string, error) {
+	gcm, err := secretCipher()
+	if err != nil {
+		return "", err
+	}
+
+	if !strings.HasPrefix(ciphertext, secretPrefix) {
+		return "", ErrSecretInvalid
+	}
+	sealed, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(ciphertext, secretPrefix))
+	if err != nil || len(sealed) < gcm.NonceSize() {
+		return "", ErrSecretInvalid
+	}
+	plaintext, err := gcm.Open(nil, sealed[:gcm.NonceSize()], sealed[gcm.NonceSize():], []byte(context))
+	if err != nil {
+		return "", ErrSecretInvalid
+	}
+	return string(plaintext), nil
+}
+
+// auditHashKey derives the key of the audit log hash chain from the secret key
+// The derivation keeps the HMAC key separate from the encryption key.
+func auditHashKey() ([]byte, error) {
+	secretKeyMutex.RLock()
+	key := secretKey
+	secretKeyMutex.RUnlock()
+
+	if key == nil {
+		return nil, ErrSecretKeyMissing
+	}
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("steri-connect audit log"))
+	return mac.Sum(nil), nil
+}
+
+func secretCipher() (cipher.AEAD, error) {
+	secretKeyMutex.RLock()
+	key := secretKey
+	secretKeyMutex.RUnlock()
+
+	if key == nil {
+		return nil, ErrSecretKeyMissing
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
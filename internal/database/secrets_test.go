@@ -0,0 +1,65 @@
+package database
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestDeviceConnectionSettingsEncryptedAtRest(t *testing.T) {
+	dir := t.TempDir()
+	if err := InitializeDatabase(filepath.Join(dir, "test.db")); err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer Close()
+
+	if err := InitSecretKey("", filepath.Join(dir, "secret.key")); err != nil {
+		t.Fatalf("Failed to initialize secret key: %v", err)
+	}
+
+	device, err := CreateDevice(&Device{Name: "Steri 1", Manufacturer: "Melag", IP: "192.168.1.10", Type: "Steri"})
+	if err != nil {
+		t.Fatalf("Failed to create device: %v", err)
+	}
+
+	settings := &DeviceConnectionSettings{DeviceID: device.ID, Port: 2121, Username: "praxis", TLSMode: TLSModeExplicit}
+	if err := settings.SetPassword("geheim"); err != nil {
+		t.Fatalf("Failed to set password: %v", err)
+	}
+	if err := SaveDeviceConnectionSettings(settings); err != nil {
+		t.Fatalf("Failed to save connection settings: %v", err)
+	}
+
+	var stored string
+	if err := db.QueryRow("SELECT secret FROM device_connection_settings WHERE device_id = ?", device.ID).Scan(&stored); err != nil {
+		t.Fatalf("Failed to read stored secret: %v", err)
+	}
+	if stored == "" || strings.Contains(stored, "geheim") {
+		t.Errorf("Password must be stored encrypted, got %q", stored)
+	}
+
+	loaded, err := GetDeviceConnectionSettings(device.ID)
+	if err != nil {
+		t.Fatalf("Failed to load connection settings: %v", err)
+	}
+	if password, err := loaded.Password(); err != nil || password != "geheim" {
+		t.Errorf("Expected decrypted password, got %q, %v", password, err)
+	}
+	if loaded.Port != 2121 || loaded.Username != "praxis" || loaded.TLSMode != TLSModeExplicit {
+		t.Errorf("Unexpected settings %+v", loaded)
+	}
+
+	// The same key file must be reused, a different key must not decrypt
+	if err := InitSecretKey("", filepath.Join(dir, "secret.key")); err != nil {
+		t.Fatalf("Failed to reload secret key: %v", err)
+	}
+	if _, err := loaded.Password(); err != nil {
+		t.Errorf("Expected password to decrypt with reloaded key, got %v", err)
+	}
+	if err := SetSecretKey(make([]byte, secretKeySize)); err != nil {
+		t.Fatalf("Failed to set key: %v", err)
+	}
+	if _, err := loaded.Password(); err != ErrSecretInvalid {
+		t.Errorf("Expected ErrSecretInvalid with wrong key, got %v", err)
+	}
+}
@@ -0,0 +1,96 @@
+package database
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+var ErrValidationNotFound = errors.New("cycle validation not found")
+
+// Results of the parameter validation (see CycleValidation)
+const (
+	ValidationPass         = "PASS"          // All rules of the program profile met
+	ValidationFail         = "FAIL"          // At least one rule violated or not verifiable
+	ValidationNotValidated = "NOT_VALIDATED" // No profile for the program
+)
+
+// SaveCycleValidation stores the validation of a cycle, replacing an earlier one
+func SaveCycleValidation(validation *CycleValidation) error {
+	if db == nil {
+		return fmt.Errorf("database not initialized")
+	}
+
+	reasons, err := json.Marshal(validation.Reasons)
+	if err != nil {
+		return fmt.Errorf("failed to marshal validation reasons: %w", err)
+	}
+	var profile sql.NullString
+	if validation.Profile != "" {
+		profile = sql.NullString{String: validation.Profile, Valid: true}
+	}
+
+	tx, err := db.Begin()
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`UPDATE cycles SET validation_result = ? WHERE id = ?`, validation.Result, validation.CycleID)
+	if err != nil {
+		return fmt.Errorf("failed to update cycle validation result: %w", err)
+	}
+	if rows, err := result.RowsAffected(); err != nil {
+		return fmt.Errorf("failed to check cycle update: %w", err)
+	} else if rows == 0 {
+		return ErrCycleNotFound
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO cycle_validations (cycle_id, result, profile, reasons, validated_at)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(cycle_id) DO UPDATE SET
+			result = excluded.result,
+			profile = excluded.profile,
+			reasons = excluded.reasons,
+			validated_at = excluded.validated_at
+	`, validation.CycleID, validation.Result, profile, string(reasons), validation.ValidatedAt)
+	if err != nil {
+		return fmt.Errorf("failed to save cycle validation: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit cycle validation: %w", err)
+	}
+	return nil
+}
+
+// GetCycleValidation retrieves the validation of a cycle
+// Returns ErrValidationNotFound if the cycle was never validated.
+func GetCycleValidation(cycleID int) (*CycleValidation, error) {
+	if db == nil {
+		return nil, fmt.Errorf("database not initialized")
+	}
+
+	validation := &CycleValidation{}
+	var profile sql.NullString
+	var reasons string
+	err := db.QueryRow(`
+		SELECT cycle_id, result, profile, reasons, validated_at
+		FROM cycle_validations
+		WHERE cycle_id = ?
+	`, cycleID).Scan(&validation.CycleID, &validation.Result, &profile, &reasons, &validation.ValidatedAt)
+	if err == sql.ErrNoRows {
+		return nil, ErrValidationNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get cycle validation: %w", err)
+	}
+
+	validation.Profile = profile.String
+	if err := json.Unmarshal([]byte(reasons), &validation.Reasons); err != nil {
+		return nil, fmt.Errorf("failed to parse validation reasons: %w", err)
+	}
+	return validation, nil
+}
@@ -0,0 +1,59 @@
+package database
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSaveCycleValidation(t *testing.T) {
+	if err := InitializeDatabase(filepath.Join(t.TempDir(), "validations.db")); err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer Close()
+
+	device, err := CreateDevice(&Device{Name: "Vacuklav", Manufacturer: "Melag", IP: "192.168.1.10", Type: "Steri"})
+	if err != nil {
+		t.Fatalf("Failed to create device: %v", err)
+	}
+	cycle, err := CreateCycle(&Cycle{DeviceID: device.ID, Program: "Universal-Programm", StartTS: time.Now().Round(0)})
+	if err != nil {
+		t.Fatalf("Failed to create cycle: %v", err)
+	}
+
+	if _, err := GetCycleValidation(cycle.ID); err != ErrValidationNotFound {
+		t.Errorf("Expected ErrValidationNotFound, got %v", err)
+	}
+
+	validation := &CycleValidation{
+		CycleID:     cycle.ID,
+		Result:      ValidationFail,
+		Profile:     "134 °C / 3:30 min",
+		Reasons:     []ValidationReason{{Rule: "hold_time", Passed: false, Message: "Held for 2:00 min"}},
+		ValidatedAt: time.Now().Round(0),
+	}
+	if err := SaveCycleValidation(validation); err != nil {
+		t.Fatalf("SaveCycleValidation failed: %v", err)
+	}
+	// Validating again replaces the verdict
+	validation.Result = ValidationPass
+	validation.Reasons = []ValidationReason{{Rule: "hold_time", Passed: true, Message: "Held for 4:00 min"}}
+	if err := SaveCycleValidation(validation); err != nil {
+		t.Fatalf("SaveCycleValidation failed on revalidation: %v", err)
+	}
+
+	stored, err := GetCycleValidation(cycle.ID)
+	if err != nil {
+		t.Fatalf("GetCycleValidation failed: %v", err)
+	}
+	if stored.Result != ValidationPass || stored.Profile != validation.Profile || len(stored.Reasons) != 1 || !stored.Reasons[0].Passed {
+		t.Errorf("Unexpected validation %+v", stored)
+	}
+	if updated, err := GetCycle(cycle.ID); err != nil || updated.ValidationResult != ValidationPass {
+		t.Errorf("Expected validation result on cycle, got %+v, err %v", updated, err)
+	}
+
+	if err := SaveCycleValidation(&CycleValidation{CycleID: 9999, Result: ValidationPass, ValidatedAt: time.Now()}); err != ErrCycleNotFound {
+		t.Errorf("Expected ErrCycleNotFound for unknown cycle, got %v", err)
+	}
+}
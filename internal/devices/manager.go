@@ -1,14 +1,12 @@
 package devices
 
 import (
+	"errors"
 	"fmt"
 	"sync"
 	"time"
 
 	"steri-connect-go/internal/adapters"
-	"steri-connect-go/internal/adapters/getinge"
-	"steri-connect-go/internal/adapters/melag"
-	"steri-connect-go/internal/config"
 	"steri-connect-go/internal/database"
 	"steri-connect-go/internal/logging"
 	"steri-connect-go/internal/api/websocket"
@@ -17,14 +15,28 @@ import (
 // Manager manages device connections
 type Manager struct {
 	adapters         map[int]adapters.DeviceAdapter
+	registrations    map[int]adapters.Registration // Device family of each adapter (guarded by adaptersMutex)
 	adaptersMutex    sync.RWMutex
 	logger           *logging.Logger
-	retryInterval    time.Duration
-	maxRetries       int
 	pingMonitors     map[int]chan bool // Channel to stop ping monitoring for each device
 	pingMonitorsMutex sync.RWMutex
+	cycleWatchers    map[int]chan bool // Channel to stop watching for device-started cycles
+	cycleWatchersMutex sync.Mutex
+	supervisors      map[int]*connectionSupervisor // Connection supervisor of each device
+	cyclePollers     map[int]*ActiveCycle // Polled cycles by cycle ID
+	cycleStarts      map[int]bool         // Devices with a cycle start in progress
+	watchdogStop     chan bool            // Stops the stale cycle watchdog (nil if not running)
+	cyclePollersMutex sync.Mutex
+	supervisorsMutex sync.Mutex
+	endpointResolver EndpointResolver
+	importJobs       map[int]*ImportJob // Latest protocol import per device
+	importMutex      sync.Mutex
 }
 
+// EndpointResolver returns the host:port an adapter should talk to instead of
+// the device IP, or "" to keep the default (used by the device simulator)
+type EndpointResolver func(device *database.Device) string
+
 var globalManager *Manager
 var managerMutex sync.RWMutex
 
@@ -46,13 +58,24 @@ func SetManager(manager *Manager) {
 func NewManager() *Manager {
 	return &Manager{
 		adapters:      make(map[int]adapters.DeviceAdapter),
+		registrations: make(map[int]adapters.Registration),
 		logger:        logging.Get(),
-		retryInterval: 5 * time.Second,
-		maxRetries:    3,
 		pingMonitors:  make(map[int]chan bool),
+		cycleWatchers: make(map[int]chan bool),
+		supervisors:   make(map[int]*connectionSupervisor),
+		cyclePollers:  make(map[int]*ActiveCycle),
+		cycleStarts:   make(map[int]bool),
+		importJobs:    make(map[int]*ImportJob),
 	}
 }
 
+// SetEndpointResolver sets the resolver applied to adapters created afterwards
+func (m *Manager) SetEndpointResolver(resolver EndpointResolver) {
+	m.adaptersMutex.Lock()
+	defer m.adaptersMutex.Unlock()
+	m.endpointResolver = resolver
+}
+
 // LoadDevices loads all devices from database and initializes connections
 func (m *Manager) LoadDevices() error {
 	m.logger.Info("Loading devices from database")
@@ -79,6 +102,9 @@ func (m *Manager) LoadDevices() error {
 		}
 	}
 
+	// Cycles that were running when the service stopped
+	m.resumeRunningCycles()
+
 	return nil
 }
 
@@ -96,41 +122,44 @@ func (m *Manager) AddDevice(device *database.Device) error {
 		return fmt.Errorf("adapter for device %d already exists", device.ID)
 	}
 
-	// Create adapter based on manufacturer
-	var adapter adapters.DeviceAdapter
-	var err error
+	// Resolve endpoint override (e.g. device simulator)
+	endpoint := ""
+	if m.endpointResolver != nil {
+		endpoint = m.endpointResolver(device)
+	}
 
-	switch device.Manufacturer {
-	case "Melag":
-		adapter, err = melag.NewMelagAdapter(device)
-		if err != nil {
-			return fmt.Errorf("failed to create Melag adapter: %w", err)
-		}
-	case "Getinge":
-		cfg := config.Get()
-		pingTimeout := time.Duration(cfg.Devices.Getinge.PingTimeout) * time.Second
-		if pingTimeout == 0 {
-			pingTimeout = 5 * time.Second // Default
-		}
-		adapter, err = getinge.NewGetingeAdapter(device, pingTimeout)
-		if err != nil {
-			return fmt.Errorf("failed to create Getinge adapter: %w", err)
-		}
-		// Start ping monitoring for Getinge devices
-		m.startPingMonitoring(device.ID, adapter)
-	default:
+	// Create adapter from the manufacturer's registered factory
+	registration, exists := adapters.Lookup(device.Manufacturer)
+	if !exists {
 		return fmt.Errorf("unsupported manufacturer: %s", device.Manufacturer)
 	}
+	adapter, err := registration.New(device, adapters.FactoryOptions{Endpoint: endpoint})
+	if err != nil {
+		return fmt.Errorf("failed to create %s adapter: %w", device.Manufacturer, err)
+	}
+
+	// Devices that only report reachability are monitored by periodic probes
+	if registration.HasCapability(adapters.CapabilityReachability) {
+		if prober, ok := adapter.(adapters.ReachabilityProber); ok {
+			m.startPingMonitoring(device.ID, prober)
+		}
+	}
+
+	// Cycles of devices that cannot be started remotely are picked up when the device starts them
+	if observesCycles(registration, adapter) {
+		m.startCycleWatch(device.ID, adapter.(adapters.CycleStatusReader))
+	}
 
 	m.adapters[device.ID] = adapter
+	m.registrations[device.ID] = registration
 
 	m.logger.Info("Device adapter created",
 		"device_id", device.ID,
 		"device_name", device.Name,
 		"manufacturer", device.Manufacturer)
 
-	// Attempt to connect in background
-	go m.connectDeviceWithRetry(adapter)
+	// Connect in background and keep the device connected
+	m.startSupervisor(adapter)
 
 	return nil
 }
@@ -140,8 +169,11 @@ func (m *Manager) RemoveDevice(deviceID int) error {
 	m.adaptersMutex.Lock()
 	defer m.adaptersMutex.Unlock()
 
-	// Stop ping monitoring if active
+	// Stop connection supervisor, ping monitoring, cycle watch and cycle polling if active
+	m.stopSupervisor(deviceID)
 	m.stopPingMonitoring(deviceID)
+	m.stopCycleWatch(deviceID)
+	m.stopDeviceCyclePolling(deviceID)
 
 	adapter, exists := m.adapters[deviceID]
 	if !exists {
@@ -156,6 +188,7 @@ func (m *Manager) RemoveDevice(deviceID int) error {
 	}
 
 	delete(m.adapters, deviceID)
+	delete(m.registrations, deviceID)
 
 	m.logger.Info("Device adapter removed",
 		"device_id", deviceID)
@@ -163,34 +196,79 @@ func (m *Manager) RemoveDevice(deviceID int) error {
 	return nil
 }
 
-// ConnectDevice connects to a device
+// ReloadDevice recreates a device's adapter from the database, e.g. after its
+// connection settings changed. The device reconnects in the background.
+func (m *Manager) ReloadDevice(deviceID int) error {
+	device, err := database.GetDevice(deviceID)
+	if err != nil {
+		return err
+	}
+
+	// Cycles polled for the device continue with the new adapter
+	var polledCycles []int
+	if m.getAdapter(deviceID) != nil {
+		polledCycles = m.stopDeviceCyclePolling(deviceID)
+		if err := m.RemoveDevice(deviceID); err != nil {
+			return err
+		}
+	}
+
+	m.logger.Info("Reloading device adapter",
+		"device_id", deviceID,
+		"device_name", device.Name)
+
+	if err := m.AddDevice(device); err != nil {
+		return err
+	}
+	for _, cycleID := range polledCycles {
+		if cycle, err := database.GetCycle(cycleID); err == nil {
+			resumeAdapterCycle(m.getAdapter(deviceID), cycle)
+		}
+		if err := m.StartCyclePolling(cycleID, deviceID); err != nil {
+			m.logger.Warn("Failed to resume cycle polling after reload",
+				"cycle_id", cycleID,
+				"device_id", deviceID,
+				"error", err)
+		}
+	}
+	return nil
+}
+
+// ConnectDevice connects to a device now and supervises the connection again
+// If the attempt fails, the supervisor keeps retrying with backoff.
 func (m *Manager) ConnectDevice(deviceID int) error {
 	adapter := m.getAdapter(deviceID)
 	if adapter == nil {
 		return fmt.Errorf("device %d not found", deviceID)
 	}
 
+	m.stopSupervisor(deviceID)
+	defer m.startSupervisor(adapter)
+
 	if err := adapter.Connect(); err != nil {
-		m.broadcastStatusChange(deviceID, adapter.GetConnectionState())
+		m.updateConnectionState(deviceID, adapter.GetConnectionState(), database.ConnectionReasonConnect, err)
 		return err
 	}
 
-	m.broadcastStatusChange(deviceID, adapter.GetConnectionState())
+	m.updateConnectionState(deviceID, adapter.GetConnectionState(), database.ConnectionReasonConnect, nil)
 	return nil
 }
 
 // DisconnectDevice disconnects from a device
+// The connection is no longer supervised until ConnectDevice is called.
 func (m *Manager) DisconnectDevice(deviceID int) error {
 	adapter := m.getAdapter(deviceID)
 	if adapter == nil {
 		return fmt.Errorf("device %d not found", deviceID)
 	}
 
+	m.stopSupervisor(deviceID)
+
 	if err := adapter.Disconnect(); err != nil {
 		return err
 	}
 
-	m.broadcastStatusChange(deviceID, adapter.GetConnectionState())
+	m.updateConnectionState(deviceID, adapter.GetConnectionState(), database.ConnectionReasonDisconnect, nil)
 	return nil
 }
 
@@ -199,6 +277,35 @@ func (m *Manager) GetAdapter(deviceID int) adapters.DeviceAdapter {
 	return m.getAdapter(deviceID)
 }
 
+// HasCapability reports whether the device's family declares the capability
+// and its adapter implements the matching optional interface
+func (m *Manager) HasCapability(deviceID int, capability adapters.Capability) bool {
+	m.adaptersMutex.RLock()
+	defer m.adaptersMutex.RUnlock()
+
+	registration, exists := m.registrations[deviceID]
+	return exists && registration.HasCapability(capability) && adapters.Supports(m.adapters[deviceID], capability)
+}
+
+// GetCapabilities returns the capabilities the device supports (nil if no adapter is loaded)
+func (m *Manager) GetCapabilities(deviceID int) []adapters.Capability {
+	m.adaptersMutex.RLock()
+	defer m.adaptersMutex.RUnlock()
+
+	registration, exists := m.registrations[deviceID]
+	if !exists {
+		return nil
+	}
+
+	capabilities := []adapters.Capability{}
+	for _, capability := range registration.Capabilities {
+		if adapters.Supports(m.adapters[deviceID], capability) {
+			capabilities = append(capabilities, capability)
+		}
+	}
+	return capabilities
+}
+
 // GetConnectionInfo returns connection information for a device
 func (m *Manager) GetConnectionInfo(deviceID int) (*adapters.ConnectionInfo, error) {
 	adapter := m.getAdapter(deviceID)
@@ -222,35 +329,56 @@ func (m *Manager) GetConnectionInfo(deviceID int) (*adapters.ConnectionInfo, err
 	return info, nil
 }
 
-// connectDeviceWithRetry attempts to connect with retry logic
-func (m *Manager) connectDeviceWithRetry(adapter adapters.DeviceAdapter) {
-	deviceID := adapter.GetDeviceID()
-	retries := 0
-
-	for retries < m.maxRetries {
-		err := adapter.Connect()
-		if err == nil {
-			m.broadcastStatusChange(deviceID, adapter.GetConnectionState())
-			return
-		}
+// updateConnectionState records a connection state observed for a device and broadcasts it
+// A non-nil err marks a failed attempt (see database.RecordDeviceConnectionState).
+func (m *Manager) updateConnectionState(deviceID int, state adapters.ConnectionState, reason string, err error) {
+	m.recordConnectionState(deviceID, state, reason, err)
+	m.broadcastStatusChange(deviceID, state)
+}
 
-		retries++
-		m.logger.Warn("Connection attempt failed, retrying",
+// recordConnectionState stores a connection state observed for a device
+// State changes are logged and broadcast as device_connection_transition event.
+func (m *Manager) recordConnectionState(deviceID int, state adapters.ConnectionState, reason string, err error) {
+	errorText := ""
+	if err != nil {
+		errorText = err.Error()
+	}
+	_, transition, recordErr := database.RecordDeviceConnectionState(deviceID, string(state), reason, errorText)
+	if recordErr != nil {
+		m.logger.Warn("Failed to record device connection state",
 			"device_id", deviceID,
-			"attempt", retries,
-			"max_retries", m.maxRetries,
-			"error", err)
-
-		if retries < m.maxRetries {
-			time.Sleep(m.retryInterval)
-		}
+			"state", state,
+			"error", recordErr)
+		return
+	}
+	if transition == nil {
+		return
 	}
 
-	m.logger.Error("Failed to connect after all retries",
+	m.logger.Info("Device connection state changed",
 		"device_id", deviceID,
-		"max_retries", m.maxRetries)
+		"from", transition.FromState,
+		"to", transition.ToState,
+		"reason", reason,
+		"error", errorText)
 
-	m.broadcastStatusChange(deviceID, adapter.GetConnectionState())
+	event := websocket.Event{
+		Event: "device_connection_transition",
+		Data: map[string]interface{}{
+			"device_id":   deviceID,
+			"from_state":  transition.FromState,
+			"to_state":    transition.ToState,
+			"reason":      transition.Reason,
+			"error":       transition.Error,
+			"duration_ms": transition.DurationMs,
+			"timestamp":   transition.Timestamp.Format(time.RFC3339),
+		},
+	}
+	if err := websocket.BroadcastEvent(event); err != nil {
+		m.logger.Warn("Failed to broadcast device connection transition",
+			"device_id", deviceID,
+			"error", err)
+	}
 }
 
 // broadcastStatusChange broadcasts device status change via WebSocket
@@ -286,17 +414,25 @@ func (m *Manager) getAdapter(deviceID int) adapters.DeviceAdapter {
 func (m *Manager) Shutdown() error {
 	m.logger.Info("Shutting down device manager")
 
+	m.cancelProtocolImports()
+	m.stopCycleWatchdog()
+
 	m.adaptersMutex.Lock()
 	defer m.adaptersMutex.Unlock()
 
 	var errors []error
 	for deviceID, adapter := range m.adapters {
+		m.stopSupervisor(deviceID)
+		// Running cycles stay in the database and are resumed on the next start
+		m.stopDeviceCyclePolling(deviceID)
 		if err := adapter.Disconnect(); err != nil {
 			m.logger.Warn("Error disconnecting device during shutdown",
 				"device_id", deviceID,
 				"error", err)
 			errors = append(errors, fmt.Errorf("device %d: %w", deviceID, err))
 		}
+		// The stored state must not claim a connection while the service is stopped
+		m.recordConnectionState(deviceID, adapters.StateDisconnected, database.ConnectionReasonShutdown, nil)
 	}
 
 	if len(errors) > 0 {
@@ -307,6 +443,9 @@ func (m *Manager) Shutdown() error {
 	return nil
 }
 
+// ErrCycleRunning is returned when a device already has a running or starting cycle
+var ErrCycleRunning = errors.New("device already has a running cycle")
+
 // ActiveCycle represents an active cycle being monitored
 type ActiveCycle struct {
 	CycleID     int
@@ -314,27 +453,123 @@ type ActiveCycle struct {
 	StopPolling chan bool
 }
 
+// BeginCycleStart reserves a device for starting a cycle, so concurrent start
+// requests cannot both reach the device. Release with EndCycleStart.
+// Returns ErrCycleRunning if the device has a polled cycle or a start in progress.
+func (m *Manager) BeginCycleStart(deviceID int) error {
+	m.cyclePollersMutex.Lock()
+	defer m.cyclePollersMutex.Unlock()
+
+	if m.cycleStarts[deviceID] {
+		return fmt.Errorf("%w: a cycle start is in progress", ErrCycleRunning)
+	}
+	if active := m.deviceCyclePollerUnsafe(deviceID); active != nil {
+		return fmt.Errorf("%w: cycle %d", ErrCycleRunning, active.CycleID)
+	}
+	m.cycleStarts[deviceID] = true
+	return nil
+}
+
+// EndCycleStart releases the reservation taken by BeginCycleStart
+func (m *Manager) EndCycleStart(deviceID int) {
+	m.cyclePollersMutex.Lock()
+	defer m.cyclePollersMutex.Unlock()
+	delete(m.cycleStarts, deviceID)
+}
+
+// GetActiveCycle returns the cycle polled for a device (nil if none)
+func (m *Manager) GetActiveCycle(deviceID int) *ActiveCycle {
+	m.cyclePollersMutex.Lock()
+	defer m.cyclePollersMutex.Unlock()
+	return m.deviceCyclePollerUnsafe(deviceID)
+}
+
+// deviceCyclePollerUnsafe returns the poller of a device's cycle (caller must hold cyclePollersMutex)
+func (m *Manager) deviceCyclePollerUnsafe(deviceID int) *ActiveCycle {
+	for _, active := range m.cyclePollers {
+		if active.DeviceID == deviceID {
+			return active
+		}
+	}
+	return nil
+}
+
 // StartCyclePolling starts polling for a cycle's status
-func (m *Manager) StartCyclePolling(cycleID int, deviceID int) {
+// Returns ErrCycleRunning if another cycle of the device is already polled.
+func (m *Manager) StartCyclePolling(cycleID int, deviceID int) error {
+	m.cyclePollersMutex.Lock()
+	if _, exists := m.cyclePollers[cycleID]; exists {
+		m.cyclePollersMutex.Unlock()
+		return nil // Already polled
+	}
+	if active := m.deviceCyclePollerUnsafe(deviceID); active != nil {
+		m.cyclePollersMutex.Unlock()
+		return fmt.Errorf("%w: cycle %d", ErrCycleRunning, active.CycleID)
+	}
+	active := &ActiveCycle{
+		CycleID:     cycleID,
+		DeviceID:    deviceID,
+		StopPolling: make(chan bool),
+	}
+	m.cyclePollers[cycleID] = active
+	m.cyclePollersMutex.Unlock()
+
 	m.logger.Info("Starting cycle status polling",
 		"cycle_id", cycleID,
 		"device_id", deviceID)
 
-	stopChan := make(chan bool)
-
 	// Start polling goroutine
-	go m.pollCycleStatus(cycleID, deviceID, stopChan)
+	go m.pollCycleStatus(active)
+	return nil
 }
 
 // StopCyclePolling stops polling for a cycle's status
 func (m *Manager) StopCyclePolling(cycleID int) {
-	m.logger.Info("Stopping cycle status polling",
-		"cycle_id", cycleID)
-	// Polling will stop automatically when cycle completes
+	m.cyclePollersMutex.Lock()
+	defer m.cyclePollersMutex.Unlock()
+
+	if active, exists := m.cyclePollers[cycleID]; exists {
+		close(active.StopPolling)
+		delete(m.cyclePollers, cycleID)
+		m.logger.Info("Stopping cycle status polling",
+			"cycle_id", cycleID)
+	}
+}
+
+// stopDeviceCyclePolling stops polling the cycles of a device
+// Returns the IDs of the cycles that were polled.
+func (m *Manager) stopDeviceCyclePolling(deviceID int) []int {
+	m.cyclePollersMutex.Lock()
+	defer m.cyclePollersMutex.Unlock()
+
+	var cycleIDs []int
+	for cycleID, active := range m.cyclePollers {
+		if active.DeviceID != deviceID {
+			continue
+		}
+		close(active.StopPolling)
+		delete(m.cyclePollers, cycleID)
+		cycleIDs = append(cycleIDs, cycleID)
+		m.logger.Info("Stopping cycle status polling",
+			"cycle_id", cycleID,
+			"device_id", deviceID)
+	}
+	return cycleIDs
+}
+
+// finishCyclePolling removes a poller whose cycle has ended
+func (m *Manager) finishCyclePolling(active *ActiveCycle) {
+	m.cyclePollersMutex.Lock()
+	defer m.cyclePollersMutex.Unlock()
+
+	if m.cyclePollers[active.CycleID] == active {
+		delete(m.cyclePollers, active.CycleID)
+	}
 }
 
 // pollCycleStatus polls the device for cycle status updates
-func (m *Manager) pollCycleStatus(cycleID int, deviceID int, stopChan chan bool) {
+func (m *Manager) pollCycleStatus(active *ActiveCycle) {
+	cycleID, deviceID := active.CycleID, active.DeviceID
 	ticker := time.NewTicker(2 * time.Second) // Poll every 2 seconds
 	defer ticker.Stop()
 
@@ -343,9 +578,10 @@ func (m *Manager) pollCycleStatus(cycleID int, deviceID int, stopChan chan bool)
 		"device_id", deviceID,
 		"interval", "2s")
 
+	var recorded cycleRecord
 	for {
 		select {
-		case <-stopChan:
+		case <-active.StopPolling:
 			m.logger.Info("Cycle status polling stopped",
 				"cycle_id", cycleID)
 			return
@@ -360,17 +596,16 @@ func (m *Manager) pollCycleStatus(cycleID int, deviceID int, stopChan chan bool)
 				continue
 			}
 
-			// Cast to MelagAdapter
-			melagAdapter, ok := adapter.(*melag.MelagAdapter)
+			statusReader, ok := adapter.(adapters.CycleStatusReader)
 			if !ok {
-				m.logger.Warn("Adapter is not a MelagAdapter",
+				m.logger.Warn("Adapter does not report cycle status",
 					"cycle_id", cycleID,
 					"device_id", deviceID)
 				continue
 			}
 
 			// Get cycle status
-			status, err := melagAdapter.GetCycleStatus()
+			status, err := statusReader.GetCycleStatus()
 			if err != nil {
 				m.logger.Warn("Failed to get cycle status",
 					"cycle_id", cycleID,
@@ -379,149 +614,241 @@ func (m *Manager) pollCycleStatus(cycleID int, deviceID int, stopChan chan bool)
 				continue
 			}
 
-			// Check if cycle is still running
-			if !status.IsRunning && status.Phase == "COMPLETED" {
-				m.logger.Info("Cycle completed, stopping polling",
-					"cycle_id", cycleID,
-					"device_id", deviceID)
-
-				// Update cycle result and end timestamp
-				endTime := time.Now()
-				err = database.UpdateCycleResult(cycleID, "OK", endTime, nil, nil)
-				if err != nil {
-					m.logger.Error("Failed to update cycle result",
-						"cycle_id", cycleID,
-						"error", err)
-				}
-
-				// Broadcast cycle_completed event
-				event := websocket.Event{
-					Event: "cycle_completed",
-					Data: map[string]interface{}{
-						"cycle_id":  cycleID,
-						"device_id": deviceID,
-						"result":    "OK",
-						"end_ts":    endTime.Format(time.RFC3339),
-					},
-				}
-				if err := websocket.BroadcastEvent(event); err != nil {
-					m.logger.Warn("Failed to broadcast cycle_completed event",
-						"cycle_id", cycleID,
-						"error", err)
-				}
-
-				// Log audit entry
-				details := map[string]interface{}{
-					"cycle_id": cycleID,
-					"device_id": deviceID,
-					"result":   "OK",
-				}
-				if err := database.LogAudit(database.ActionCycleCompleted, "cycle", &cycleID, "", details); err != nil {
-					m.logger.Warn("Failed to log cycle completion audit",
-						"cycle_id", cycleID,
-						"error", err)
-				}
-
+			if m.applyCycleStatus(cycleID, deviceID, status, &recorded) {
+				m.finishCyclePolling(active)
 				return
 			}
+		}
+	}
+}
 
-			if !status.IsRunning && status.Phase == "FAILED" {
-				m.logger.Info("Cycle failed, stopping polling",
-					"cycle_id", cycleID,
-					"device_id", deviceID)
+// cycleRecord holds the values already written for a cycle, so they are only updated on change
+type cycleRecord struct {
+	cycleNumber string
+	a0Value     *float64
+	lastSample  *database.CycleSample // Last stored process values (see recordCycleSample)
+}
 
-				// Update cycle result and end timestamp
-				endTime := time.Now()
-				errorDesc := "Cycle failed - see device logs"
-				err = database.UpdateCycleResult(cycleID, "NOK", endTime, nil, &errorDesc)
-				if err != nil {
-					m.logger.Error("Failed to update cycle result",
-						"cycle_id", cycleID,
-						"error", err)
-				}
-
-				// Broadcast cycle_failed event
-				event := websocket.Event{
-					Event: "cycle_failed",
-					Data: map[string]interface{}{
-						"cycle_id":        cycleID,
-						"device_id":       deviceID,
-						"result":          "NOK",
-						"error_description": errorDesc,
-						"end_ts":          endTime.Format(time.RFC3339),
-					},
-				}
-				if err := websocket.BroadcastEvent(event); err != nil {
-					m.logger.Warn("Failed to broadcast cycle_failed event",
-						"cycle_id", cycleID,
-						"error", err)
-				}
-
-				// Log audit entry
-				details := map[string]interface{}{
-					"cycle_id": cycleID,
-					"device_id": deviceID,
-					"result":   "NOK",
-					"error_description": errorDesc,
-				}
-				if err := database.LogAudit(database.ActionCycleFailed, "cycle", &cycleID, "", details); err != nil {
-					m.logger.Warn("Failed to log cycle failure audit",
-						"cycle_id", cycleID,
-						"error", err)
-				}
+// applyCycleStatus stores a status read from the device for a cycle and broadcasts it
+// Returns true once the cycle has completed or failed.
+func (m *Manager) applyCycleStatus(cycleID int, deviceID int, status adapters.CycleStatus, recorded *cycleRecord) bool {
+	// Remember the device's cycle number so a later archive import does not duplicate the cycle
+	if status.CycleNumber != "" && status.CycleNumber != recorded.cycleNumber {
+		if err := database.SetCycleDeviceCycleNumber(cycleID, status.CycleNumber); err != nil {
+			m.logger.Warn("Failed to record device cycle number",
+				"cycle_id", cycleID,
+				"device_cycle_number", status.CycleNumber,
+				"error", err)
+		}
+		recorded.cycleNumber = status.CycleNumber
+	}
 
-				return
-			}
+	// Washer-disinfectors report the accumulated A0 value
+	if status.A0Value != nil && (recorded.a0Value == nil || *recorded.a0Value != *status.A0Value) {
+		if err := database.SetCycleA0Value(cycleID, *status.A0Value); err != nil {
+			m.logger.Warn("Failed to record A0 value",
+				"cycle_id", cycleID,
+				"a0_value", *status.A0Value,
+				"error", err)
+		}
+		recorded.a0Value = status.A0Value
+	}
 
-			// Update cycle status in database
-			var progress *int
-			if status.ProgressPercent >= 0 {
-				progressVal := status.ProgressPercent
-				progress = &progressVal
-			}
+	// Check if cycle is still running
+	if !status.IsRunning && status.Phase == "COMPLETED" {
+		m.logger.Info("Cycle completed, stopping polling",
+			"cycle_id", cycleID,
+			"device_id", deviceID)
+
+		// The reading at completion is the last point of the curve; samples are only stored while the cycle runs
+		m.recordCycleSample(cycleID, status, recorded)
+
+		// Update cycle result and end timestamp
+		endTime := time.Now()
+		err := database.UpdateCycleResult(cycleID, "OK", endTime, nil, nil)
+		if errors.Is(err, database.ErrCycleEnded) {
+			// Closed meanwhile (e.g. by the stale cycle watchdog); keep that result
+			m.logger.Warn("Ignoring result of ended cycle",
+				"cycle_id", cycleID,
+				"device_id", deviceID)
+			return true
+		}
+		if err != nil {
+			// The cycle stays open; a later status read or the stale cycle watchdog ends it
+			m.logger.Error("Failed to update cycle result",
+				"cycle_id", cycleID,
+				"error", err)
+			return false
+		}
 
-			err = database.UpdateCycleStatus(
-				cycleID,
-				status.Phase,
-				progress,
-				status.Temperature,
-				status.Pressure,
-			)
+		// Broadcast cycle_completed event
+		event := websocket.Event{
+			Event: "cycle_completed",
+			Data: map[string]interface{}{
+				"cycle_id":  cycleID,
+				"device_id": deviceID,
+				"result":    "OK",
+				"a0_value":  status.A0Value,
+				"end_ts":    endTime.Format(time.RFC3339),
+			},
+		}
+		// Independent verdict from the recorded process values
+		if validation := m.validateCycle(cycleID); validation != nil {
+			event.Data["validation_result"] = validation.Result
+			event.Data["validation_reasons"] = validation.Reasons
+		}
+		if err := websocket.BroadcastEvent(event); err != nil {
+			m.logger.Warn("Failed to broadcast cycle_completed event",
+				"cycle_id", cycleID,
+				"error", err)
+		}
 
-			if err != nil {
-				m.logger.Error("Failed to update cycle status in database",
-					"cycle_id", cycleID,
-					"error", err)
-				continue
-			}
+		// Log audit entry
+		details := map[string]interface{}{
+			"cycle_id": cycleID,
+			"device_id": deviceID,
+			"result":   "OK",
+		}
+		if err := database.LogAudit(database.ActionCycleCompleted, "cycle", &cycleID, "", details); err != nil {
+			m.logger.Warn("Failed to log cycle completion audit",
+				"cycle_id", cycleID,
+				"error", err)
+		}
 
-			// Broadcast status update via WebSocket
-			event := websocket.Event{
-				Event: "cycle_status_update",
-				Data: map[string]interface{}{
-					"cycle_id":        cycleID,
-					"device_id":       deviceID,
-					"phase":           status.Phase,
-					"progress_percent": status.ProgressPercent,
-					"temperature":     status.Temperature,
-					"pressure":        status.Pressure,
-					"time_remaining":  status.TimeRemaining,
-					"is_running":      status.IsRunning,
-				},
-			}
+		return true
+	}
 
-			if err := websocket.BroadcastEvent(event); err != nil {
-				m.logger.Warn("Failed to broadcast cycle status update",
-					"cycle_id", cycleID,
-					"error", err)
-				// Continue even if broadcast fails
-			}
+	if !status.IsRunning && status.Phase == "FAILED" {
+		m.logger.Info("Cycle failed, stopping polling",
+			"cycle_id", cycleID,
+			"device_id", deviceID)
 
-			m.logger.Debug("Cycle status updated",
+		// Update cycle result and end timestamp
+		endTime := time.Now()
+		errorDesc := "Cycle failed - see device logs"
+		if status.Error != "" {
+			errorDesc = status.Error
+		}
+		var errorCode *string
+		if status.ErrorCode != "" {
+			errorCode = &status.ErrorCode
+		}
+		err := database.UpdateCycleResult(cycleID, "NOK", endTime, errorCode, &errorDesc)
+		if errors.Is(err, database.ErrCycleEnded) {
+			// Closed meanwhile (e.g. by the stale cycle watchdog); keep that result
+			m.logger.Warn("Ignoring result of ended cycle",
+				"cycle_id", cycleID,
+				"device_id", deviceID)
+			return true
+		}
+		if err != nil {
+			// The cycle stays open; a later status read or the stale cycle watchdog ends it
+			m.logger.Error("Failed to update cycle result",
 				"cycle_id", cycleID,
-				"phase", status.Phase,
-				"progress", status.ProgressPercent)
+				"error", err)
+			return false
+		}
+
+		// Broadcast cycle_failed event
+		event := websocket.Event{
+			Event: "cycle_failed",
+			Data: map[string]interface{}{
+				"cycle_id":        cycleID,
+				"device_id":       deviceID,
+				"result":          "NOK",
+				"error_code":      status.ErrorCode,
+				"error_description": errorDesc,
+				"a0_value":        status.A0Value,
+				"end_ts":          endTime.Format(time.RFC3339),
+			},
+		}
+		if err := websocket.BroadcastEvent(event); err != nil {
+			m.logger.Warn("Failed to broadcast cycle_failed event",
+				"cycle_id", cycleID,
+				"error", err)
+		}
+
+		// Log audit entry
+		details := map[string]interface{}{
+			"cycle_id": cycleID,
+			"device_id": deviceID,
+			"result":   "NOK",
+			"error_description": errorDesc,
+		}
+		if err := database.LogAudit(database.ActionCycleFailed, "cycle", &cycleID, "", details); err != nil {
+			m.logger.Warn("Failed to log cycle failure audit",
+				"cycle_id", cycleID,
+				"error", err)
 		}
+
+		return true
 	}
-}
 
+	// A repeated status is no report from the device: keep phase, values and last_status_at,
+	// so the stale cycle watchdog notices a device that stopped writing status files
+	if status.Stale {
+		return false
+	}
+
+	// Update cycle status in database
+	var progress *int
+	if status.ProgressPercent >= 0 {
+		progressVal := status.ProgressPercent
+		progress = &progressVal
+	}
+
+	err := database.UpdateCycleStatus(
+		cycleID,
+		status.Phase,
+		progress,
+		status.Temperature,
+		status.Pressure,
+	)
+
+	if errors.Is(err, database.ErrCycleEnded) {
+		// Closed meanwhile (e.g. by the stale cycle watchdog) while the device keeps running
+		m.logger.Warn("Ignoring status of ended cycle",
+			"cycle_id", cycleID,
+			"device_id", deviceID)
+		return true
+	}
+	if err != nil {
+		m.logger.Error("Failed to update cycle status in database",
+			"cycle_id", cycleID,
+			"error", err)
+		return false
+	}
+
+	// Keep the curve; the cycle row only holds the latest values
+	m.recordCycleSample(cycleID, status, recorded)
+
+	// Broadcast status update via WebSocket
+	event := websocket.Event{
+		Event: "cycle_status_update",
+		Data: map[string]interface{}{
+			"cycle_id":        cycleID,
+			"device_id":       deviceID,
+			"phase":           status.Phase,
+			"progress_percent": status.ProgressPercent,
+			"temperature":     status.Temperature,
+			"pressure":        status.Pressure,
+			"a0_value":        status.A0Value,
+			"time_remaining":  status.TimeRemaining,
+			"is_running":      status.IsRunning,
+		},
+	}
+
+	if err := websocket.BroadcastEvent(event); err != nil {
+		m.logger.Warn("Failed to broadcast cycle status update",
+			"cycle_id", cycleID,
+			"error", err)
+		// Continue even if broadcast fails
+	}
+
+	m.logger.Debug("Cycle status updated",
+		"cycle_id", cycleID,
+		"phase", status.Phase,
+		"progress", status.ProgressPercent)
+
+	return false
+}
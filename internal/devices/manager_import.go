@@ -0,0 +1,179 @@
+package devices
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"steri-connect-go/internal/adapters"
+	"steri-connect-go/internal/api/websocket"
+	"steri-connect-go/internal/database"
+)
+
+var (
+	ErrImportRunning      = errors.New("protocol import already running")
+	ErrImportNotSupported = errors.New("protocol import not supported for this device")
+	ErrDeviceNotConnected = errors.New("device is not connected")
+)
+
+// Import job states
+const (
+	ImportStatusRunning   = "running"
+	ImportStatusCompleted = "completed"
+	ImportStatusFailed    = "failed"
+)
+
+// importProgressInterval limits how often import_progress events are broadcast
+const importProgressInterval = time.Second
+
+// ImportJob describes a protocol archive import for a device
+type ImportJob struct {
+	DeviceID   int        `json:"device_id"`
+	Status     string     `json:"status"` // "running", "completed", "failed"
+	StartedAt  time.Time  `json:"started_at"`
+	FinishedAt *time.Time `json:"finished_at,omitempty"`
+	Error      string     `json:"error,omitempty"`
+	adapters.ImportProgress
+
+	cancel context.CancelFunc
+}
+
+// StartProtocolImport starts a background import of the device's protocol archive
+func (m *Manager) StartProtocolImport(deviceID int) (ImportJob, error) {
+	adapter := m.GetAdapter(deviceID)
+	if adapter == nil {
+		return ImportJob{}, ErrDeviceNotConnected
+	}
+	importer, ok := adapter.(adapters.ProtocolArchiveImporter)
+	if !ok || !m.HasCapability(deviceID, adapters.CapabilityProtocolImport) {
+		return ImportJob{}, ErrImportNotSupported
+	}
+	if !adapter.IsConnected() {
+		return ImportJob{}, ErrDeviceNotConnected
+	}
+
+	m.importMutex.Lock()
+	defer m.importMutex.Unlock()
+
+	if job, exists := m.importJobs[deviceID]; exists && job.Status == ImportStatusRunning {
+		return *job, ErrImportRunning
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	job := &ImportJob{
+		DeviceID:  deviceID,
+		Status:    ImportStatusRunning,
+		StartedAt: time.Now(),
+		cancel:    cancel,
+	}
+	m.importJobs[deviceID] = job
+
+	m.logger.Info("Starting protocol import", "device_id", deviceID)
+
+	go m.runProtocolImport(ctx, importer, job)
+
+	return *job, nil
+}
+
+// GetProtocolImport returns the latest import job for a device
+func (m *Manager) GetProtocolImport(deviceID int) (ImportJob, bool) {
+	m.importMutex.Lock()
+	defer m.importMutex.Unlock()
+
+	job, exists := m.importJobs[deviceID]
+	if !exists {
+		return ImportJob{}, false
+	}
+	return *job, true
+}
+
+// cancelProtocolImports stops all running imports
+func (m *Manager) cancelProtocolImports() {
+	m.importMutex.Lock()
+	defer m.importMutex.Unlock()
+
+	for _, job := range m.importJobs {
+		if job.Status == ImportStatusRunning {
+			job.cancel()
+		}
+	}
+}
+
+// runProtocolImport performs the import and reports progress
+func (m *Manager) runProtocolImport(ctx context.Context, importer adapters.ProtocolArchiveImporter, job *ImportJob) {
+	deviceID := job.DeviceID
+	var lastBroadcast time.Time
+
+	progress, err := importer.ImportProtocolArchive(ctx, func(progress adapters.ImportProgress) {
+		m.importMutex.Lock()
+		job.ImportProgress = progress
+		m.importMutex.Unlock()
+
+		if time.Since(lastBroadcast) < importProgressInterval && progress.Processed < progress.Total {
+			return
+		}
+		lastBroadcast = time.Now()
+		m.broadcastImportEvent("import_progress", deviceID, progress, nil)
+	})
+
+	finishedAt := time.Now()
+	m.importMutex.Lock()
+	job.ImportProgress = progress
+	job.FinishedAt = &finishedAt
+	job.cancel()
+	if err != nil {
+		job.Status = ImportStatusFailed
+		job.Error = err.Error()
+	} else {
+		job.Status = ImportStatusCompleted
+	}
+	m.importMutex.Unlock()
+
+	if err != nil {
+		m.logger.Error("Protocol import failed",
+			"device_id", deviceID,
+			"error", err)
+		m.broadcastImportEvent("import_failed", deviceID, progress, err)
+		return
+	}
+
+	m.broadcastImportEvent("import_completed", deviceID, progress, nil)
+
+	details := map[string]interface{}{
+		"device_id":  deviceID,
+		"total":      progress.Total,
+		"imported":   progress.Imported,
+		"duplicates": progress.Duplicates,
+		"skipped":    progress.Skipped,
+		"failed":     progress.Failed,
+	}
+	if err := database.LogAudit(database.ActionCyclesImported, "device", &deviceID, "", details); err != nil {
+		m.logger.Warn("Failed to create audit log for protocol import",
+			"device_id", deviceID,
+			"error", err)
+	}
+}
+
+// broadcastImportEvent sends an import progress event to WebSocket clients
+func (m *Manager) broadcastImportEvent(eventName string, deviceID int, progress adapters.ImportProgress, err error) {
+	data := map[string]interface{}{
+		"device_id":  deviceID,
+		"total":      progress.Total,
+		"processed":  progress.Processed,
+		"imported":   progress.Imported,
+		"duplicates": progress.Duplicates,
+		"skipped":    progress.Skipped,
+		"failed":     progress.Failed,
+	}
+	if err != nil {
+		data["error"] = fmt.Sprintf("%v", err)
+	}
+
+	if err := websocket.BroadcastEvent(websocket.Event{Event: eventName, Data: data}); err != nil {
+		m.logger.Warn("Failed to broadcast import event",
+			"event", eventName,
+			"device_id", deviceID,
+			"error", err)
+	}
+}
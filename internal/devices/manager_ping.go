@@ -1,16 +1,16 @@
 package devices
 
 import (
+	"fmt"
 	"time"
 
 	"steri-connect-go/internal/adapters"
-	"steri-connect-go/internal/adapters/getinge"
 	"steri-connect-go/internal/config"
 	"steri-connect-go/internal/database"
 )
 
-// startPingMonitoring starts ping monitoring for a Getinge device
-func (m *Manager) startPingMonitoring(deviceID int, adapter adapters.DeviceAdapter) {
+// startPingMonitoring starts ping monitoring for a reachability-only device (e.g. Getinge)
+func (m *Manager) startPingMonitoring(deviceID int, adapter adapters.ReachabilityProber) {
 	cfg := config.Get()
 	pingInterval := time.Duration(cfg.Devices.Getinge.PingInterval) * time.Second
 	if pingInterval == 0 {
@@ -22,7 +22,7 @@ func (m *Manager) startPingMonitoring(deviceID int, adapter adapters.DeviceAdapt
 	m.pingMonitors[deviceID] = stopChan
 	m.pingMonitorsMutex.Unlock()
 
-	m.logger.Info("Starting ping monitoring for Getinge device",
+	m.logger.Info("Starting ping monitoring for device",
 		"device_id", deviceID,
 		"ping_interval", pingInterval)
 
@@ -42,8 +42,8 @@ func (m *Manager) stopPingMonitoring(deviceID int) {
 	}
 }
 
-// pingDeviceLoop continuously pings a Getinge device
-func (m *Manager) pingDeviceLoop(deviceID int, adapter adapters.DeviceAdapter, interval time.Duration, stopChan chan bool) {
+// pingDeviceLoop continuously pings a device
+func (m *Manager) pingDeviceLoop(deviceID int, adapter adapters.ReachabilityProber, interval time.Duration, stopChan chan bool) {
 	ticker := time.NewTicker(interval)
 	defer ticker.Stop()
 
@@ -63,46 +63,48 @@ func (m *Manager) pingDeviceLoop(deviceID int, adapter adapters.DeviceAdapter, i
 }
 
 // performPing performs a single ping and updates status
-func (m *Manager) performPing(deviceID int, adapter adapters.DeviceAdapter) {
-	getingeAdapter, ok := adapter.(*getinge.GetingeAdapter)
-	if !ok {
-		m.logger.Warn("Adapter is not a GetingeAdapter",
-			"device_id", deviceID)
-		return
-	}
-
-	reachable, err := getingeAdapter.Ping()
+func (m *Manager) performPing(deviceID int, adapter adapters.ReachabilityProber) {
+	result, err := adapter.Probe()
+	probeErr := err
 	if err != nil {
 		m.logger.Warn("Ping failed",
 			"device_id", deviceID,
 			"error", err)
-		reachable = false
+		result.Reachable = false
 	}
+	reachable := result.Reachable
 
 	// Update last ping time and reachability in adapter
 	now := time.Now()
-	getingeAdapter.SetLastPing(&now)
-	getingeAdapter.SetLastReachable(reachable)
+	adapter.SetLastPing(&now)
+	adapter.SetLastReachable(reachable)
 
 	// Update connection state
 	if reachable {
-		getingeAdapter.SetConnectionState(adapters.StateConnected)
+		adapter.SetConnectionState(adapters.StateConnected)
 	} else {
-		getingeAdapter.SetConnectionState(adapters.StateError)
+		adapter.SetConnectionState(adapters.StateError)
 	}
 
-	// Save RDG status to database
-	_, err = database.CreateRDGStatus(deviceID, reachable)
-	if err != nil {
-		m.logger.Error("Failed to save RDG status",
-			"device_id", deviceID,
-			"error", err)
+	// Save RDG status to database (no measurement if the probe could not be sent)
+	status := &database.RDGStatus{
+		DeviceID:  deviceID,
+		Reachable: reachable,
+		Method:    result.Method,
 	}
-
-	// Update device connection status in database
-	err = database.UpdateDeviceConnectionStatus(deviceID, reachable)
+	if err == nil {
+		probes := result.Sent
+		status.Probes = &probes
+		packetLoss := result.PacketLoss()
+		status.PacketLoss = &packetLoss
+		if result.Reachable {
+			rttMs := float64(result.RTT.Microseconds()) / 1000
+			status.RTTMs = &rttMs
+		}
+	}
+	_, err = database.CreateRDGStatus(status)
 	if err != nil {
-		m.logger.Error("Failed to update device connection status",
+		m.logger.Error("Failed to save RDG status",
 			"device_id", deviceID,
 			"error", err)
 	}
@@ -111,6 +113,16 @@ func (m *Manager) performPing(deviceID int, adapter adapters.DeviceAdapter) {
 	details := map[string]interface{}{
 		"device_id": deviceID,
 		"reachable": reachable,
+		"method":    result.Method,
+	}
+	if status.Probes != nil {
+		details["probes"] = *status.Probes
+	}
+	if status.PacketLoss != nil {
+		details["packet_loss"] = *status.PacketLoss
+	}
+	if status.RTTMs != nil {
+		details["rtt_ms"] = *status.RTTMs
 	}
 	if err := database.LogAudit(database.ActionRDGStatusUpdate, "device", &deviceID, "system", details); err != nil {
 		m.logger.Warn("Failed to log RDG status update audit",
@@ -118,7 +130,13 @@ func (m *Manager) performPing(deviceID int, adapter adapters.DeviceAdapter) {
 			"error", err)
 	}
 
-	// Broadcast status change event
-	m.broadcastStatusChange(deviceID, adapter.GetConnectionState())
+	// Record and broadcast the connection state
+	state := adapters.StateError
+	if reachable {
+		state = adapters.StateConnected
+	} else if probeErr == nil {
+		probeErr = fmt.Errorf("no answer to %d %s probe(s)", result.Sent, result.Method)
+	}
+	m.updateConnectionState(deviceID, state, database.ConnectionReasonProbe, probeErr)
 }
 
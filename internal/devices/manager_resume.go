@@ -0,0 +1,136 @@
+package devices
+
+import (
+	"errors"
+	"time"
+
+	"steri-connect-go/internal/adapters"
+	"steri-connect-go/internal/database"
+)
+
+// CyclePhaseInterrupted is the phase of a cycle that could not be followed after a restart
+const CyclePhaseInterrupted = "INTERRUPTED"
+
+// resumeRunningCycles continues the cycles the database still lists as running after a restart
+// Cycles of devices whose status is polled are polled again (the newest per device); all
+// others can no longer be followed and are marked as interrupted.
+func (m *Manager) resumeRunningCycles() {
+	cycles, err := database.GetRunningCycles()
+	if err != nil {
+		m.logger.Warn("Failed to load running cycles",
+			"error", err)
+		return
+	}
+
+	// Newest first, so an older leftover never displaces the latest cycle of a device
+	for _, cycle := range cycles {
+		if reason := m.resumeCycle(cycle); reason != "" {
+			m.interruptCycle(cycle, reason)
+		}
+	}
+}
+
+// RestoreDevice adds the adapter of a device again after RemoveDevice, e.g. when deleting
+// the device failed. Its running cycles are continued as after a restart.
+func (m *Manager) RestoreDevice(deviceID int) error {
+	device, err := database.GetDevice(deviceID)
+	if err != nil {
+		return err
+	}
+	if err := m.AddDevice(device); err != nil {
+		return err
+	}
+
+	cycles, err := database.GetRunningCycles()
+	if err != nil {
+		return err
+	}
+	for _, cycle := range cycles {
+		if cycle.DeviceID != deviceID {
+			continue
+		}
+		if reason := m.resumeCycle(cycle); reason != "" {
+			m.interruptCycle(cycle, reason)
+		}
+	}
+	return nil
+}
+
+// resumeCycle polls a running cycle again
+// Returns why the cycle cannot be resumed, or "" once it is polled.
+func (m *Manager) resumeCycle(cycle database.CycleWithDevice) string {
+	m.adaptersMutex.RLock()
+	adapter, exists := m.adapters[cycle.DeviceID]
+	registration := m.registrations[cycle.DeviceID]
+	m.adaptersMutex.RUnlock()
+
+	if !exists {
+		return "device is not connected by the service"
+	}
+	if observesCycles(registration, adapter) {
+		return "cycle was started at the device, its end was not observed"
+	}
+	if !registration.HasCapability(adapters.CapabilityCycleStatus) || !adapters.Supports(adapter, adapters.CapabilityCycleStatus) {
+		return "device does not report cycle status"
+	}
+
+	resumeAdapterCycle(adapter, &cycle.Cycle)
+	if err := m.StartCyclePolling(cycle.ID, cycle.DeviceID); err != nil {
+		if errors.Is(err, ErrCycleRunning) {
+			return "a later cycle was started on the device"
+		}
+		return err.Error()
+	}
+
+	m.logger.Info("Resumed polling of running cycle",
+		"cycle_id", cycle.ID,
+		"device_id", cycle.DeviceID,
+		"phase", cycle.Phase)
+	return ""
+}
+
+// resumeAdapterCycle tells an adapter that only reports new status which cycle it follows
+// again, so status and protocol written while the service was down are not skipped
+func resumeAdapterCycle(adapter adapters.DeviceAdapter, cycle *database.Cycle) {
+	resumer, ok := adapter.(adapters.CycleResumer)
+	if !ok {
+		return
+	}
+	resumer.ResumeCycle(cycle.DeviceCycleNumber, cycle.StartTS)
+}
+
+// interruptCycle ends a running cycle that can no longer be followed (result NOK)
+func (m *Manager) interruptCycle(cycle database.CycleWithDevice, reason string) {
+	cycleID := cycle.ID
+	errorDesc := "Interrupted by service restart: " + reason
+
+	m.logger.Warn("Marking running cycle as interrupted",
+		"cycle_id", cycleID,
+		"device_id", cycle.DeviceID,
+		"reason", reason)
+
+	if err := database.UpdateCycleStatus(cycleID, CyclePhaseInterrupted, nil, nil, nil); err != nil {
+		m.logger.Error("Failed to update cycle phase",
+			"cycle_id", cycleID,
+			"error", err)
+	}
+	if err := database.UpdateCycleResult(cycleID, "NOK", time.Now(), nil, &errorDesc); err != nil {
+		m.logger.Error("Failed to update cycle result",
+			"cycle_id", cycleID,
+			"error", err)
+		return
+	}
+
+	details := map[string]interface{}{
+		"cycle_id":          cycleID,
+		"device_id":         cycle.DeviceID,
+		"result":            "NOK",
+		"last_phase":        cycle.Phase,
+		"error_description": errorDesc,
+	}
+	if err := database.LogAudit(database.ActionCycleInterrupted, "cycle", &cycleID, "system", details); err != nil {
+		m.logger.Warn("Failed to log cycle interruption audit",
+			"cycle_id", cycleID,
+			"error", err)
+	}
+}
@@ -0,0 +1,86 @@
+package devices
+
+import (
+	"math"
+	"time"
+
+	"steri-connect-go/internal/adapters"
+	"steri-connect-go/internal/config"
+	"steri-connect-go/internal/database"
+)
+
+// recordCycleSample stores the process values of a status read, downsampled as configured
+// Only new readings are stored, stamped with the device clock if the device reports it;
+// a repeated status (Stale) or a reading not newer than the last sample is skipped.
+func (m *Manager) recordCycleSample(cycleID int, status adapters.CycleStatus, recorded *cycleRecord) {
+	if status.Stale {
+		return
+	}
+	timestamp := time.Now().Round(0)
+	if status.MeasuredAt != nil {
+		timestamp = status.MeasuredAt.Round(0)
+	}
+	if recorded.lastSample != nil && !timestamp.After(recorded.lastSample.Timestamp) {
+		return
+	}
+
+	sample := database.CycleSample{
+		CycleID:     cycleID,
+		Timestamp:   timestamp,
+		Phase:       status.Phase,
+		Temperature: status.Temperature,
+		Pressure:    status.Pressure,
+	}
+	if status.ProgressPercent >= 0 {
+		progress := status.ProgressPercent
+		sample.ProgressPercent = &progress
+	}
+	extra := map[string]interface{}{}
+	if status.A0Value != nil {
+		extra["a0_value"] = *status.A0Value
+	}
+	if status.TimeRemaining != nil {
+		extra["time_remaining_s"] = int(status.TimeRemaining.Seconds())
+	}
+	if len(extra) > 0 {
+		sample.Extra = extra
+	}
+
+	if !sampleDue(recorded.lastSample, sample, config.Get().Devices.CycleSamples) {
+		return
+	}
+
+	stored, err := database.RecordCycleSample(&sample)
+	if err != nil {
+		m.logger.Warn("Failed to record cycle sample",
+			"cycle_id", cycleID,
+			"error", err)
+		return
+	}
+	if stored {
+		recorded.lastSample = &sample
+	}
+}
+
+// sampleDue reports whether next is stored after last (nil if nothing was stored yet)
+func sampleDue(last *database.CycleSample, next database.CycleSample, cfg config.CycleSamplesConfig) bool {
+	if last == nil || next.Phase != last.Phase {
+		return true
+	}
+	if next.Timestamp.Sub(last.Timestamp) >= time.Duration(cfg.MinInterval)*time.Second {
+		return true
+	}
+	return exceedsDelta(last.Temperature, next.Temperature, cfg.TemperatureDelta) ||
+		exceedsDelta(last.Pressure, next.Pressure, cfg.PressureDelta)
+}
+
+// exceedsDelta reports whether a value changed by more than delta (0 = changes not considered)
+func exceedsDelta(last, next *float64, delta float64) bool {
+	if delta <= 0 || next == nil {
+		return false
+	}
+	if last == nil {
+		return true
+	}
+	return math.Abs(*next-*last) > delta
+}
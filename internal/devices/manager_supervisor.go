@@ -0,0 +1,329 @@
+package devices
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/rand/v2"
+	"sync"
+	"time"
+
+	"steri-connect-go/internal/adapters"
+	"steri-connect-go/internal/config"
+	"steri-connect-go/internal/database"
+)
+
+// Supervisor states reported in ReconnectStatus
+const (
+	SupervisorConnecting = "connecting" // Connection attempt in progress
+	SupervisorWaiting    = "waiting"    // Waiting for the next attempt after a failure
+	SupervisorMonitoring = "monitoring" // Connected, session checked periodically
+	SupervisorConnected  = "connected"  // Connected, the adapter's own monitoring took over
+)
+
+// upcomingDelays is the number of future retry delays listed in ReconnectStatus
+const upcomingDelays = 5
+
+// ReconnectStatus describes how the manager keeps a device connected
+type ReconnectStatus struct {
+	State               string     `json:"state"`
+	Attempt             int        `json:"attempt"` // Failed attempts since the last successful connect
+	LastError           string     `json:"last_error,omitempty"`
+	LastAttemptAt       *time.Time `json:"last_attempt_at,omitempty"`
+	NextAttemptAt       *time.Time `json:"next_attempt_at,omitempty"`
+	NextDelay           string     `json:"next_delay,omitempty"` // Jittered delay before NextAttemptAt
+	UpcomingDelays      []string   `json:"upcoming_delays"`      // Nominal delays of the following attempts
+	MaxDelay            string     `json:"max_delay"`
+	LastHealthCheckAt   *time.Time `json:"last_health_check_at,omitempty"`
+	HealthCheckInterval string     `json:"health_check_interval,omitempty"` // Empty if the adapter has no session check
+}
+
+// connectionSupervisor is the state of one device's supervisor goroutine
+type connectionSupervisor struct {
+	cancel context.CancelFunc
+	done   chan struct{} // Closed when the goroutine has ended
+	policy backoffPolicy
+
+	mu     sync.Mutex
+	status ReconnectStatus
+}
+
+// update changes the reported status (thread-safe)
+func (s *connectionSupervisor) update(fn func(status *ReconnectStatus)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	fn(&s.status)
+}
+
+// backoffPolicy computes the delays between connection attempts
+type backoffPolicy struct {
+	initial    time.Duration
+	max        time.Duration
+	multiplier float64
+	jitter     float64
+}
+
+// newBackoffPolicy creates the policy from the reconnect configuration
+func newBackoffPolicy(cfg config.ReconnectConfig) backoffPolicy {
+	policy := backoffPolicy{
+		initial:    time.Duration(cfg.InitialDelay) * time.Second,
+		max:        time.Duration(cfg.MaxDelay) * time.Second,
+		multiplier: cfg.Multiplier,
+		jitter:     cfg.Jitter,
+	}
+	if policy.initial <= 0 {
+		policy.initial = 5 * time.Second // Default
+	}
+	if policy.max < policy.initial {
+		policy.max = policy.initial
+	}
+	if policy.multiplier < 1 {
+		policy.multiplier = 1
+	}
+	return policy
+}
+
+// delay returns the nominal delay after the given number of failed attempts (>= 1)
+func (p backoffPolicy) delay(failures int) time.Duration {
+	d := float64(p.initial) * math.Pow(p.multiplier, float64(failures-1))
+	if d >= float64(p.max) {
+		return p.max
+	}
+	return time.Duration(d)
+}
+
+// jittered varies d by up to ±jitter; r is a random number in [0, 1). The result never exceeds the cap.
+func (p backoffPolicy) jittered(d time.Duration, r float64) time.Duration {
+	d = time.Duration(float64(d) * (1 + p.jitter*(2*r-1)))
+	if d > p.max {
+		return p.max
+	}
+	return d
+}
+
+// upcoming returns the nominal delays of the next attempts, up to the first one at the cap
+func (p backoffPolicy) upcoming(failures int) []string {
+	delays := []string{}
+	for i := 1; i <= upcomingDelays; i++ {
+		d := p.delay(failures + i)
+		delays = append(delays, d.String())
+		if d == p.max {
+			break
+		}
+	}
+	return delays
+}
+
+// startSupervisor starts the goroutine that connects a device and keeps it connected
+func (m *Manager) startSupervisor(adapter adapters.DeviceAdapter) {
+	cfg := config.Get()
+	deviceID := adapter.GetDeviceID()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	supervisor := &connectionSupervisor{
+		cancel: cancel,
+		done:   make(chan struct{}),
+		policy: newBackoffPolicy(cfg.Devices.Reconnect),
+	}
+	supervisor.status = ReconnectStatus{
+		State:          SupervisorConnecting,
+		UpcomingDelays: supervisor.policy.upcoming(0),
+		MaxDelay:       supervisor.policy.max.String(),
+	}
+
+	healthInterval := time.Duration(cfg.Devices.Reconnect.HealthCheckInterval) * time.Second
+	if _, ok := adapter.(adapters.HealthChecker); ok && healthInterval > 0 {
+		supervisor.status.HealthCheckInterval = healthInterval.String()
+	} else {
+		healthInterval = 0
+	}
+
+	m.supervisorsMutex.Lock()
+	previous, exists := m.supervisors[deviceID]
+	m.supervisors[deviceID] = supervisor
+	m.supervisorsMutex.Unlock()
+
+	if exists {
+		previous.stop()
+	}
+	go m.superviseConnection(ctx, adapter, supervisor, healthInterval)
+}
+
+// stopSupervisor stops supervising a device's connection
+// It returns once the supervisor goroutine has ended, so a connection attempt still in
+// progress cannot disconnect the adapter after the caller connected it again.
+func (m *Manager) stopSupervisor(deviceID int) {
+	m.supervisorsMutex.Lock()
+	supervisor, exists := m.supervisors[deviceID]
+	delete(m.supervisors, deviceID)
+	m.supervisorsMutex.Unlock()
+
+	if exists {
+		supervisor.stop()
+		m.logger.Info("Stopped connection supervisor for device",
+			"device_id", deviceID)
+	}
+}
+
+// stop cancels the supervisor goroutine and waits for it to end
+func (s *connectionSupervisor) stop() {
+	s.cancel()
+	<-s.done
+}
+
+// GetReconnectStatus returns the connection supervision of a device (false if it is not supervised)
+func (m *Manager) GetReconnectStatus(deviceID int) (ReconnectStatus, bool) {
+	m.supervisorsMutex.Lock()
+	supervisor, exists := m.supervisors[deviceID]
+	m.supervisorsMutex.Unlock()
+	if !exists {
+		return ReconnectStatus{}, false
+	}
+
+	supervisor.mu.Lock()
+	defer supervisor.mu.Unlock()
+	status := supervisor.status
+	status.UpcomingDelays = append([]string(nil), status.UpcomingDelays...)
+	return status, true
+}
+
+// superviseConnection connects the device, retrying with jittered exponential backoff, and
+// checks the session every healthInterval (0 if the adapter has no session check). Adapters
+// without a session check are left to their own monitoring once connected (e.g. ping loop).
+// The goroutine ends when ctx is cancelled (RemoveDevice, Shutdown).
+func (m *Manager) superviseConnection(ctx context.Context, adapter adapters.DeviceAdapter, supervisor *connectionSupervisor, healthInterval time.Duration) {
+	defer close(supervisor.done)
+	deviceID := adapter.GetDeviceID()
+	policy := supervisor.policy
+
+	failures := 0
+	for {
+		if !adapter.IsConnected() {
+			now := time.Now()
+			supervisor.update(func(status *ReconnectStatus) {
+				status.State = SupervisorConnecting
+				status.LastAttemptAt = &now
+				status.NextAttemptAt = nil
+				status.NextDelay = ""
+			})
+			if failures == 0 {
+				m.updateConnectionState(deviceID, adapters.StateConnecting, database.ConnectionReasonConnect, nil)
+			}
+
+			err := adapter.Connect()
+			if ctx.Err() != nil {
+				// Removed while connecting; do not leave the session open
+				if err == nil {
+					adapter.Disconnect()
+				}
+				return
+			}
+			if err != nil {
+				failures++
+				m.updateConnectionState(deviceID, adapter.GetConnectionState(), database.ConnectionReasonConnect, err)
+
+				delay := policy.jittered(policy.delay(failures), rand.Float64())
+				next := time.Now().Add(delay)
+				supervisor.update(func(status *ReconnectStatus) {
+					status.State = SupervisorWaiting
+					status.Attempt = failures
+					status.LastError = err.Error()
+					status.NextAttemptAt = &next
+					status.NextDelay = delay.String()
+					status.UpcomingDelays = policy.upcoming(failures)
+				})
+				m.logger.Warn("Connection attempt failed, retrying",
+					"device_id", deviceID,
+					"attempt", failures,
+					"retry_in", delay.String(),
+					"error", err)
+
+				if !sleepContext(ctx, delay) {
+					return
+				}
+				continue
+			}
+
+			if failures > 0 {
+				m.logger.Info("Device reconnected",
+					"device_id", deviceID,
+					"failed_attempts", failures)
+			}
+			failures = 0
+			m.updateConnectionState(deviceID, adapter.GetConnectionState(), database.ConnectionReasonConnect, nil)
+		}
+
+		supervisor.update(func(status *ReconnectStatus) {
+			status.Attempt = 0
+			status.NextAttemptAt = nil
+			status.NextDelay = ""
+			status.UpcomingDelays = policy.upcoming(0)
+			status.State = SupervisorConnected
+			if healthInterval > 0 {
+				status.State = SupervisorMonitoring
+			}
+		})
+		if healthInterval == 0 {
+			return
+		}
+
+		lost := m.monitorSession(ctx, adapter, supervisor, healthInterval)
+		if lost == nil {
+			return // Cancelled
+		}
+		m.logger.Warn("Device connection lost, reconnecting",
+			"device_id", deviceID,
+			"error", lost)
+		supervisor.update(func(status *ReconnectStatus) {
+			status.LastError = lost.Error()
+		})
+		m.updateConnectionState(deviceID, adapter.GetConnectionState(), database.ConnectionReasonHealthCheck, lost)
+		adapter.Disconnect() // Drops what is left of the session
+	}
+}
+
+// monitorSession checks the session of a connected device every interval
+// Returns why the connection was lost, or nil when ctx is cancelled.
+func (m *Manager) monitorSession(ctx context.Context, adapter adapters.DeviceAdapter, supervisor *connectionSupervisor, interval time.Duration) error {
+	checker := adapter.(adapters.HealthChecker)
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+
+		// Closed elsewhere, e.g. by a failed command
+		if !adapter.IsConnected() {
+			return fmt.Errorf("connection closed (state %s)", adapter.GetConnectionState())
+		}
+
+		err := checker.CheckHealth()
+		now := time.Now()
+		supervisor.update(func(status *ReconnectStatus) {
+			status.LastHealthCheckAt = &now
+		})
+		if ctx.Err() != nil {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+	}
+}
+
+// sleepContext waits for d; returns false if ctx is cancelled first
+func sleepContext(ctx context.Context, d time.Duration) bool {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
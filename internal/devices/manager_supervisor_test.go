@@ -0,0 +1,110 @@
+package devices
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"steri-connect-go/internal/adapters"
+	"steri-connect-go/internal/config"
+)
+
+func TestBackoffPolicy(t *testing.T) {
+	policy := newBackoffPolicy(config.ReconnectConfig{InitialDelay: 5, MaxDelay: 60, Multiplier: 2, Jitter: 0.2})
+
+	expected := []time.Duration{5 * time.Second, 10 * time.Second, 20 * time.Second, 40 * time.Second, 60 * time.Second, 60 * time.Second}
+	for i, want := range expected {
+		if got := policy.delay(i + 1); got != want {
+			t.Errorf("delay(%d) = %s, want %s", i+1, got, want)
+		}
+	}
+
+	if got := policy.jittered(10*time.Second, 0); got != 8*time.Second {
+		t.Errorf("Expected -20%% jitter, got %s", got)
+	}
+	if got := policy.jittered(60*time.Second, 0.99); got != 60*time.Second {
+		t.Errorf("Expected jittered delay capped at max, got %s", got)
+	}
+
+	upcoming := policy.upcoming(2)
+	if len(upcoming) != 3 || upcoming[0] != "20s" || upcoming[2] != "1m0s" {
+		t.Errorf("Unexpected upcoming delays %v", upcoming)
+	}
+}
+
+// blockingAdapter is a device whose first connection attempt waits until release is closed
+type blockingAdapter struct {
+	entered chan struct{}
+	release chan struct{}
+
+	mu        sync.Mutex
+	attempts  int
+	connected bool
+}
+
+func (a *blockingAdapter) Connect() error {
+	a.mu.Lock()
+	a.attempts++
+	first := a.attempts == 1
+	a.mu.Unlock()
+
+	if first {
+		close(a.entered)
+		<-a.release
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.connected = true
+	return nil
+}
+
+func (a *blockingAdapter) Disconnect() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.connected = false
+	return nil
+}
+
+func (a *blockingAdapter) IsConnected() bool {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.connected
+}
+
+func (a *blockingAdapter) GetDeviceID() int { return 1 }
+
+func (a *blockingAdapter) GetConnectionState() adapters.ConnectionState {
+	if a.IsConnected() {
+		return adapters.StateConnected
+	}
+	return adapters.StateDisconnected
+}
+
+func TestConnectDeviceWaitsForCancelledSupervisor(t *testing.T) {
+	manager := NewManager()
+	adapter := &blockingAdapter{entered: make(chan struct{}), release: make(chan struct{})}
+	manager.adapters[adapter.GetDeviceID()] = adapter
+
+	// The supervisor is still inside Connect when the device is connected manually
+	manager.startSupervisor(adapter)
+	<-adapter.entered
+
+	result := make(chan error, 1)
+	go func() { result <- manager.ConnectDevice(adapter.GetDeviceID()) }()
+
+	select {
+	case err := <-result:
+		t.Fatalf("ConnectDevice returned before the cancelled supervisor ended: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+	close(adapter.release)
+
+	if err := <-result; err != nil {
+		t.Fatalf("ConnectDevice failed: %v", err)
+	}
+	manager.stopSupervisor(adapter.GetDeviceID())
+	if !adapter.IsConnected() {
+		t.Errorf("Cancelled supervisor disconnected the device after ConnectDevice")
+	}
+}
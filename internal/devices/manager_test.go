@@ -0,0 +1,420 @@
+package devices
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"steri-connect-go/internal/adapters"
+	_ "steri-connect-go/internal/adapters/melag"
+	"steri-connect-go/internal/config"
+	"steri-connect-go/internal/database"
+	"steri-connect-go/internal/logging"
+	"steri-connect-go/internal/simulator"
+)
+
+func TestMain(m *testing.M) {
+	if err := logging.Init(logging.Config{Level: "ERROR", Format: "text", Output: "stdout"}); err != nil {
+		panic(err)
+	}
+	// Audit entries are keyed with the secret key
+	if err := database.SetSecretKey([]byte("0123456789abcdef0123456789abcdef")); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+func TestCyclePollingRegistry(t *testing.T) {
+	manager := NewManager()
+
+	if err := manager.StartCyclePolling(1, 10); err != nil {
+		t.Fatalf("StartCyclePolling failed: %v", err)
+	}
+	if err := manager.StartCyclePolling(2, 10); !errors.Is(err, ErrCycleRunning) {
+		t.Errorf("Expected ErrCycleRunning for a second cycle, got %v", err)
+	}
+	if err := manager.BeginCycleStart(10); !errors.Is(err, ErrCycleRunning) {
+		t.Errorf("Expected start on busy device to be refused, got %v", err)
+	}
+	if err := manager.StartCyclePolling(3, 11); err != nil {
+		t.Fatalf("StartCyclePolling on another device failed: %v", err)
+	}
+	if active := manager.GetActiveCycle(10); active == nil || active.CycleID != 1 {
+		t.Errorf("Expected cycle 1 to be polled, got %+v", active)
+	}
+
+	manager.StopCyclePolling(1)
+	if active := manager.GetActiveCycle(10); active != nil {
+		t.Errorf("Expected no polled cycle after stop, got %+v", active)
+	}
+	if err := manager.BeginCycleStart(10); err != nil {
+		t.Fatalf("BeginCycleStart failed: %v", err)
+	}
+	if err := manager.BeginCycleStart(10); !errors.Is(err, ErrCycleRunning) {
+		t.Errorf("Expected concurrent start to be refused, got %v", err)
+	}
+	manager.EndCycleStart(10)
+
+	if stopped := manager.stopDeviceCyclePolling(11); len(stopped) != 1 || stopped[0] != 3 {
+		t.Errorf("Expected cycle 3 to be stopped, got %v", stopped)
+	}
+}
+
+func TestResumeRunningCyclesInterruptsUnfollowableCycles(t *testing.T) {
+	if err := database.InitializeDatabase(filepath.Join(t.TempDir(), "resume.db")); err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer database.Close()
+
+	device, err := database.CreateDevice(&database.Device{Name: "Vacuklav", Manufacturer: "Melag", IP: "192.168.1.10", Type: "Steri"})
+	if err != nil {
+		t.Fatalf("Failed to create device: %v", err)
+	}
+	cycle, err := database.CreateCycle(&database.Cycle{DeviceID: device.ID, Program: "Universal", StartTS: time.Now(), Phase: "Sterilisation"})
+	if err != nil {
+		t.Fatalf("Failed to create cycle: %v", err)
+	}
+
+	// No adapter is loaded for the device
+	manager := NewManager()
+	manager.resumeRunningCycles()
+
+	interrupted, err := database.GetCycle(cycle.ID)
+	if err != nil {
+		t.Fatalf("GetCycle failed: %v", err)
+	}
+	if interrupted.EndTS == nil || interrupted.Result != "NOK" || interrupted.Phase != CyclePhaseInterrupted {
+		t.Fatalf("Expected interrupted cycle, got %+v", interrupted)
+	}
+	running, err := database.GetRunningCycles()
+	if err != nil {
+		t.Fatalf("GetRunningCycles failed: %v", err)
+	}
+	if len(running) != 0 {
+		t.Errorf("Expected no running cycles, got %+v", running)
+	}
+
+	logs, err := database.GetAuditLogs("cycle", &cycle.ID, 10)
+	if err != nil {
+		t.Fatalf("GetAuditLogs failed: %v", err)
+	}
+	if len(logs) != 1 || logs[0].Action != string(database.ActionCycleInterrupted) {
+		t.Errorf("Expected cycle_interrupted audit entry, got %+v", logs)
+	}
+}
+
+func TestResumeCycleEndedDuringDowntime(t *testing.T) {
+	if err := database.InitializeDatabase(filepath.Join(t.TempDir(), "restart.db")); err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer database.Close()
+
+	device, err := database.CreateDevice(&database.Device{Name: "Vacuklav", Manufacturer: "Melag", IP: "192.168.1.10", Type: "Steri"})
+	if err != nil {
+		t.Fatalf("Failed to create device: %v", err)
+	}
+	started := time.Now().Add(-40 * time.Minute).Truncate(time.Second)
+	cycle, err := database.CreateCycle(&database.Cycle{DeviceID: device.ID, Program: "Universal-Programm", StartTS: started, Phase: "Sterilisation"})
+	if err != nil {
+		t.Fatalf("Failed to create cycle: %v", err)
+	}
+	if err := database.SetCycleDeviceCycleNumber(cycle.ID, "0042"); err != nil {
+		t.Fatalf("Failed to set device cycle number: %v", err)
+	}
+
+	// The cycle ended while the service was down; its protocol is on the box before the restart
+	server := simulator.NewFTPServer(simulator.MelagUsername, simulator.MelagPassword)
+	if err := server.Start("127.0.0.1:0"); err != nil {
+		t.Fatalf("Failed to start test FTP server: %v", err)
+	}
+	defer server.Close()
+	server.PutFile("/0041.pro", "Charge: 0041\r\nProgramm: Universal-Programm\r\nErgebnis: Programm erfolgreich beendet\r\n", started.Add(-2*time.Minute))
+	server.PutFile("/0042.pro", "Charge: 0042\r\nProgramm: Universal-Programm\r\nPhase: Trocknung\r\nErgebnis: Programm abgebrochen\r\nFehler: 27 Trockenzeit überschritten\r\n", started.Add(30*time.Minute))
+
+	manager := NewManager()
+	manager.SetEndpointResolver(func(*database.Device) string { return server.Addr() })
+	if err := manager.LoadDevices(); err != nil {
+		t.Fatalf("LoadDevices failed: %v", err)
+	}
+	defer manager.Shutdown()
+
+	deadline := time.Now().Add(15 * time.Second)
+	for {
+		ended, err := database.GetCycle(cycle.ID)
+		if err != nil {
+			t.Fatalf("GetCycle failed: %v", err)
+		}
+		if ended.EndTS != nil {
+			if ended.Result != "NOK" || ended.ErrorCode != "27" {
+				t.Errorf("Expected the result of the protocol written during downtime, got %+v", ended)
+			}
+			return
+		}
+		if time.Now().After(deadline) {
+			t.Fatalf("Resumed cycle did not end, phase %s", ended.Phase)
+		}
+		time.Sleep(100 * time.Millisecond)
+	}
+}
+
+func TestCloseStaleCycles(t *testing.T) {
+	if err := database.InitializeDatabase(filepath.Join(t.TempDir(), "stale.db")); err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer database.Close()
+
+	device, err := database.CreateDevice(&database.Device{Name: "Vacuklav", Manufacturer: "Melag", IP: "192.168.1.10", Type: "Steri"})
+	if err != nil {
+		t.Fatalf("Failed to create device: %v", err)
+	}
+	now := time.Now()
+	createCycle := func(program string, started time.Time) int {
+		cycle, err := database.CreateCycle(&database.Cycle{DeviceID: device.ID, Program: program, StartTS: started, Phase: "Sterilisation"})
+		if err != nil {
+			t.Fatalf("Failed to create cycle: %v", err)
+		}
+		return cycle.ID
+	}
+	tooLong := createCycle("Schnell-Programm B", now.Add(-45*time.Minute))
+	silent := createCycle("Universal-Programm", now.Add(-20*time.Minute))
+	reporting := createCycle("Universal-Programm", now.Add(-20*time.Minute))
+	if err := database.UpdateCycleStatus(reporting, "Trocknung", nil, nil, nil); err != nil {
+		t.Fatalf("UpdateCycleStatus failed: %v", err)
+	}
+
+	cfg := config.CycleWatchdogConfig{
+		CheckInterval:      60,
+		StatusTimeout:      10,
+		MaxDuration:        180,
+		ProgramMaxDuration: map[string]int{"Schnell-Programm B": 30},
+	}
+	manager := NewManager()
+	closed := manager.closeStaleCycles(cfg, now)
+	if len(closed) != 2 || closed[0] != tooLong || closed[1] != silent {
+		t.Fatalf("Expected cycles %d and %d to be closed, got %v", tooLong, silent, closed)
+	}
+
+	for cycleID, code := range map[int]string{tooLong: StaleCodeMaxDuration, silent: StaleCodeNoStatus} {
+		cycle, err := database.GetCycle(cycleID)
+		if err != nil {
+			t.Fatalf("GetCycle failed: %v", err)
+		}
+		if cycle.EndTS == nil || cycle.Result != database.CycleResultUnknown || cycle.ErrorCode != code || cycle.ErrorDescription == "" {
+			t.Errorf("Expected cycle closed with %s, got %+v", code, cycle)
+		}
+		logs, err := database.GetAuditLogs("cycle", &cycleID, 10)
+		if err != nil {
+			t.Fatalf("GetAuditLogs failed: %v", err)
+		}
+		if len(logs) != 1 || logs[0].Action != string(database.ActionCycleStale) {
+			t.Errorf("Expected cycle_stale audit entry, got %+v", logs)
+		}
+	}
+
+	// A result arriving late must not overwrite the watchdog's verdict
+	if err := database.UpdateCycleResult(silent, "OK", now, nil, nil); !errors.Is(err, database.ErrCycleEnded) {
+		t.Errorf("Expected ErrCycleEnded for a closed cycle, got %v", err)
+	}
+	if closed := manager.closeStaleCycles(cfg, now.Add(time.Hour)); len(closed) != 1 || closed[0] != reporting {
+		t.Errorf("Expected only cycle %d to be closed later, got %v", reporting, closed)
+	}
+}
+
+func TestRecordCycleSampleSkipsCachedStatus(t *testing.T) {
+	if err := database.InitializeDatabase(filepath.Join(t.TempDir(), "samples.db")); err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer database.Close()
+
+	device, err := database.CreateDevice(&database.Device{Name: "Vacuklav", Manufacturer: "Melag", IP: "192.168.1.10", Type: "Steri"})
+	if err != nil {
+		t.Fatalf("Failed to create device: %v", err)
+	}
+	cycle, err := database.CreateCycle(&database.Cycle{DeviceID: device.ID, Program: "Universal-Programm", StartTS: time.Now(), Phase: "Sterilisation"})
+	if err != nil {
+		t.Fatalf("Failed to create cycle: %v", err)
+	}
+
+	manager := NewManager()
+	var recorded cycleRecord
+	temperature, pressure := 134.2, 3.05
+	measured := time.Now().Add(-time.Minute).Truncate(time.Second)
+	status := adapters.CycleStatus{Phase: "Sterilisation", IsRunning: true, Temperature: &temperature, Pressure: &pressure, MeasuredAt: &measured}
+
+	manager.recordCycleSample(cycle.ID, status, &recorded)
+	// The adapter repeats its last status while the device writes no new file
+	cached := status
+	cached.Stale = true
+	for i := 0; i < 3; i++ {
+		manager.recordCycleSample(cycle.ID, cached, &recorded)
+	}
+	// A status file rewritten with the same reading time is no new reading either
+	manager.recordCycleSample(cycle.ID, status, &recorded)
+
+	next := measured.Add(2 * time.Second)
+	status.MeasuredAt = &next
+	manager.recordCycleSample(cycle.ID, status, &recorded)
+
+	samples, err := database.GetCycleSamples(cycle.ID)
+	if err != nil {
+		t.Fatalf("GetCycleSamples failed: %v", err)
+	}
+	if len(samples) != 2 {
+		t.Fatalf("Expected 2 samples, got %+v", samples)
+	}
+	if !samples[0].Timestamp.Equal(measured) || !samples[1].Timestamp.Equal(next) {
+		t.Errorf("Expected samples stamped with the device time, got %v and %v", samples[0].Timestamp, samples[1].Timestamp)
+	}
+}
+
+func TestCachedStatusDoesNotKeepCycleAlive(t *testing.T) {
+	if err := database.InitializeDatabase(filepath.Join(t.TempDir(), "cached.db")); err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer database.Close()
+
+	device, err := database.CreateDevice(&database.Device{Name: "Vacuklav", Manufacturer: "Melag", IP: "192.168.1.10", Type: "Steri"})
+	if err != nil {
+		t.Fatalf("Failed to create device: %v", err)
+	}
+	now := time.Now()
+	createCycle := func() int {
+		cycle, err := database.CreateCycle(&database.Cycle{DeviceID: device.ID, Program: "Universal-Programm", StartTS: now.Add(-20 * time.Minute), Phase: "Sterilisation"})
+		if err != nil {
+			t.Fatalf("Failed to create cycle: %v", err)
+		}
+		return cycle.ID
+	}
+	cached, reporting := createCycle(), createCycle()
+
+	manager := NewManager()
+	temperature := 134.2
+	status := adapters.CycleStatus{Phase: "Trocknung", IsRunning: true, Temperature: &temperature}
+	// The box is reachable, but the autoclave writes no new status file
+	stale := status
+	stale.Stale = true
+	manager.applyCycleStatus(cached, device.ID, stale, &cycleRecord{})
+	manager.applyCycleStatus(cached, device.ID, adapters.CycleStatus{Phase: "STARTING", IsRunning: true, Stale: true}, &cycleRecord{})
+	manager.applyCycleStatus(reporting, device.ID, status, &cycleRecord{})
+
+	if cycle, err := database.GetCycle(cached); err != nil || cycle.Phase != "Sterilisation" {
+		t.Errorf("Expected phase of cycle without new status to be kept, got %+v, err %v", cycle, err)
+	}
+
+	cfg := config.CycleWatchdogConfig{CheckInterval: 60, StatusTimeout: 10, MaxDuration: 180}
+	closed := manager.closeStaleCycles(cfg, now)
+	if len(closed) != 1 || closed[0] != cached {
+		t.Fatalf("Expected only cycle %d to be closed, got %v", cached, closed)
+	}
+	if cycle, err := database.GetCycle(cached); err != nil || cycle.ErrorCode != StaleCodeNoStatus {
+		t.Errorf("Expected cycle closed with %s, got %+v, err %v", StaleCodeNoStatus, cycle, err)
+	}
+}
+
+func TestCycleWatchDoesNotRecordRunClosedByWatchdog(t *testing.T) {
+	if err := database.InitializeDatabase(filepath.Join(t.TempDir(), "watch.db")); err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer database.Close()
+
+	device, err := database.CreateDevice(&database.Device{Name: "Washer 1", Manufacturer: "Getinge", IP: "192.168.1.20", Type: "RDG"})
+	if err != nil {
+		t.Fatalf("Failed to create device: %v", err)
+	}
+	countCycles := func() int {
+		_, total, err := database.GetAllCycles(database.CycleListOptions{DeviceID: &device.ID})
+		if err != nil {
+			t.Fatalf("GetAllCycles failed: %v", err)
+		}
+		return total
+	}
+
+	manager := NewManager()
+	watch := &cycleWatch{deviceID: device.ID}
+	temperature := 93.0
+	running := adapters.CycleStatus{Program: "Vario TD", Phase: "Desinfektion", IsRunning: true, ProgressPercent: -1, Temperature: &temperature}
+
+	manager.observeCycleStatus(watch, running)
+	observed := watch.activeCycleID
+	if observed == 0 {
+		t.Fatal("Expected observed cycle to be recorded")
+	}
+
+	// The washer keeps running longer than the watchdog allows
+	cfg := config.CycleWatchdogConfig{CheckInterval: 60, StatusTimeout: 10, MaxDuration: 60}
+	if closed := manager.closeStaleCycles(cfg, time.Now().Add(2*time.Hour)); len(closed) != 1 || closed[0] != observed {
+		t.Fatalf("Expected cycle %d to be closed, got %v", observed, closed)
+	}
+	for i := 0; i < 3; i++ {
+		manager.observeCycleStatus(watch, running)
+	}
+	if watch.activeCycleID != 0 || countCycles() != 1 {
+		t.Fatalf("Expected the closed run not to be recorded again, following %d with %d cycles", watch.activeCycleID, countCycles())
+	}
+	if cycle, err := database.GetCycle(observed); err != nil || cycle.ErrorCode != StaleCodeMaxDuration {
+		t.Errorf("Expected watchdog verdict to be kept, got %+v, err %v", cycle, err)
+	}
+
+	// The next program after the device was idle is a new cycle
+	manager.observeCycleStatus(watch, adapters.CycleStatus{ProgressPercent: -1})
+	manager.observeCycleStatus(watch, running)
+	if watch.activeCycleID == 0 || watch.activeCycleID == observed || countCycles() != 2 {
+		t.Errorf("Expected a new cycle after the device was idle, following %d with %d cycles", watch.activeCycleID, countCycles())
+	}
+}
+
+func TestCycleCompletionRecordsFinalSample(t *testing.T) {
+	if err := database.InitializeDatabase(filepath.Join(t.TempDir(), "completion.db")); err != nil {
+		t.Fatalf("Failed to initialize database: %v", err)
+	}
+	defer database.Close()
+
+	device, err := database.CreateDevice(&database.Device{Name: "Vacuklav", Manufacturer: "Melag", IP: "192.168.1.10", Type: "Steri"})
+	if err != nil {
+		t.Fatalf("Failed to create device: %v", err)
+	}
+	cycle, err := database.CreateCycle(&database.Cycle{DeviceID: device.ID, Program: "Universal-Programm", StartTS: time.Now().Add(-time.Hour), Phase: "Sterilisation"})
+	if err != nil {
+		t.Fatalf("Failed to create cycle: %v", err)
+	}
+
+	manager := NewManager()
+	var recorded cycleRecord
+	temperature, pressure := 134.2, 3.05
+	measured := time.Now().Add(-time.Minute).Truncate(time.Second)
+	manager.applyCycleStatus(cycle.ID, device.ID, adapters.CycleStatus{Phase: "Sterilisation", IsRunning: true, ProgressPercent: 60, Temperature: &temperature, Pressure: &pressure, MeasuredAt: &measured}, &recorded)
+
+	// Storing the result fails
+	if _, err := database.DB().Exec(`CREATE TRIGGER test_result_fails BEFORE UPDATE OF result ON cycles BEGIN SELECT RAISE(ABORT, 'disk I/O error'); END`); err != nil {
+		t.Fatalf("Failed to create trigger: %v", err)
+	}
+	final, cooled := measured.Add(30*time.Second), 98.5
+	completed := adapters.CycleStatus{Phase: "COMPLETED", ProgressPercent: 100, Temperature: &cooled, Pressure: &pressure, MeasuredAt: &final}
+	if manager.applyCycleStatus(cycle.ID, device.ID, completed, &recorded) {
+		t.Error("Expected cycle to stay open while its result cannot be stored")
+	}
+	if logs, err := database.GetAuditLogs("cycle", &cycle.ID, 10); err != nil || len(logs) != 0 {
+		t.Errorf("Expected no completion or validation to be reported, got %+v, %v", logs, err)
+	}
+
+	if _, err := database.DB().Exec(`DROP TRIGGER test_result_fails`); err != nil {
+		t.Fatalf("Failed to drop trigger: %v", err)
+	}
+	if !manager.applyCycleStatus(cycle.ID, device.ID, completed, &recorded) {
+		t.Fatal("Expected cycle to complete")
+	}
+
+	samples, err := database.GetCycleSamples(cycle.ID)
+	if err != nil {
+		t.Fatalf("GetCycleSamples failed: %v", err)
+	}
+	if len(samples) != 2 || !samples[1].Timestamp.Equal(final) || samples[1].Temperature == nil || *samples[1].Temperature != cooled {
+		t.Errorf("Expected the reading at completion as last sample, got %+v", samples)
+	}
+	logs, err := database.GetAuditLogs("cycle", &cycle.ID, 10)
+	if err != nil || len(logs) == 0 || logs[0].Action != string(database.ActionCycleCompleted) {
+		t.Errorf("Expected cycle_completed audit entry, got %+v, %v", logs, err)
+	}
+}
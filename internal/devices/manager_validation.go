@@ -0,0 +1,42 @@
+package devices
+
+import (
+	"steri-connect-go/internal/config"
+	"steri-connect-go/internal/database"
+	"steri-connect-go/internal/validation"
+)
+
+// validateCycle checks the recorded process values of a completed cycle against its program profile
+// and logs an audit entry; returns nil if validation is disabled or failed
+func (m *Manager) validateCycle(cycleID int) *database.CycleValidation {
+	cfg := config.Get().Validation
+	if !cfg.Enabled {
+		return nil
+	}
+
+	result, err := validation.ValidateCycle(cycleID, cfg)
+	if err != nil {
+		m.logger.Error("Failed to validate cycle",
+			"cycle_id", cycleID,
+			"error", err)
+		return nil
+	}
+
+	m.logger.Info("Cycle validated",
+		"cycle_id", cycleID,
+		"profile", result.Profile,
+		"validation_result", result.Result)
+
+	details := map[string]interface{}{
+		"cycle_id": cycleID,
+		"profile":  result.Profile,
+		"result":   result.Result,
+		"reasons":  result.Reasons,
+	}
+	if err := database.LogAudit(database.ActionCycleValidated, "cycle", &cycleID, "system", details); err != nil {
+		m.logger.Warn("Failed to log cycle validation audit",
+			"cycle_id", cycleID,
+			"error", err)
+	}
+	return result
+}
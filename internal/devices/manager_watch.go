@@ -0,0 +1,202 @@
+package devices
+
+import (
+	"time"
+
+	"steri-connect-go/internal/adapters"
+	"steri-connect-go/internal/api/websocket"
+	"steri-connect-go/internal/config"
+	"steri-connect-go/internal/database"
+)
+
+// observesCycles reports whether cycles of the device are started at the device and
+// only observed (the adapter reads cycle status but cannot start cycles)
+func observesCycles(registration adapters.Registration, adapter adapters.DeviceAdapter) bool {
+	readsStatus := registration.HasCapability(adapters.CapabilityCycleStatus) && adapters.Supports(adapter, adapters.CapabilityCycleStatus)
+	startsCycles := registration.HasCapability(adapters.CapabilityCycleStart) && adapters.Supports(adapter, adapters.CapabilityCycleStart)
+	return readsStatus && !startsCycles
+}
+
+// startCycleWatch watches a device for cycles started at the device (e.g. Getinge washers)
+func (m *Manager) startCycleWatch(deviceID int, reader adapters.CycleStatusReader) {
+	cfg := config.Get()
+	interval := time.Duration(cfg.Devices.Getinge.Modbus.PollInterval) * time.Second
+	if interval == 0 {
+		interval = 5 * time.Second // Default
+	}
+
+	m.cycleWatchersMutex.Lock()
+	stopChan := make(chan bool)
+	m.cycleWatchers[deviceID] = stopChan
+	m.cycleWatchersMutex.Unlock()
+
+	m.logger.Info("Starting cycle watch for device",
+		"device_id", deviceID,
+		"interval", interval)
+
+	go m.cycleWatchLoop(deviceID, reader, interval, stopChan)
+}
+
+// stopCycleWatch stops watching a device for cycles
+func (m *Manager) stopCycleWatch(deviceID int) {
+	m.cycleWatchersMutex.Lock()
+	defer m.cycleWatchersMutex.Unlock()
+
+	if stopChan, exists := m.cycleWatchers[deviceID]; exists {
+		close(stopChan)
+		delete(m.cycleWatchers, deviceID)
+		m.logger.Info("Stopped cycle watch for device",
+			"device_id", deviceID)
+	}
+}
+
+// cycleWatch is the state of a cycle watch between two reads
+type cycleWatch struct {
+	deviceID      int
+	activeCycleID int // Cycle being followed, 0 while the device is idle
+	activeProgram string
+	recorded      cycleRecord
+
+	// The followed cycle was closed elsewhere (stale cycle watchdog) while the device
+	// kept running closedProgram; that run is not recorded as a new cycle
+	closedRunning bool
+	closedProgram string
+}
+
+// cycleWatchLoop creates a cycle whenever the device starts running a program and
+// follows it until the device reports the result. Reading start and end in one loop
+// keeps back-to-back programs apart.
+func (m *Manager) cycleWatchLoop(deviceID int, reader adapters.CycleStatusReader, interval time.Duration, stopChan chan bool) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	watch := &cycleWatch{deviceID: deviceID}
+	for {
+		select {
+		case <-stopChan:
+			m.logger.Info("Cycle watch stopped",
+				"device_id", deviceID)
+			return
+		case <-ticker.C:
+		}
+
+		adapter := m.GetAdapter(deviceID)
+		if adapter == nil || !adapter.IsConnected() {
+			continue
+		}
+
+		status, err := reader.GetCycleStatus()
+		if err != nil {
+			m.logger.Warn("Failed to read cycle status",
+				"device_id", deviceID,
+				"error", err)
+			continue
+		}
+
+		m.observeCycleStatus(watch, status)
+	}
+}
+
+// observeCycleStatus applies one status read by the cycle watch
+func (m *Manager) observeCycleStatus(watch *cycleWatch, status adapters.CycleStatus) {
+	// The run of a closed cycle goes on until the device is idle, reports a result or runs another program
+	if watch.closedRunning {
+		if status.IsRunning && status.Result == "" && status.Program == watch.closedProgram {
+			return
+		}
+		watch.closedRunning = false
+		watch.closedProgram = ""
+	}
+
+	// A different program means the followed one ended between two reads; its result was not seen
+	if watch.activeCycleID != 0 && status.IsRunning && status.Program != watch.activeProgram {
+		m.applyCycleStatus(watch.activeCycleID, watch.deviceID, adapters.CycleStatus{
+			Phase:           "FAILED",
+			ProgressPercent: -1,
+			Error:           "Program result not observed",
+		}, &watch.recorded)
+		watch.activeCycleID = 0
+	}
+
+	if watch.activeCycleID == 0 {
+		if status.IsRunning {
+			watch.activeCycleID = m.recordObservedCycle(watch.deviceID, status)
+			watch.activeProgram = status.Program
+			watch.recorded = cycleRecord{}
+		}
+		return
+	}
+
+	// A program that stops without a result (e.g. aborted at the device) is not disinfected
+	if !status.IsRunning && status.Result == "" {
+		status.Phase = "FAILED"
+		status.Error = "Program ended without result"
+	}
+
+	if m.applyCycleStatus(watch.activeCycleID, watch.deviceID, status, &watch.recorded) {
+		// Only a cycle closed meanwhile ends while the device still runs
+		if status.IsRunning {
+			watch.closedRunning = true
+			watch.closedProgram = watch.activeProgram
+		}
+		watch.activeCycleID = 0
+	}
+}
+
+// recordObservedCycle creates the cycle row for a cycle started at the device
+// Returns the cycle ID, or 0 if the cycle could not be stored.
+func (m *Manager) recordObservedCycle(deviceID int, status adapters.CycleStatus) int {
+	cycle := &database.Cycle{
+		DeviceID:    deviceID,
+		Program:     status.Program,
+		StartTS:     time.Now(),
+		Phase:       status.Phase,
+		Temperature: status.Temperature,
+	}
+
+	createdCycle, err := database.CreateCycle(cycle)
+	if err != nil {
+		m.logger.Error("Failed to create cycle record for observed cycle",
+			"device_id", deviceID,
+			"error", err)
+		return 0
+	}
+	cycleID := createdCycle.ID
+
+	m.logger.Info("Cycle started at device",
+		"cycle_id", cycleID,
+		"device_id", deviceID,
+		"program", status.Program,
+		"phase", status.Phase)
+
+	event := websocket.Event{
+		Event: "cycle_started",
+		Data: map[string]interface{}{
+			"cycle_id":  cycleID,
+			"device_id": deviceID,
+			"program":   status.Program,
+			"phase":     status.Phase,
+			"source":    "device",
+		},
+	}
+	if err := websocket.BroadcastEvent(event); err != nil {
+		m.logger.Warn("Failed to broadcast cycle_started event",
+			"cycle_id", cycleID,
+			"error", err)
+	}
+
+	details := map[string]interface{}{
+		"cycle_id":  cycleID,
+		"device_id": deviceID,
+		"program":   status.Program,
+		"phase":     status.Phase,
+		"source":    "device",
+	}
+	if err := database.LogAudit(database.ActionCycleStarted, "cycle", &cycleID, "system", details); err != nil {
+		m.logger.Warn("Failed to log cycle start audit",
+			"cycle_id", cycleID,
+			"error", err)
+	}
+
+	return cycleID
+}
@@ -0,0 +1,182 @@
+package devices
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"steri-connect-go/internal/api/websocket"
+	"steri-connect-go/internal/config"
+	"steri-connect-go/internal/database"
+)
+
+// Error codes of cycles closed by the stale cycle watchdog
+const (
+	StaleCodeNoStatus    = "STALE_NO_STATUS"    // Device stopped reporting the cycle
+	StaleCodeMaxDuration = "STALE_MAX_DURATION" // Cycle ran longer than its program can
+)
+
+// StartCycleWatchdog starts checking running cycles for stale ones (stopped by Shutdown)
+func (m *Manager) StartCycleWatchdog() {
+	cfg := config.Get().Devices.CycleWatchdog
+	interval := time.Duration(cfg.CheckInterval) * time.Second
+	if interval == 0 {
+		interval = time.Minute // Default
+	}
+
+	m.cyclePollersMutex.Lock()
+	if m.watchdogStop != nil {
+		m.cyclePollersMutex.Unlock()
+		return // Already running
+	}
+	stopChan := make(chan bool)
+	m.watchdogStop = stopChan
+	m.cyclePollersMutex.Unlock()
+
+	m.logger.Info("Starting stale cycle watchdog",
+		"interval", interval,
+		"status_timeout_minutes", cfg.StatusTimeout,
+		"max_duration_minutes", cfg.MaxDuration)
+
+	go m.cycleWatchdogLoop(cfg, interval, stopChan)
+}
+
+// stopCycleWatchdog stops the stale cycle watchdog
+func (m *Manager) stopCycleWatchdog() {
+	m.cyclePollersMutex.Lock()
+	defer m.cyclePollersMutex.Unlock()
+
+	if m.watchdogStop != nil {
+		close(m.watchdogStop)
+		m.watchdogStop = nil
+	}
+}
+
+// cycleWatchdogLoop checks the running cycles every interval
+func (m *Manager) cycleWatchdogLoop(cfg config.CycleWatchdogConfig, interval time.Duration, stopChan chan bool) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-stopChan:
+			m.logger.Info("Stale cycle watchdog stopped")
+			return
+		case <-ticker.C:
+			m.closeStaleCycles(cfg, time.Now())
+		}
+	}
+}
+
+// closeStaleCycles closes every running cycle that is stale at now
+// Returns the IDs of the closed cycles.
+func (m *Manager) closeStaleCycles(cfg config.CycleWatchdogConfig, now time.Time) []int {
+	cycles, err := database.GetRunningCycleActivity()
+	if err != nil {
+		m.logger.Warn("Failed to load running cycles for stale check",
+			"error", err)
+		return nil
+	}
+
+	var closed []int
+	for _, cycle := range cycles {
+		code, description := staleReason(cycle, cfg, now)
+		if code == "" {
+			continue
+		}
+		if m.closeStaleCycle(cycle, code, description, now) {
+			closed = append(closed, cycle.CycleID)
+		}
+	}
+	return closed
+}
+
+// staleReason reports why a running cycle is stale at now (empty code if it is not)
+func staleReason(cycle database.RunningCycleActivity, cfg config.CycleWatchdogConfig, now time.Time) (code, description string) {
+	maxDuration := time.Duration(cfg.MaxDuration) * time.Minute
+	if minutes, ok := cfg.ProgramMaxDuration[cycle.Program]; ok {
+		maxDuration = time.Duration(minutes) * time.Minute
+	}
+	if running := now.Sub(cycle.StartTS); maxDuration > 0 && running > maxDuration {
+		return StaleCodeMaxDuration, fmt.Sprintf("Cycle running for %s, longer than the maximum of %s for program %q",
+			running.Round(time.Second), maxDuration, cycle.Program)
+	}
+
+	statusTimeout := time.Duration(cfg.StatusTimeout) * time.Minute
+	lastStatus := cycle.StartTS
+	if cycle.LastStatusAt != nil {
+		lastStatus = *cycle.LastStatusAt
+	}
+	if silent := now.Sub(lastStatus); statusTimeout > 0 && silent > statusTimeout {
+		return StaleCodeNoStatus, fmt.Sprintf("No status from the device for %s (limit %s), last phase %q",
+			silent.Round(time.Second), statusTimeout, cycle.Phase)
+	}
+
+	return "", ""
+}
+
+// closeStaleCycle ends a stale cycle with result UNKNOWN, broadcasts cycle_stale and logs an audit entry
+// Returns false if the cycle could not be closed or had already ended.
+func (m *Manager) closeStaleCycle(cycle database.RunningCycleActivity, code, description string, now time.Time) bool {
+	cycleID := cycle.CycleID
+	m.StopCyclePolling(cycleID)
+
+	err := database.UpdateCycleResult(cycleID, database.CycleResultUnknown, now, &code, &description)
+	if errors.Is(err, database.ErrCycleEnded) {
+		return false // Result arrived meanwhile
+	}
+	if err != nil {
+		m.logger.Error("Failed to close stale cycle",
+			"cycle_id", cycleID,
+			"error", err)
+		return false
+	}
+
+	m.logger.Warn("Closed stale cycle",
+		"cycle_id", cycleID,
+		"device_id", cycle.DeviceID,
+		"error_code", code,
+		"error_description", description)
+
+	var lastStatusAt interface{}
+	if cycle.LastStatusAt != nil {
+		lastStatusAt = cycle.LastStatusAt.Format(time.RFC3339)
+	}
+	event := websocket.Event{
+		Event: "cycle_stale",
+		Data: map[string]interface{}{
+			"cycle_id":          cycleID,
+			"device_id":         cycle.DeviceID,
+			"program":           cycle.Program,
+			"phase":             cycle.Phase,
+			"result":            database.CycleResultUnknown,
+			"error_code":        code,
+			"error_description": description,
+			"start_ts":          cycle.StartTS.Format(time.RFC3339),
+			"last_status_at":    lastStatusAt,
+			"end_ts":            now.Format(time.RFC3339),
+		},
+	}
+	if err := websocket.BroadcastEvent(event); err != nil {
+		m.logger.Warn("Failed to broadcast cycle_stale event",
+			"cycle_id", cycleID,
+			"error", err)
+	}
+
+	details := map[string]interface{}{
+		"cycle_id":          cycleID,
+		"device_id":         cycle.DeviceID,
+		"program":           cycle.Program,
+		"last_phase":        cycle.Phase,
+		"result":            database.CycleResultUnknown,
+		"error_code":        code,
+		"error_description": description,
+	}
+	if err := database.LogAudit(database.ActionCycleStale, "cycle", &cycleID, "system", details); err != nil {
+		m.logger.Warn("Failed to log stale cycle audit",
+			"cycle_id", cycleID,
+			"error", err)
+	}
+
+	return true
+}
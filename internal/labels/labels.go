@@ -0,0 +1,128 @@
+package labels
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
+
+	"steri-connect-go/internal/config"
+	"steri-connect-go/internal/database"
+)
+
+var (
+	ErrUnknownPackaging = errors.New("unknown packaging type")
+	ErrCycleNotReleased = errors.New("labels can only be printed for released cycles")
+	ErrInvalidCopies    = fmt.Errorf("copies must be between 1 and %d", MaxCopies)
+)
+
+// MaxCopies limits the number of labels generated for one cycle
+const MaxCopies = 100
+
+// Label represents one sterile goods label of a released cycle load
+type Label struct {
+	CycleID       int       `json:"cycle_id"`
+	BatchNumber   string    `json:"batch_number"` // Cycle number assigned by the device, cycle ID if unknown
+	DeviceName    string    `json:"device_name"`
+	Program       string    `json:"program,omitempty"`
+	PackagingType string    `json:"packaging_type"`
+	PackagingName string    `json:"packaging_name"`
+	SterilizedAt  time.Time `json:"sterilized_at"`
+	ExpiresAt     time.Time `json:"expires_at"`
+	Copy          int       `json:"copy"`   // 1-based counter of this label
+	Copies        int       `json:"copies"` // Number of labels printed for the cycle
+	Code          string    `json:"code"`   // Barcode content
+}
+
+// FindPackagingRule returns the configured rule for a packaging type
+func FindPackagingRule(cfg config.LabelsConfig, packagingType string) (config.PackagingRule, error) {
+	for _, rule := range cfg.Packaging {
+		if rule.Type == packagingType {
+			return rule, nil
+		}
+	}
+	return config.PackagingRule{}, ErrUnknownPackaging
+}
+
+// ExpiryDate returns the date until which goods sterilized at sterilizedAt may be used
+// Months are added first; if the target month is too short the expiry is its last day
+// (August 31 + 6 months = February 28), so the storage period is never exceeded.
+func ExpiryDate(sterilizedAt time.Time, rule config.PackagingRule) time.Time {
+	day := time.Date(sterilizedAt.Year(), sterilizedAt.Month(), sterilizedAt.Day(), 0, 0, 0, 0, sterilizedAt.Location())
+	expiry := day.AddDate(0, rule.ExpiryMonths, 0)
+	if expiry.Day() != day.Day() {
+		expiry = expiry.AddDate(0, 0, -expiry.Day())
+	}
+	return expiry.AddDate(0, 0, rule.ExpiryDays)
+}
+
+// Build creates the labels for a released cycle
+// packagingType falls back to the configured default packaging if empty.
+func Build(cycle *database.CycleWithDevice, cfg config.LabelsConfig, packagingType string, copies int) ([]Label, error) {
+	if cycle.ReleaseStatus != database.ReleaseStatusReleased {
+		return nil, ErrCycleNotReleased
+	}
+	if copies < 1 || copies > MaxCopies {
+		return nil, ErrInvalidCopies
+	}
+	if packagingType == "" {
+		packagingType = cfg.DefaultPackaging
+	}
+	rule, err := FindPackagingRule(cfg, packagingType)
+	if err != nil {
+		return nil, err
+	}
+
+	sterilizedAt := cycle.StartTS
+	if cycle.EndTS != nil {
+		sterilizedAt = *cycle.EndTS
+	}
+	expiresAt := ExpiryDate(sterilizedAt, rule)
+
+	batch := cycle.DeviceCycleNumber
+	if batch == "" {
+		batch = strconv.Itoa(cycle.ID)
+	}
+
+	name := rule.Name
+	if name == "" {
+		name = rule.Type
+	}
+
+	labels := make([]Label, 0, copies)
+	for n := 1; n <= copies; n++ {
+		labels = append(labels, Label{
+			CycleID:       cycle.ID,
+			BatchNumber:   batch,
+			DeviceName:    cycle.DeviceName,
+			Program:       cycle.Program,
+			PackagingType: rule.Type,
+			PackagingName: name,
+			SterilizedAt:  sterilizedAt,
+			ExpiresAt:     expiresAt,
+			Copy:          n,
+			Copies:        copies,
+			Code:          Code(cycle.ID, n, expiresAt),
+		})
+	}
+
+	return labels, nil
+}
+
+// Code returns the barcode content of a label: cycle ID, copy counter and expiry date,
+// e.g. "4711/2/20270416". Scanning it leads back to the cycle protocol.
+func Code(cycleID, copyNumber int, expiresAt time.Time) string {
+	return fmt.Sprintf("%d/%d/%s", cycleID, copyNumber, expiresAt.Format("20060102"))
+}
+
+// Lines returns the human-readable text of a label, one entry per line
+func (l Label) Lines() []string {
+	return []string{
+		fmt.Sprintf("Batch %s   Copy %d/%d", l.BatchNumber, l.Copy, l.Copies),
+		l.DeviceName,
+		fmt.Sprintf("Cycle %d  %s", l.CycleID, l.Program),
+		fmt.Sprintf("Sterilized: %s", l.SterilizedAt.Format("2006-01-02")),
+		fmt.Sprintf("Expires: %s", l.ExpiresAt.Format("2006-01-02")),
+		l.PackagingName,
+	}
+}
@@ -0,0 +1,82 @@
+package labels
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"steri-connect-go/internal/config"
+	"steri-connect-go/internal/database"
+)
+
+func TestBuild(t *testing.T) {
+	cfg := config.LabelsConfig{
+		Symbology:        "code128",
+		WidthMM:          60,
+		HeightMM:         40,
+		DotsPerMM:        8,
+		DefaultPackaging: "pouch",
+		Packaging: []config.PackagingRule{
+			{Type: "pouch", Name: "Sterilization pouch", ExpiryMonths: 6},
+			{Type: "container", ExpiryDays: 30},
+		},
+	}
+
+	end := time.Date(2026, 8, 31, 23, 10, 0, 0, time.UTC)
+	cycle := &database.CycleWithDevice{
+		Cycle: database.Cycle{
+			ID:                17,
+			StartTS:           end.Add(-45 * time.Minute),
+			EndTS:             &end,
+			Program:           "Universal-Programm",
+			DeviceCycleNumber: "4711",
+			ReleaseStatus:     database.ReleaseStatusCompleted,
+		},
+		DeviceName: "Melag Vacuklav 41B+",
+	}
+
+	if _, err := Build(cycle, cfg, "", 1); err != ErrCycleNotReleased {
+		t.Errorf("Expected ErrCycleNotReleased, got %v", err)
+	}
+
+	cycle.ReleaseStatus = database.ReleaseStatusReleased
+	if _, err := Build(cycle, cfg, "foil", 1); err != ErrUnknownPackaging {
+		t.Errorf("Expected ErrUnknownPackaging, got %v", err)
+	}
+	if _, err := Build(cycle, cfg, "", 0); err != ErrInvalidCopies {
+		t.Errorf("Expected ErrInvalidCopies, got %v", err)
+	}
+
+	items, err := Build(cycle, cfg, "", 3)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if len(items) != 3 || items[2].Copy != 3 || items[2].Copies != 3 {
+		t.Fatalf("Expected 3 numbered labels, got %+v", items)
+	}
+	// Six months after August 31 ends with February
+	if got := items[0].ExpiresAt.Format("2006-01-02"); got != "2027-02-28" {
+		t.Errorf("Expected expiry 2027-02-28, got %s", got)
+	}
+	if items[1].BatchNumber != "4711" || items[1].Code != "17/2/20270228" || items[1].PackagingName != "Sterilization pouch" {
+		t.Errorf("Unexpected label %+v", items[1])
+	}
+
+	items, err = Build(cycle, cfg, "container", 1)
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if got := items[0].ExpiresAt.Format("2006-01-02"); got != "2026-09-30" {
+		t.Errorf("Expected expiry 2026-09-30, got %s", got)
+	}
+
+	for _, symbology := range []string{"code128", "datamatrix"} {
+		zpl, err := GenerateZPL(items, cfg, symbology)
+		if err != nil {
+			t.Fatalf("GenerateZPL(%s) failed: %v", symbology, err)
+		}
+		if !bytes.HasPrefix(zpl, []byte("^XA")) || !bytes.Contains(zpl, []byte("^FD17/1/20260930^FS")) {
+			t.Errorf("Unexpected ZPL for %s:\n%s", symbology, zpl)
+		}
+	}
+}
@@ -0,0 +1,69 @@
+package labels
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"strings"
+
+	"steri-connect-go/internal/barcode"
+	"steri-connect-go/internal/config"
+)
+
+// GenerateZPL generates ZPL II for Zebra label printers, one label format per label
+// Barcodes are rendered by the printer (^BC Code 128, ^BX Data Matrix ECC 200).
+func GenerateZPL(labels []Label, cfg config.LabelsConfig, symbology string) ([]byte, error) {
+	dots := func(mm float64) int {
+		return int(math.Round(mm * float64(cfg.DotsPerMM)))
+	}
+	width, height := dots(cfg.WidthMM), dots(cfg.HeightMM)
+	margin := dots(2)
+	lineHeight := dots(math.Min(3, (cfg.HeightMM-4)/10))
+
+	var buf bytes.Buffer
+	for _, label := range labels {
+		textWidth := width - 2*margin
+		var code string
+
+		switch symbology {
+		case "code128":
+			modules, err := barcode.Code128(label.Code)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode label %d: %w", label.Copy, err)
+			}
+			// Module width in dots so that the symbol and its quiet zones fit the label
+			module := max(1, (width-2*margin)/(len(modules)+20))
+			barHeight := height - 2*margin - 7*lineHeight
+			code = fmt.Sprintf("^FO%d,%d^BY%d^BCN,%d,N,N,N^FH^FD%s^FS\n",
+				margin+10*module, height-margin-barHeight, module, barHeight, zplText(label.Code))
+		case "datamatrix":
+			matrix, err := barcode.DataMatrix(label.Code)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode label %d: %w", label.Copy, err)
+			}
+			size := min(height-2*margin, width*2/5)
+			module := max(1, size/(len(matrix)+2))
+			textWidth -= size
+			code = fmt.Sprintf("^FO%d,%d^BXN,%d,200^FH^FD%s^FS\n",
+				width-margin-module*(len(matrix)+1), margin+module, module, zplText(label.Code))
+		default:
+			return nil, fmt.Errorf("unsupported symbology: %s", symbology)
+		}
+
+		buf.WriteString("^XA\n^CI28\n")
+		fmt.Fprintf(&buf, "^PW%d\n^LL%d\n", width, height)
+		for i, line := range label.Lines() {
+			fmt.Fprintf(&buf, "^FO%d,%d^A0N,%d,%d^FB%d,1,0,L^FH^FD%s^FS\n",
+				margin, margin+i*lineHeight, lineHeight-2, lineHeight-2, textWidth, zplText(line))
+		}
+		buf.WriteString(code)
+		buf.WriteString("^XZ\n")
+	}
+
+	return buf.Bytes(), nil
+}
+
+// zplText escapes field data for use with ^FH (underscore as hex indicator)
+func zplText(text string) string {
+	return strings.NewReplacer("_", "_5F", "^", "_5E", "~", "_7E").Replace(text)
+}
@@ -0,0 +1,113 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"time"
+
+	"github.com/jung-kurt/gofpdf/v2"
+	"steri-connect-go/internal/database"
+)
+
+// GenerateAuditPDF generates a PDF report of audit log entries for auditors
+// The report states the result of the hash chain verification and the applied filter.
+func GenerateAuditPDF(entries []database.AuditLog, chain *database.AuditChainReport, filter string) ([]byte, error) {
+	pdf := gofpdf.New("L", "mm", "A4", "")
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.AliasNbPages("")
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-12)
+		pdf.SetFont("Arial", "", 8)
+		pdf.CellFormat(0, 6, fmt.Sprintf("Page %d/{nb}", pdf.PageNo()), "", 0, "R", false, 0, "")
+	})
+	pdf.AddPage()
+
+	pdf.SetFont("Arial", "B", 16)
+	pdf.Cell(40, 10, "Audit Log Report")
+	pdf.Ln(12)
+
+	// Integrity Section
+	pdf.SetFont("Arial", "B", 12)
+	pdf.Cell(40, 8, "Integrity")
+	pdf.Ln(8)
+
+	pdf.SetFont("Arial", "", 10)
+	if chain.Valid {
+		pdf.SetTextColor(0, 128, 0)
+		pdf.Cell(50, 6, fmt.Sprintf("Hash chain intact: %d entries verified", chain.Verified))
+	} else {
+		pdf.SetTextColor(255, 0, 0)
+		pdf.Cell(50, 6, fmt.Sprintf("Hash chain broken at entry %d (%s)", chain.FirstBroken.ID, chain.FirstBroken.Reason))
+	}
+	pdf.SetTextColor(0, 0, 0)
+	pdf.Ln(6)
+	if chain.LegacyEntries > 0 {
+		pdf.Cell(50, 6, fmt.Sprintf("Entries from before hash chaining (not verifiable): %d", chain.LegacyEntries))
+		pdf.Ln(6)
+	}
+	if chain.LastHash != "" {
+		pdf.Cell(50, 6, fmt.Sprintf("Chain head: entry %d, %s", chain.LastID, chain.LastHash))
+		pdf.Ln(6)
+	}
+	pdf.Cell(50, 6, fmt.Sprintf("Verified: %s", chain.VerifiedAt.Format("2006-01-02 15:04:05")))
+	pdf.Ln(6)
+	if filter != "" {
+		pdf.MultiCell(0, 6, tr(fmt.Sprintf("Filter: %s", filter)), "", "L", false)
+	}
+	pdf.Cell(50, 6, fmt.Sprintf("Entries in this report: %d", len(entries)))
+	pdf.Ln(10)
+
+	// Entries Section
+	header := func() {
+		pdf.SetFont("Arial", "B", 9)
+		pdf.CellFormat(14, 6, "ID", "B", 0, "L", false, 0, "")
+		pdf.CellFormat(34, 6, "Timestamp", "B", 0, "L", false, 0, "")
+		pdf.CellFormat(44, 6, "Action", "B", 0, "L", false, 0, "")
+		pdf.CellFormat(28, 6, "Entity", "B", 0, "L", false, 0, "")
+		pdf.CellFormat(30, 6, "User", "B", 0, "L", false, 0, "")
+		pdf.CellFormat(22, 6, "Hash", "B", 0, "L", false, 0, "")
+		pdf.CellFormat(105, 6, "Details", "B", 1, "L", false, 0, "")
+		pdf.SetFont("Arial", "", 8)
+	}
+	header()
+
+	_, pageHeight := pdf.GetPageSize()
+	_, _, _, bottom := pdf.GetMargins()
+	for _, entry := range entries {
+		// Start a new page with header instead of splitting the row
+		lines := pdf.SplitLines([]byte(tr(entry.Details)), 105)
+		if pdf.GetY()+float64(max(1, len(lines)))*5 > pageHeight-bottom-12 {
+			pdf.AddPage()
+			header()
+		}
+
+		entity := entry.EntityType
+		if entry.EntityID != nil {
+			entity = fmt.Sprintf("%s %d", entry.EntityType, *entry.EntityID)
+		}
+		hash := entry.Hash
+		if len(hash) > 12 {
+			hash = hash[:12]
+		}
+
+		pdf.CellFormat(14, 5, fmt.Sprintf("%d", entry.ID), "", 0, "L", false, 0, "")
+		pdf.CellFormat(34, 5, entry.Timestamp.Format("2006-01-02 15:04:05"), "", 0, "L", false, 0, "")
+		pdf.CellFormat(44, 5, entry.Action, "", 0, "L", false, 0, "")
+		pdf.CellFormat(28, 5, tr(entity), "", 0, "L", false, 0, "")
+		pdf.CellFormat(30, 5, tr(entry.User), "", 0, "L", false, 0, "")
+		pdf.CellFormat(22, 5, hash, "", 0, "L", false, 0, "")
+		pdf.MultiCell(105, 5, tr(entry.Details), "", "L", false)
+	}
+
+	pdf.Ln(4)
+	pdf.SetFont("Arial", "", 10)
+	pdf.Cell(50, 6, fmt.Sprintf("Report Generated: %s", time.Now().Format("2006-01-02 15:04:05")))
+	pdf.Ln(6)
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("failed to generate PDF: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,264 @@
+package pdf
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/jung-kurt/gofpdf/v2"
+	"steri-connect-go/internal/database"
+	"steri-connect-go/internal/validation"
+)
+
+// Chart layout in mm
+const (
+	chartHeight     = 70
+	chartAxisMargin = 16 // Room for the axis labels left and right of the plot
+)
+
+// phaseSummary holds the process values of one phase of a cycle
+type phaseSummary struct {
+	Phase       string
+	Start       time.Time
+	End         time.Time
+	Samples     []database.CycleSample
+	MinTemp     *float64
+	MaxTemp     *float64
+	MinPressure *float64
+	MaxPressure *float64
+	Hold        *time.Duration // Part of the validation hold within the phase, nil without hold
+}
+
+// summarizePhases splits samples (oldest first) into phases; a phase lasts until the next
+// one starts, the last one until end. hold is the hold counted by the parameter validation
+// (nil if the program has none), shown for the phases it overlaps.
+func summarizePhases(samples []database.CycleSample, end time.Time, hold *validation.Hold) []phaseSummary {
+	var phases []phaseSummary
+	for _, sample := range samples {
+		if len(phases) == 0 || phases[len(phases)-1].Phase != sample.Phase {
+			if len(phases) > 0 {
+				phases[len(phases)-1].End = sample.Timestamp
+			}
+			phases = append(phases, phaseSummary{Phase: sample.Phase, Start: sample.Timestamp})
+		}
+		phase := &phases[len(phases)-1]
+		phase.Samples = append(phase.Samples, sample)
+		phase.MinTemp, phase.MaxTemp = extend(phase.MinTemp, phase.MaxTemp, sample.Temperature)
+		phase.MinPressure, phase.MaxPressure = extend(phase.MinPressure, phase.MaxPressure, sample.Pressure)
+	}
+	if len(phases) == 0 {
+		return nil
+	}
+
+	last := &phases[len(phases)-1]
+	last.End = end
+	if last.End.Before(last.Start) {
+		last.End = last.Start
+	}
+
+	if hold != nil {
+		for i := range phases {
+			phases[i].Hold = holdWithin(*hold, phases[i].Start, phases[i].End)
+		}
+	}
+	return phases
+}
+
+// holdWithin returns the part of the hold between start and end, nil if they do not overlap
+func holdWithin(hold validation.Hold, start, end time.Time) *time.Duration {
+	if hold.Start.After(start) {
+		start = hold.Start
+	}
+	if hold.End.Before(end) {
+		end = hold.End
+	}
+	if !end.After(start) {
+		return nil
+	}
+	d := end.Sub(start)
+	return &d
+}
+
+// extend widens the range [min, max] by value (ignored if nil)
+func extend(min, max *float64, value *float64) (*float64, *float64) {
+	if value == nil {
+		return min, max
+	}
+	if min == nil || *value < *min {
+		min = value
+	}
+	if max == nil || *value > *max {
+		max = value
+	}
+	return min, max
+}
+
+// niceAxis rounds the range [min, max] outwards to steps of 1, 2, 2.5 or 5 times a power of ten
+func niceAxis(min, max float64, ticks int) (lo, hi, step float64) {
+	if max-min < 1e-9 {
+		min, max = min-1, max+1
+	}
+	raw := (max - min) / float64(ticks)
+	magnitude := math.Pow(10, math.Floor(math.Log10(raw)))
+	step = 10 * magnitude
+	for _, factor := range []float64{1, 2, 2.5, 5} {
+		if factor*magnitude >= raw {
+			step = factor * magnitude
+			break
+		}
+	}
+	return math.Floor(min/step) * step, math.Ceil(max/step) * step, step
+}
+
+// drawProcessChart plots temperature (left axis) and pressure (right axis) over the minutes since
+// start, with a dashed line and label at the start of every phase
+func drawProcessChart(pdf *gofpdf.Fpdf, tr func(string) string, phases []phaseSummary, start, end time.Time) {
+	pageWidth, pageHeight := pdf.GetPageSize()
+	left, _, right, bottom := pdf.GetMargins()
+	if pdf.GetY()+chartHeight+20 > pageHeight-bottom {
+		pdf.AddPage()
+	}
+
+	x0 := left + chartAxisMargin
+	width := pageWidth - left - right - 2*chartAxisMargin
+	y0 := pdf.GetY() + 2
+	height := float64(chartHeight)
+
+	var minTemp, maxTemp, minPressure, maxPressure *float64
+	for _, phase := range phases {
+		minTemp, maxTemp = extend(minTemp, maxTemp, phase.MinTemp)
+		minTemp, maxTemp = extend(minTemp, maxTemp, phase.MaxTemp)
+		minPressure, maxPressure = extend(minPressure, maxPressure, phase.MinPressure)
+		minPressure, maxPressure = extend(minPressure, maxPressure, phase.MaxPressure)
+	}
+
+	duration := end.Sub(start).Minutes()
+	if duration <= 0 {
+		duration = 1
+	}
+	timeLo, timeHi, timeStep := niceAxis(0, duration, 8)
+	timeLo = 0
+	xOf := func(t time.Time) float64 {
+		return x0 + (t.Sub(start).Minutes()-timeLo)/(timeHi-timeLo)*width
+	}
+
+	// Frame and time axis
+	pdf.SetDrawColor(0, 0, 0)
+	pdf.SetLineWidth(0.2)
+	pdf.Rect(x0, y0, width, height, "D")
+	pdf.SetFont("Arial", "", 7)
+	for minute := timeLo; minute <= timeHi+timeStep/2; minute += timeStep {
+		x := x0 + (minute-timeLo)/(timeHi-timeLo)*width
+		pdf.Line(x, y0+height, x, y0+height+1)
+		pdf.Text(x-3, y0+height+4, formatAxisValue(minute, timeStep))
+	}
+	pdf.Text(x0+width/2-10, y0+height+8, "Time since start (min)")
+
+	// Value axes with grid lines of the temperature axis
+	if minTemp != nil {
+		lo, hi, step := niceAxis(*minTemp, *maxTemp, 5)
+		pdf.SetTextColor(200, 0, 0)
+		for value := lo; value <= hi+step/2; value += step {
+			y := y0 + height - (value-lo)/(hi-lo)*height
+			pdf.SetDrawColor(220, 220, 220)
+			pdf.Line(x0, y, x0+width, y)
+			label := formatAxisValue(value, step)
+			pdf.Text(x0-pdf.GetStringWidth(label)-1.5, y+1, label)
+		}
+		pdf.TransformBegin()
+		pdf.TransformRotate(90, left+3, y0+height/2+12)
+		pdf.Text(left+3, y0+height/2+12, tr("Temperature (°C)"))
+		pdf.TransformEnd()
+		plotSeries(pdf, phases, xOf, func(sample database.CycleSample) *float64 { return sample.Temperature }, y0, height, lo, hi, [3]int{200, 0, 0})
+	}
+	if minPressure != nil {
+		lo, hi, step := niceAxis(*minPressure, *maxPressure, 5)
+		pdf.SetTextColor(0, 0, 200)
+		for value := lo; value <= hi+step/2; value += step {
+			y := y0 + height - (value-lo)/(hi-lo)*height
+			pdf.SetDrawColor(0, 0, 0)
+			pdf.Line(x0+width, y, x0+width+1, y)
+			pdf.Text(x0+width+1.5, y+1, formatAxisValue(value, step))
+		}
+		pdf.TransformBegin()
+		pdf.TransformRotate(90, pageWidth-right-1, y0+height/2+8)
+		pdf.Text(pageWidth-right-1, y0+height/2+8, "Pressure (bar)")
+		pdf.TransformEnd()
+		plotSeries(pdf, phases, xOf, func(sample database.CycleSample) *float64 { return sample.Pressure }, y0, height, lo, hi, [3]int{0, 0, 200})
+	}
+	pdf.SetTextColor(0, 0, 0)
+
+	// Phase boundaries
+	pdf.SetDrawColor(120, 120, 120)
+	pdf.SetDashPattern([]float64{1, 1}, 0)
+	pdf.SetFont("Arial", "", 6)
+	for i, phase := range phases {
+		x := xOf(phase.Start)
+		if i > 0 {
+			pdf.Line(x, y0, x, y0+height)
+		}
+		pdf.TransformBegin()
+		pdf.TransformRotate(90, x+2.5, y0+height-1)
+		pdf.Text(x+2.5, y0+height-1, tr(phase.Phase))
+		pdf.TransformEnd()
+	}
+	pdf.SetDashPattern([]float64{}, 0)
+	pdf.SetDrawColor(0, 0, 0)
+
+	pdf.SetY(y0 + height + 10)
+}
+
+// plotSeries draws one process value as line, interrupted where the device reported no value
+func plotSeries(pdf *gofpdf.Fpdf, phases []phaseSummary, xOf func(time.Time) float64, value func(database.CycleSample) *float64, y0, height, lo, hi float64, color [3]int) {
+	pdf.SetDrawColor(color[0], color[1], color[2])
+	pdf.SetFillColor(color[0], color[1], color[2])
+	pdf.SetLineWidth(0.4)
+
+	havePrevious := false
+	var previousX, previousY float64
+	for _, phase := range phases {
+		for _, sample := range phase.Samples {
+			v := value(sample)
+			if v == nil {
+				havePrevious = false
+				continue
+			}
+			x := xOf(sample.Timestamp)
+			y := y0 + height - (*v-lo)/(hi-lo)*height
+			if havePrevious {
+				pdf.Line(previousX, previousY, x, y)
+			} else {
+				pdf.Circle(x, y, 0.3, "F")
+			}
+			previousX, previousY, havePrevious = x, y, true
+		}
+	}
+
+	pdf.SetLineWidth(0.2)
+	pdf.SetDrawColor(0, 0, 0)
+	pdf.SetFillColor(0, 0, 0)
+}
+
+// formatAxisValue formats a tick label with as many decimals as the step needs
+func formatAxisValue(value, step float64) string {
+	if step >= 1 {
+		return fmt.Sprintf("%.0f", value)
+	}
+	decimals := int(math.Ceil(-math.Log10(step)))
+	return strings.TrimRight(strings.TrimRight(fmt.Sprintf("%.*f", decimals, value), "0"), ".")
+}
+
+// formatDuration formats a duration as minutes and seconds, e.g. "3:30"
+func formatDuration(d time.Duration) string {
+	seconds := int(d.Round(time.Second).Seconds())
+	return fmt.Sprintf("%d:%02d", seconds/60, seconds%60)
+}
+
+// formatRange formats the range of a process value, "-" if there was none
+func formatRange(value *float64, format string) string {
+	if value == nil {
+		return "-"
+	}
+	return fmt.Sprintf(format, *value)
+}
@@ -3,15 +3,29 @@ package pdf
 import (
 	"bytes"
 	"fmt"
+	"slices"
+	"strings"
 	"time"
 
 	"github.com/jung-kurt/gofpdf/v2"
 	"steri-connect-go/internal/database"
+	"steri-connect-go/internal/validation"
 )
 
 // GenerateCyclePDF generates a PDF document for a cycle protocol
-func GenerateCyclePDF(cycle *database.CycleWithDevice) ([]byte, error) {
+// loads lists the instrument sets in the cycle load, releases the release decisions
+// (Chargenfreigabe) and samples the recorded process values, oldest first; all may be empty.
+// validation is the parameter validation verdict, nil if the cycle was not validated; hold
+// is the hold the validation counts in samples, nil if the program has no profile or no hold.
+func GenerateCyclePDF(cycle *database.CycleWithDevice, loads []database.CycleLoad, releases []database.CycleRelease, samples []database.CycleSample, validation *database.CycleValidation, hold *validation.Hold) ([]byte, error) {
 	pdf := gofpdf.New("P", "mm", "A4", "")
+	tr := pdf.UnicodeTranslatorFromDescriptor("")
+	pdf.AliasNbPages("")
+	pdf.SetFooterFunc(func() {
+		pdf.SetY(-15)
+		pdf.SetFont("Arial", "I", 8)
+		pdf.CellFormat(0, 10, fmt.Sprintf("Page %d/{nb}", pdf.PageNo()), "", 0, "C", false, 0, "")
+	})
 	pdf.AddPage()
 
 	// Set font
@@ -71,6 +85,68 @@ func GenerateCyclePDF(cycle *database.CycleWithDevice) ([]byte, error) {
 		pdf.Ln(6)
 	}
 
+	if cycle.A0Value != nil {
+		pdf.Cell(50, 6, fmt.Sprintf("A0 Value: %.0f", *cycle.A0Value))
+		pdf.Ln(6)
+	}
+
+	pdf.Ln(4)
+
+	// Process Curve Section
+	pdf.SetFont("Arial", "B", 12)
+	pdf.Cell(40, 8, "Process Curve")
+	pdf.Ln(8)
+
+	end := time.Now()
+	if cycle.EndTS != nil {
+		end = *cycle.EndTS
+	} else if len(samples) > 0 {
+		end = samples[len(samples)-1].Timestamp
+	}
+	phases := summarizePhases(samples, end, hold)
+
+	pdf.SetFont("Arial", "", 10)
+	if len(phases) == 0 {
+		pdf.Cell(50, 6, "No process values recorded")
+		pdf.Ln(6)
+	} else {
+		drawProcessChart(pdf, tr, phases, cycle.StartTS, end)
+
+		pdf.SetFont("Arial", "B", 9)
+		pdf.CellFormat(44, 6, "Phase", "B", 0, "L", false, 0, "")
+		pdf.CellFormat(18, 6, "Start", "B", 0, "R", false, 0, "")
+		pdf.CellFormat(20, 6, "Duration", "B", 0, "R", false, 0, "")
+		pdf.CellFormat(22, 6, tr("Min °C"), "B", 0, "R", false, 0, "")
+		pdf.CellFormat(22, 6, tr("Max °C"), "B", 0, "R", false, 0, "")
+		pdf.CellFormat(22, 6, "Min bar", "B", 0, "R", false, 0, "")
+		pdf.CellFormat(22, 6, "Max bar", "B", 0, "R", false, 0, "")
+		pdf.CellFormat(20, 6, "Hold", "B", 1, "R", false, 0, "")
+
+		pdf.SetFont("Arial", "", 9)
+		for _, phase := range phases {
+			pdf.CellFormat(44, 6, tr(phase.Phase), "", 0, "L", false, 0, "")
+			pdf.CellFormat(18, 6, formatDuration(phase.Start.Sub(cycle.StartTS)), "", 0, "R", false, 0, "")
+			pdf.CellFormat(20, 6, formatDuration(phase.End.Sub(phase.Start)), "", 0, "R", false, 0, "")
+			pdf.CellFormat(22, 6, formatRange(phase.MinTemp, "%.1f"), "", 0, "R", false, 0, "")
+			pdf.CellFormat(22, 6, formatRange(phase.MaxTemp, "%.1f"), "", 0, "R", false, 0, "")
+			pdf.CellFormat(22, 6, formatRange(phase.MinPressure, "%.2f"), "", 0, "R", false, 0, "")
+			pdf.CellFormat(22, 6, formatRange(phase.MaxPressure, "%.2f"), "", 0, "R", false, 0, "")
+			holdText := "-"
+			if phase.Hold != nil {
+				holdText = formatDuration(*phase.Hold)
+			}
+			pdf.CellFormat(20, 6, holdText, "", 1, "R", false, 0, "")
+		}
+
+		pdf.SetFont("Arial", "I", 8)
+		note := fmt.Sprintf("Start and duration in min:s since cycle start. %d samples.", len(samples))
+		if hold != nil {
+			note += fmt.Sprintf(" Hold: part of the %s hold counted by the parameter validation.", formatDuration(hold.Duration))
+		}
+		pdf.Cell(50, 5, tr(note))
+		pdf.Ln(5)
+	}
+
 	pdf.Ln(4)
 
 	// Result Section
@@ -81,8 +157,8 @@ func GenerateCyclePDF(cycle *database.CycleWithDevice) ([]byte, error) {
 	pdf.SetFont("Arial", "", 10)
 	if cycle.Result != "" {
 		resultColor := "green"
-		if cycle.Result == "NOK" {
-			resultColor = "red"
+		if cycle.Result != "OK" {
+			resultColor = "red" // NOK or UNKNOWN (closed as stale)
 		}
 		pdf.SetTextColor(0, 0, 0)
 		if resultColor == "red" {
@@ -114,6 +190,117 @@ func GenerateCyclePDF(cycle *database.CycleWithDevice) ([]byte, error) {
 
 	pdf.Ln(4)
 
+	// Parameter Validation Section
+	pdf.SetFont("Arial", "B", 12)
+	pdf.Cell(40, 8, "Parameter Validation")
+	pdf.Ln(8)
+
+	pdf.SetFont("Arial", "", 10)
+	if validation == nil {
+		pdf.Cell(50, 6, "Not validated")
+		pdf.Ln(6)
+	} else {
+		switch validation.Result {
+		case database.ValidationPass:
+			pdf.SetTextColor(0, 128, 0)
+		case database.ValidationFail:
+			pdf.SetTextColor(255, 0, 0)
+		}
+		pdf.Cell(50, 6, fmt.Sprintf("Validation Result: %s", validation.Result))
+		pdf.SetTextColor(0, 0, 0)
+		pdf.Ln(6)
+		if validation.Profile != "" {
+			pdf.Cell(50, 6, tr(fmt.Sprintf("Profile: %s", validation.Profile)))
+			pdf.Ln(6)
+		}
+		pdf.Cell(50, 6, fmt.Sprintf("Validated: %s", validation.ValidatedAt.Format("2006-01-02 15:04:05")))
+		pdf.Ln(8)
+
+		pdf.SetFont("Arial", "B", 9)
+		pdf.CellFormat(38, 6, "Rule", "B", 0, "L", false, 0, "")
+		pdf.CellFormat(14, 6, "Check", "B", 0, "L", false, 0, "")
+		pdf.CellFormat(0, 6, "Details", "B", 1, "L", false, 0, "")
+
+		pdf.SetFont("Arial", "", 9)
+		for _, reason := range validation.Reasons {
+			pdf.CellFormat(38, 5, reason.Rule, "", 0, "L", false, 0, "")
+			if reason.Passed {
+				pdf.SetTextColor(0, 128, 0)
+				pdf.CellFormat(14, 5, "OK", "", 0, "L", false, 0, "")
+			} else {
+				pdf.SetTextColor(255, 0, 0)
+				pdf.CellFormat(14, 5, "FAIL", "", 0, "L", false, 0, "")
+			}
+			pdf.SetTextColor(0, 0, 0)
+			pdf.MultiCell(0, 5, tr(reason.Message), "", "L", false)
+		}
+	}
+
+	pdf.Ln(4)
+
+	// Load Section
+	pdf.SetFont("Arial", "B", 12)
+	pdf.Cell(40, 8, "Load")
+	pdf.Ln(8)
+
+	pdf.SetFont("Arial", "", 10)
+	if len(loads) == 0 {
+		pdf.Cell(50, 6, "No instrument sets assigned")
+		pdf.Ln(6)
+	} else {
+		pdf.SetFont("Arial", "B", 9)
+		pdf.CellFormat(30, 6, "Code", "B", 0, "L", false, 0, "")
+		pdf.CellFormat(60, 6, "Name", "B", 0, "L", false, 0, "")
+		pdf.CellFormat(20, 6, "Kind", "B", 0, "L", false, 0, "")
+		pdf.CellFormat(12, 6, "Qty", "B", 0, "R", false, 0, "")
+		pdf.CellFormat(35, 6, "Operator", "B", 0, "L", false, 0, "")
+		pdf.CellFormat(33, 6, "Assigned", "B", 1, "L", false, 0, "")
+
+		pdf.SetFont("Arial", "", 9)
+		for _, load := range loads {
+			pdf.CellFormat(30, 6, load.SetCode, "", 0, "L", false, 0, "")
+			pdf.CellFormat(60, 6, load.SetName, "", 0, "L", false, 0, "")
+			pdf.CellFormat(20, 6, load.SetKind, "", 0, "L", false, 0, "")
+			pdf.CellFormat(12, 6, fmt.Sprintf("%d", load.Quantity), "", 0, "R", false, 0, "")
+			pdf.CellFormat(35, 6, load.Operator, "", 0, "L", false, 0, "")
+			pdf.CellFormat(33, 6, load.AssignedAt.Format("2006-01-02 15:04"), "", 1, "L", false, 0, "")
+		}
+	}
+
+	pdf.Ln(4)
+
+	// Release Section
+	pdf.SetFont("Arial", "B", 12)
+	pdf.Cell(40, 8, "Release")
+	pdf.Ln(8)
+
+	pdf.SetFont("Arial", "", 10)
+	if cycle.ReleaseStatus != "" {
+		pdf.Cell(50, 6, fmt.Sprintf("Release Status: %s", cycle.ReleaseStatus))
+	} else {
+		pdf.Cell(50, 6, "Release Status: Cycle running")
+	}
+	pdf.Ln(6)
+
+	for _, release := range releases {
+		line := fmt.Sprintf("%s  %s -> %s by %s",
+			release.Timestamp.Format("2006-01-02 15:04:05"), release.FromStatus, release.ToStatus, release.User)
+		if release.Checklist != nil {
+			line += fmt.Sprintf(" (indicator: %s, packaging intact: %s, dry: %s)",
+				yesNo(release.Checklist.ChemicalIndicator), yesNo(release.Checklist.PackagingIntact), yesNo(release.Checklist.Dry))
+		}
+		pdf.Cell(50, 6, line)
+		pdf.Ln(6)
+		if release.Reason != "" {
+			pdf.Cell(50, 6, fmt.Sprintf("    Reason: %s", release.Reason))
+			pdf.Ln(6)
+		}
+	}
+
+	pdf.Ln(4)
+
+	drawSignatureBlock(pdf, tr, loads, releases)
+
 	// Audit Information Section
 	pdf.SetFont("Arial", "B", 12)
 	pdf.Cell(40, 8, "Audit Information")
@@ -125,11 +312,6 @@ func GenerateCyclePDF(cycle *database.CycleWithDevice) ([]byte, error) {
 	pdf.Cell(50, 6, "Generated By: Steri-Connect System")
 	pdf.Ln(6)
 
-	// Footer
-	pdf.SetY(-15)
-	pdf.SetFont("Arial", "I", 8)
-	pdf.CellFormat(0, 10, fmt.Sprintf("Page %d", pdf.PageNo()), "", 0, "C", false, 0, "")
-
 	// Generate PDF bytes
 	var buf bytes.Buffer
 	err := pdf.Output(&buf)
@@ -140,3 +322,58 @@ func GenerateCyclePDF(cycle *database.CycleWithDevice) ([]byte, error) {
 	return buf.Bytes(), nil
 }
 
+// drawSignatureBlock prints signature fields for the operator who loaded the device and the
+// person who released the load, with the names known from the load and release history
+func drawSignatureBlock(pdf *gofpdf.Fpdf, tr func(string) string, loads []database.CycleLoad, releases []database.CycleRelease) {
+	_, pageHeight := pdf.GetPageSize()
+	_, _, _, bottom := pdf.GetMargins()
+	if pdf.GetY()+40 > pageHeight-bottom {
+		pdf.AddPage()
+	}
+
+	var operators []string
+	for _, load := range loads {
+		if !slices.Contains(operators, load.Operator) {
+			operators = append(operators, load.Operator)
+		}
+	}
+	releasedBy, releaseLabel := "", "Released by"
+	for _, release := range releases {
+		switch release.ToStatus {
+		case database.ReleaseStatusReleased:
+			releasedBy, releaseLabel = release.User, "Released by"
+		case database.ReleaseStatusRejected:
+			releasedBy, releaseLabel = release.User, "Rejected by"
+		}
+	}
+
+	pdf.SetFont("Arial", "B", 12)
+	pdf.Cell(40, 8, "Signatures")
+	pdf.Ln(10)
+
+	left, _, _, _ := pdf.GetMargins()
+	y := pdf.GetY()
+	fields := []struct{ label, name string }{
+		{"Operator (loading)", strings.Join(operators, ", ")},
+		{releaseLabel, releasedBy},
+	}
+	for i, field := range fields {
+		x := left + float64(i)*95
+		pdf.SetFont("Arial", "B", 9)
+		pdf.Text(x, y, field.label)
+		pdf.SetFont("Arial", "", 9)
+		pdf.Text(x, y+6, tr("Name: "+field.name))
+		pdf.Line(x, y+20, x+85, y+20)
+		pdf.SetFont("Arial", "", 7)
+		pdf.Text(x, y+24, "Date, signature")
+	}
+	pdf.SetY(y + 30)
+}
+
+// yesNo formats a checklist item
+func yesNo(checked bool) string {
+	if checked {
+		return "yes"
+	}
+	return "no"
+}
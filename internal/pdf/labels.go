@@ -0,0 +1,97 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+
+	"github.com/jung-kurt/gofpdf/v2"
+	"steri-connect-go/internal/barcode"
+	"steri-connect-go/internal/config"
+	"steri-connect-go/internal/labels"
+)
+
+// GenerateLabelsPDF generates a PDF with one page per label in the configured label size
+func GenerateLabelsPDF(items []labels.Label, cfg config.LabelsConfig, symbology string) ([]byte, error) {
+	const margin = 2.0
+	width, height := cfg.WidthMM, cfg.HeightMM
+	lineHeight := math.Min(3, (height-2*margin)/10)
+
+	// Portrait keeps the page size as given, also for labels wider than high
+	pdf := gofpdf.NewCustom(&gofpdf.InitType{
+		OrientationStr: "P",
+		UnitStr:        "mm",
+		Size:           gofpdf.SizeType{Wd: width, Ht: height},
+	})
+	pdf.SetMargins(margin, margin, margin)
+	pdf.SetAutoPageBreak(false, 0)
+
+	for _, label := range items {
+		pdf.AddPage()
+		textWidth := width - 2*margin
+
+		switch symbology {
+		case "code128":
+			modules, err := barcode.Code128(label.Code)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode label %d: %w", label.Copy, err)
+			}
+			// Bars over the full width, including 10 module quiet zones on both sides
+			module := (width - 2*margin) / float64(len(modules)+20)
+			barHeight := height - 2*margin - 7*lineHeight
+			top := height - margin - barHeight
+			for i := 0; i < len(modules); {
+				if !modules[i] {
+					i++
+					continue
+				}
+				start := i
+				for i < len(modules) && modules[i] {
+					i++
+				}
+				pdf.Rect(margin+float64(10+start)*module, top, float64(i-start)*module, barHeight-lineHeight, "F")
+			}
+			pdf.SetFont("Arial", "", lineHeight*2)
+			pdf.SetXY(margin, height-margin-lineHeight)
+			pdf.CellFormat(width-2*margin, lineHeight, label.Code, "", 0, "C", false, 0, "")
+		case "datamatrix":
+			matrix, err := barcode.DataMatrix(label.Code)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode label %d: %w", label.Copy, err)
+			}
+			size := math.Min(height-2*margin, width*2/5)
+			module := size / float64(len(matrix)+2)
+			left := width - margin - size + module
+			top := margin + module
+			for row := range matrix {
+				for col, dark := range matrix[row] {
+					if dark {
+						pdf.Rect(left+float64(col)*module, top+float64(row)*module, module, module, "F")
+					}
+				}
+			}
+			textWidth -= size
+		default:
+			return nil, fmt.Errorf("unsupported symbology: %s", symbology)
+		}
+
+		pdf.SetXY(margin, margin)
+		for i, line := range label.Lines() {
+			style := ""
+			if i == 0 {
+				style = "B"
+			}
+			// Font size in points filling the line height in mm
+			pdf.SetFont("Arial", style, lineHeight*2.4)
+			pdf.CellFormat(textWidth, lineHeight, line, "", 2, "L", false, 0, "")
+		}
+	}
+
+	var buf bytes.Buffer
+	err := pdf.Output(&buf)
+	if err != nil {
+		return nil, err
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,130 @@
+package pdf
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/jung-kurt/gofpdf/v2"
+	"steri-connect-go/internal/database"
+)
+
+// GenerateRecallPDF generates a PDF report of a recall case and its affected cycles
+func GenerateRecallPDF(report *database.RecallReport) ([]byte, error) {
+	recall := report.Case
+
+	pdf := gofpdf.New("P", "mm", "A4", "")
+	pdf.AddPage()
+
+	pdf.SetFont("Arial", "B", 16)
+	pdf.Cell(40, 10, fmt.Sprintf("Recall Report #%d", recall.ID))
+	pdf.Ln(12)
+
+	// Case Section
+	pdf.SetFont("Arial", "B", 12)
+	pdf.Cell(40, 8, "Recall Case")
+	pdf.Ln(8)
+
+	pdf.SetFont("Arial", "", 10)
+	pdf.Cell(50, 6, fmt.Sprintf("Status: %s", recall.Status))
+	pdf.Ln(6)
+	pdf.Cell(50, 6, fmt.Sprintf("Device: %s (ID %d)", report.FailedCycle.DeviceName, recall.DeviceID))
+	pdf.Ln(6)
+	pdf.Cell(50, 6, fmt.Sprintf("Opened: %s by %s", recall.Created.Format("2006-01-02 15:04:05"), recall.OpenedBy))
+	pdf.Ln(6)
+	if recall.Description != "" {
+		pdf.MultiCell(0, 6, fmt.Sprintf("Description: %s", recall.Description), "", "L", false)
+	}
+	if recall.ClosedAt != nil {
+		pdf.Cell(50, 6, fmt.Sprintf("Closed: %s by %s", recall.ClosedAt.Format("2006-01-02 15:04:05"), recall.ClosedBy))
+		pdf.Ln(6)
+		pdf.MultiCell(0, 6, fmt.Sprintf("Resolution: %s", recall.Resolution), "", "L", false)
+	}
+
+	pdf.Ln(4)
+
+	// Window Section
+	pdf.SetFont("Arial", "B", 12)
+	pdf.Cell(40, 8, "Recall Window")
+	pdf.Ln(8)
+
+	pdf.SetFont("Arial", "", 10)
+	failed := report.FailedCycle
+	pdf.SetTextColor(255, 0, 0)
+	pdf.Cell(50, 6, fmt.Sprintf("Failed indicator: cycle %d, %s, started %s",
+		failed.ID, failed.Program, failed.StartTS.Format("2006-01-02 15:04:05")))
+	pdf.SetTextColor(0, 0, 0)
+	pdf.Ln(6)
+	if test := report.LastPassedTest; test != nil {
+		pdf.Cell(50, 6, fmt.Sprintf("Last passed test: cycle %d, %s, started %s",
+			test.ID, test.Program, test.StartTS.Format("2006-01-02 15:04:05")))
+	} else {
+		pdf.Cell(50, 6, "Last passed test: none found, all earlier cycles of the device are affected")
+	}
+	pdf.Ln(6)
+	pdf.Cell(50, 6, fmt.Sprintf("Affected cycles: %d", len(report.Cycles)))
+	pdf.Ln(10)
+
+	// Affected Cycles Section
+	pdf.SetFont("Arial", "B", 12)
+	pdf.Cell(40, 8, "Affected Cycles")
+	pdf.Ln(8)
+
+	pdf.SetFont("Arial", "B", 9)
+	pdf.CellFormat(14, 6, "Cycle", "B", 0, "L", false, 0, "")
+	pdf.CellFormat(32, 6, "Start", "B", 0, "L", false, 0, "")
+	pdf.CellFormat(40, 6, "Program", "B", 0, "L", false, 0, "")
+	pdf.CellFormat(14, 6, "Result", "B", 0, "L", false, 0, "")
+	pdf.CellFormat(28, 6, "Release", "B", 0, "L", false, 0, "")
+	pdf.CellFormat(62, 6, "Operators / Sets", "B", 1, "L", false, 0, "")
+
+	pdf.SetFont("Arial", "", 9)
+	for _, cycle := range report.Cycles {
+		sets := make([]string, 0, len(cycle.Loads))
+		for _, load := range cycle.Loads {
+			sets = append(sets, fmt.Sprintf("%s x%d", load.SetCode, load.Quantity))
+		}
+		load := strings.Join(cycle.Operators, ", ")
+		if len(sets) > 0 {
+			load += " / " + strings.Join(sets, ", ")
+		}
+
+		if cycle.ID == recall.FailedCycleID {
+			pdf.SetTextColor(255, 0, 0)
+		}
+		pdf.CellFormat(14, 6, fmt.Sprintf("%d", cycle.ID), "", 0, "L", false, 0, "")
+		pdf.CellFormat(32, 6, cycle.StartTS.Format("2006-01-02 15:04"), "", 0, "L", false, 0, "")
+		pdf.CellFormat(40, 6, cycle.Program, "", 0, "L", false, 0, "")
+		pdf.CellFormat(14, 6, cycle.Result, "", 0, "L", false, 0, "")
+		pdf.CellFormat(28, 6, cycle.ReleaseStatus, "", 0, "L", false, 0, "")
+		pdf.MultiCell(62, 6, load, "", "L", false)
+		pdf.SetTextColor(0, 0, 0)
+	}
+
+	pdf.Ln(4)
+
+	// Notes Section
+	if len(report.Notes) > 0 {
+		pdf.SetFont("Arial", "B", 12)
+		pdf.Cell(40, 8, "QA Notes")
+		pdf.Ln(8)
+
+		pdf.SetFont("Arial", "", 10)
+		for _, note := range report.Notes {
+			pdf.MultiCell(0, 6, fmt.Sprintf("%s  %s: %s", note.Created.Format("2006-01-02 15:04"), note.User, note.Note), "", "L", false)
+		}
+		pdf.Ln(4)
+	}
+
+	pdf.SetFont("Arial", "", 10)
+	pdf.Cell(50, 6, fmt.Sprintf("Report Generated: %s", time.Now().Format("2006-01-02 15:04:05")))
+	pdf.Ln(6)
+
+	var buf bytes.Buffer
+	if err := pdf.Output(&buf); err != nil {
+		return nil, fmt.Errorf("failed to generate PDF: %w", err)
+	}
+
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,342 @@
+package simulator
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// FTPServer is a minimal in-memory FTP server emulating a MELAnet Box
+// It supports the subset of RFC 959/3659 used by github.com/jlaffaye/ftp:
+// login, FEAT, TYPE, EPSV/PASV, MLSD, RETR, STOR, DELE and NOOP.
+type FTPServer struct {
+	username string
+	password string
+	listener net.Listener
+
+	mu        sync.Mutex
+	files     map[string]ftpFile
+	retrCount map[string]int
+	onStore   func(name string, data []byte)
+	silent    bool // Commands are read but not answered
+}
+
+type ftpFile struct {
+	data    []byte
+	modTime time.Time
+}
+
+// NewFTPServer creates an FTP server accepting the given credentials
+func NewFTPServer(username, password string) *FTPServer {
+	return &FTPServer{
+		username:  username,
+		password:  password,
+		files:     make(map[string]ftpFile),
+		retrCount: make(map[string]int),
+	}
+}
+
+// Start listens on addr ("127.0.0.1:0" picks a free port) and serves clients in the background
+func (s *FTPServer) Start(addr string) error {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	s.listener = listener
+
+	go s.serve()
+	return nil
+}
+
+// Close stops accepting new control connections
+func (s *FTPServer) Close() error {
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Close()
+}
+
+// Addr returns the host:port of the control connection
+func (s *FTPServer) Addr() string {
+	if s.listener == nil {
+		return ""
+	}
+	return s.listener.Addr().String()
+}
+
+// PutFile stores a file with the given modification time
+func (s *FTPServer) PutFile(name string, content string, modTime time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.files[cleanFTPPath(name)] = ftpFile{data: []byte(content), modTime: modTime.UTC()}
+}
+
+// ReadFile returns the content of a stored file
+func (s *FTPServer) ReadFile(name string) ([]byte, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	file, ok := s.files[cleanFTPPath(name)]
+	return file.data, ok
+}
+
+// DeleteFile removes a stored file
+func (s *FTPServer) DeleteFile(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.files, cleanFTPPath(name))
+}
+
+// HasFile reports whether a file exists on the server
+func (s *FTPServer) HasFile(name string) bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	_, ok := s.files[cleanFTPPath(name)]
+	return ok
+}
+
+// RetrCount returns how often a file has been downloaded
+func (s *FTPServer) RetrCount(name string) int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.retrCount[cleanFTPPath(name)]
+}
+
+// OnStore registers a callback invoked when a client has uploaded a file
+func (s *FTPServer) OnStore(fn func(name string, data []byte)) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.onStore = fn
+}
+
+// SetUnresponsive stops answering commands on open sessions, like a box whose
+// connection dropped without the TCP session being closed
+func (s *FTPServer) SetUnresponsive(silent bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.silent = silent
+}
+
+// unresponsive reports whether commands are left unanswered
+func (s *FTPServer) unresponsive() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.silent
+}
+
+func (s *FTPServer) serve() {
+	for {
+		conn, err := s.listener.Accept()
+		if err != nil {
+			return
+		}
+		go s.handle(conn)
+	}
+}
+
+func (s *FTPServer) handle(conn net.Conn) {
+	defer conn.Close()
+
+	reader := bufio.NewReader(conn)
+	reply := func(format string, args ...interface{}) {
+		fmt.Fprintf(conn, format+"\r\n", args...)
+	}
+
+	var dataListener net.Listener
+	defer func() {
+		if dataListener != nil {
+			dataListener.Close()
+		}
+	}()
+
+	// acceptData waits for the client to open the passive data connection
+	acceptData := func() net.Conn {
+		if dataListener == nil {
+			reply("425 Use EPSV or PASV first")
+			return nil
+		}
+		defer func() {
+			dataListener.Close()
+			dataListener = nil
+		}()
+		dataListener.(*net.TCPListener).SetDeadline(time.Now().Add(5 * time.Second))
+		dataConn, err := dataListener.Accept()
+		if err != nil {
+			reply("425 Can't open data connection")
+			return nil
+		}
+		return dataConn
+	}
+
+	reply("220 MELAnet Box ready")
+
+	user := ""
+	loggedIn := false
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			return
+		}
+		if s.unresponsive() {
+			continue
+		}
+		command, arg, _ := strings.Cut(strings.TrimRight(line, "\r\n"), " ")
+		command = strings.ToUpper(command)
+
+		if !loggedIn && command != "USER" && command != "PASS" && command != "QUIT" {
+			reply("530 Not logged in")
+			continue
+		}
+
+		switch command {
+		case "USER":
+			user = arg
+			reply("331 Password required")
+		case "PASS":
+			if user == s.username && arg == s.password {
+				loggedIn = true
+				reply("230 Logged in")
+			} else {
+				reply("530 Login incorrect")
+			}
+		case "FEAT":
+			reply("211-Features:\r\n MLST type*;size*;modify*;\r\n UTF8\r\n211 End")
+		case "TYPE", "OPTS":
+			reply("200 OK")
+		case "NOOP":
+			reply("200 NOOP ok")
+		case "PWD":
+			reply("257 \"/\" is the current directory")
+		case "CWD":
+			reply("250 Directory changed")
+		case "EPSV", "PASV":
+			if dataListener != nil {
+				dataListener.Close()
+			}
+			host, _, _ := net.SplitHostPort(conn.LocalAddr().String())
+			dataListener, err = net.Listen("tcp", net.JoinHostPort(host, "0"))
+			if err != nil {
+				reply("425 Can't open passive listener")
+				continue
+			}
+			port := dataListener.Addr().(*net.TCPAddr).Port
+			if command == "EPSV" {
+				reply("229 Entering Extended Passive Mode (|||%d|)", port)
+			} else {
+				ip := net.ParseIP(host).To4()
+				if ip == nil {
+					ip = net.IPv4(127, 0, 0, 1).To4()
+				}
+				reply("227 Entering Passive Mode (%d,%d,%d,%d,%d,%d)", ip[0], ip[1], ip[2], ip[3], port/256, port%256)
+			}
+		case "MLSD":
+			dir := cleanFTPPath(arg)
+			reply("150 Opening data connection")
+			dataConn := acceptData()
+			if dataConn == nil {
+				continue
+			}
+			for _, line := range s.listDir(dir) {
+				fmt.Fprintf(dataConn, "%s\r\n", line)
+			}
+			dataConn.Close()
+			reply("226 Transfer complete")
+		case "RETR":
+			name := cleanFTPPath(arg)
+			s.mu.Lock()
+			file, ok := s.files[name]
+			if ok {
+				s.retrCount[name]++
+			}
+			s.mu.Unlock()
+			if !ok {
+				reply("550 File not found")
+				continue
+			}
+			reply("150 Opening data connection")
+			dataConn := acceptData()
+			if dataConn == nil {
+				continue
+			}
+			dataConn.Write(file.data)
+			dataConn.Close()
+			reply("226 Transfer complete")
+		case "STOR":
+			name := cleanFTPPath(arg)
+			reply("150 Ready to receive")
+			dataConn := acceptData()
+			if dataConn == nil {
+				continue
+			}
+			data, _ := io.ReadAll(dataConn)
+			dataConn.Close()
+			s.mu.Lock()
+			s.files[name] = ftpFile{data: data, modTime: time.Now().UTC()}
+			onStore := s.onStore
+			s.mu.Unlock()
+			// Run the callback before completing so its effects are visible once STOR returns
+			if onStore != nil {
+				onStore(name, data)
+			}
+			reply("226 Transfer complete")
+		case "DELE":
+			name := cleanFTPPath(arg)
+			s.mu.Lock()
+			_, ok := s.files[name]
+			delete(s.files, name)
+			s.mu.Unlock()
+			if !ok {
+				reply("550 File not found")
+				continue
+			}
+			reply("250 File deleted")
+		case "QUIT":
+			reply("221 Goodbye")
+			return
+		default:
+			reply("502 Command not implemented")
+		}
+	}
+}
+
+// listDir returns MLSD lines for all files and directories directly inside dir
+// Directories are implied by the paths of the stored files.
+func (s *FTPServer) listDir(dir string) []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var lines []string
+	subdirs := make(map[string]time.Time)
+	for name, file := range s.files {
+		parent := path.Dir(name)
+		if parent == dir {
+			lines = append(lines, fmt.Sprintf("type=file;size=%d;modify=%s; %s",
+				len(file.data), file.modTime.Format("20060102150405"), path.Base(name)))
+			continue
+		}
+		// Find the child of dir on the way to the file
+		for parent != "/" && path.Dir(parent) != dir {
+			parent = path.Dir(parent)
+		}
+		if path.Dir(parent) == dir && parent != dir {
+			if file.modTime.After(subdirs[parent]) {
+				subdirs[parent] = file.modTime
+			}
+		}
+	}
+	for subdir, modTime := range subdirs {
+		lines = append(lines, fmt.Sprintf("type=dir;modify=%s; %s",
+			modTime.Format("20060102150405"), path.Base(subdir)))
+	}
+	sort.Strings(lines)
+	return lines
+}
+
+func cleanFTPPath(p string) string {
+	return path.Clean("/" + p)
+}
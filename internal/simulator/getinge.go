@@ -0,0 +1,368 @@
+package simulator
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"net"
+	"strconv"
+	"sync"
+	"time"
+
+	"steri-connect-go/internal/config"
+	"steri-connect-go/internal/logging"
+)
+
+var (
+	ErrWasherBusy     = errors.New("washer is running a program")
+	ErrUnknownProgram = errors.New("unknown washer program")
+)
+
+// Values of the simulated result register
+const (
+	washerResultNone   = 0
+	washerResultPassed = 1
+	washerResultFailed = 2
+)
+
+// GetingeEndpoint simulates a Getinge washer-disinfector with a Modbus TCP status interface
+// It answers "read holding registers" requests with program, phase, temperature
+// (tenths of °C), A0 value, result and error code at the configured addresses.
+// Programs are started at the device (RunCycle), as on the real washer panel.
+// SetReachable(false) takes the endpoint off the network to simulate an outage;
+// a running program carries on.
+type GetingeEndpoint struct {
+	model          string
+	registers      config.GetingeModbusRegisters
+	profiles       []Profile
+	timeScale      float64
+	statusInterval time.Duration
+	logger         *logging.Logger
+
+	mu       sync.Mutex
+	addr     string
+	listener net.Listener
+	conns    map[net.Conn]struct{}
+	values   []uint16 // Holding registers
+	running  bool
+	stopChan chan struct{}
+	wg       sync.WaitGroup
+}
+
+// NewGetingeEndpoint creates a simulated Getinge washer for the given model name
+// timeScale speeds up the device clock like for the simulated autoclave.
+func NewGetingeEndpoint(model string, registers config.GetingeModbusRegisters, profiles []Profile, timeScale float64, statusInterval time.Duration) *GetingeEndpoint {
+	if timeScale <= 0 {
+		timeScale = 1
+	}
+	if statusInterval <= 0 {
+		statusInterval = time.Second
+	}
+
+	size := 0
+	for _, register := range []int{registers.Program, registers.Phase, registers.Temperature, registers.A0Value, registers.Result, registers.ErrorCode} {
+		if register+1 > size {
+			size = register + 1
+		}
+	}
+
+	return &GetingeEndpoint{
+		model:          model,
+		registers:      registers,
+		profiles:       profiles,
+		timeScale:      timeScale,
+		statusInterval: statusInterval,
+		logger:         logging.Get(),
+		conns:          make(map[net.Conn]struct{}),
+		values:         make([]uint16, size),
+		stopChan:       make(chan struct{}),
+	}
+}
+
+// Start listens on addr ("127.0.0.1:0" picks a free port)
+func (e *GetingeEndpoint) Start(addr string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", addr, err)
+	}
+	e.listener = listener
+	e.addr = listener.Addr().String()
+
+	go e.serve(listener)
+	return nil
+}
+
+// Addr returns the host:port of the endpoint, also while it is unreachable
+func (e *GetingeEndpoint) Addr() string {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.addr
+}
+
+// SetReachable brings the endpoint back online or takes it off the network
+func (e *GetingeEndpoint) SetReachable(reachable bool) error {
+	e.mu.Lock()
+	online := e.listener != nil
+	addr := e.addr
+	e.mu.Unlock()
+
+	switch {
+	case reachable && !online:
+		return e.Start(addr)
+	case !reachable && online:
+		return e.closeListener()
+	}
+	return nil
+}
+
+// Close stops the endpoint and aborts a running program
+func (e *GetingeEndpoint) Close() error {
+	e.mu.Lock()
+	select {
+	case <-e.stopChan:
+	default:
+		close(e.stopChan)
+	}
+	e.mu.Unlock()
+
+	err := e.closeListener()
+	e.wg.Wait()
+	return err
+}
+
+// RunCycle starts a program as if an operator pressed start on the washer
+func (e *GetingeEndpoint) RunCycle(program string) error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.running {
+		return ErrWasherBusy
+	}
+	number := 0
+	for i, profile := range e.profiles {
+		if profile.Program == program {
+			number = i + 1
+		}
+	}
+	if number == 0 {
+		return fmt.Errorf("%w: %s", ErrUnknownProgram, program)
+	}
+
+	e.running = true
+	e.wg.Add(1)
+	go e.runCycle(e.profiles[number-1], number)
+	return nil
+}
+
+// Programs returns the names of the washer programs
+func (e *GetingeEndpoint) Programs() []string {
+	programs := make([]string, len(e.profiles))
+	for i, profile := range e.profiles {
+		programs[i] = profile.Program
+	}
+	return programs
+}
+
+// IsRunning returns true while a simulated program is running
+func (e *GetingeEndpoint) IsRunning() bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	return e.running
+}
+
+// closeListener takes the endpoint off the network, dropping open connections
+func (e *GetingeEndpoint) closeListener() error {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if e.listener == nil {
+		return nil
+	}
+	err := e.listener.Close()
+	e.listener = nil
+	for conn := range e.conns {
+		conn.Close()
+	}
+	return err
+}
+
+// runCycle plays a profile, updating the status registers until the program ends
+func (e *GetingeEndpoint) runCycle(profile Profile, number int) {
+	defer e.wg.Done()
+
+	run := &cycleRun{profile: profile, startTime: time.Now()}
+	realStart := time.Now()
+	lastElapsed := time.Duration(0)
+	a0 := 0.0
+
+	e.setRegisters(map[int]uint16{
+		e.registers.Program:   uint16(number),
+		e.registers.A0Value:   0,
+		e.registers.Result:    washerResultNone,
+		e.registers.ErrorCode: 0,
+	})
+
+	ticker := time.NewTicker(e.statusInterval)
+	defer ticker.Stop()
+
+	for {
+		elapsed := time.Duration(float64(time.Since(realStart)) * e.timeScale)
+		sample, finished := run.sampleAt(elapsed)
+		a0 += a0Increment(sample.temperature, elapsed-lastElapsed)
+		lastElapsed = elapsed
+
+		registers := map[int]uint16{
+			e.registers.Phase:       uint16(phaseIndex(profile, sample.phase)),
+			e.registers.Temperature: uint16(int16(math.Round(sample.temperature * 10))),
+			e.registers.A0Value:     uint16(math.Min(math.Round(a0), math.MaxUint16)),
+		}
+
+		if finished {
+			registers[e.registers.Phase] = 0
+			registers[e.registers.Result] = washerResultPassed
+			if sample.failed {
+				registers[e.registers.Result] = washerResultFailed
+				code, _ := strconv.Atoi(profile.ErrorCode)
+				registers[e.registers.ErrorCode] = uint16(code)
+			}
+			e.setRegisters(registers)
+
+			e.mu.Lock()
+			e.running = false
+			e.mu.Unlock()
+
+			e.logger.Info("Simulated washer program finished",
+				"model", e.model,
+				"program", profile.Program,
+				"a0_value", math.Round(a0),
+				"failed", sample.failed)
+			return
+		}
+		e.setRegisters(registers)
+
+		select {
+		case <-e.stopChan:
+			e.mu.Lock()
+			e.running = false
+			e.mu.Unlock()
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// setRegisters updates holding registers by address
+func (e *GetingeEndpoint) setRegisters(values map[int]uint16) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+	for address, value := range values {
+		e.values[address] = value
+	}
+}
+
+// serve accepts Modbus TCP connections
+func (e *GetingeEndpoint) serve(listener net.Listener) {
+	for {
+		conn, err := listener.Accept()
+		if err != nil {
+			return
+		}
+
+		e.mu.Lock()
+		e.conns[conn] = struct{}{}
+		e.mu.Unlock()
+
+		go e.handleConn(conn)
+	}
+}
+
+// handleConn answers Modbus requests until the client disconnects
+func (e *GetingeEndpoint) handleConn(conn net.Conn) {
+	defer func() {
+		e.mu.Lock()
+		delete(e.conns, conn)
+		e.mu.Unlock()
+		conn.Close()
+	}()
+
+	header := make([]byte, 7)
+	for {
+		if _, err := io.ReadFull(conn, header); err != nil {
+			return
+		}
+		length := binary.BigEndian.Uint16(header[4:])
+		if length < 2 || length > 256 {
+			return
+		}
+		pdu := make([]byte, length-1)
+		if _, err := io.ReadFull(conn, pdu); err != nil {
+			return
+		}
+
+		response := e.handlePDU(pdu)
+		reply := make([]byte, 7+len(response))
+		copy(reply, header[:4]) // Transaction and protocol identifier
+		binary.BigEndian.PutUint16(reply[4:], uint16(len(response)+1))
+		reply[6] = header[6] // Unit identifier
+		copy(reply[7:], response)
+		if _, err := conn.Write(reply); err != nil {
+			return
+		}
+	}
+}
+
+// handlePDU answers one request PDU; only "read holding registers" is supported
+func (e *GetingeEndpoint) handlePDU(pdu []byte) []byte {
+	functionCode := pdu[0]
+	if functionCode != 0x03 {
+		return []byte{functionCode | 0x80, 0x01} // Illegal function
+	}
+	if len(pdu) != 5 {
+		return []byte{functionCode | 0x80, 0x03} // Illegal data value
+	}
+
+	address := int(binary.BigEndian.Uint16(pdu[1:]))
+	quantity := int(binary.BigEndian.Uint16(pdu[3:]))
+	if quantity < 1 || quantity > 125 {
+		return []byte{functionCode | 0x80, 0x03}
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if address+quantity > len(e.values) {
+		return []byte{functionCode | 0x80, 0x02} // Illegal data address
+	}
+
+	response := make([]byte, 2+2*quantity)
+	response[0] = functionCode
+	response[1] = byte(2 * quantity)
+	for i := 0; i < quantity; i++ {
+		binary.BigEndian.PutUint16(response[2+2*i:], e.values[address+i])
+	}
+	return response
+}
+
+// phaseIndex returns the 1-based index of a phase in the profile
+func phaseIndex(profile Profile, name string) int {
+	for i, phase := range profile.Phases {
+		if phase.Name == name {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+// a0Increment returns the A0 contribution of holding temperature for d
+// (EN ISO 15883: z = 10 K, reference 80 °C, counted from 65 °C)
+func a0Increment(temperature float64, d time.Duration) float64 {
+	if temperature < 65 || d <= 0 {
+		return 0
+	}
+	return math.Pow(10, (temperature-80)/10) * d.Seconds()
+}
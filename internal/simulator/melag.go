@@ -0,0 +1,322 @@
+package simulator
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+	"time"
+
+	"steri-connect-go/internal/logging"
+)
+
+// Default MELAnet Box credentials, matching the Melag adapter defaults
+const (
+	MelagUsername = "melanet"
+	MelagPassword = "melanet"
+)
+
+// firmwareVersion is reported in the "Software" line of every protocol
+const firmwareVersion = "5.20 (Simulator)"
+
+// MelagBox simulates a Cliniclave autoclave behind a MELAnet Box
+// Start requests (*.req) uploaded by the Melag adapter are acknowledged with
+// an *.ack file; accepted requests run the matching profile, rewriting a
+// status file (*.sta) while running and writing a protocol file (*.pro) at
+// the end of the cycle.
+type MelagBox struct {
+	server         *FTPServer
+	serial         string
+	profiles       []Profile
+	timeScale      float64
+	statusInterval time.Duration
+	logger         *logging.Logger
+
+	mu          sync.Mutex
+	running     bool
+	doorOpen    bool
+	cycleNumber int
+	stopChan    chan struct{}
+	wg          sync.WaitGroup
+}
+
+// NewMelagBox creates a simulated MELAnet Box
+// timeScale speeds up the device clock (30 runs a 30 minute cycle in one minute).
+func NewMelagBox(serial string, profiles []Profile, timeScale float64, statusInterval time.Duration) *MelagBox {
+	if timeScale <= 0 {
+		timeScale = 1
+	}
+	if statusInterval <= 0 {
+		statusInterval = time.Second
+	}
+
+	box := &MelagBox{
+		server:         NewFTPServer(MelagUsername, MelagPassword),
+		serial:         serial,
+		profiles:       profiles,
+		timeScale:      timeScale,
+		statusInterval: statusInterval,
+		logger:         logging.Get(),
+		stopChan:       make(chan struct{}),
+	}
+	box.server.OnStore(box.handleUpload)
+	return box
+}
+
+// Start starts the FTP endpoint on addr
+func (b *MelagBox) Start(addr string) error {
+	return b.server.Start(addr)
+}
+
+// Close stops the FTP endpoint and aborts a running cycle
+func (b *MelagBox) Close() error {
+	b.mu.Lock()
+	select {
+	case <-b.stopChan:
+	default:
+		close(b.stopChan)
+	}
+	b.mu.Unlock()
+
+	err := b.server.Close()
+	b.wg.Wait()
+	return err
+}
+
+// Addr returns the host:port of the FTP endpoint
+func (b *MelagBox) Addr() string {
+	return b.server.Addr()
+}
+
+// Server returns the underlying FTP server, e.g. to place protocol files
+func (b *MelagBox) Server() *FTPServer {
+	return b.server
+}
+
+// SetDoorOpen opens or closes the simulated autoclave door
+func (b *MelagBox) SetDoorOpen(open bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.doorOpen = open
+}
+
+// IsRunning returns true while a simulated cycle is running
+func (b *MelagBox) IsRunning() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.running
+}
+
+func (b *MelagBox) setRunning(running bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.running = running
+}
+
+// handleUpload answers start requests uploaded by the adapter
+func (b *MelagBox) handleUpload(name string, data []byte) {
+	if !strings.EqualFold(path.Ext(name), ".req") {
+		return
+	}
+
+	fields := parseKeyValues(data)
+	program := fields["programm"]
+	ackName := strings.TrimSuffix(name, path.Ext(name)) + ".ack"
+	b.server.DeleteFile(name)
+
+	b.mu.Lock()
+	profile, known := findProfile(b.profiles, program)
+	var ack string
+	switch {
+	case !strings.EqualFold(fields["befehl"], "START"):
+		ack = "Status: ABGELEHNT\r\nFehler: 10 Unbekannter Befehl\r\n"
+	case b.running:
+		ack = "Status: BELEGT\r\n"
+	case b.doorOpen:
+		ack = "Status: TUER_OFFEN\r\n"
+	case !known:
+		ack = "Status: ABGELEHNT\r\nFehler: 12 Programm unbekannt\r\n"
+	default:
+		ack = "Status: OK\r\n"
+		b.running = true
+		b.cycleNumber++
+		b.wg.Add(1)
+		go b.runCycle(profile, b.cycleNumber)
+	}
+	b.mu.Unlock()
+
+	b.logger.Info("Simulated MELAnet Box received start request",
+		"job", fields["auftrag"],
+		"program", program,
+		"ack", strings.TrimSpace(strings.SplitN(ack, "\r\n", 2)[0]))
+
+	b.server.PutFile(ackName, ack, time.Now())
+}
+
+// runCycle plays a profile, writing status files until the cycle ends
+func (b *MelagBox) runCycle(profile Profile, cycleNumber int) {
+	defer b.wg.Done()
+
+	dir := "/"
+	statusName := path.Join(dir, fmt.Sprintf("%04d.sta", cycleNumber))
+	protocolName := path.Join(dir, fmt.Sprintf("%04d.pro", cycleNumber))
+
+	run := &cycleRun{
+		profile:     profile,
+		serial:      b.serial,
+		cycleNumber: cycleNumber,
+		startTime:   time.Now(),
+	}
+	realStart := time.Now()
+
+	ticker := time.NewTicker(b.statusInterval)
+	defer ticker.Stop()
+
+	for {
+		elapsed := time.Duration(float64(time.Since(realStart)) * b.timeScale)
+		sample, finished := run.sampleAt(elapsed)
+
+		if finished {
+			// The autoclave is free again before the protocol becomes visible
+			b.setRunning(false)
+			b.server.DeleteFile(statusName)
+			b.server.PutFile(protocolName, run.renderProtocol(sample, elapsed), time.Now())
+			b.logger.Info("Simulated cycle finished",
+				"cycle_number", cycleNumber,
+				"program", profile.Program,
+				"failed", sample.failed)
+			return
+		}
+		b.server.PutFile(statusName, run.renderStatus(sample), time.Now())
+
+		select {
+		case <-b.stopChan:
+			b.setRunning(false)
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// cycleRun holds the state of one simulated cycle
+type cycleRun struct {
+	profile     Profile
+	serial      string
+	cycleNumber int
+	startTime   time.Time
+}
+
+// cycleSample is the simulated device state at one point in time
+type cycleSample struct {
+	phase       string
+	temperature float64
+	pressure    float64
+	remaining   time.Duration
+	progress    int
+	failed      bool
+}
+
+// sampleAt computes the device state after elapsed device time
+func (r *cycleRun) sampleAt(elapsed time.Duration) (cycleSample, bool) {
+	total := r.profile.Duration()
+	var phaseStart time.Duration
+
+	for _, phase := range r.profile.Phases {
+		phaseEnd := phaseStart + phase.Duration
+		failing := phase.Name == r.profile.FailAtPhase
+		if failing && elapsed >= phaseStart+phase.Duration/2 {
+			sample := interpolate(phase, 0.5)
+			sample.failed = true
+			return sample, true
+		}
+		if elapsed < phaseEnd {
+			sample := interpolate(phase, float64(elapsed-phaseStart)/float64(phase.Duration))
+			sample.remaining = (total - elapsed).Truncate(time.Second)
+			sample.progress = int(100 * elapsed / total)
+			return sample, false
+		}
+		phaseStart = phaseEnd
+	}
+
+	last := r.profile.Phases[len(r.profile.Phases)-1]
+	sample := interpolate(last, 1)
+	sample.progress = 100
+	return sample, true
+}
+
+// renderStatus renders the status file written while the cycle runs
+func (r *cycleRun) renderStatus(sample cycleSample) string {
+	var b strings.Builder
+	r.renderHeader(&b)
+	fmt.Fprintf(&b, "Zeit: %s\r\n", time.Now().Format("15:04:05"))
+	fmt.Fprintf(&b, "Phase: %s\r\n", sample.phase)
+	fmt.Fprintf(&b, "Temperatur: %.1f °C\r\n", sample.temperature)
+	fmt.Fprintf(&b, "Druck: %.2f bar\r\n", sample.pressure)
+	fmt.Fprintf(&b, "Restzeit: %s\r\n", formatClock(sample.remaining))
+	fmt.Fprintf(&b, "Fortschritt: %d %%\r\n", sample.progress)
+	return b.String()
+}
+
+// renderProtocol renders the protocol file written at the end of the cycle
+func (r *cycleRun) renderProtocol(sample cycleSample, elapsed time.Duration) string {
+	var b strings.Builder
+	r.renderHeader(&b)
+	fmt.Fprintf(&b, "Endzeit: %s\r\n", r.startTime.Add(elapsed).Format("15:04:05"))
+	fmt.Fprintf(&b, "Phase: %s\r\n", sample.phase)
+	fmt.Fprintf(&b, "Temperatur: %.1f °C\r\n", sample.temperature)
+	fmt.Fprintf(&b, "Druck: %.2f bar\r\n", sample.pressure)
+	if sample.failed {
+		fmt.Fprintf(&b, "Ergebnis: Programm abgebrochen\r\n")
+		fmt.Fprintf(&b, "Fehler: %s %s\r\n", r.profile.ErrorCode, r.profile.ErrorDescription)
+	} else {
+		fmt.Fprintf(&b, "Ergebnis: Programm erfolgreich beendet\r\n")
+	}
+	return b.String()
+}
+
+func (r *cycleRun) renderHeader(b *strings.Builder) {
+	fmt.Fprintf(b, "MELAG Cliniclave 45 (Simulator)\r\n")
+	fmt.Fprintf(b, "Seriennummer: %s\r\n", r.serial)
+	fmt.Fprintf(b, "Software: %s\r\n", firmwareVersion)
+	fmt.Fprintf(b, "Charge: %04d\r\n", r.cycleNumber)
+	fmt.Fprintf(b, "Programm: %s\r\n", r.profile.Program)
+	fmt.Fprintf(b, "Datum: %s\r\n", r.startTime.Format("02.01.2006"))
+	fmt.Fprintf(b, "Startzeit: %s\r\n", r.startTime.Format("15:04:05"))
+}
+
+// interpolate returns the phase values at fraction (0..1) of the phase
+func interpolate(phase Phase, fraction float64) cycleSample {
+	if fraction < 0 {
+		fraction = 0
+	}
+	if fraction > 1 {
+		fraction = 1
+	}
+	return cycleSample{
+		phase:       phase.Name,
+		temperature: phase.StartTemperature + (phase.EndTemperature-phase.StartTemperature)*fraction,
+		pressure:    phase.StartPressure + (phase.EndPressure-phase.StartPressure)*fraction,
+	}
+}
+
+// formatClock formats a duration as hh:mm:ss
+func formatClock(d time.Duration) string {
+	d = d.Round(time.Second)
+	return fmt.Sprintf("%02d:%02d:%02d", int(d.Hours()), int(d.Minutes())%60, int(d.Seconds())%60)
+}
+
+// parseKeyValues parses "Key: Value" lines into a map with lower-case keys
+func parseKeyValues(data []byte) map[string]string {
+	fields := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		key, value, ok := strings.Cut(scanner.Text(), ":")
+		if !ok {
+			continue
+		}
+		fields[strings.ToLower(strings.TrimSpace(key))] = strings.TrimSpace(value)
+	}
+	return fields
+}
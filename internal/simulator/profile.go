@@ -0,0 +1,113 @@
+package simulator
+
+import (
+	"strings"
+	"time"
+)
+
+// Phase is one step of a simulated cycle; temperature and pressure change
+// linearly from the start to the end value over the phase duration
+type Phase struct {
+	Name             string
+	Duration         time.Duration // Device time, scaled by the simulator time scale
+	StartTemperature float64
+	EndTemperature   float64
+	StartPressure    float64
+	EndPressure      float64
+}
+
+// Profile is a scripted cycle run by the simulated autoclave
+type Profile struct {
+	Program string
+	Phases  []Phase
+
+	// FailAtPhase aborts the cycle halfway through the named phase
+	FailAtPhase      string
+	ErrorCode        string
+	ErrorDescription string
+}
+
+// Duration returns the total device time of the profile
+func (p Profile) Duration() time.Duration {
+	var total time.Duration
+	for _, phase := range p.Phases {
+		total += phase.Duration
+	}
+	return total
+}
+
+// universalPhases models a Cliniclave 45 universal program: heat-up with
+// fractionated pre-vacuum, sterilisation hold at 134 °C and vacuum drying
+var universalPhases = []Phase{
+	{Name: "Aufheizen", Duration: 8 * time.Minute, StartTemperature: 22, EndTemperature: 134, StartPressure: 1.0, EndPressure: 3.04},
+	{Name: "Sterilisation", Duration: 5*time.Minute + 30*time.Second, StartTemperature: 134, EndTemperature: 134.5, StartPressure: 3.04, EndPressure: 3.06},
+	{Name: "Druckentlastung", Duration: 2 * time.Minute, StartTemperature: 134, EndTemperature: 105, StartPressure: 3.06, EndPressure: 1.0},
+	{Name: "Trocknung", Duration: 10 * time.Minute, StartTemperature: 105, EndTemperature: 80, StartPressure: 1.0, EndPressure: 0.1},
+}
+
+// quickPhases models the quick program B for unwrapped instruments
+var quickPhases = []Phase{
+	{Name: "Aufheizen", Duration: 5 * time.Minute, StartTemperature: 22, EndTemperature: 134, StartPressure: 1.0, EndPressure: 3.04},
+	{Name: "Sterilisation", Duration: 3*time.Minute + 30*time.Second, StartTemperature: 134, EndTemperature: 134.5, StartPressure: 3.04, EndPressure: 3.06},
+	{Name: "Druckentlastung", Duration: 1 * time.Minute, StartTemperature: 134, EndTemperature: 105, StartPressure: 3.06, EndPressure: 1.0},
+	{Name: "Trocknung", Duration: 3 * time.Minute, StartTemperature: 105, EndTemperature: 90, StartPressure: 1.0, EndPressure: 0.2},
+}
+
+// DefaultProfiles returns the built-in cycle profiles keyed by program name
+func DefaultProfiles() []Profile {
+	return []Profile{
+		{Program: "Universal-Programm", Phases: universalPhases},
+		{Program: "Schnell-Programm B", Phases: quickPhases},
+		{
+			Program:          "Test Fehler Trocknung",
+			Phases:           universalPhases,
+			FailAtPhase:      "Trocknung",
+			ErrorCode:        "27",
+			ErrorDescription: "Trockenzeit überschritten",
+		},
+		{
+			Program:          "Test Fehler Aufheizen",
+			Phases:           quickPhases,
+			FailAtPhase:      "Aufheizen",
+			ErrorCode:        "11",
+			ErrorDescription: "Aufheizzeit überschritten",
+		},
+	}
+}
+
+// findProfile looks up a profile by program name (case-insensitive)
+func findProfile(profiles []Profile, program string) (Profile, bool) {
+	for _, profile := range profiles {
+		if strings.EqualFold(profile.Program, program) {
+			return profile, true
+		}
+	}
+	return Profile{}, false
+}
+
+// washerPhases models an Aquadis instrument program; the phase register of the
+// simulated washer holds the 1-based index into this list, matching the default
+// devices.getinge.modbus.phases map. Pressure is not used by the washer.
+var washerPhases = []Phase{
+	{Name: "Vorspülen", Duration: 3 * time.Minute, StartTemperature: 20, EndTemperature: 30},
+	{Name: "Reinigen", Duration: 8 * time.Minute, StartTemperature: 30, EndTemperature: 55},
+	{Name: "Neutralisieren", Duration: 3 * time.Minute, StartTemperature: 55, EndTemperature: 45},
+	{Name: "Zwischenspülen", Duration: 2 * time.Minute, StartTemperature: 45, EndTemperature: 50},
+	{Name: "Thermische Desinfektion", Duration: 5 * time.Minute, StartTemperature: 90, EndTemperature: 93},
+	{Name: "Trocknen", Duration: 10 * time.Minute, StartTemperature: 93, EndTemperature: 60},
+}
+
+// DefaultWasherProfiles returns the built-in programs of the simulated washer-disinfector
+// The program register holds the 1-based index into this list.
+func DefaultWasherProfiles() []Profile {
+	return []Profile{
+		{Program: "Instrumente 93 °C", Phases: washerPhases},
+		{
+			Program:          "Test Fehler Desinfektion",
+			Phases:           washerPhases,
+			FailAtPhase:      "Thermische Desinfektion",
+			ErrorCode:        "42",
+			ErrorDescription: "Desinfektionstemperatur nicht erreicht",
+		},
+	}
+}
@@ -0,0 +1,168 @@
+// Package simulator provides simulated Melag and Getinge devices so the whole
+// stack can be exercised without real hardware. It is enabled with the
+// --simulate flag of cmd/server.
+package simulator
+
+import (
+	"fmt"
+	"time"
+
+	"steri-connect-go/internal/config"
+	"steri-connect-go/internal/database"
+	"steri-connect-go/internal/logging"
+)
+
+// Simulator runs one simulated MELAnet Box and one simulated Getinge endpoint
+type Simulator struct {
+	cfg     config.SimulatorConfig
+	melag   *MelagBox
+	getinge *GetingeEndpoint
+	logger  *logging.Logger
+
+	stopChan chan struct{}
+}
+
+// New creates a simulator from configuration
+func New(cfg config.SimulatorConfig) *Simulator {
+	statusInterval := time.Duration(cfg.StatusInterval) * time.Second
+
+	washer := NewGetingeEndpoint("Aquadis 56", config.Get().Devices.Getinge.Modbus.Registers,
+		DefaultWasherProfiles(), cfg.TimeScale, statusInterval)
+
+	return &Simulator{
+		cfg:      cfg,
+		melag:    NewMelagBox("SIM-CC45-0001", DefaultProfiles(), cfg.TimeScale, statusInterval),
+		getinge:  washer,
+		logger:   logging.Get(),
+		stopChan: make(chan struct{}),
+	}
+}
+
+// Start starts all simulated endpoints
+func (s *Simulator) Start() error {
+	if err := s.melag.Start(s.cfg.MelagAddress); err != nil {
+		return fmt.Errorf("failed to start simulated MELAnet Box: %w", err)
+	}
+	if err := s.getinge.Start(s.cfg.GetingeAddress); err != nil {
+		s.melag.Close()
+		return fmt.Errorf("failed to start simulated Getinge endpoint: %w", err)
+	}
+
+	if s.cfg.WasherInterval > 0 {
+		go s.runWasherPrograms(time.Duration(s.cfg.WasherInterval) * time.Second)
+	}
+
+	s.logger.Info("Device simulator started",
+		"melag_address", s.melag.Addr(),
+		"getinge_address", s.getinge.Addr(),
+		"time_scale", s.cfg.TimeScale)
+
+	return nil
+}
+
+// runWasherPrograms starts the washer programs in turn, each interval after the previous
+// one finished (the washer is unloaded and loaded in between)
+func (s *Simulator) runWasherPrograms(interval time.Duration) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	programs := s.getinge.Programs()
+	idleSince := time.Now()
+	for next := 0; ; {
+		select {
+		case <-s.stopChan:
+			return
+		case <-ticker.C:
+		}
+
+		if s.getinge.IsRunning() {
+			idleSince = time.Now()
+			continue
+		}
+		if time.Since(idleSince) < interval {
+			continue
+		}
+
+		if err := s.getinge.RunCycle(programs[next%len(programs)]); err != nil {
+			s.logger.Warn("Simulated washer did not start a program", "error", err)
+		}
+		next++
+	}
+}
+
+// Stop stops all simulated endpoints
+func (s *Simulator) Stop() {
+	close(s.stopChan)
+	if err := s.melag.Close(); err != nil {
+		s.logger.Warn("Error stopping simulated MELAnet Box", "error", err)
+	}
+	if err := s.getinge.Close(); err != nil {
+		s.logger.Warn("Error stopping simulated Getinge endpoint", "error", err)
+	}
+	s.logger.Info("Device simulator stopped")
+}
+
+// Melag returns the simulated MELAnet Box
+func (s *Simulator) Melag() *MelagBox {
+	return s.melag
+}
+
+// Getinge returns the simulated Getinge endpoint
+func (s *Simulator) Getinge() *GetingeEndpoint {
+	return s.getinge
+}
+
+// Endpoint returns the simulated endpoint for a device (usable as devices.EndpointResolver)
+func (s *Simulator) Endpoint(device *database.Device) string {
+	switch device.Manufacturer {
+	case "Melag":
+		return s.melag.Addr()
+	case "Getinge":
+		return s.getinge.Addr()
+	}
+	return ""
+}
+
+// SeedDevices creates one simulated device per manufacturer if the database has no devices yet
+func (s *Simulator) SeedDevices() error {
+	existing, err := database.GetAllDevices()
+	if err != nil {
+		return fmt.Errorf("failed to check existing devices: %w", err)
+	}
+	if len(existing) > 0 {
+		return nil
+	}
+
+	seed := []database.Device{
+		{
+			Name:         "Simulated Cliniclave 45",
+			Model:        "Cliniclave 45",
+			Manufacturer: "Melag",
+			IP:           "127.0.0.1",
+			Serial:       "SIM-CC45-0001",
+			Type:         "Steri",
+			Location:     "Simulator",
+		},
+		{
+			Name:         "Simulated Aquadis 56",
+			Model:        "Aquadis 56",
+			Manufacturer: "Getinge",
+			IP:           "127.0.0.1",
+			Serial:       "SIM-AQ56-0001",
+			Type:         "RDG",
+			Location:     "Simulator",
+		},
+	}
+
+	for i := range seed {
+		created, err := database.CreateDevice(&seed[i])
+		if err != nil {
+			return fmt.Errorf("failed to create simulated device %s: %w", seed[i].Name, err)
+		}
+		s.logger.Info("Simulated device created",
+			"device_id", created.ID,
+			"device_name", created.Name)
+	}
+
+	return nil
+}
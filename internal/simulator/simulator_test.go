@@ -0,0 +1,221 @@
+package simulator_test
+
+import (
+	"errors"
+	"os"
+	"strings"
+	"testing"
+	"time"
+
+	"steri-connect-go/internal/adapters"
+	"steri-connect-go/internal/adapters/getinge"
+	"steri-connect-go/internal/adapters/melag"
+	"steri-connect-go/internal/config"
+	"steri-connect-go/internal/database"
+	"steri-connect-go/internal/logging"
+	"steri-connect-go/internal/simulator"
+)
+
+func TestMain(m *testing.M) {
+	if err := logging.Init(logging.Config{Level: "ERROR", Format: "text", Output: "stdout"}); err != nil {
+		panic(err)
+	}
+	os.Exit(m.Run())
+}
+
+func TestMelagBoxRunsProfiles(t *testing.T) {
+	// 12.5 minute quick program runs in well under a second
+	box := simulator.NewMelagBox("SIM-TEST", simulator.DefaultProfiles(), 2000, 20*time.Millisecond)
+	if err := box.Start("127.0.0.1:0"); err != nil {
+		t.Fatalf("Failed to start simulated MELAnet Box: %v", err)
+	}
+	t.Cleanup(func() { box.Close() })
+
+	adapter, err := melag.NewMelagAdapter(&database.Device{ID: 1, Name: "Sim", Manufacturer: "Melag", IP: "127.0.0.1"})
+	if err != nil {
+		t.Fatalf("Failed to create adapter: %v", err)
+	}
+	adapter.SetEndpoint(box.Addr())
+	if err := adapter.Connect(); err != nil {
+		t.Fatalf("Failed to connect to simulator: %v", err)
+	}
+	t.Cleanup(func() { adapter.Disconnect() })
+
+	box.SetDoorOpen(true)
+	if err := adapter.StartCycle(adapters.CycleStartParams{Program: "Schnell-Programm B"}); !errors.Is(err, melag.ErrDoorOpen) {
+		t.Fatalf("Expected ErrDoorOpen, got %v", err)
+	}
+	box.SetDoorOpen(false)
+
+	if err := adapter.StartCycle(adapters.CycleStartParams{Program: "Unbekannt"}); !errors.Is(err, melag.ErrProgramRejected) {
+		t.Fatalf("Expected ErrProgramRejected, got %v", err)
+	}
+
+	if err := adapter.StartCycle(adapters.CycleStartParams{Program: "Schnell-Programm B"}); err != nil {
+		t.Fatalf("Expected start to be accepted, got %v", err)
+	}
+	if status := waitForCycleEnd(t, adapter); status.Phase != "COMPLETED" || status.Result != "OK" {
+		t.Errorf("Expected completed cycle, got %+v", status)
+	}
+
+	if err := adapter.StartCycle(adapters.CycleStartParams{Program: "Test Fehler Aufheizen"}); err != nil {
+		t.Fatalf("Expected start to be accepted, got %v", err)
+	}
+	if status := waitForCycleEnd(t, adapter); status.Phase != "FAILED" || status.ErrorCode != "11" {
+		t.Errorf("Expected failed cycle with error 11, got %+v", status)
+	}
+
+	info, err := adapter.GetDeviceInfo()
+	if err != nil || info.SerialNumber != "SIM-TEST" || info.Firmware == "" || !strings.HasPrefix(info.Model, "Cliniclave") {
+		t.Errorf("Unexpected device info %+v, %v", info, err)
+	}
+}
+
+func TestGetingeEndpointReachability(t *testing.T) {
+	endpoint := simulator.NewGetingeEndpoint("Aquadis 56", config.Get().Devices.Getinge.Modbus.Registers,
+		simulator.DefaultWasherProfiles(), 1, time.Second)
+	if err := endpoint.Start("127.0.0.1:0"); err != nil {
+		t.Fatalf("Failed to start simulated Getinge endpoint: %v", err)
+	}
+	t.Cleanup(func() { endpoint.Close() })
+
+	adapter, err := getinge.NewGetingeAdapter(&database.Device{ID: 2, Name: "Sim", Manufacturer: "Getinge", IP: "127.0.0.1"}, time.Second)
+	if err != nil {
+		t.Fatalf("Failed to create adapter: %v", err)
+	}
+	adapter.SetProbeAddress(endpoint.Addr())
+
+	// Getinge devices only report reachability
+	if adapters.Supports(adapter, adapters.CapabilityCycleStart) || !adapters.Supports(adapter, adapters.CapabilityReachability) {
+		t.Errorf("Unexpected capabilities for Getinge adapter")
+	}
+
+	if reachable, err := adapter.Ping(); !reachable || err != nil {
+		t.Errorf("Expected simulated device to be reachable, got %v, %v", reachable, err)
+	}
+
+	if err := endpoint.SetReachable(false); err != nil {
+		t.Fatalf("Failed to take endpoint offline: %v", err)
+	}
+	if reachable, _ := adapter.Ping(); reachable {
+		t.Errorf("Expected simulated device to be unreachable")
+	}
+
+	if err := endpoint.SetReachable(true); err != nil {
+		t.Fatalf("Failed to bring endpoint online: %v", err)
+	}
+	if reachable, err := adapter.Ping(); !reachable || err != nil {
+		t.Errorf("Expected simulated device to be reachable again, got %v, %v", reachable, err)
+	}
+}
+
+func TestGetingeWasherOverModbus(t *testing.T) {
+	// 31 minute washer program runs in well under a second
+	modbus := config.Get().Devices.Getinge.Modbus
+	endpoint := simulator.NewGetingeEndpoint("Aquadis 56", modbus.Registers, simulator.DefaultWasherProfiles(), 4000, 10*time.Millisecond)
+	if err := endpoint.Start("127.0.0.1:0"); err != nil {
+		t.Fatalf("Failed to start simulated Getinge endpoint: %v", err)
+	}
+	t.Cleanup(func() { endpoint.Close() })
+
+	adapter, err := getinge.NewGetingeModbusAdapter(&database.Device{ID: 2, Name: "Sim", Manufacturer: "Getinge", IP: "127.0.0.1"}, time.Second,
+		getinge.ModbusSettings{
+			Address: endpoint.Addr(),
+			UnitID:  1,
+			Timeout: time.Second,
+			Registers: getinge.RegisterMap{
+				Program:     uint16(modbus.Registers.Program),
+				Phase:       uint16(modbus.Registers.Phase),
+				Temperature: uint16(modbus.Registers.Temperature),
+				A0Value:     uint16(modbus.Registers.A0Value),
+				Result:      uint16(modbus.Registers.Result),
+				ErrorCode:   uint16(modbus.Registers.ErrorCode),
+			},
+			Phases:   modbus.Phases,
+			Programs: map[int]string{1: "Instrumente 93 °C"},
+		})
+	if err != nil {
+		t.Fatalf("Failed to create adapter: %v", err)
+	}
+	t.Cleanup(func() { adapter.Disconnect() })
+
+	// Washer cycles are observed, not started remotely
+	if adapters.Supports(adapter, adapters.CapabilityCycleStart) || !adapters.Supports(adapter, adapters.CapabilityCycleStatus) {
+		t.Errorf("Unexpected capabilities for Getinge Modbus adapter")
+	}
+
+	if status, err := adapter.GetCycleStatus(); err != nil || status.IsRunning || status.Phase != "IDLE" {
+		t.Fatalf("Expected idle washer, got %+v, %v", status, err)
+	}
+
+	if err := endpoint.RunCycle("Instrumente 93 °C"); err != nil {
+		t.Fatalf("Failed to start washer program: %v", err)
+	}
+	if err := endpoint.RunCycle("Instrumente 93 °C"); !errors.Is(err, simulator.ErrWasherBusy) {
+		t.Errorf("Expected ErrWasherBusy, got %v", err)
+	}
+
+	status := waitForWasher(t, endpoint, adapter)
+	if status.Phase != "COMPLETED" || status.Result != "OK" || status.Program != "Instrumente 93 °C" {
+		t.Errorf("Expected completed cycle, got %+v", status)
+	}
+	if status.A0Value == nil || *status.A0Value < 3000 {
+		t.Errorf("Expected A0 value of at least 3000, got %v", status.A0Value)
+	}
+
+	if err := endpoint.RunCycle("Test Fehler Desinfektion"); err != nil {
+		t.Fatalf("Failed to start washer program: %v", err)
+	}
+	status = waitForWasher(t, endpoint, adapter)
+	if status.Phase != "FAILED" || status.ErrorCode != "42" || status.Program != "Programm 2" {
+		t.Errorf("Expected failed cycle with error 42, got %+v", status)
+	}
+
+	// The client reconnects after an outage
+	endpoint.SetReachable(false)
+	if _, err := adapter.GetCycleStatus(); err == nil {
+		t.Errorf("Expected read to fail while the washer is offline")
+	}
+	endpoint.SetReachable(true)
+	if _, err := adapter.GetCycleStatus(); err != nil {
+		t.Errorf("Expected read to succeed after reconnect, got %v", err)
+	}
+}
+
+// waitForWasher waits until the washer program has finished and returns the final status
+func waitForWasher(t *testing.T, endpoint *simulator.GetingeEndpoint, reader adapters.CycleStatusReader) adapters.CycleStatus {
+	t.Helper()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for endpoint.IsRunning() {
+		if time.Now().After(deadline) {
+			t.Fatalf("Washer program did not finish in time")
+		}
+		time.Sleep(20 * time.Millisecond)
+	}
+
+	status, err := reader.GetCycleStatus()
+	if err != nil {
+		t.Fatalf("GetCycleStatus failed: %v", err)
+	}
+	return status
+}
+
+// waitForCycleEnd polls the adapter until the running cycle has finished
+func waitForCycleEnd(t *testing.T, adapter *melag.MelagAdapter) adapters.CycleStatus {
+	t.Helper()
+
+	deadline := time.Now().Add(10 * time.Second)
+	for time.Now().Before(deadline) {
+		status, err := adapter.GetCycleStatus()
+		if err != nil {
+			t.Fatalf("GetCycleStatus failed: %v", err)
+		}
+		if !status.IsRunning {
+			return status
+		}
+		time.Sleep(50 * time.Millisecond)
+	}
+	t.Fatalf("Cycle did not finish in time")
+	return adapters.CycleStatus{}
+}
@@ -0,0 +1,26 @@
+package validation
+
+import "math"
+
+// atmosphericPressure is added to gauge pressures (bar)
+const atmosphericPressure = 1.01325
+
+// Coefficients of the saturation line (IAPWS-IF97, region 4)
+var saturationCoefficients = [10]float64{
+	0.11670521452767e4, -0.72421316703206e6, -0.17073846940092e2, 0.12020824702470e5,
+	-0.32325550322333e7, 0.14915108613530e2, -0.48232657361591e4, 0.40511340542057e6,
+	-0.23855557567849, 0.65017534844798e3,
+}
+
+// SaturationTemperature returns the temperature (°C) of saturated steam at an absolute
+// pressure in bar (IAPWS-IF97 backward equation, valid from 0.006 to 220 bar)
+func SaturationTemperature(pressure float64) float64 {
+	n := saturationCoefficients
+	beta := math.Pow(pressure/10, 0.25) // bar -> MPa
+	e := beta*beta + n[2]*beta + n[5]
+	f := n[0]*beta*beta + n[3]*beta + n[6]
+	g := n[1]*beta*beta + n[4]*beta + n[7]
+	d := 2 * g / (-f - math.Sqrt(f*f-4*e*g))
+	kelvin := (n[9] + d - math.Sqrt((n[9]+d)*(n[9]+d)-4*(n[8]+n[9]*d))) / 2
+	return kelvin - 273.15
+}
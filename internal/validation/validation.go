@@ -0,0 +1,388 @@
+// Package validation checks the recorded process values of steam sterilisation cycles
+// against program profiles derived from EN ISO 17665 and EN 13060: sterilisation
+// temperature band, hold time, pressure band, saturated steam and equilibration time.
+// The verdict is independent of the result reported by the device.
+package validation
+
+import (
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"steri-connect-go/internal/config"
+	"steri-connect-go/internal/database"
+)
+
+// Rules reported in the validation reasons
+const (
+	RuleTemperatureReached = "temperature_reached"
+	RuleHoldTime           = "hold_time"
+	RuleTemperatureBand    = "temperature_band"
+	RulePressureBand       = "pressure_band"
+	RuleSaturatedSteam     = "saturated_steam"
+	RuleEquilibrationTime  = "equilibration_time"
+	RuleProfile            = "profile"
+)
+
+// Profile holds the sterilisation parameters a program must reach
+type Profile struct {
+	Name                     string
+	SterilisationTemperature float64 // °C
+	TemperatureBand          float64 // K
+	HoldTime                 time.Duration
+	PressureMin              float64 // bar, 0 = no lower limit
+	PressureMax              float64 // bar, 0 = no upper limit
+	GaugePressure            bool
+	SaturationTolerance      float64       // K, 0 = not checked
+	MaxEquilibrationTime     time.Duration // 0 = not checked
+	MaxSampleGap             time.Duration
+}
+
+// NewProfile creates a profile from its configuration
+func NewProfile(cfg config.ValidationProfile) Profile {
+	return Profile{
+		Name:                     cfg.Name,
+		SterilisationTemperature: cfg.SterilisationTemperature,
+		TemperatureBand:          cfg.TemperatureBand,
+		HoldTime:                 time.Duration(cfg.HoldTime) * time.Second,
+		PressureMin:              cfg.PressureMin,
+		PressureMax:              cfg.PressureMax,
+		GaugePressure:            cfg.GaugePressure,
+		SaturationTolerance:      cfg.SaturationTolerance,
+		MaxEquilibrationTime:     time.Duration(cfg.MaxEquilibrationTime) * time.Second,
+		MaxSampleGap:             time.Duration(cfg.MaxSampleGap) * time.Second,
+	}
+}
+
+// FindProfile returns the profile of a program (case-insensitive); false if there is none
+func FindProfile(profiles []config.ValidationProfile, program string) (Profile, bool) {
+	program = strings.TrimSpace(program)
+	for _, profile := range profiles {
+		for _, name := range profile.Programs {
+			if strings.EqualFold(strings.TrimSpace(name), program) {
+				return NewProfile(profile), true
+			}
+		}
+	}
+	return Profile{}, false
+}
+
+// Validate checks the samples of a finished cycle against the profile of its program
+func Validate(cycle *database.Cycle, samples []database.CycleSample, profiles []config.ValidationProfile, now time.Time) database.CycleValidation {
+	validation := database.CycleValidation{CycleID: cycle.ID, ValidatedAt: now}
+
+	profile, ok := FindProfile(profiles, cycle.Program)
+	if !ok {
+		validation.Result = database.ValidationNotValidated
+		validation.Reasons = []database.ValidationReason{{
+			Rule:    RuleProfile,
+			Passed:  false,
+			Message: fmt.Sprintf("No validation profile for program %q", cycle.Program),
+		}}
+		return validation
+	}
+
+	validation.Profile = profile.Name
+	validation.Reasons = Evaluate(profile, samples)
+	validation.Result = database.ValidationPass
+	for _, reason := range validation.Reasons {
+		if !reason.Passed {
+			validation.Result = database.ValidationFail
+		}
+	}
+	return validation
+}
+
+// ValidateCycle validates a finished cycle with the configured profiles and stores the verdict
+func ValidateCycle(cycleID int, cfg config.ValidationConfig) (*database.CycleValidation, error) {
+	cycle, err := database.GetCycle(cycleID)
+	if err != nil {
+		return nil, err
+	}
+	if cycle.EndTS == nil {
+		return nil, fmt.Errorf("cycle %d is still running", cycleID)
+	}
+	samples, err := database.GetCycleSamples(cycleID)
+	if err != nil {
+		return nil, err
+	}
+
+	validation := Validate(cycle, samples, cfg.Profiles, time.Now().Round(0))
+	if err := database.SaveCycleValidation(&validation); err != nil {
+		return nil, err
+	}
+	return &validation, nil
+}
+
+// plateau is a continuous period at or above the sterilisation temperature
+// lead and trail are the sample intervals before and after it in which the temperature
+// crossed the threshold (0 if the plateau starts or ends at the recording or at a gap).
+type plateau struct {
+	samples []database.CycleSample
+	lead    time.Duration
+	trail   time.Duration
+}
+
+// start estimates when the temperature was reached: on average half an interval before
+// the first sample at temperature
+func (p plateau) start() time.Time {
+	return p.samples[0].Timestamp.Add(-p.lead / 2)
+}
+
+// end estimates when the temperature fell below the threshold
+func (p plateau) end() time.Time {
+	return p.samples[len(p.samples)-1].Timestamp.Add(p.trail / 2)
+}
+
+// hold estimates the time at temperature; counting only from the first to the last sample
+// would miss up to one sample interval, so a hold of exactly the minimum could fail
+func (p plateau) hold() time.Duration {
+	if len(p.samples) == 0 {
+		return 0
+	}
+	return p.end().Sub(p.start())
+}
+
+// Hold is the period a cycle held the sterilisation temperature, as counted by Evaluate
+type Hold struct {
+	Start    time.Time
+	End      time.Time
+	Duration time.Duration
+	Samples  int
+}
+
+// FindHold returns the hold of a cycle's samples (oldest first) under the profile of its
+// program; false if the program has no profile or the temperature was not reached
+func FindHold(profiles []config.ValidationProfile, program string, samples []database.CycleSample) (Hold, bool) {
+	profile, ok := FindProfile(profiles, program)
+	if !ok {
+		return Hold{}, false
+	}
+	best, _, ok := findPlateau(profile, samples)
+	if !ok {
+		return Hold{}, false
+	}
+	return Hold{
+		Start:    best.start(),
+		End:      best.end(),
+		Duration: best.hold(),
+		Samples:  len(best.samples),
+	}, true
+}
+
+// findPlateau returns the longest plateau at or above the sterilisation temperature and
+// the estimated time the temperature was first reached; false if it was never reached
+func findPlateau(profile Profile, samples []database.CycleSample) (best plateau, firstReached time.Time, ok bool) {
+	var plateaus []plateau
+	var current plateau
+	var previous *database.CycleSample
+	for i, sample := range samples {
+		if sample.Temperature == nil {
+			continue
+		}
+		reached := *sample.Temperature >= profile.SterilisationTemperature
+		var interval time.Duration
+		if previous != nil {
+			interval = sample.Timestamp.Sub(previous.Timestamp)
+		}
+		gap := interval > profile.MaxSampleGap
+
+		if len(current.samples) > 0 && (!reached || gap) {
+			if !gap {
+				current.trail = interval
+			}
+			plateaus = append(plateaus, current)
+			current = plateau{}
+		}
+		if reached {
+			if len(current.samples) == 0 && !gap {
+				current.lead = interval
+			}
+			current.samples = append(current.samples, sample)
+		}
+		previous = &samples[i]
+	}
+	if len(current.samples) > 0 {
+		plateaus = append(plateaus, current)
+	}
+	if len(plateaus) == 0 {
+		return plateau{}, time.Time{}, false
+	}
+
+	best = plateaus[0]
+	for _, p := range plateaus[1:] {
+		if p.hold() > best.hold() {
+			best = p
+		}
+	}
+	return best, plateaus[0].start(), true
+}
+
+// Evaluate applies the rules of a profile to the samples of a cycle (oldest first)
+// The hold is the longest period in which every sample reached the sterilisation
+// temperature and no two samples were more than MaxSampleGap apart, extended by half a
+// sample interval at each edge where the temperature crossed the threshold; the other
+// rules are checked within that period.
+func Evaluate(profile Profile, samples []database.CycleSample) []database.ValidationReason {
+	var maxTemperature *float64
+	for _, sample := range samples {
+		if sample.Temperature != nil && (maxTemperature == nil || *sample.Temperature > *maxTemperature) {
+			maxTemperature = sample.Temperature
+		}
+	}
+
+	sterilisation := profile.SterilisationTemperature
+	if maxTemperature == nil {
+		return []database.ValidationReason{{
+			Rule:    RuleTemperatureReached,
+			Message: "No temperature values recorded",
+		}}
+	}
+	if *maxTemperature < sterilisation {
+		return []database.ValidationReason{{
+			Rule:    RuleTemperatureReached,
+			Message: fmt.Sprintf("Sterilisation temperature %.1f °C not reached (maximum %.1f °C)", sterilisation, *maxTemperature),
+		}}
+	}
+
+	// Find the first time the temperature was reached and the longest plateau
+	best, firstReached, _ := findPlateau(profile, samples)
+
+	reasons := []database.ValidationReason{{
+		Rule:    RuleTemperatureReached,
+		Passed:  true,
+		Message: fmt.Sprintf("Sterilisation temperature %.1f °C reached at %s", sterilisation, firstReached.Format("15:04:05")),
+	}}
+
+	hold := best.hold()
+	reasons = append(reasons, database.ValidationReason{
+		Rule:   RuleHoldTime,
+		Passed: hold >= profile.HoldTime,
+		Message: fmt.Sprintf("Held at %.1f °C or above for %s (required %s, %d samples)",
+			sterilisation, formatDuration(hold), formatDuration(profile.HoldTime), len(best.samples)),
+	})
+
+	upper := sterilisation + profile.TemperatureBand
+	var plateauMax float64
+	for _, sample := range best.samples {
+		plateauMax = math.Max(plateauMax, *sample.Temperature)
+	}
+	reasons = append(reasons, database.ValidationReason{
+		Rule:    RuleTemperatureBand,
+		Passed:  plateauMax <= upper,
+		Message: fmt.Sprintf("Maximum %.1f °C during the hold (band %.1f-%.1f °C)", plateauMax, sterilisation, upper),
+	})
+
+	if profile.PressureMin > 0 || profile.PressureMax > 0 {
+		reasons = append(reasons, checkPressureBand(profile, best))
+	}
+	if profile.SaturationTolerance > 0 {
+		reasons = append(reasons, checkSaturatedSteam(profile, best))
+	}
+	if profile.MaxEquilibrationTime > 0 {
+		equilibration := best.start().Sub(firstReached)
+		reasons = append(reasons, database.ValidationReason{
+			Rule:   RuleEquilibrationTime,
+			Passed: equilibration <= profile.MaxEquilibrationTime,
+			Message: fmt.Sprintf("Equilibration time %s (maximum %s)",
+				formatDuration(equilibration), formatDuration(profile.MaxEquilibrationTime)),
+		})
+	}
+
+	return reasons
+}
+
+// checkPressureBand checks that every pressure value during the hold is within the band
+func checkPressureBand(profile Profile, hold plateau) database.ValidationReason {
+	reason := database.ValidationReason{Rule: RulePressureBand}
+
+	var min, max *float64
+	missing := 0
+	for _, sample := range hold.samples {
+		if sample.Pressure == nil {
+			missing++
+			continue
+		}
+		if min == nil || *sample.Pressure < *min {
+			min = sample.Pressure
+		}
+		if max == nil || *sample.Pressure > *max {
+			max = sample.Pressure
+		}
+	}
+	band := formatPressureBand(profile)
+	if min == nil {
+		reason.Message = fmt.Sprintf("No pressure values during the hold (band %s)", band)
+		return reason
+	}
+
+	reason.Passed = missing == 0 &&
+		(profile.PressureMin == 0 || *min >= profile.PressureMin) &&
+		(profile.PressureMax == 0 || *max <= profile.PressureMax)
+	reason.Message = fmt.Sprintf("Pressure %.2f-%.2f bar during the hold (band %s)", *min, *max, band)
+	if missing > 0 {
+		reason.Message += fmt.Sprintf(", %d samples without pressure", missing)
+	}
+	return reason
+}
+
+// checkSaturatedSteam compares the measured temperature with the temperature of saturated
+// steam at the measured pressure; lower temperatures indicate air or non-condensable gases,
+// higher ones superheated steam
+func checkSaturatedSteam(profile Profile, hold plateau) database.ValidationReason {
+	reason := database.ValidationReason{Rule: RuleSaturatedSteam}
+
+	var deviation float64
+	compared := 0
+	for _, sample := range hold.samples {
+		if sample.Pressure == nil {
+			continue
+		}
+		pressure := *sample.Pressure
+		if profile.GaugePressure {
+			pressure += atmosphericPressure
+		}
+		if pressure <= 0 {
+			continue
+		}
+		d := *sample.Temperature - SaturationTemperature(pressure)
+		if compared == 0 || math.Abs(d) > math.Abs(deviation) {
+			deviation = d
+		}
+		compared++
+	}
+	if compared == 0 {
+		reason.Message = "No pressure values during the hold to check for saturated steam"
+		return reason
+	}
+
+	reason.Passed = math.Abs(deviation) <= profile.SaturationTolerance
+	reason.Message = fmt.Sprintf("Largest deviation from saturated steam temperature %+.1f K (limit %.1f K)", deviation, profile.SaturationTolerance)
+	if !reason.Passed {
+		if deviation < 0 {
+			reason.Message += ", air or non-condensable gases in the chamber"
+		} else {
+			reason.Message += ", superheated steam"
+		}
+	}
+	return reason
+}
+
+// formatPressureBand formats the pressure limits of a profile
+func formatPressureBand(profile Profile) string {
+	switch {
+	case profile.PressureMin > 0 && profile.PressureMax > 0:
+		return fmt.Sprintf("%.2f-%.2f bar", profile.PressureMin, profile.PressureMax)
+	case profile.PressureMin > 0:
+		return fmt.Sprintf(">= %.2f bar", profile.PressureMin)
+	default:
+		return fmt.Sprintf("<= %.2f bar", profile.PressureMax)
+	}
+}
+
+// formatDuration formats a duration as minutes and seconds, e.g. "3:30 min"
+func formatDuration(d time.Duration) string {
+	seconds := int(d.Round(time.Second).Seconds())
+	return fmt.Sprintf("%d:%02d min", seconds/60, seconds%60)
+}
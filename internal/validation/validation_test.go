@@ -0,0 +1,173 @@
+package validation
+
+import (
+	"math"
+	"strings"
+	"testing"
+	"time"
+
+	"steri-connect-go/internal/config"
+	"steri-connect-go/internal/database"
+)
+
+var testProfile = config.ValidationProfile{
+	Name:                     "134 °C / 3:30 min",
+	Programs:                 []string{"Universal-Programm"},
+	SterilisationTemperature: 134,
+	TemperatureBand:          3,
+	HoldTime:                 210,
+	PressureMin:              3.0,
+	PressureMax:              3.35,
+	SaturationTolerance:      2,
+	MaxEquilibrationTime:     15,
+	MaxSampleGap:             10,
+}
+
+// cycleSamples builds samples every 5 s: 60 s heat-up, hold for the given duration at
+// temperature/pressure, then 60 s drying
+func cycleSamples(hold time.Duration, temperature, pressure float64) []database.CycleSample {
+	start := time.Date(2025, 11, 22, 10, 0, 0, 0, time.UTC)
+	var samples []database.CycleSample
+	add := func(offset time.Duration, phase string, t, p float64) {
+		samples = append(samples, database.CycleSample{Timestamp: start.Add(offset), Phase: phase, Temperature: &t, Pressure: &p})
+	}
+	for offset := time.Duration(0); offset < time.Minute; offset += 5 * time.Second {
+		add(offset, "Aufheizen", 20+float64(offset/time.Second)*1.8, 1+float64(offset/time.Second)*0.03)
+	}
+	for offset := time.Duration(0); offset <= hold; offset += 5 * time.Second {
+		add(time.Minute+offset, "Sterilisation", temperature, pressure)
+	}
+	for offset := 5 * time.Second; offset <= time.Minute; offset += 5 * time.Second {
+		add(time.Minute+hold+offset, "Trocknung", 110, 0.2)
+	}
+	return samples
+}
+
+func failedRules(reasons []database.ValidationReason) []string {
+	var failed []string
+	for _, reason := range reasons {
+		if !reason.Passed {
+			failed = append(failed, reason.Rule)
+		}
+	}
+	return failed
+}
+
+func TestSaturationTemperature(t *testing.T) {
+	tests := []struct {
+		pressure, expected float64
+	}{
+		{1.01325, 99.97},
+		{2.0, 120.21},
+		{3.0, 133.53},
+		{3.04, 133.95},
+	}
+	for _, test := range tests {
+		if got := SaturationTemperature(test.pressure); math.Abs(got-test.expected) > 0.05 {
+			t.Errorf("SaturationTemperature(%.2f) = %.2f, expected %.2f", test.pressure, got, test.expected)
+		}
+	}
+}
+
+func TestEvaluate(t *testing.T) {
+	profile := NewProfile(testProfile)
+
+	tests := []struct {
+		name    string
+		samples []database.CycleSample
+		failed  []string
+	}{
+		{"pass", cycleSamples(4*time.Minute, 134.2, 3.05), nil},
+		{"short hold", cycleSamples(3*time.Minute, 134.2, 3.05), []string{RuleHoldTime}},
+		{"superheated", cycleSamples(4*time.Minute, 137.5, 3.05), []string{RuleTemperatureBand, RuleSaturatedSteam}},
+		{"air", cycleSamples(4*time.Minute, 134.2, 3.3), []string{RuleSaturatedSteam}},
+		{"not reached", cycleSamples(4*time.Minute, 133.5, 3.0), []string{RuleTemperatureReached}},
+	}
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reasons := Evaluate(profile, test.samples)
+			if failed := failedRules(reasons); strings.Join(failed, ",") != strings.Join(test.failed, ",") {
+				t.Errorf("Expected failed rules %v, got %v: %+v", test.failed, failed, reasons)
+			}
+		})
+	}
+
+	// A gap in the recording splits the hold; the longer part starts after the gap
+	samples := cycleSamples(4*time.Minute, 134.2, 3.05)
+	gapped := append(append([]database.CycleSample{}, samples[:30]...), samples[33:]...)
+	reasons := Evaluate(profile, gapped)
+	if failed := failedRules(reasons); strings.Join(failed, ",") != RuleHoldTime+","+RuleEquilibrationTime {
+		t.Errorf("Expected hold_time and equilibration_time to fail on a recording gap, got %v: %+v", failed, reasons)
+	}
+}
+
+func TestEvaluateHoldOfExactlyTheMinimum(t *testing.T) {
+	profile := NewProfile(testProfile)
+	start := time.Date(2025, 11, 22, 10, 0, 0, 0, time.UTC)
+
+	// Samples every 2 s; the chamber is at temperature from 61 s to 271 s, exactly 3:30 min,
+	// so the first and last sample at temperature are only 3:28 min apart
+	temperature := func(offset time.Duration) float64 {
+		if offset > 61*time.Second && offset < 271*time.Second {
+			return 134.2
+		}
+		return 130
+	}
+	var samples []database.CycleSample
+	for offset := time.Duration(0); offset <= 330*time.Second; offset += 2 * time.Second {
+		t, p := temperature(offset), 3.05
+		samples = append(samples, database.CycleSample{Timestamp: start.Add(offset), Phase: "Sterilisation", Temperature: &t, Pressure: &p})
+	}
+
+	reasons := Evaluate(profile, samples)
+	if failed := failedRules(reasons); len(failed) != 0 {
+		t.Errorf("Expected a hold of exactly the minimum to pass, failed %v: %+v", failed, reasons)
+	}
+
+	// One interval less is too short
+	short := append([]database.CycleSample{}, samples...)
+	for i := range short {
+		if short[i].Timestamp.Equal(start.Add(270 * time.Second)) {
+			cool := 130.0
+			short[i].Temperature = &cool
+		}
+	}
+	if failed := failedRules(Evaluate(profile, short)); strings.Join(failed, ",") != RuleHoldTime {
+		t.Errorf("Expected hold_time to fail for a 3:28 min hold, got %v", failed)
+	}
+}
+
+func TestValidate(t *testing.T) {
+	profiles := []config.ValidationProfile{testProfile}
+	now := time.Now()
+	samples := cycleSamples(4*time.Minute, 134.2, 3.05)
+
+	result := Validate(&database.Cycle{ID: 7, Program: "universal-programm"}, samples, profiles, now)
+	if result.CycleID != 7 || result.Result != database.ValidationPass || result.Profile != testProfile.Name {
+		t.Errorf("Expected PASS with profile %q, got %+v", testProfile.Name, result)
+	}
+
+	result = Validate(&database.Cycle{ID: 8, Program: "Universal-Programm"}, cycleSamples(time.Minute, 134.2, 3.05), profiles, now)
+	if result.Result != database.ValidationFail {
+		t.Errorf("Expected FAIL for a short hold, got %+v", result)
+	}
+
+	result = Validate(&database.Cycle{ID: 9, Program: "Bowie-Dick-Test"}, samples, profiles, now)
+	if result.Result != database.ValidationNotValidated || result.Profile != "" || len(result.Reasons) != 1 {
+		t.Errorf("Expected NOT_VALIDATED without profile, got %+v", result)
+	}
+}
+
+func TestFindHold(t *testing.T) {
+	profiles := []config.ValidationProfile{testProfile}
+	samples := cycleSamples(4*time.Minute, 134.2, 3.05)
+
+	// Half of the 5 s intervals at the edges count as hold
+	hold, ok := FindHold(profiles, "Universal-Programm", samples)
+	if !ok || hold.Duration != 4*time.Minute+5*time.Second || hold.Start != samples[12].Timestamp.Add(-2500*time.Millisecond) || hold.Samples != 49 {
+		t.Errorf("Expected 4:05 min hold from half an interval before the first sample at temperature, got %+v, %v", hold, ok)
+	}
+	if _, ok := FindHold(profiles, "Bowie-Dick-Test", samples); ok {
+		t.Errorf("Expected no hold for a program without profile")
+	}
+}